@@ -0,0 +1,341 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/chuckie/goinsight/internal/cache"
+	"github.com/chuckie/goinsight/internal/config"
+	"github.com/chuckie/goinsight/internal/db"
+	apihttp "github.com/chuckie/goinsight/internal/http"
+	"github.com/chuckie/goinsight/internal/jira"
+	"github.com/chuckie/goinsight/internal/llm"
+	"github.com/chuckie/goinsight/internal/profiler"
+	"github.com/chuckie/goinsight/internal/repository"
+	"github.com/chuckie/goinsight/internal/service"
+	"github.com/chuckie/goinsight/internal/sqlguard"
+	"github.com/chuckie/goinsight/internal/tracker"
+)
+
+func main() {
+	if err := run(); err != nil {
+		log.Fatalf("Server error: %v", err)
+	}
+}
+
+// run wires all application components, serves HTTP until SIGINT/SIGTERM,
+// then drains in-flight requests and releases resources in reverse order
+func run() error {
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	// Connect to database (retries while the database starts up)
+	dbClient, err := db.NewClient(cfg.DatabaseURL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer func() {
+		if err := dbClient.Close(); err != nil {
+			log.Printf("Failed to close database: %v", err)
+		}
+		fmt.Println("Database connection closed")
+	}()
+
+	queryLimits := db.QueryLimits{
+		StatementTimeout: cfg.QueryStatementTimeout,
+		LockTimeout:      cfg.QueryLockTimeout,
+		WorkMem:          cfg.QueryWorkMem,
+	}
+	if err := dbClient.SetQueryLimits(queryLimits); err != nil {
+		return fmt.Errorf("invalid query limits: %w", err)
+	}
+
+	// Apply pending migrations
+	if err := db.RunMigrations(dbClient.DB(), cfg.MigrationsDir); err != nil {
+		return fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	// Initialize repository layer
+	repos := repository.NewRepositories(dbClient.DB())
+	repoConfig := repository.DefaultRepositoryConfig()
+	repoConfig.QueryLimits = queryLimits
+	if err := repos.ApplyConfig(repoConfig); err != nil {
+		return fmt.Errorf("failed to configure repositories: %w", err)
+	}
+	if err := repos.ValidateConnection(); err != nil {
+		return fmt.Errorf("repository connection validation failed: %w", err)
+	}
+	fmt.Printf("Repository layer initialized (max: %d open, %d idle)\n",
+		repoConfig.MaxOpenConnections, repoConfig.MaxIdleConnections)
+	fmt.Printf("Generated queries run read-only (statement_timeout: %v, lock_timeout: %v, work_mem: %s)\n",
+		queryLimits.StatementTimeout, queryLimits.LockTimeout, queryLimits.WorkMem)
+
+	// Background work (e.g. schema refresh) stops when run returns
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+
+	// Load the schema catalog used to build the SQL generation prompt
+	schemaCatalog := db.NewSchemaCatalog(dbClient.DB(), sqlguard.DefaultAllowedTables)
+	if err := schemaCatalog.Refresh(backgroundCtx); err != nil {
+		log.Printf("Schema catalog unavailable, using built-in schema: %v", err)
+	} else {
+		fmt.Printf("Schema catalog loaded (%d tables, refresh every %v)\n",
+			len(schemaCatalog.Tables()), cfg.SchemaRefreshInterval)
+	}
+	schemaCatalog.Start(backgroundCtx, cfg.SchemaRefreshInterval)
+
+	// Initialize the LLM provider chain; later providers are used when
+	// earlier ones time out, rate-limit or return server errors
+	providers := make([]llm.Provider, 0, len(cfg.LLMProviders))
+	for _, name := range cfg.LLMProviders {
+		client, err := newLLMClient(cfg, name)
+		if err != nil {
+			return err
+		}
+		providers = append(providers, llm.Provider{Name: name, Client: client})
+		fmt.Printf("LLM provider: %s (model: %s)\n", name, cfg.ModelFor(name))
+	}
+	llmClient, err := llm.NewFallbackClient(providers, cfg.LLMCooldown)
+	if err != nil {
+		return fmt.Errorf("failed to create LLM client: %w", err)
+	}
+	llmClient.SetSchemaProvider(schemaCatalog)
+
+	// Initialize the issue tracker for /api/jira-tickets (optional)
+	issueTracker, err := newIssueTracker(cfg)
+	if err != nil {
+		return err
+	}
+
+	// Initialize profiler
+	profilerConfig := profiler.DefaultConfig()
+	profilerConfig.EnableConsoleLogging = cfg.Debug
+	if cfg.Debug {
+		profilerConfig.MinLogLevel = profiler.DEBUG
+	}
+	profilerComponents, err := profiler.InitializeProfiler(profilerConfig)
+	if err != nil {
+		return fmt.Errorf("failed to initialize profiler: %w", err)
+	}
+	defer func() {
+		if err := profilerComponents.Cleanup(); err != nil {
+			log.Printf("Failed to clean up profiler: %v", err)
+		}
+	}()
+
+	// Initialize cache manager
+	cacheConfig := cache.DefaultCacheConfig()
+	cacheConfig.Backend = cfg.CacheBackend
+	cacheConfig.RedisURL = cfg.RedisURL
+	cacheConfig.Namespace = cfg.CacheNamespace
+	cacheConfig.MaxBytes = cfg.CacheMaxBytes
+	cacheConfig.StaleGrace = cfg.CacheStaleGrace
+	cacheConfig.RefreshWorkers = cfg.CacheRefreshWorkers
+	cacheManager, err := cache.NewCacheManagerFromConfig(backgroundCtx, cacheConfig)
+	if err != nil {
+		return fmt.Errorf("failed to initialize cache: %w", err)
+	}
+	defer func() {
+		if err := cacheManager.Close(); err != nil {
+			log.Printf("Failed to close cache: %v", err)
+		}
+		fmt.Println("Query result cache closed")
+	}()
+	if cacheConfig.Backend == cache.BackendRedis {
+		fmt.Printf("Query Result Cache enabled (redis, namespace: %s, ttl: %v)\n",
+			cacheConfig.Namespace, cacheConfig.DefaultTTL)
+	} else {
+		fmt.Printf("Query Result Cache enabled (max entries: %d, max bytes: %d, ttl: %v)\n",
+			cacheConfig.MaxSize, cacheConfig.MaxBytes, cacheConfig.DefaultTTL)
+	}
+	if cacheConfig.StaleGrace > 0 {
+		fmt.Printf("Stale answers served for %v while refreshed (%d workers)\n",
+			cacheConfig.StaleGrace, cacheConfig.RefreshWorkers)
+	}
+
+	// Build service layer and HTTP handler
+	feedbackService := service.NewFeedbackServiceFull(
+		repos.Feedback,
+		llmClient,
+		issueTracker,
+		profilerComponents.Logger,
+		profilerComponents.QueryProfiler,
+		profilerComponents.SlowQueryLog,
+		profilerComponents.QueryOptimizer,
+		cacheManager,
+	)
+	feedbackService.SetMaxQueryRows(cfg.MaxQueryRows)
+	feedbackService.SetMaxRepairAttempts(cfg.SQLRepairAttempts)
+	feedbackService.SetSchemaProvider(schemaCatalog)
+	if cfg.ConversationTurns > 0 {
+		feedbackService.SetConversationRepository(repos.Conversations)
+		feedbackService.SetConversationTurns(cfg.ConversationTurns)
+	}
+	feedbackService.SetJiraDraftRepository(repos.JiraDrafts)
+	feedbackService.SetJiraDraftTTL(cfg.JiraDraftTTL)
+	feedbackService.SetAnalysisRepository(repos.Analyses)
+	feedbackService.SetInsightTicketRepository(repos.InsightTickets)
+	feedbackService.StartTicketSync(backgroundCtx, cfg.JiraSyncInterval)
+	if cfg.SemanticCacheThreshold > 0 {
+		embedder, err := newEmbedder(cfg)
+		if err != nil {
+			return err
+		}
+		feedbackService.SetSemanticCache(embedder, cfg.SemanticCacheThreshold)
+		fmt.Printf("Semantic cache enabled (embeddings: %s, threshold: %.2f)\n",
+			cfg.EmbeddingProvider, cfg.SemanticCacheThreshold)
+	}
+	handler := apihttp.NewServiceHandler(feedbackService, issueTracker)
+	handler.SetJiraWebhookSecret(cfg.JiraWebhookSecret)
+
+	server := &http.Server{
+		Addr:              ":" + cfg.Port,
+		Handler:           apihttp.NewRouter(handler),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// Listen for shutdown signals
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		fmt.Printf("Server listening on :%s (env: %s)\n", cfg.Port, cfg.Env)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+		close(serverErr)
+	}()
+
+	select {
+	case err := <-serverErr:
+		if err != nil {
+			return fmt.Errorf("server failed: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+	}
+
+	// Stop accepting new connections and wait for in-flight requests
+	// (e.g. long-running /api/ask LLM calls) to finish
+	fmt.Printf("Shutdown signal received, draining in-flight requests (timeout: %v)...\n", cfg.ShutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Graceful shutdown did not complete: %v", err)
+		_ = server.Close()
+	} else {
+		fmt.Println("All in-flight requests completed")
+	}
+
+	return nil
+}
+
+// newIssueTracker builds the issue tracker selected by ISSUE_TRACKER, or
+// returns nil if its credentials are not set. It fails if the Jira field
+// mapping file cannot be loaded.
+func newIssueTracker(cfg *config.Config) (tracker.IssueTracker, error) {
+	switch cfg.IssueTracker {
+	case tracker.GitHub:
+		if cfg.GitHubToken == "" || cfg.GitHubRepository == "" {
+			fmt.Println("Issue tracker disabled (GITHUB_TOKEN, GITHUB_REPOSITORY not set)")
+			return nil, nil
+		}
+		fmt.Printf("GitHub Issues integration enabled (%s)\n", cfg.GitHubRepository)
+		return tracker.NewGitHubClient(cfg.GitHubAPIURL, cfg.GitHubToken, cfg.GitHubRepository), nil
+
+	case tracker.Linear:
+		if cfg.LinearAPIKey == "" || cfg.LinearTeamID == "" {
+			fmt.Println("Issue tracker disabled (LINEAR_API_KEY, LINEAR_TEAM_ID not set)")
+			return nil, nil
+		}
+		fmt.Printf("Linear integration enabled (team %s)\n", cfg.LinearTeamID)
+		return tracker.NewLinearClient(cfg.LinearAPIURL, cfg.LinearAPIKey, cfg.LinearTeamID), nil
+
+	default:
+		if cfg.JiraBaseURL == "" || cfg.JiraEmail == "" || cfg.JiraAPIToken == "" {
+			fmt.Println("Jira integration disabled (JIRA_BASE_URL, JIRA_EMAIL, JIRA_API_TOKEN not set)")
+			return nil, nil
+		}
+		jiraClient := jira.NewClient(cfg.JiraBaseURL, cfg.JiraEmail, cfg.JiraAPIToken, cfg.JiraProjectKey)
+		jiraClient.SetAPIVersion(cfg.JiraAPIVersion)
+		jiraClient.SetDuplicateThreshold(cfg.JiraDuplicateThreshold)
+		jiraClient.SetEpicFields(cfg.JiraEpicLinkField, cfg.JiraEpicNameField)
+		jiraClient.SetAnalysisURL(cfg.PublicBaseURL)
+		jiraClient.SetConcurrency(cfg.JiraConcurrency)
+		jiraClient.SetMaxRetries(cfg.JiraMaxRetries)
+		if cfg.JiraFieldMappingFile != "" {
+			mapping, err := jira.LoadFieldMapping(cfg.JiraFieldMappingFile)
+			if err != nil {
+				return nil, err
+			}
+			jiraClient.SetFieldMapping(mapping)
+		}
+		fmt.Printf("Jira integration enabled (%s)\n", cfg.JiraBaseURL)
+		return jiraClient, nil
+	}
+}
+
+// newLLMClient creates the LLM client for a provider
+func newLLMClient(cfg *config.Config, provider string) (llm.Client, error) {
+	model := cfg.ModelFor(provider)
+
+	var client llm.Client
+	switch provider {
+	case "openai":
+		client = llm.NewOpenAIClient(cfg.OpenAIAPIKey, model)
+	case "groq":
+		client = llm.NewGroqClient(cfg.GroqAPIKey, model)
+	case "ollama":
+		client = llm.NewOllamaClient(cfg.OllamaURL, model)
+	case "mock":
+		client = llm.NewMockClient()
+	default:
+		return nil, fmt.Errorf("unsupported LLM provider: %s", provider)
+	}
+
+	if retryAware, ok := client.(llm.RetryAware); ok {
+		retryAware.SetRetryPolicy(llmRetryPolicy(cfg))
+	}
+	return client, nil
+}
+
+// newEmbedder creates the embedder used for semantic cache lookups
+func newEmbedder(cfg *config.Config) (llm.Embedder, error) {
+	var embedder llm.Embedder
+	switch cfg.EmbeddingProvider {
+	case "openai":
+		embedder = llm.NewOpenAIEmbedder(cfg.OpenAIAPIKey, cfg.EmbeddingModel)
+	case "ollama":
+		embedder = llm.NewOllamaEmbedder(cfg.OllamaURL, cfg.EmbeddingModel)
+	case "mock":
+		embedder = llm.NewMockEmbedder()
+	default:
+		return nil, fmt.Errorf("unsupported embedding provider: %s", cfg.EmbeddingProvider)
+	}
+
+	if retryAware, ok := embedder.(llm.RetryAware); ok {
+		retryAware.SetRetryPolicy(llmRetryPolicy(cfg))
+	}
+	return embedder, nil
+}
+
+// llmRetryPolicy returns the configured retry policy for LLM requests
+func llmRetryPolicy(cfg *config.Config) llm.RetryPolicy {
+	return llm.RetryPolicy{
+		MaxRetries: cfg.LLMMaxRetries,
+		BaseDelay:  cfg.LLMRetryBaseDelay,
+		MaxDelay:   cfg.LLMRetryMaxDelay,
+	}
+}
@@ -1,159 +1,243 @@
-package cache
-
-import (
-	"context"
-	"time"
-)
-
-// CacheManager provides high-level cache operations for query results
-// Handles caching strategy, TTL management, and invalidation
-type CacheManager struct {
-	cache      Cache
-	enabled    bool
-	defaultTTL time.Duration
-	maxSize    int64
-}
-
-// NewCacheManager creates a new cache manager instance
-// Parameters:
-//   - enabled: Whether caching is active
-//   - maxSize: Maximum number of entries in cache
-//   - defaultTTL: Default time-to-live for cached entries
-func NewCacheManager(enabled bool, maxSize int64, defaultTTL time.Duration) *CacheManager {
-	if defaultTTL == 0 {
-		defaultTTL = 5 * time.Minute
-	}
-
-	var cache Cache
-	if enabled {
-		cache = NewMemoryCache(maxSize, defaultTTL)
-	}
-
-	return &CacheManager{
-		cache:      cache,
-		enabled:    enabled,
-		defaultTTL: defaultTTL,
-		maxSize:    maxSize,
-	}
-}
-
-// IsCacheEnabled checks if caching is enabled
-func (cm *CacheManager) IsCacheEnabled() bool {
-	return cm.enabled && cm.cache != nil
-}
-
-// CacheQueryResult stores query results in cache
-func (cm *CacheManager) CacheQueryResult(ctx context.Context, query string, results interface{}, ttl time.Duration) error {
-	if !cm.IsCacheEnabled() {
-		return nil
-	}
-
-	if ttl == 0 {
-		ttl = cm.defaultTTL
-	}
-
-	if memCache, ok := cm.cache.(*MemoryCache); ok {
-		return memCache.CacheQuery(ctx, query, results, ttl)
-	}
-
-	return cm.cache.Set(ctx, query, results, ttl)
-}
-
-// GetCachedQueryResult retrieves cached query results
-// Returns (results, found, error)
-func (cm *CacheManager) GetCachedQueryResult(ctx context.Context, query string) (interface{}, bool, error) {
-	if !cm.IsCacheEnabled() {
-		return nil, false, nil
-	}
-
-	if memCache, ok := cm.cache.(*MemoryCache); ok {
-		results, err := memCache.GetCachedQuery(ctx, query)
-		if err != nil {
-			// Key not found is not an error for this use case
-			if cacheErr, ok := err.(*CacheError); ok && cacheErr.Code == ErrKeyNotFound {
-				return nil, false, nil
-			}
-			return nil, false, err
-		}
-		return results, true, nil
-	}
-
-	results, err := cm.cache.Get(ctx, query)
-	if err != nil {
-		if cacheErr, ok := err.(*CacheError); ok && cacheErr.Code == ErrKeyNotFound {
-			return nil, false, nil
-		}
-		return nil, false, err
-	}
-
-	return results, true, nil
-}
-
-// InvalidateQuery removes a specific cached query
-func (cm *CacheManager) InvalidateQuery(ctx context.Context, query string) error {
-	if !cm.IsCacheEnabled() {
-		return nil
-	}
-
-	if memCache, ok := cm.cache.(*MemoryCache); ok {
-		return memCache.InvalidateQuery(ctx, query)
-	}
-
-	return cm.cache.Delete(ctx, query)
-}
-
-// InvalidatePattern removes cached queries matching a pattern
-// Useful for: Invalidating all queries involving a table after UPDATE/DELETE
-func (cm *CacheManager) InvalidatePattern(ctx context.Context, pattern string) error {
-	if !cm.IsCacheEnabled() {
-		return nil
-	}
-
-	if memCache, ok := cm.cache.(*MemoryCache); ok {
-		return memCache.InvalidatePattern(ctx, pattern)
-	}
-
-	return nil
-}
-
-// ClearCache removes all cached entries
-func (cm *CacheManager) ClearCache(ctx context.Context) error {
-	if !cm.IsCacheEnabled() {
-		return nil
-	}
-
-	return cm.cache.Clear(ctx)
-}
-
-// GetCacheStats returns current cache statistics
-func (cm *CacheManager) GetCacheStats(ctx context.Context) CacheStats {
-	if !cm.IsCacheEnabled() {
-		return CacheStats{}
-	}
-
-	return cm.cache.GetStats(ctx)
-}
-
-// Close closes the cache manager
-func (cm *CacheManager) Close() error {
-	if cm.cache != nil {
-		return cm.cache.Close()
-	}
-	return nil
-}
-
-// CacheConfig holds configuration for caching
-type CacheConfig struct {
-	Enabled    bool
-	MaxSize    int64
-	DefaultTTL time.Duration
-}
-
-// DefaultCacheConfig returns sensible default cache configuration
-func DefaultCacheConfig() CacheConfig {
-	return CacheConfig{
-		Enabled:    true,
-		MaxSize:    1000,         // Max 1000 cached queries
-		DefaultTTL: 5 * time.Minute, // 5 minute default TTL
-	}
-}
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// CacheManager provides high-level cache operations for query results
+// Handles caching strategy, TTL management, and invalidation
+type CacheManager struct {
+	cache      Cache
+	enabled    bool
+	defaultTTL time.Duration
+	maxSize    int64
+
+	// Stale-while-revalidate (nil refreshes when disabled)
+	staleGrace time.Duration
+	refreshes  *refreshPool
+	staleHits  atomic.Int64
+}
+
+// NewCacheManager creates a new cache manager instance
+// Parameters:
+//   - enabled: Whether caching is active
+//   - maxSize: Maximum number of entries in cache
+//   - defaultTTL: Default time-to-live for cached entries
+func NewCacheManager(enabled bool, maxSize int64, defaultTTL time.Duration) *CacheManager {
+	if defaultTTL == 0 {
+		defaultTTL = 5 * time.Minute
+	}
+
+	var cache Cache
+	if enabled {
+		cache = NewMemoryCache(maxSize, defaultTTL)
+	}
+
+	return &CacheManager{
+		cache:      cache,
+		enabled:    enabled,
+		defaultTTL: defaultTTL,
+		maxSize:    maxSize,
+	}
+}
+
+// NewCacheManagerWithCache creates a cache manager on an existing cache, such
+// as a RedisCache shared by several API replicas
+func NewCacheManagerWithCache(cache Cache, defaultTTL time.Duration) *CacheManager {
+	if defaultTTL == 0 {
+		defaultTTL = 5 * time.Minute
+	}
+
+	return &CacheManager{
+		cache:      cache,
+		enabled:    cache != nil,
+		defaultTTL: defaultTTL,
+	}
+}
+
+// IsCacheEnabled checks if caching is enabled
+func (cm *CacheManager) IsCacheEnabled() bool {
+	return cm.enabled && cm.cache != nil
+}
+
+// CacheQueryResult stores query results in cache
+func (cm *CacheManager) CacheQueryResult(ctx context.Context, query string, results interface{}, ttl time.Duration) error {
+	if !cm.IsCacheEnabled() {
+		return nil
+	}
+
+	if ttl == 0 {
+		ttl = cm.defaultTTL
+	}
+
+	if memCache, ok := cm.cache.(*MemoryCache); ok {
+		return memCache.CacheQuery(ctx, query, results, ttl)
+	}
+
+	return cm.cache.Set(ctx, query, results, ttl)
+}
+
+// GetCachedQueryResult retrieves cached query results
+// Returns (results, found, error)
+func (cm *CacheManager) GetCachedQueryResult(ctx context.Context, query string) (interface{}, bool, error) {
+	if !cm.IsCacheEnabled() {
+		return nil, false, nil
+	}
+
+	if memCache, ok := cm.cache.(*MemoryCache); ok {
+		results, err := memCache.GetCachedQuery(ctx, query)
+		if err != nil {
+			// Key not found is not an error for this use case
+			if cacheErr, ok := err.(*CacheError); ok && cacheErr.Code == ErrKeyNotFound {
+				return nil, false, nil
+			}
+			return nil, false, err
+		}
+		return results, true, nil
+	}
+
+	results, err := cm.cache.Get(ctx, query)
+	if err != nil {
+		if cacheErr, ok := err.(*CacheError); ok && cacheErr.Code == ErrKeyNotFound {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	return results, true, nil
+}
+
+// InvalidateQuery removes a specific cached query
+func (cm *CacheManager) InvalidateQuery(ctx context.Context, query string) error {
+	if !cm.IsCacheEnabled() {
+		return nil
+	}
+
+	if memCache, ok := cm.cache.(*MemoryCache); ok {
+		return memCache.InvalidateQuery(ctx, query)
+	}
+
+	return cm.cache.Delete(ctx, query)
+}
+
+// InvalidatePattern removes cached queries matching a pattern
+// Useful for: Invalidating all queries involving a table after UPDATE/DELETE
+func (cm *CacheManager) InvalidatePattern(ctx context.Context, pattern string) error {
+	if !cm.IsCacheEnabled() {
+		return nil
+	}
+
+	return cm.cache.InvalidatePattern(ctx, pattern)
+}
+
+// ClearCache removes all cached entries
+func (cm *CacheManager) ClearCache(ctx context.Context) error {
+	if !cm.IsCacheEnabled() {
+		return nil
+	}
+
+	return cm.cache.Clear(ctx)
+}
+
+// GetCacheStats returns current cache statistics
+func (cm *CacheManager) GetCacheStats(ctx context.Context) CacheStats {
+	if !cm.IsCacheEnabled() {
+		return CacheStats{}
+	}
+
+	stats := cm.cache.GetStats(ctx)
+	stats.StaleHits = cm.staleHits.Load()
+	if cm.refreshes != nil {
+		stats.Refreshes = cm.refreshes.completed.Load()
+		stats.RefreshFailures = cm.refreshes.failed.Load()
+		stats.RefreshesDropped = cm.refreshes.dropped.Load()
+	}
+	return stats
+}
+
+// Close closes the cache manager, cancelling background refreshes
+func (cm *CacheManager) Close() error {
+	if cm.refreshes != nil {
+		cm.refreshes.close()
+	}
+	if cm.cache != nil {
+		return cm.cache.Close()
+	}
+	return nil
+}
+
+// Cache backends, selected with the CACHE_BACKEND setting
+const (
+	BackendMemory = "memory"
+	BackendRedis  = "redis"
+)
+
+// DefaultMaxBytes is the default memory limit of the memory backend
+const DefaultMaxBytes = 64 << 20 // 64 MiB
+
+// CacheConfig holds configuration for caching
+type CacheConfig struct {
+	Enabled    bool
+	Backend    string // memory or redis
+	MaxSize    int64  // memory backend only
+	MaxBytes   int64  // approximate memory limit of the memory backend (0 = unlimited)
+	DefaultTTL time.Duration
+	RedisURL   string // redis backend only
+	Namespace  string // prefix of redis keys
+
+	// Stale-while-revalidate: how long after expiring an entry is still
+	// served while it is refreshed (0 disables), and the refreshes run at once
+	StaleGrace     time.Duration
+	RefreshWorkers int
+}
+
+// DefaultCacheConfig returns sensible default cache configuration
+func DefaultCacheConfig() CacheConfig {
+	return CacheConfig{
+		Enabled:    true,
+		Backend:    BackendMemory,
+		MaxSize:    1000,         // Max 1000 cached queries
+		MaxBytes:   DefaultMaxBytes,
+		DefaultTTL: 5 * time.Minute, // 5 minute default TTL
+		RefreshWorkers: DefaultRefreshWorkers,
+	}
+}
+
+// NewCacheManagerFromConfig creates the cache manager for the configured
+// backend; the redis backend fails if the server cannot be reached
+func NewCacheManagerFromConfig(ctx context.Context, config CacheConfig) (*CacheManager, error) {
+	cm, err := newCacheManagerForBackend(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	if cm.IsCacheEnabled() && config.StaleGrace > 0 {
+		cm.SetStaleWhileRevalidate(config.StaleGrace, config.RefreshWorkers)
+	}
+	return cm, nil
+}
+
+// newCacheManagerForBackend creates the cache manager for config.Backend
+func newCacheManagerForBackend(ctx context.Context, config CacheConfig) (*CacheManager, error) {
+	switch config.Backend {
+	case "", BackendMemory:
+		if !config.Enabled {
+			return NewCacheManagerWithCache(nil, config.DefaultTTL), nil
+		}
+		memoryCache := NewMemoryCacheWithMaxBytes(config.MaxSize, config.MaxBytes, config.DefaultTTL)
+		return NewCacheManagerWithCache(memoryCache, config.DefaultTTL), nil
+	case BackendRedis:
+		if !config.Enabled {
+			return NewCacheManagerWithCache(nil, config.DefaultTTL), nil
+		}
+		redisCache, err := NewRedisCache(ctx, config.RedisURL, config.Namespace, config.DefaultTTL)
+		if err != nil {
+			return nil, err
+		}
+		return NewCacheManagerWithCache(redisCache, config.DefaultTTL), nil
+	default:
+		return nil, fmt.Errorf("unknown cache backend %q (must be %s or %s)", config.Backend, BackendMemory, BackendRedis)
+	}
+}
@@ -1,363 +1,353 @@
-package cache
-
-import (
-	"context"
-	"crypto/md5"
-	"fmt"
-	"sync"
-	"time"
-)
-
-// MemoryCache implements an in-memory caching layer with TTL support
-// Algorithm:
-// 1. Hash-based lookup: O(1) average case for Get/Set
-// 2. TTL expiration: Background cleanup on access + periodic sweeps
-// 3. Circular buffer for LRU eviction when max size exceeded
-// 4. Thread-safe with read-write locks for concurrent access
-type MemoryCache struct {
-	mu sync.RWMutex
-
-	// Main cache storage: hash(key) -> CacheEntry
-	data map[string]*CacheEntry
-
-	// TTL tracking for expiration detection
-	expirations map[string]time.Time
-
-	// LRU tracking for eviction
-	accessTimes map[string]time.Time
-
-	// Cache configuration
-	maxSize      int64
-	currentSize  int64
-	defaultTTL   time.Duration
-	cleanupTimer *time.Timer
-
-	// Statistics
-	stats CacheStats
-
-	// Query result specific tracking
-	queryHashes map[string]string // query -> hash mapping for invalidation
-}
-
-// NewMemoryCache creates a new in-memory cache instance
-// Parameters:
-//   - maxSize: Maximum number of entries (0 = unlimited)
-//   - defaultTTL: Default time-to-live for entries
-func NewMemoryCache(maxSize int64, defaultTTL time.Duration) *MemoryCache {
-	if defaultTTL == 0 {
-		defaultTTL = 5 * time.Minute // Default 5 minutes
-	}
-
-	mc := &MemoryCache{
-		data:         make(map[string]*CacheEntry),
-		expirations:  make(map[string]time.Time),
-		accessTimes:  make(map[string]time.Time),
-		maxSize:      maxSize,
-		defaultTTL:   defaultTTL,
-		queryHashes:  make(map[string]string),
-		cleanupTimer: time.NewTimer(1 * time.Minute),
-	}
-
-	// Start background cleanup goroutine
-	go mc.cleanupExpired()
-
-	return mc
-}
-
-// Set stores a value in the cache with TTL
-func (mc *MemoryCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
-	if key == "" {
-		return NewCacheError(ErrInvalidKey, "cache key cannot be empty", nil)
-	}
-
-	if ttl == 0 {
-		ttl = mc.defaultTTL
-	}
-
-	if ttl < 0 {
-		return NewCacheError(ErrInvalidTTL, "TTL must be positive", nil)
-	}
-
-	mc.mu.Lock()
-	defer mc.mu.Unlock()
-
-	// Check if key already exists
-	_, exists := mc.data[key]
-
-	// If key doesn't exist and we're at capacity, evict LRU
-	if !exists && mc.maxSize > 0 && int64(len(mc.data)) >= mc.maxSize {
-		mc.evictLRU()
-	}
-
-	now := time.Now()
-	expiresAt := now.Add(ttl)
-
-	entry := &CacheEntry{
-		Data:      value,
-		Timestamp: now,
-		TTL:       ttl,
-		ExpiresAt: expiresAt,
-	}
-
-	// Store entry
-	mc.data[key] = entry
-	mc.expirations[key] = expiresAt
-	mc.accessTimes[key] = now
-	
-	// Only increment size if this is a new key
-	if !exists {
-		mc.currentSize++
-	}
-
-	return nil
-}
-
-// Get retrieves a value from the cache
-func (mc *MemoryCache) Get(ctx context.Context, key string) (interface{}, error) {
-	if key == "" {
-		return nil, NewCacheError(ErrInvalidKey, "cache key cannot be empty", nil)
-	}
-
-	mc.mu.RLock()
-	entry, exists := mc.data[key]
-	mc.mu.RUnlock()
-
-	if !exists {
-		mc.mu.Lock()
-		mc.stats.Misses++
-		mc.mu.Unlock()
-		return nil, NewCacheError(ErrKeyNotFound, fmt.Sprintf("key '%s' not found in cache", key), nil)
-	}
-
-	// Check if expired
-	if time.Now().After(entry.ExpiresAt) {
-		mc.mu.Lock()
-		delete(mc.data, key)
-		delete(mc.expirations, key)
-		delete(mc.accessTimes, key)
-		mc.stats.Evictions++
-		mc.mu.Unlock()
-		return nil, NewCacheError(ErrKeyNotFound, fmt.Sprintf("key '%s' has expired", key), nil)
-	}
-
-	// Update access time for LRU
-	mc.mu.Lock()
-	mc.accessTimes[key] = time.Now()
-	mc.stats.Hits++
-	mc.mu.Unlock()
-
-	return entry.Data, nil
-}
-
-// Delete removes a value from the cache
-func (mc *MemoryCache) Delete(ctx context.Context, key string) error {
-	if key == "" {
-		return NewCacheError(ErrInvalidKey, "cache key cannot be empty", nil)
-	}
-
-	mc.mu.Lock()
-	defer mc.mu.Unlock()
-
-	if _, exists := mc.data[key]; !exists {
-		return NewCacheError(ErrKeyNotFound, fmt.Sprintf("key '%s' not found in cache", key), nil)
-	}
-
-	delete(mc.data, key)
-	delete(mc.expirations, key)
-	delete(mc.accessTimes, key)
-	mc.currentSize--
-
-	return nil
-}
-
-// Exists checks if a key exists and is not expired
-func (mc *MemoryCache) Exists(ctx context.Context, key string) (bool, error) {
-	if key == "" {
-		return false, NewCacheError(ErrInvalidKey, "cache key cannot be empty", nil)
-	}
-
-	mc.mu.RLock()
-	entry, exists := mc.data[key]
-	mc.mu.RUnlock()
-
-	if !exists {
-		return false, nil
-	}
-
-	// Check if expired
-	if time.Now().After(entry.ExpiresAt) {
-		_ = mc.Delete(ctx, key)
-		return false, nil
-	}
-
-	return true, nil
-}
-
-// Clear removes all entries from the cache
-func (mc *MemoryCache) Clear(ctx context.Context) error {
-	mc.mu.Lock()
-	defer mc.mu.Unlock()
-
-	mc.data = make(map[string]*CacheEntry)
-	mc.expirations = make(map[string]time.Time)
-	mc.accessTimes = make(map[string]time.Time)
-	mc.queryHashes = make(map[string]string)
-	mc.currentSize = 0
-
-	return nil
-}
-
-// GetStats returns cache statistics
-func (mc *MemoryCache) GetStats(ctx context.Context) CacheStats {
-	mc.mu.RLock()
-	defer mc.mu.RUnlock()
-
-	stats := mc.stats
-	stats.Size = int64(len(mc.data))
-	stats.MaxSize = mc.maxSize
-
-	// Calculate hit rate
-	totalRequests := stats.Hits + stats.Misses
-	if totalRequests > 0 {
-		// Hits and misses are already in stats
-	}
-
-	return stats
-}
-
-// evictLRU removes the least recently used entry
-// Algorithm: Find entry with oldest access time
-func (mc *MemoryCache) evictLRU() {
-	var lruKey string
-	var lruTime time.Time
-
-	for key, accessTime := range mc.accessTimes {
-		if lruTime.IsZero() || accessTime.Before(lruTime) {
-			lruKey = key
-			lruTime = accessTime
-		}
-	}
-
-	if lruKey != "" {
-		delete(mc.data, lruKey)
-		delete(mc.expirations, lruKey)
-		delete(mc.accessTimes, lruKey)
-		mc.currentSize--
-		mc.stats.Evictions++
-	}
-}
-
-// cleanupExpired runs periodically to clean expired entries
-func (mc *MemoryCache) cleanupExpired() {
-	ticker := time.NewTicker(1 * time.Minute)
-	defer ticker.Stop()
-
-	for range ticker.C {
-		mc.mu.Lock()
-		now := time.Now()
-		var expiredKeys []string
-
-		for key, expiresAt := range mc.expirations {
-			if now.After(expiresAt) {
-				expiredKeys = append(expiredKeys, key)
-			}
-		}
-
-		for _, key := range expiredKeys {
-			delete(mc.data, key)
-			delete(mc.expirations, key)
-			delete(mc.accessTimes, key)
-			mc.currentSize--
-			mc.stats.Evictions++
-		}
-
-		mc.stats.TTLCheckups++
-		mc.mu.Unlock()
-	}
-}
-
-// GenerateQueryHash creates a consistent hash for SQL queries
-// Algorithm: MD5 hash of normalized query
-func (mc *MemoryCache) GenerateQueryHash(query string) string {
-	hash := md5.Sum([]byte(query))
-	return fmt.Sprintf("%x", hash)
-}
-
-// CacheQuery stores query results with query text tracking
-func (mc *MemoryCache) CacheQuery(ctx context.Context, query string, results interface{}, ttl time.Duration) error {
-	hash := mc.GenerateQueryHash(query)
-
-	mc.mu.Lock()
-	mc.queryHashes[query] = hash
-	mc.mu.Unlock()
-
-	return mc.Set(ctx, hash, results, ttl)
-}
-
-// GetCachedQuery retrieves cached query results
-func (mc *MemoryCache) GetCachedQuery(ctx context.Context, query string) (interface{}, error) {
-	hash := mc.GenerateQueryHash(query)
-	return mc.Get(ctx, hash)
-}
-
-// InvalidateQuery removes a cached query result
-func (mc *MemoryCache) InvalidateQuery(ctx context.Context, query string) error {
-	hash := mc.GenerateQueryHash(query)
-
-	mc.mu.Lock()
-	delete(mc.queryHashes, query)
-	mc.mu.Unlock()
-
-	return mc.Delete(ctx, hash)
-}
-
-// InvalidatePattern removes all cached queries matching a pattern
-// Useful for: UPDATE/DELETE invalidation
-func (mc *MemoryCache) InvalidatePattern(ctx context.Context, pattern string) error {
-	mc.mu.Lock()
-	defer mc.mu.Unlock()
-
-	var keysToDelete []string
-	for query, hash := range mc.queryHashes {
-		// Simple pattern matching: contains substring
-		if _, exists := mc.data[hash]; exists && len(pattern) > 0 {
-			// Check if pattern appears in query
-			if len(query) > len(pattern) {
-				for i := 0; i <= len(query)-len(pattern); i++ {
-					if query[i:i+len(pattern)] == pattern {
-						keysToDelete = append(keysToDelete, hash)
-						delete(mc.queryHashes, query)
-						break
-					}
-				}
-			}
-		}
-	}
-
-	for _, key := range keysToDelete {
-		delete(mc.data, key)
-		delete(mc.expirations, key)
-		delete(mc.accessTimes, key)
-		mc.currentSize--
-		mc.stats.Evictions++
-	}
-
-	return nil
-}
-
-// Close closes the cache and cleans up resources
-func (mc *MemoryCache) Close() error {
-	mc.mu.Lock()
-	defer mc.mu.Unlock()
-
-	if mc.cleanupTimer != nil {
-		mc.cleanupTimer.Stop()
-	}
-
-	mc.data = nil
-	mc.expirations = nil
-	mc.accessTimes = nil
-	mc.queryHashes = nil
-
-	return nil
-}
+package cache
+
+import (
+	"container/list"
+	"context"
+	"crypto/md5"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryCache implements an in-memory caching layer with TTL support
+// Algorithm:
+// 1. Hash-based lookup: O(1) average case for Get/Set
+// 2. TTL expiration: Background cleanup on access + periodic sweeps
+// 3. Doubly linked list in access order: O(1) LRU eviction when a limit is exceeded
+// 4. Size accounting: each entry's approximate bytes count against maxBytes
+// 5. Thread-safe with a mutex (Get reorders the LRU list, so reads lock too)
+type MemoryCache struct {
+	mu sync.Mutex
+
+	// Main cache storage: key -> LRU list element holding a *memoryEntry
+	items map[string]*list.Element
+
+	// LRU order for eviction: most recently used at the front
+	lru *list.List
+
+	// Cache configuration
+	maxSize      int64 // entries (0 = unlimited)
+	maxBytes     int64 // approximate bytes (0 = unlimited)
+	currentBytes int64
+	defaultTTL   time.Duration
+	done         chan struct{}
+	closeOnce    sync.Once
+
+	// Statistics
+	stats CacheStats
+}
+
+// memoryEntry is a cached entry with its key, so the LRU list can find it
+type memoryEntry struct {
+	key   string
+	query string // query text of CacheQuery entries, for InvalidatePattern
+	entry *CacheEntry
+}
+
+// NewMemoryCache creates a new in-memory cache instance
+// Parameters:
+//   - maxSize: Maximum number of entries (0 = unlimited)
+//   - defaultTTL: Default time-to-live for entries
+func NewMemoryCache(maxSize int64, defaultTTL time.Duration) *MemoryCache {
+	return NewMemoryCacheWithMaxBytes(maxSize, 0, defaultTTL)
+}
+
+// NewMemoryCacheWithMaxBytes creates an in-memory cache that is also bounded
+// by the approximate memory its entries hold
+// Parameters:
+//   - maxSize: Maximum number of entries (0 = unlimited)
+//   - maxBytes: Maximum approximate size of all entries in bytes (0 = unlimited)
+//   - defaultTTL: Default time-to-live for entries
+func NewMemoryCacheWithMaxBytes(maxSize, maxBytes int64, defaultTTL time.Duration) *MemoryCache {
+	if defaultTTL == 0 {
+		defaultTTL = 5 * time.Minute // Default 5 minutes
+	}
+
+	mc := &MemoryCache{
+		items:      make(map[string]*list.Element),
+		lru:        list.New(),
+		maxSize:    maxSize,
+		maxBytes:   maxBytes,
+		defaultTTL: defaultTTL,
+		done:       make(chan struct{}),
+	}
+
+	// Start background cleanup goroutine
+	go mc.cleanupExpired()
+
+	return mc
+}
+
+// Set stores a value in the cache with TTL
+func (mc *MemoryCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	return mc.set(key, "", value, ttl)
+}
+
+// set stores a value, recording the query text it caches the results of
+func (mc *MemoryCache) set(key, query string, value interface{}, ttl time.Duration) error {
+	if key == "" {
+		return NewCacheError(ErrInvalidKey, "cache key cannot be empty", nil)
+	}
+
+	if ttl == 0 {
+		ttl = mc.defaultTTL
+	}
+
+	if ttl < 0 {
+		return NewCacheError(ErrInvalidTTL, "TTL must be positive", nil)
+	}
+
+	// Measure outside the lock: large query results take a while to walk
+	size := entryOverhead + int64(len(key)+len(query)) + estimateSize(value)
+
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	// Replace any existing entry
+	if elem, exists := mc.items[key]; exists {
+		mc.removeElement(elem)
+	}
+
+	// A value larger than the whole cache is not stored at all
+	if mc.maxBytes > 0 && size > mc.maxBytes {
+		mc.stats.Rejected++
+		return NewCacheError(ErrCacheFull, fmt.Sprintf("value of about %d bytes exceeds the cache limit of %d bytes", size, mc.maxBytes), nil)
+	}
+
+	// Evict least recently used entries until the new one fits
+	for mc.lru.Len() > 0 && mc.overLimit(size) {
+		mc.evictLRU()
+	}
+
+	now := time.Now()
+	entry := &CacheEntry{
+		Data:      value,
+		Timestamp: now,
+		TTL:       ttl,
+		ExpiresAt: now.Add(ttl),
+		Size:      size,
+	}
+
+	mc.items[key] = mc.lru.PushFront(&memoryEntry{key: key, query: query, entry: entry})
+	mc.currentBytes += size
+
+	return nil
+}
+
+// overLimit reports whether adding an entry of size bytes would exceed the
+// entry or byte limit
+func (mc *MemoryCache) overLimit(size int64) bool {
+	if mc.maxSize > 0 && int64(mc.lru.Len()) >= mc.maxSize {
+		return true
+	}
+	return mc.maxBytes > 0 && mc.currentBytes+size > mc.maxBytes
+}
+
+// Get retrieves a value from the cache
+func (mc *MemoryCache) Get(ctx context.Context, key string) (interface{}, error) {
+	if key == "" {
+		return nil, NewCacheError(ErrInvalidKey, "cache key cannot be empty", nil)
+	}
+
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	elem, exists := mc.items[key]
+	if !exists {
+		mc.stats.Misses++
+		return nil, NewCacheError(ErrKeyNotFound, fmt.Sprintf("key '%s' not found in cache", key), nil)
+	}
+
+	// Check if expired
+	entry := elem.Value.(*memoryEntry).entry
+	if time.Now().After(entry.ExpiresAt) {
+		mc.removeElement(elem)
+		mc.stats.Evictions++
+		return nil, NewCacheError(ErrKeyNotFound, fmt.Sprintf("key '%s' has expired", key), nil)
+	}
+
+	// Mark as most recently used
+	mc.lru.MoveToFront(elem)
+	mc.stats.Hits++
+
+	return entry.Data, nil
+}
+
+// Delete removes a value from the cache
+func (mc *MemoryCache) Delete(ctx context.Context, key string) error {
+	if key == "" {
+		return NewCacheError(ErrInvalidKey, "cache key cannot be empty", nil)
+	}
+
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	elem, exists := mc.items[key]
+	if !exists {
+		return NewCacheError(ErrKeyNotFound, fmt.Sprintf("key '%s' not found in cache", key), nil)
+	}
+
+	mc.removeElement(elem)
+
+	return nil
+}
+
+// Exists checks if a key exists and is not expired
+func (mc *MemoryCache) Exists(ctx context.Context, key string) (bool, error) {
+	if key == "" {
+		return false, NewCacheError(ErrInvalidKey, "cache key cannot be empty", nil)
+	}
+
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	elem, exists := mc.items[key]
+	if !exists {
+		return false, nil
+	}
+
+	// Check if expired
+	if time.Now().After(elem.Value.(*memoryEntry).entry.ExpiresAt) {
+		mc.removeElement(elem)
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// Clear removes all entries from the cache
+func (mc *MemoryCache) Clear(ctx context.Context) error {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	mc.items = make(map[string]*list.Element)
+	mc.lru.Init()
+	mc.currentBytes = 0
+
+	return nil
+}
+
+// GetStats returns cache statistics
+func (mc *MemoryCache) GetStats(ctx context.Context) CacheStats {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	stats := mc.stats
+	stats.Size = int64(len(mc.items))
+	stats.MaxSize = mc.maxSize
+	stats.Bytes = mc.currentBytes
+	stats.MaxBytes = mc.maxBytes
+
+	for elem := mc.lru.Front(); elem != nil; elem = elem.Next() {
+		stats.LargestEntryBytes = max(stats.LargestEntryBytes, elem.Value.(*memoryEntry).entry.Size)
+	}
+
+	return stats
+}
+
+// evictLRU removes the least recently used entry
+// Algorithm: O(1) removal from the back of the LRU list
+func (mc *MemoryCache) evictLRU() {
+	if elem := mc.lru.Back(); elem != nil {
+		mc.removeElement(elem)
+		mc.stats.Evictions++
+	}
+}
+
+// removeElement removes an entry from the map, the LRU list and the byte count
+func (mc *MemoryCache) removeElement(elem *list.Element) {
+	me := mc.lru.Remove(elem).(*memoryEntry)
+	delete(mc.items, me.key)
+	mc.currentBytes -= me.entry.Size
+}
+
+// cleanupExpired runs periodically to clean expired entries until the cache
+// is closed
+func (mc *MemoryCache) cleanupExpired() {
+	ticker := time.NewTicker(1 * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-mc.done:
+			return
+		case <-ticker.C:
+		}
+
+		mc.mu.Lock()
+		now := time.Now()
+		for elem := mc.lru.Front(); elem != nil; {
+			next := elem.Next()
+			if now.After(elem.Value.(*memoryEntry).entry.ExpiresAt) {
+				mc.removeElement(elem)
+				mc.stats.Evictions++
+			}
+			elem = next
+		}
+
+		mc.stats.TTLCheckups++
+		mc.mu.Unlock()
+	}
+}
+
+// GenerateQueryHash creates a consistent hash for SQL queries
+// Algorithm: MD5 hash of normalized query
+func (mc *MemoryCache) GenerateQueryHash(query string) string {
+	hash := md5.Sum([]byte(query))
+	return fmt.Sprintf("%x", hash)
+}
+
+// CacheQuery stores query results with query text tracking
+func (mc *MemoryCache) CacheQuery(ctx context.Context, query string, results interface{}, ttl time.Duration) error {
+	return mc.set(mc.GenerateQueryHash(query), query, results, ttl)
+}
+
+// GetCachedQuery retrieves cached query results
+func (mc *MemoryCache) GetCachedQuery(ctx context.Context, query string) (interface{}, error) {
+	hash := mc.GenerateQueryHash(query)
+	return mc.Get(ctx, hash)
+}
+
+// InvalidateQuery removes a cached query result
+func (mc *MemoryCache) InvalidateQuery(ctx context.Context, query string) error {
+	hash := mc.GenerateQueryHash(query)
+	return mc.Delete(ctx, hash)
+}
+
+// InvalidatePattern removes all cached queries matching a pattern
+// Useful for: UPDATE/DELETE invalidation
+func (mc *MemoryCache) InvalidatePattern(ctx context.Context, pattern string) error {
+	if pattern == "" {
+		return nil
+	}
+
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	for elem := mc.lru.Front(); elem != nil; {
+		next := elem.Next()
+		// Simple pattern matching: contains substring
+		if query := elem.Value.(*memoryEntry).query; query != "" && strings.Contains(query, pattern) {
+			mc.removeElement(elem)
+			mc.stats.Evictions++
+		}
+		elem = next
+	}
+
+	return nil
+}
+
+// Close closes the cache and cleans up resources
+func (mc *MemoryCache) Close() error {
+	mc.closeOnce.Do(func() { close(mc.done) })
+
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	mc.items = make(map[string]*list.Element)
+	mc.lru.Init()
+	mc.currentBytes = 0
+
+	return nil
+}
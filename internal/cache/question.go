@@ -0,0 +1,250 @@
+package cache
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// questionStopWords are dropped from normalized questions: articles,
+// auxiliaries and request phrasing that do not change what is asked.
+// Negations and words like "how", "why" and "per" are kept.
+var questionStopWords = map[string]bool{
+	"a": true, "an": true, "the": true,
+	"is": true, "are": true, "was": true, "were": true, "be": true, "been": true, "am": true,
+	"do": true, "does": true, "did": true,
+	"what": true, "whats": true, "which": true,
+	"can": true, "could": true, "would": true, "will": true, "please": true,
+	"you": true, "me": true, "us": true, "i": true,
+	"show": true, "tell": true, "give": true, "list": true,
+	"there": true, "theres": true,
+}
+
+// Number words canonicalized to digits: units and teens stand alone, tens
+// can be followed by a unit ("twenty five")
+var (
+	numberUnits = map[string]int{
+		"zero": 0, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6,
+		"seven": 7, "eight": 8, "nine": 9,
+	}
+	numberTeens = map[string]int{
+		"ten": 10, "eleven": 11, "twelve": 12, "thirteen": 13, "fourteen": 14,
+		"fifteen": 15, "sixteen": 16, "seventeen": 17, "eighteen": 18, "nineteen": 19,
+	}
+	numberTens = map[string]int{
+		"twenty": 20, "thirty": 30, "forty": 40, "fifty": 50, "sixty": 60,
+		"seventy": 70, "eighty": 80, "ninety": 90,
+	}
+)
+
+// numberScales multiply the number words before them
+var numberScales = map[string]int{
+	"hundred": 100, "thousand": 1000, "million": 1000000,
+}
+
+// months maps month names and abbreviations to their number
+var months = map[string]int{
+	"january": 1, "jan": 1, "february": 2, "feb": 2, "march": 3, "mar": 3,
+	"april": 4, "apr": 4, "may": 5, "june": 6, "jun": 6, "july": 7, "jul": 7,
+	"august": 8, "aug": 8, "september": 9, "sep": 9, "sept": 9,
+	"october": 10, "oct": 10, "november": 11, "nov": 11, "december": 12, "dec": 12,
+}
+
+// Date and number forms canonicalized before tokenizing
+var (
+	monthPattern    = `(january|jan|february|feb|march|mar|april|apr|may|june|jun|july|jul|august|aug|september|sept|sep|october|oct|november|nov|december|dec)\.?`
+	isoDate         = regexp.MustCompile(`\b(\d{4})[-/.](\d{1,2})[-/.](\d{1,2})\b`)
+	usDate          = regexp.MustCompile(`\b(\d{1,2})/(\d{1,2})/(\d{4})\b`)
+	monthDayYear    = regexp.MustCompile(`\b` + monthPattern + `\s+(\d{1,2})(?:st|nd|rd|th)?,?\s+(\d{4})\b`)
+	dayMonthYear    = regexp.MustCompile(`\b(\d{1,2})(?:st|nd|rd|th)?\s+(?:of\s+)?` + monthPattern + `,?\s+(\d{4})\b`)
+	monthYear       = regexp.MustCompile(`\b` + monthPattern + `,?\s+(\d{4})\b`)
+	thousandsSep    = regexp.MustCompile(`(\d),(\d{3})\b`)
+	questionToken   = regexp.MustCompile(`\d{4}-\d{2}(?:-\d{2})?|\d+\.\d+|[\p{L}\p{N}]+`)
+	ordinalNumber   = regexp.MustCompile(`^(\d+)(?:st|nd|rd|th)$`)
+	apostrophes     = strings.NewReplacer("'", "", "’", "")
+	percentReplacer = strings.NewReplacer("%", " percent ")
+)
+
+// NormalizeQuestion returns the form of a question that cache keys are built
+// from, so that rewordings of the same question share a cache entry:
+//   - lowercased, with punctuation and stop words ("what", "are", "the") removed
+//   - number words and formatted numbers as plain digits ("ten" and "10.0" are "10")
+//   - only valid compounds read as one number ("two hundred and five" is "205",
+//     "two and five" is "2 and 5")
+//   - dates as ISO 8601 ("March 1st, 2024" and "3/1/2024" are "2024-03-01")
+//
+// "What are the top billing issues?" and "top billing issues" both normalize
+// to "top billing issues". Relative dates ("last month") are kept as written.
+func NormalizeQuestion(question string) string {
+	text := strings.ToLower(question)
+	text = apostrophes.Replace(text)
+	text = percentReplacer.Replace(text)
+	text = canonicalDates(text)
+	for thousandsSep.MatchString(text) {
+		text = thousandsSep.ReplaceAllString(text, "$1$2")
+	}
+
+	tokens := questionToken.FindAllString(text, -1)
+	var words []string
+	for i := 0; i < len(tokens); {
+		// A lone "one" is usually a pronoun ("which one"), so it stays a word
+		if value, next, ok := numberPhrase(tokens, i); ok && !(next == i+1 && tokens[i] == "one") {
+			words = append(words, strconv.Itoa(value))
+			i = next
+			continue
+		}
+		token := tokens[i]
+		i++
+		if questionStopWords[token] {
+			continue
+		}
+		words = append(words, canonicalNumber(token))
+	}
+
+	if len(words) == 0 {
+		// A question made only of stop words is kept as asked
+		return strings.Join(strings.Fields(strings.ToLower(question)), " ")
+	}
+	return strings.Join(words, " ")
+}
+
+// numberPhrase reads the number written in words starting at tokens[i],
+// returning its value and the index after it. Only valid compounds are
+// combined: tens and a unit, a number below 100 before hundred, and a
+// number below 1000 before thousand or million, each scale smaller than the
+// one before it; "and" is read only after a scale word.
+func numberPhrase(tokens []string, i int) (value, next int, ok bool) {
+	total, lastScale := 0, 0
+	for {
+		group, j, ok := smallNumber(tokens, i)
+		if !ok {
+			break
+		}
+		if j < len(tokens) && tokens[j] == "hundred" {
+			j, group = numberAfterScale(tokens, j+1, group*100)
+		}
+		if j < len(tokens) {
+			if scale := numberScales[tokens[j]]; scale > 100 && (lastScale == 0 || scale < lastScale) {
+				total += group * scale
+				lastScale = scale
+				i = j + 1
+				if i < len(tokens) && tokens[i] == "and" {
+					if _, _, ok := smallNumber(tokens, i+1); ok {
+						// "two thousand and five"
+						i++
+					}
+				}
+				continue
+			}
+		}
+		return total + group, j, true
+	}
+	if lastScale > 0 {
+		// "two thousand" with nothing after the scale
+		return total, i, true
+	}
+	return 0, i, false
+}
+
+// numberAfterScale adds the number below 100 that follows a scale word at
+// tokens[j], optionally after "and" ("one hundred and five")
+func numberAfterScale(tokens []string, j, value int) (int, int) {
+	k := j
+	if k < len(tokens) && tokens[k] == "and" {
+		k++
+	}
+	if rest, next, ok := smallNumber(tokens, k); ok {
+		return next, value + rest
+	}
+	return j, value
+}
+
+// smallNumber reads a number below 100 written in words at tokens[i]: a
+// unit, a teen, or tens optionally followed by a unit
+func smallNumber(tokens []string, i int) (value, next int, ok bool) {
+	if i >= len(tokens) {
+		return 0, i, false
+	}
+	if tens, ok := numberTens[tokens[i]]; ok {
+		if i+1 < len(tokens) {
+			if unit, ok := numberUnits[tokens[i+1]]; ok && unit > 0 {
+				return tens + unit, i + 2, true
+			}
+		}
+		return tens, i + 1, true
+	}
+	if teen, ok := numberTeens[tokens[i]]; ok {
+		return teen, i + 1, true
+	}
+	if unit, ok := numberUnits[tokens[i]]; ok {
+		return unit, i + 1, true
+	}
+	return 0, i, false
+}
+
+// canonicalNumber returns numeric tokens without ordinal suffixes, leading
+// zeros or trailing decimal zeros; other tokens are returned unchanged
+func canonicalNumber(token string) string {
+	if match := ordinalNumber.FindStringSubmatch(token); match != nil {
+		token = match[1]
+	}
+	if token == "" || token[0] < '0' || token[0] > '9' {
+		return token
+	}
+	if value, err := strconv.ParseFloat(token, 64); err == nil {
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	return token
+}
+
+// canonicalDates rewrites the dates in lowercased text as YYYY-MM-DD, or
+// YYYY-MM for a month and year; US-style numeric dates are read month first
+func canonicalDates(text string) string {
+	text = isoDate.ReplaceAllStringFunc(text, func(s string) string {
+		m := isoDate.FindStringSubmatch(s)
+		return isoDateString(m[1], month(m[2]), m[3], s)
+	})
+	text = usDate.ReplaceAllStringFunc(text, func(s string) string {
+		m := usDate.FindStringSubmatch(s)
+		return isoDateString(m[3], month(m[1]), m[2], s)
+	})
+	text = monthDayYear.ReplaceAllStringFunc(text, func(s string) string {
+		m := monthDayYear.FindStringSubmatch(s)
+		return isoDateString(m[3], months[m[1]], m[2], s)
+	})
+	text = dayMonthYear.ReplaceAllStringFunc(text, func(s string) string {
+		m := dayMonthYear.FindStringSubmatch(s)
+		return isoDateString(m[3], months[m[2]], m[1], s)
+	})
+	return monthYear.ReplaceAllStringFunc(text, func(s string) string {
+		m := monthYear.FindStringSubmatch(s)
+		return m[2] + "-" + twoDigits(months[m[1]])
+	})
+}
+
+// month parses a numeric month, returning 0 if it is not one
+func month(s string) int {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 || n > 12 {
+		return 0
+	}
+	return n
+}
+
+// isoDateString formats a date as YYYY-MM-DD, or returns original if the
+// month or day is out of range
+func isoDateString(year string, monthNumber int, day, original string) string {
+	d, err := strconv.Atoi(day)
+	if monthNumber == 0 || err != nil || d < 1 || d > 31 {
+		return original
+	}
+	return year + "-" + twoDigits(monthNumber) + "-" + twoDigits(d)
+}
+
+// twoDigits formats n with a leading zero below 10
+func twoDigits(n int) string {
+	if n < 10 {
+		return "0" + strconv.Itoa(n)
+	}
+	return strconv.Itoa(n)
+}
@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+// TestNormalizeQuestion tests the canonical form questions are cached under
+func TestNormalizeQuestion(t *testing.T) {
+	tests := []struct {
+		name     string
+		question string
+		want     string
+	}{
+		{name: "case and punctuation", question: "Top Billing Issues?", want: "top billing issues"},
+		{name: "stop words", question: "What are the top billing issues", want: "top billing issues"},
+		{name: "request phrasing", question: "Can you please show me the top billing issues?", want: "top billing issues"},
+		{name: "whitespace", question: "  top   billing\tissues ", want: "top billing issues"},
+		{name: "apostrophes", question: "What's the churn rate?", want: "churn rate"},
+		{name: "number words", question: "top ten billing issues", want: "top 10 billing issues"},
+		{name: "compound number words", question: "the last twenty five tickets", want: "last 25 tickets"},
+		{name: "number scales", question: "one hundred and five accounts", want: "105 accounts"},
+		{name: "large number scales", question: "two thousand and five hundred tickets", want: "2500 tickets"},
+		{name: "separate numbers joined by and", question: "Compare tier two and three accounts", want: "compare tier 2 and 3 accounts"},
+		{name: "single number", question: "Compare tier five accounts", want: "compare tier 5 accounts"},
+		{name: "adjacent numbers not compounds", question: "top ten five issues", want: "top 10 5 issues"},
+		{name: "lone one kept", question: "Which one is worse", want: "one worse"},
+		{name: "one in a compound", question: "one hundred accounts", want: "100 accounts"},
+		{name: "formatted numbers", question: "accounts over 1,000 with 10.0 tickets", want: "accounts over 1000 with 10 tickets"},
+		{name: "ordinals", question: "issues in the 3rd quarter", want: "issues in 3 quarter"},
+		{name: "percent", question: "churn above 5%", want: "churn above 5 percent"},
+		{name: "ISO date", question: "feedback since 2024-3-1", want: "feedback since 2024-03-01"},
+		{name: "US date", question: "feedback since 3/1/2024", want: "feedback since 2024-03-01"},
+		{name: "month day year", question: "feedback since March 1st, 2024", want: "feedback since 2024-03-01"},
+		{name: "day month year", question: "feedback since 1 Mar 2024", want: "feedback since 2024-03-01"},
+		{name: "month year", question: "feedback in March 2024", want: "feedback in 2024-03"},
+		{name: "relative dates kept", question: "feedback from last month", want: "feedback from last month"},
+		{name: "negation kept", question: "Which accounts are not churned?", want: "accounts not churned"},
+		{name: "only stop words", question: "What can you do?", want: "what can you do?"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeQuestion(tt.question); got != tt.want {
+				t.Errorf("NormalizeQuestion(%q) = %q, want %q", tt.question, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSemanticIndexLookup tests matching embeddings against the threshold
+func TestSemanticIndexLookup(t *testing.T) {
+	index := NewSemanticIndex(0.9, 0, time.Minute)
+	index.Add("billing", "top billing issues", []float64{1, 0, 0})
+	index.Add("churn", "churn by plan", []float64{0, 1, 0})
+
+	tests := []struct {
+		name      string
+		vector    []float64
+		wantKey   string
+		wantFound bool
+	}{
+		{name: "identical", vector: []float64{1, 0, 0}, wantKey: "billing", wantFound: true},
+		{name: "similar", vector: []float64{1, 0.2, 0}, wantKey: "billing", wantFound: true},
+		{name: "closest of two", vector: []float64{0.1, 1, 0}, wantKey: "churn", wantFound: true},
+		{name: "below threshold", vector: []float64{1, 1, 0}, wantFound: false},
+		{name: "unrelated", vector: []float64{0, 0, 1}, wantFound: false},
+		{name: "different dimensions", vector: []float64{1, 0}, wantFound: false},
+		{name: "zero vector", vector: []float64{0, 0, 0}, wantFound: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, found := index.Lookup(tt.vector)
+			if found != tt.wantFound || match.Key != tt.wantKey {
+				t.Errorf("Lookup(%v) = %+v, %v; want key %q, %v", tt.vector, match, found, tt.wantKey, tt.wantFound)
+			}
+		})
+	}
+}
+
+// TestSemanticIndexEviction tests replacement, removal, capacity and expiry
+func TestSemanticIndexEviction(t *testing.T) {
+	index := NewSemanticIndex(0.9, 2, time.Minute)
+	index.Add("a", "question a", []float64{1, 0})
+	index.Add("a", "question a reworded", []float64{1, 0})
+	if index.Len() != 1 {
+		t.Fatalf("re-adding a key should replace it, got %d entries", index.Len())
+	}
+
+	index.Add("b", "question b", []float64{0, 1})
+	index.Add("c", "question c", []float64{1, 1})
+	if index.Len() != 2 {
+		t.Errorf("expected capacity of 2 entries, got %d", index.Len())
+	}
+	if match, found := index.Lookup([]float64{1, 0}); found {
+		t.Errorf("oldest entry should be evicted, matched %+v", match)
+	}
+
+	index.Remove("b")
+	if _, found := index.Lookup([]float64{0, 1}); found {
+		t.Error("removed entry should not match")
+	}
+
+	expiring := NewSemanticIndex(0.9, 0, 10*time.Millisecond)
+	expiring.Add("a", "question a", []float64{1, 0})
+	time.Sleep(20 * time.Millisecond)
+	if _, found := expiring.Lookup([]float64{1, 0}); found || expiring.Len() != 0 {
+		t.Error("expired entry should not match and should be pruned")
+	}
+}
@@ -0,0 +1,252 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultNamespace prefixes the keys of a RedisCache when no namespace is given
+const DefaultNamespace = "goinsight"
+
+// scanBatch is how many keys a SCAN asks Redis for per call
+const scanBatch = 500
+
+// RedisCache implements Cache on a Redis server, so that API replicas share
+// one cache and cached entries survive restarts
+// Algorithm:
+// 1. Namespaced keys: "<namespace>:<key>", so deployments can share a database
+// 2. Gob-encoded values: types stored in interfaces must be registered
+// 3. TTL expiration: left to Redis (SET with a TTL)
+// 4. Pattern invalidation: SCAN the namespace for keys containing the pattern
+type RedisCache struct {
+	client     *redis.Client
+	namespace  string
+	defaultTTL time.Duration
+
+	// Statistics of this process; Size is read from Redis
+	hits      atomic.Int64
+	misses    atomic.Int64
+	evictions atomic.Int64
+}
+
+// redisValue wraps cached values so that any registered type can be decoded
+type redisValue struct {
+	Data interface{}
+}
+
+func init() {
+	// Types commonly found in query results
+	RegisterType(map[string]interface{}{})
+	RegisterType([]map[string]interface{}{})
+	RegisterType([]interface{}{})
+	RegisterType(time.Time{})
+}
+
+// RegisterType records a concrete type stored in a RedisCache, so that it
+// decodes back to the same type. Values are stored as gob, which only encodes
+// exported fields.
+func RegisterType(value interface{}) {
+	gob.Register(value)
+}
+
+// NewRedisCache connects to the Redis server at redisURL
+// (redis://[user:password@]host:port/db)
+// Parameters:
+//   - namespace: Prefix of every key (DefaultNamespace if empty)
+//   - defaultTTL: Default time-to-live for entries
+func NewRedisCache(ctx context.Context, redisURL, namespace string, defaultTTL time.Duration) (*RedisCache, error) {
+	options, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis URL: %w", err)
+	}
+
+	if namespace == "" {
+		namespace = DefaultNamespace
+	}
+	if defaultTTL == 0 {
+		defaultTTL = 5 * time.Minute
+	}
+
+	client := redis.NewClient(options)
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+
+	return &RedisCache{
+		client:     client,
+		namespace:  namespace,
+		defaultTTL: defaultTTL,
+	}, nil
+}
+
+// key returns the namespaced Redis key of a cache key
+func (rc *RedisCache) key(key string) string {
+	return rc.namespace + ":" + key
+}
+
+// match returns a SCAN pattern for the namespace's keys matching pattern
+func (rc *RedisCache) match(pattern string) string {
+	return escapeGlob(rc.namespace) + ":" + pattern
+}
+
+// Set stores a value in the cache with TTL
+func (rc *RedisCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	if key == "" {
+		return NewCacheError(ErrInvalidKey, "cache key cannot be empty", nil)
+	}
+
+	if ttl == 0 {
+		ttl = rc.defaultTTL
+	}
+
+	if ttl < 0 {
+		return NewCacheError(ErrInvalidTTL, "TTL must be positive", nil)
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(redisValue{Data: value}); err != nil {
+		return NewCacheError(ErrEncoding, fmt.Sprintf("failed to encode value for key '%s'", key), err)
+	}
+
+	if err := rc.client.Set(ctx, rc.key(key), buf.Bytes(), ttl).Err(); err != nil {
+		return NewCacheError(ErrBackend, "failed to store value in redis", err)
+	}
+
+	return nil
+}
+
+// Get retrieves a value from the cache
+func (rc *RedisCache) Get(ctx context.Context, key string) (interface{}, error) {
+	if key == "" {
+		return nil, NewCacheError(ErrInvalidKey, "cache key cannot be empty", nil)
+	}
+
+	data, err := rc.client.Get(ctx, rc.key(key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		rc.misses.Add(1)
+		return nil, NewCacheError(ErrKeyNotFound, fmt.Sprintf("key '%s' not found in cache", key), nil)
+	}
+	if err != nil {
+		return nil, NewCacheError(ErrBackend, "failed to read value from redis", err)
+	}
+
+	var value redisValue
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&value); err != nil {
+		// Entries written by an incompatible version are treated as misses
+		rc.misses.Add(1)
+		return nil, NewCacheError(ErrEncoding, fmt.Sprintf("failed to decode value for key '%s'", key), err)
+	}
+
+	rc.hits.Add(1)
+	return value.Data, nil
+}
+
+// Delete removes a value from the cache
+func (rc *RedisCache) Delete(ctx context.Context, key string) error {
+	if key == "" {
+		return NewCacheError(ErrInvalidKey, "cache key cannot be empty", nil)
+	}
+
+	deleted, err := rc.client.Del(ctx, rc.key(key)).Result()
+	if err != nil {
+		return NewCacheError(ErrBackend, "failed to delete value from redis", err)
+	}
+	if deleted == 0 {
+		return NewCacheError(ErrKeyNotFound, fmt.Sprintf("key '%s' not found in cache", key), nil)
+	}
+
+	return nil
+}
+
+// Exists checks if a key exists and is not expired
+func (rc *RedisCache) Exists(ctx context.Context, key string) (bool, error) {
+	if key == "" {
+		return false, NewCacheError(ErrInvalidKey, "cache key cannot be empty", nil)
+	}
+
+	n, err := rc.client.Exists(ctx, rc.key(key)).Result()
+	if err != nil {
+		return false, NewCacheError(ErrBackend, "failed to check key in redis", err)
+	}
+
+	return n > 0, nil
+}
+
+// Clear removes all entries in the cache's namespace
+func (rc *RedisCache) Clear(ctx context.Context) error {
+	_, err := rc.deleteMatching(ctx, rc.match("*"))
+	return err
+}
+
+// InvalidatePattern removes all cached entries whose key (the question or
+// SQL text) contains pattern
+// Useful for: UPDATE/DELETE invalidation
+func (rc *RedisCache) InvalidatePattern(ctx context.Context, pattern string) error {
+	if pattern == "" {
+		return nil
+	}
+
+	deleted, err := rc.deleteMatching(ctx, rc.match("*"+escapeGlob(pattern)+"*"))
+	rc.evictions.Add(deleted)
+	return err
+}
+
+// GetStats returns cache statistics. Hits, misses and evictions are those
+// seen by this process; Size counts the keys in the namespace.
+func (rc *RedisCache) GetStats(ctx context.Context) CacheStats {
+	stats := CacheStats{
+		Hits:      rc.hits.Load(),
+		Misses:    rc.misses.Load(),
+		Evictions: rc.evictions.Load(),
+	}
+
+	iter := rc.client.Scan(ctx, 0, rc.match("*"), scanBatch).Iterator()
+	for iter.Next(ctx) {
+		stats.Size++
+	}
+
+	return stats
+}
+
+// Close closes the connection pool
+func (rc *RedisCache) Close() error {
+	return rc.client.Close()
+}
+
+// deleteMatching deletes the keys matching a SCAN pattern, in batches,
+// returning how many were deleted
+func (rc *RedisCache) deleteMatching(ctx context.Context, match string) (int64, error) {
+	var deleted int64
+	var cursor uint64
+	for {
+		keys, next, err := rc.client.Scan(ctx, cursor, match, scanBatch).Result()
+		if err != nil {
+			return deleted, NewCacheError(ErrBackend, "failed to scan redis keys", err)
+		}
+		if len(keys) > 0 {
+			n, err := rc.client.Del(ctx, keys...).Result()
+			if err != nil {
+				return deleted, NewCacheError(ErrBackend, "failed to delete keys from redis", err)
+			}
+			deleted += n
+		}
+		if next == 0 {
+			return deleted, nil
+		}
+		cursor = next
+	}
+}
+
+// escapeGlob escapes the characters special to Redis MATCH patterns
+func escapeGlob(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`).Replace(s)
+}
@@ -0,0 +1,270 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+// cachedAnswer stands in for the response types the service caches
+type cachedAnswer struct {
+	Summary string
+	Rows    []map[string]interface{}
+}
+
+func init() {
+	RegisterType(&cachedAnswer{})
+}
+
+// startRedis starts a redis-server on a free port for the test, returning its
+// URL; the test is skipped when redis-server is not installed
+func startRedis(t *testing.T) string {
+	t.Helper()
+	path, err := exec.LookPath("redis-server")
+	if err != nil {
+		t.Skip("redis-server not installed")
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to find a free port: %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+	_, port, _ := net.SplitHostPort(addr)
+
+	cmd := exec.Command(path, "--port", port, "--bind", "127.0.0.1", "--save", "", "--appendonly", "no", "--dir", t.TempDir())
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("failed to start redis-server: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+		if conn, err := net.Dial("tcp", addr); err == nil {
+			conn.Close()
+			return "redis://" + addr + "/0"
+		}
+	}
+	t.Fatalf("redis-server did not start on %s", addr)
+	return ""
+}
+
+// newTestRedisCache connects a RedisCache in namespace to the test server
+func newTestRedisCache(t *testing.T, redisURL, namespace string) *RedisCache {
+	t.Helper()
+	cache, err := NewRedisCache(context.Background(), redisURL, namespace, 5*time.Minute)
+	if err != nil {
+		t.Fatalf("NewRedisCache() unexpected error: %v", err)
+	}
+	t.Cleanup(func() { cache.Close() })
+	return cache
+}
+
+// TestRedisCacheSetGet tests that values decode back to their own types
+func TestRedisCacheSetGet(t *testing.T) {
+	cache := newTestRedisCache(t, startRedis(t), "test")
+	ctx := context.Background()
+
+	created := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		key   string
+		value interface{}
+	}{
+		{"string value", "key1", "value1"},
+		{"number value", "key2", 42},
+		{"nil value", "key3", nil},
+		{"map value", "key4", map[string]interface{}{"nested": "data"}},
+		{"registered type", "SELECT * FROM feedback_enriched", &cachedAnswer{
+			Summary: "Refunds are slow",
+			Rows:    []map[string]interface{}{{"count": int64(12), "created_at": created}},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := cache.Set(ctx, tt.key, tt.value, 0); err != nil {
+				t.Fatalf("Set() unexpected error: %v", err)
+			}
+			got, err := cache.Get(ctx, tt.key)
+			if err != nil {
+				t.Fatalf("Get() unexpected error: %v", err)
+			}
+			if fmt.Sprintf("%#v", got) != fmt.Sprintf("%#v", tt.value) {
+				if answer, ok := got.(*cachedAnswer); !ok || fmt.Sprint(*answer) != fmt.Sprint(*tt.value.(*cachedAnswer)) {
+					t.Errorf("Get() = %#v, want %#v", got, tt.value)
+				}
+			}
+		})
+	}
+
+	if _, err := cache.Get(ctx, "missing"); err == nil || err.(*CacheError).Code != ErrKeyNotFound {
+		t.Errorf("Get() missing key error = %v, want %s", err, ErrKeyNotFound)
+	}
+	if err := cache.Set(ctx, "key", "value", -time.Second); err == nil {
+		t.Error("Set() with a negative TTL expected an error")
+	}
+}
+
+// TestRedisCacheTTL tests that entries expire after their TTL
+func TestRedisCacheTTL(t *testing.T) {
+	cache := newTestRedisCache(t, startRedis(t), "test")
+	ctx := context.Background()
+
+	if err := cache.Set(ctx, "short", "value", 100*time.Millisecond); err != nil {
+		t.Fatalf("Set() unexpected error: %v", err)
+	}
+	if exists, _ := cache.Exists(ctx, "short"); !exists {
+		t.Fatal("Exists() = false right after Set()")
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	if exists, _ := cache.Exists(ctx, "short"); exists {
+		t.Error("Exists() = true after the TTL")
+	}
+}
+
+// TestRedisCacheNamespaces tests that caches in different namespaces do not see each other's keys
+func TestRedisCacheNamespaces(t *testing.T) {
+	redisURL := startRedis(t)
+	staging := newTestRedisCache(t, redisURL, "staging")
+	production := newTestRedisCache(t, redisURL, "production")
+	ctx := context.Background()
+
+	_ = staging.Set(ctx, "question", "staging answer", 0)
+	_ = production.Set(ctx, "question", "production answer", 0)
+
+	if got, _ := production.Get(ctx, "question"); got != "production answer" {
+		t.Errorf("production Get() = %v, want its own answer", got)
+	}
+
+	if err := staging.Clear(ctx); err != nil {
+		t.Fatalf("Clear() unexpected error: %v", err)
+	}
+	if exists, _ := staging.Exists(ctx, "question"); exists {
+		t.Error("staging key survived Clear()")
+	}
+	if exists, _ := production.Exists(ctx, "question"); !exists {
+		t.Error("Clear() removed another namespace's key")
+	}
+}
+
+// TestRedisCacheInvalidatePattern tests that only keys containing the pattern are removed
+func TestRedisCacheInvalidatePattern(t *testing.T) {
+	cache := newTestRedisCache(t, startRedis(t), "test")
+	ctx := context.Background()
+
+	queries := []string{
+		"SELECT * FROM feedback_enriched WHERE product_area = 'billing'",
+		"SELECT COUNT(*) FROM feedback_enriched",
+		"SELECT * FROM accounts",
+	}
+	for _, query := range queries {
+		_ = cache.Set(ctx, query, "results", 0)
+	}
+
+	// Glob characters in the pattern are matched literally
+	if err := cache.InvalidatePattern(ctx, "COUNT(*)"); err != nil {
+		t.Fatalf("InvalidatePattern() unexpected error: %v", err)
+	}
+	if err := cache.InvalidatePattern(ctx, "billing"); err != nil {
+		t.Fatalf("InvalidatePattern() unexpected error: %v", err)
+	}
+
+	for i, query := range queries {
+		exists, _ := cache.Exists(ctx, query)
+		if want := i == 2; exists != want {
+			t.Errorf("Exists(%q) = %v, want %v", query, exists, want)
+		}
+	}
+	if stats := cache.GetStats(ctx); stats.Evictions != 2 || stats.Size != 1 {
+		t.Errorf("GetStats() = %+v, want 2 evictions and size 1", stats)
+	}
+}
+
+// TestRedisCacheStats tests hit and miss counting
+func TestRedisCacheStats(t *testing.T) {
+	cache := newTestRedisCache(t, startRedis(t), "test")
+	ctx := context.Background()
+
+	_ = cache.Set(ctx, "key", "value", 0)
+	_, _ = cache.Get(ctx, "key")
+	_, _ = cache.Get(ctx, "key")
+	_, _ = cache.Get(ctx, "missing")
+
+	stats := cache.GetStats(ctx)
+	if stats.Hits != 2 || stats.Misses != 1 || stats.Size != 1 {
+		t.Errorf("GetStats() = %+v, want 2 hits, 1 miss and size 1", stats)
+	}
+}
+
+// TestCacheManagerRedis tests the cache manager on the redis backend
+func TestCacheManagerRedis(t *testing.T) {
+	config := DefaultCacheConfig()
+	config.Backend = BackendRedis
+	config.RedisURL = startRedis(t)
+	config.Namespace = "test"
+
+	manager, err := NewCacheManagerFromConfig(context.Background(), config)
+	if err != nil {
+		t.Fatalf("NewCacheManagerFromConfig() unexpected error: %v", err)
+	}
+	defer manager.Close()
+	ctx := context.Background()
+
+	question := "What are the top billing issues?"
+	if _, found, err := manager.GetCachedQueryResult(ctx, question); found || err != nil {
+		t.Fatalf("GetCachedQueryResult() = found %v, error %v; want a miss", found, err)
+	}
+	if err := manager.CacheQueryResult(ctx, question, &cachedAnswer{Summary: "Refunds"}, 0); err != nil {
+		t.Fatalf("CacheQueryResult() unexpected error: %v", err)
+	}
+	cached, found, err := manager.GetCachedQueryResult(ctx, question)
+	if answer, ok := cached.(*cachedAnswer); !found || err != nil || !ok || answer.Summary != "Refunds" {
+		t.Errorf("GetCachedQueryResult() = %#v, %v, %v; want the cached answer", cached, found, err)
+	}
+
+	if err := manager.InvalidatePattern(ctx, "billing"); err != nil {
+		t.Fatalf("InvalidatePattern() unexpected error: %v", err)
+	}
+	if _, found, _ := manager.GetCachedQueryResult(ctx, question); found {
+		t.Error("GetCachedQueryResult() found an invalidated question")
+	}
+}
+
+// TestNewCacheManagerFromConfig tests backend selection errors
+func TestNewCacheManagerFromConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		backend string
+		url     string
+		wantErr string
+	}{
+		{"unknown backend", "memcached", "", "unknown cache backend"},
+		{"invalid redis URL", BackendRedis, "localhost:6379", "invalid redis URL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultCacheConfig()
+			config.Backend = tt.backend
+			config.RedisURL = tt.url
+			_, err := NewCacheManagerFromConfig(context.Background(), config)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("NewCacheManagerFromConfig() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	manager, err := NewCacheManagerFromConfig(context.Background(), DefaultCacheConfig())
+	if err != nil || !manager.IsCacheEnabled() {
+		t.Errorf("NewCacheManagerFromConfig() default = %v, %v; want an enabled memory cache", manager, err)
+	}
+}
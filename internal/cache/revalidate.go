@@ -0,0 +1,204 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Stale-while-revalidate defaults
+const (
+	DefaultRefreshWorkers = 2
+	DefaultRefreshTimeout = 2 * time.Minute
+
+	// refreshQueuePerWorker bounds the refreshes waiting for a worker
+	refreshQueuePerWorker = 16
+)
+
+// RefreshFunc recomputes and re-caches a stale entry; stale is the value
+// that was served while the refresh runs
+type RefreshFunc func(ctx context.Context, key string, stale interface{}) error
+
+// revalidatingEntry is a value cached with CacheRevalidatingResult. It is
+// fresh until FreshUntil and stays in the cache for the grace window after.
+type revalidatingEntry struct {
+	Value      interface{}
+	FreshUntil time.Time
+}
+
+func init() {
+	RegisterType(&revalidatingEntry{})
+}
+
+// SetStaleWhileRevalidate enables serving entries cached with
+// CacheRevalidatingResult for up to grace after they expire, while at most
+// workers background refreshes run at a time (0 grace disables it)
+func (cm *CacheManager) SetStaleWhileRevalidate(grace time.Duration, workers int) {
+	if cm.refreshes != nil {
+		cm.refreshes.close()
+		cm.refreshes = nil
+	}
+
+	cm.staleGrace = grace
+	if grace > 0 {
+		if workers <= 0 {
+			workers = DefaultRefreshWorkers
+		}
+		cm.refreshes = newRefreshPool(workers, DefaultRefreshTimeout)
+	}
+}
+
+// CacheRevalidatingResult stores a result that, once stale-while-revalidate
+// is enabled, can be served stale for the grace window after its TTL
+func (cm *CacheManager) CacheRevalidatingResult(ctx context.Context, key string, result interface{}, ttl time.Duration) error {
+	if cm.staleGrace <= 0 {
+		return cm.CacheQueryResult(ctx, key, result, ttl)
+	}
+
+	if ttl == 0 {
+		ttl = cm.defaultTTL
+	}
+	entry := &revalidatingEntry{Value: result, FreshUntil: time.Now().Add(ttl)}
+	return cm.CacheQueryResult(ctx, key, entry, ttl+cm.staleGrace)
+}
+
+// GetRevalidatingResult retrieves a result stored with CacheRevalidatingResult.
+// A result past its TTL but within the grace window is returned with stale
+// set, and refresh is scheduled to run in the background, once per key.
+// Returns (results, found, stale, error)
+func (cm *CacheManager) GetRevalidatingResult(ctx context.Context, key string, refresh RefreshFunc) (interface{}, bool, bool, error) {
+	cached, found, err := cm.GetCachedQueryResult(ctx, key)
+	if err != nil || !found {
+		return nil, false, false, err
+	}
+
+	entry, ok := cached.(*revalidatingEntry)
+	if !ok {
+		// Cached without a grace window
+		return cached, true, false, nil
+	}
+	if time.Now().Before(entry.FreshUntil) {
+		return entry.Value, true, false, nil
+	}
+
+	cm.staleHits.Add(1)
+	if cm.refreshes != nil && refresh != nil {
+		cm.refreshes.schedule(key, entry.Value, refresh)
+	}
+	return entry.Value, true, true, nil
+}
+
+// refreshPool runs refreshes of stale entries on a fixed number of workers
+// Algorithm:
+// 1. Deduplication: a key waiting for or being refreshed is not scheduled again
+// 2. Bounded queue: refreshes beyond the queue are dropped, the next stale read retries
+// 3. Cancellation: closing the pool cancels running refreshes and drops queued ones
+type refreshPool struct {
+	mu      sync.Mutex
+	jobs    chan refreshJob
+	pending map[string]bool
+	closed  bool
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	timeout time.Duration
+	wg      sync.WaitGroup
+
+	// Statistics
+	completed atomic.Int64
+	failed    atomic.Int64
+	dropped   atomic.Int64
+}
+
+// refreshJob is a scheduled refresh of one key
+type refreshJob struct {
+	key     string
+	stale   interface{}
+	refresh RefreshFunc
+}
+
+// newRefreshPool starts a pool of workers, each refresh limited to timeout
+func newRefreshPool(workers int, timeout time.Duration) *refreshPool {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &refreshPool{
+		jobs:    make(chan refreshJob, workers*refreshQueuePerWorker),
+		pending: make(map[string]bool),
+		ctx:     ctx,
+		cancel:  cancel,
+		timeout: timeout,
+	}
+
+	p.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go p.work()
+	}
+
+	return p
+}
+
+// schedule queues a refresh of key unless one is already pending
+func (p *refreshPool) schedule(key string, stale interface{}, refresh RefreshFunc) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed || p.pending[key] {
+		return
+	}
+
+	select {
+	case p.jobs <- refreshJob{key: key, stale: stale, refresh: refresh}:
+		p.pending[key] = true
+	default:
+		p.dropped.Add(1)
+	}
+}
+
+// work runs queued refreshes until the pool is closed
+func (p *refreshPool) work() {
+	defer p.wg.Done()
+
+	for job := range p.jobs {
+		if p.ctx.Err() == nil {
+			if err := p.run(job); err != nil {
+				p.failed.Add(1)
+			} else {
+				p.completed.Add(1)
+			}
+		}
+
+		p.mu.Lock()
+		delete(p.pending, job.key)
+		p.mu.Unlock()
+	}
+}
+
+// run calls a job's refresh function; a panic fails the refresh instead of
+// crashing the server
+func (p *refreshPool) run(job refreshJob) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(p.ctx, p.timeout)
+	defer cancel()
+	return job.refresh(ctx, job.key, job.stale)
+}
+
+// close cancels running refreshes and waits for the workers to stop
+func (p *refreshPool) close() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	p.cancel()
+	close(p.jobs)
+	p.mu.Unlock()
+
+	p.wg.Wait()
+}
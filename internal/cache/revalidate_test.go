@@ -0,0 +1,154 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// waitForRefreshes waits until the manager has completed or failed n refreshes
+func waitForRefreshes(t *testing.T, cm *CacheManager, n int64) CacheStats {
+	t.Helper()
+	ctx := context.Background()
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		if stats := cm.GetCacheStats(ctx); stats.Refreshes+stats.RefreshFailures >= n {
+			return stats
+		}
+	}
+	t.Fatalf("GetCacheStats() = %+v, want %d refreshes", cm.GetCacheStats(ctx), n)
+	return CacheStats{}
+}
+
+// TestStaleWhileRevalidate tests serving an expired entry within the grace window while it is refreshed
+func TestStaleWhileRevalidate(t *testing.T) {
+	cm := NewCacheManager(true, 100, time.Minute)
+	defer cm.Close()
+	cm.SetStaleWhileRevalidate(time.Minute, 1)
+	ctx := context.Background()
+
+	release := make(chan struct{})
+	var calls atomic.Int32
+	refresh := func(ctx context.Context, key string, stale interface{}) error {
+		calls.Add(1)
+		if stale != "old answer" {
+			t.Errorf("refresh got stale value %v", stale)
+		}
+		<-release
+		return cm.CacheRevalidatingResult(ctx, key, "new answer", 0)
+	}
+
+	_ = cm.CacheRevalidatingResult(ctx, "question", "old answer", 20*time.Millisecond)
+	value, found, stale, err := cm.GetRevalidatingResult(ctx, "question", refresh)
+	if err != nil || !found || stale || value != "old answer" {
+		t.Fatalf("fresh read = %v, %v, %v, %v", value, found, stale, err)
+	}
+	if calls.Load() != 0 {
+		t.Error("fresh entries should not be refreshed")
+	}
+
+	// Past the TTL: served stale, refreshed once however often it is read
+	time.Sleep(30 * time.Millisecond)
+	for i := 0; i < 3; i++ {
+		value, found, stale, err = cm.GetRevalidatingResult(ctx, "question", refresh)
+		if err != nil || !found || !stale || value != "old answer" {
+			t.Fatalf("stale read %d = %v, %v, %v, %v", i, value, found, stale, err)
+		}
+	}
+	close(release)
+	stats := waitForRefreshes(t, cm, 1)
+	if calls.Load() != 1 || stats.StaleHits != 3 || stats.Refreshes != 1 {
+		t.Errorf("refresh calls = %d, stats = %+v, want 1 refresh of 3 stale hits", calls.Load(), stats)
+	}
+
+	value, _, stale, _ = cm.GetRevalidatingResult(ctx, "question", refresh)
+	if stale || value != "new answer" {
+		t.Errorf("read after refresh = %v (stale %v), want the fresh answer", value, stale)
+	}
+}
+
+// TestStaleWhileRevalidateGraceWindow tests that entries are gone once the grace window ends
+func TestStaleWhileRevalidateGraceWindow(t *testing.T) {
+	tests := []struct {
+		name      string
+		grace     time.Duration
+		wantFound bool
+	}{
+		{name: "within grace window", grace: time.Minute, wantFound: true},
+		{name: "after grace window", grace: 10 * time.Millisecond, wantFound: false},
+		{name: "disabled", grace: 0, wantFound: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm := NewCacheManager(true, 100, time.Minute)
+			defer cm.Close()
+			cm.SetStaleWhileRevalidate(tt.grace, 1)
+			ctx := context.Background()
+
+			_ = cm.CacheRevalidatingResult(ctx, "question", "answer", 10*time.Millisecond)
+			time.Sleep(30 * time.Millisecond)
+
+			_, found, stale, err := cm.GetRevalidatingResult(ctx, "question", nil)
+			if err != nil || found != tt.wantFound || stale != tt.wantFound {
+				t.Errorf("GetRevalidatingResult() found = %v, stale = %v, err = %v; want found %v", found, stale, err, tt.wantFound)
+			}
+		})
+	}
+}
+
+// TestRefreshPoolBounded tests that refreshes run on a bounded number of workers and excess ones are dropped
+func TestRefreshPoolBounded(t *testing.T) {
+	pool := newRefreshPool(2, time.Minute)
+	release := make(chan struct{})
+	var running, maxRunning atomic.Int32
+	refresh := func(ctx context.Context, key string, stale interface{}) error {
+		n := running.Add(1)
+		for {
+			m := maxRunning.Load()
+			if n <= m || maxRunning.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		<-release
+		running.Add(-1)
+		if key == "failing" {
+			return errors.New("refresh failed")
+		}
+		return nil
+	}
+
+	// Occupy both workers, then fill the queue and overflow it by 5
+	pool.schedule("failing", nil, refresh)
+	pool.schedule("key0", nil, refresh)
+	for deadline := time.Now().Add(2 * time.Second); running.Load() < 2 && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	queued := 2 + 2*refreshQueuePerWorker
+	for i := 1; i <= 2*refreshQueuePerWorker+5; i++ {
+		pool.schedule(fmt.Sprintf("key%d", i), nil, refresh)
+	}
+	// A key already pending is not scheduled twice
+	pool.schedule("failing", nil, refresh)
+
+	close(release)
+	for deadline := time.Now().Add(2 * time.Second); pool.completed.Load()+pool.failed.Load() < int64(queued) && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	pool.close()
+
+	if maxRunning.Load() > 2 {
+		t.Errorf("%d refreshes ran at once, want at most 2", maxRunning.Load())
+	}
+	if pool.completed.Load()+pool.failed.Load() != int64(queued) || pool.failed.Load() != 1 {
+		t.Errorf("completed = %d, failed = %d, want %d refreshes with 1 failure", pool.completed.Load(), pool.failed.Load(), queued)
+	}
+	if pool.dropped.Load() != 5 {
+		t.Errorf("dropped = %d, want 5 refreshes dropped", pool.dropped.Load())
+	}
+
+	// Closed pools ignore new refreshes
+	pool.schedule("late", nil, refresh)
+}
@@ -0,0 +1,160 @@
+package cache
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// DefaultSemanticEntries bounds the questions a SemanticIndex remembers
+const DefaultSemanticEntries = 1000
+
+// SemanticIndex remembers the embeddings of cached questions, so that a
+// question worded differently but meaning the same can be served the cached
+// answer of the closest one
+// Algorithm:
+// 1. Linear scan: cosine similarity against every live entry, O(n·d) per lookup
+// 2. TTL expiration: entries older than the cache TTL are skipped and pruned
+// 3. FIFO eviction: the oldest entry is dropped when maxEntries is reached
+type SemanticIndex struct {
+	mu         sync.Mutex
+	entries    []semanticEntry
+	threshold  float64
+	maxEntries int
+	ttl        time.Duration
+}
+
+// semanticEntry is a cached question and its embedding
+type semanticEntry struct {
+	key       string
+	question  string
+	vector    []float64
+	norm      float64
+	expiresAt time.Time
+}
+
+// SemanticMatch is the cached question closest to a looked up embedding
+type SemanticMatch struct {
+	Key        string  // cache key of the cached answer
+	Question   string  // the cached question as asked
+	Similarity float64 // cosine similarity of the embeddings
+}
+
+// NewSemanticIndex creates an index matching questions at least threshold
+// similar (cosine similarity, 0-1)
+// Parameters:
+//   - maxEntries: Maximum number of questions remembered (0 = DefaultSemanticEntries)
+//   - ttl: How long a question is remembered, normally the cache TTL
+func NewSemanticIndex(threshold float64, maxEntries int, ttl time.Duration) *SemanticIndex {
+	if maxEntries <= 0 {
+		maxEntries = DefaultSemanticEntries
+	}
+	if ttl == 0 {
+		ttl = 5 * time.Minute
+	}
+
+	return &SemanticIndex{
+		threshold:  threshold,
+		maxEntries: maxEntries,
+		ttl:        ttl,
+	}
+}
+
+// Add remembers the embedding of the question cached under key, replacing an
+// earlier embedding for the same key
+func (si *SemanticIndex) Add(key, question string, vector []float64) {
+	norm := vectorNorm(vector)
+	if norm == 0 {
+		return
+	}
+
+	si.mu.Lock()
+	defer si.mu.Unlock()
+
+	si.removeLocked(key)
+	if len(si.entries) >= si.maxEntries {
+		si.entries = si.entries[1:]
+	}
+	si.entries = append(si.entries, semanticEntry{
+		key:       key,
+		question:  question,
+		vector:    vector,
+		norm:      norm,
+		expiresAt: time.Now().Add(si.ttl),
+	})
+}
+
+// Lookup returns the remembered question most similar to vector, if it is
+// at least as similar as the threshold
+func (si *SemanticIndex) Lookup(vector []float64) (SemanticMatch, bool) {
+	norm := vectorNorm(vector)
+	if norm == 0 {
+		return SemanticMatch{}, false
+	}
+
+	si.mu.Lock()
+	defer si.mu.Unlock()
+
+	now := time.Now()
+	live := si.entries[:0]
+	var best SemanticMatch
+	found := false
+	for _, entry := range si.entries {
+		if now.After(entry.expiresAt) {
+			continue
+		}
+		live = append(live, entry)
+
+		if len(entry.vector) != len(vector) {
+			continue
+		}
+		similarity := dot(entry.vector, vector) / (entry.norm * norm)
+		if similarity >= si.threshold && (!found || similarity > best.Similarity) {
+			best = SemanticMatch{Key: entry.key, Question: entry.question, Similarity: similarity}
+			found = true
+		}
+	}
+	si.entries = live
+
+	return best, found
+}
+
+// Remove forgets the question cached under key, e.g. once its answer has
+// left the cache
+func (si *SemanticIndex) Remove(key string) {
+	si.mu.Lock()
+	defer si.mu.Unlock()
+	si.removeLocked(key)
+}
+
+// Len returns the number of remembered questions, including expired ones
+// not yet pruned
+func (si *SemanticIndex) Len() int {
+	si.mu.Lock()
+	defer si.mu.Unlock()
+	return len(si.entries)
+}
+
+// removeLocked removes the entry for key; si.mu must be held
+func (si *SemanticIndex) removeLocked(key string) {
+	for i, entry := range si.entries {
+		if entry.key == key {
+			si.entries = append(si.entries[:i], si.entries[i+1:]...)
+			return
+		}
+	}
+}
+
+// dot returns the dot product of two vectors of the same length
+func dot(a, b []float64) float64 {
+	var sum float64
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+// vectorNorm returns the Euclidean length of a vector
+func vectorNorm(v []float64) float64 {
+	return math.Sqrt(dot(v, v))
+}
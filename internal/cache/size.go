@@ -0,0 +1,136 @@
+package cache
+
+import (
+	"reflect"
+	"time"
+)
+
+// Approximate bookkeeping costs counted on top of the cached values
+const (
+	entryOverhead    = 200 // map slot, LRU list element, memoryEntry and CacheEntry
+	mapOverhead      = 48  // map header
+	mapEntryOverhead = 16  // per-entry hash bucket space
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// estimateSize returns the approximate number of bytes a value holds,
+// following pointers, slices, maps and interfaces. Memory reachable twice
+// through the same pointer is counted once.
+func estimateSize(value interface{}) int64 {
+	if value == nil {
+		return 0
+	}
+	v := reflect.ValueOf(value)
+	s := sizer{seen: make(map[uintptr]bool)}
+	return int64(v.Type().Size()) + s.referenced(v)
+}
+
+// sizer walks a value, remembering the pointers it has already counted
+type sizer struct {
+	seen map[uintptr]bool
+}
+
+// visit reports whether ptr has not been counted yet, marking it as counted
+func (s *sizer) visit(ptr uintptr) bool {
+	if ptr == 0 || s.seen[ptr] {
+		return false
+	}
+	s.seen[ptr] = true
+	return true
+}
+
+// referenced returns the bytes v refers to outside its own inline size
+func (s *sizer) referenced(v reflect.Value) int64 {
+	switch v.Kind() {
+	case reflect.String:
+		return int64(v.Len())
+
+	case reflect.Slice:
+		if v.IsNil() || !s.visit(v.Pointer()) {
+			return 0
+		}
+		size := int64(v.Cap()) * int64(v.Type().Elem().Size())
+		if mayReference(v.Type().Elem()) {
+			for i := 0; i < v.Len(); i++ {
+				size += s.referenced(v.Index(i))
+			}
+		}
+		return size
+
+	case reflect.Array:
+		var size int64
+		if mayReference(v.Type().Elem()) {
+			for i := 0; i < v.Len(); i++ {
+				size += s.referenced(v.Index(i))
+			}
+		}
+		return size
+
+	case reflect.Map:
+		if v.IsNil() || !s.visit(v.Pointer()) {
+			return 0
+		}
+		entrySize := int64(v.Type().Key().Size()+v.Type().Elem().Size()) + mapEntryOverhead
+		size := mapOverhead + int64(v.Len())*entrySize
+		iter := v.MapRange()
+		for iter.Next() {
+			size += s.referenced(iter.Key()) + s.referenced(iter.Value())
+		}
+		return size
+
+	case reflect.Pointer:
+		if v.IsNil() || !s.visit(v.Pointer()) {
+			return 0
+		}
+		return int64(v.Type().Elem().Size()) + s.referenced(v.Elem())
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return 0
+		}
+		elem := v.Elem()
+		switch elem.Kind() {
+		case reflect.Pointer, reflect.Map, reflect.Chan, reflect.Func, reflect.UnsafePointer:
+			// Stored in the interface itself
+			return s.referenced(elem)
+		default:
+			// Boxed on the heap
+			return int64(elem.Type().Size()) + s.referenced(elem)
+		}
+
+	case reflect.Struct:
+		if v.Type() == timeType {
+			// Only refers to a shared *time.Location
+			return 0
+		}
+		var size int64
+		for i := 0; i < v.NumField(); i++ {
+			size += s.referenced(v.Field(i))
+		}
+		return size
+	}
+
+	return 0
+}
+
+// mayReference reports whether values of type t can refer to memory outside
+// their inline size
+func mayReference(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Pointer, reflect.Interface:
+		return true
+	case reflect.Array:
+		return mayReference(t.Elem())
+	case reflect.Struct:
+		if t == timeType {
+			return false
+		}
+		for i := 0; i < t.NumField(); i++ {
+			if mayReference(t.Field(i).Type) {
+				return true
+			}
+		}
+	}
+	return false
+}
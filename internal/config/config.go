@@ -1,119 +1,407 @@
-package config
-
-import (
-	"fmt"
-	"os"
-	"strconv"
-
-	"github.com/joho/godotenv"
-)
-
-// Default LLM models for each provider
-const (
-	DefaultOpenAIModel = "gpt-4o-mini"
-	DefaultGroqModel   = "llama-3.3-70b-versatile"
-	DefaultOllamaModel = "llama3"
-)
-
-// Config holds all application configuration loaded from environment variables
-type Config struct {
-	// Database
-	DatabaseURL string
-
-	// LLM Provider
-	OpenAIAPIKey string
-	GroqAPIKey   string
-	OllamaURL    string
-	LLMModel     string
-	LLMProvider  string
-
-	// Server
-	Port string
-	Env  string
-
-	// Jira
-	JiraBaseURL    string
-	JiraEmail      string
-	JiraAPIToken   string
-	JiraProjectKey string
-
-	// Debug
-	Debug bool
-}
-
-// Load reads configuration from environment variables
-// It attempts to load from .env file first (for local development)
-// but doesn't fail if the file doesn't exist (for production/Docker environments)
-func Load() (*Config, error) {
-	// Try to load .env file - ignore error if it doesn't exist
-	// In production/Docker, env vars will be set directly
-	_ = godotenv.Load()
-
-	cfg := &Config{
-		DatabaseURL:  getEnv("DATABASE_URL", ""),
-		OpenAIAPIKey: getEnv("OPENAI_API_KEY", ""),
-		GroqAPIKey:   getEnv("GROQ_API_KEY", ""),
-		OllamaURL:    getEnv("OLLAMA_URL", "http://localhost:11434"),
-		LLMModel:     getEnv("LLM_MODEL", ""),
-		LLMProvider:  getEnv("LLM_PROVIDER", "mock"),
-		Port:         getEnv("PORT", "8080"),
-		Env:          getEnv("ENV", "development"),
-		JiraBaseURL:    getEnv("JIRA_BASE_URL", ""),
-		JiraEmail:      getEnv("JIRA_EMAIL", ""),
-		JiraAPIToken:   getEnv("JIRA_API_TOKEN", ""),
-		JiraProjectKey: getEnv("JIRA_PROJECT_KEY", ""),
-		Debug:          getEnvBool("DEBUG", false),
-	}
-
-	// Validate required fields
-	if cfg.DatabaseURL == "" {
-		return nil, fmt.Errorf("DATABASE_URL environment variable is required")
-	}
-
-	// Validate LLM configuration based on provider
-	switch cfg.LLMProvider {
-	case "openai":
-		if cfg.OpenAIAPIKey == "" {
-			return nil, fmt.Errorf("OPENAI_API_KEY is required when LLM_PROVIDER=openai")
-		}
-		if cfg.LLMModel == "" {
-			cfg.LLMModel = DefaultOpenAIModel
-		}
-	case "groq":
-		if cfg.GroqAPIKey == "" {
-			return nil, fmt.Errorf("GROQ_API_KEY is required when LLM_PROVIDER=groq")
-		}
-		if cfg.LLMModel == "" {
-			cfg.LLMModel = DefaultGroqModel
-		}
-	case "ollama":
-		if cfg.LLMModel == "" {
-			cfg.LLMModel = DefaultOllamaModel
-		}
-	case "mock":
-		// No validation needed for mock
-	default:
-		return nil, fmt.Errorf("invalid LLM_PROVIDER: %s (must be: openai, groq, ollama, or mock)", cfg.LLMProvider)
-	}
-
-	return cfg, nil
-}
-
-// getEnv retrieves an environment variable or returns a default value
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
-}
-
-// getEnvBool retrieves a boolean environment variable
-func getEnvBool(key string, defaultValue bool) bool {
-	if value := os.Getenv(key); value != "" {
-		boolVal, err := strconv.ParseBool(value)
-		if err == nil {
-			return boolVal
-		}
-	}
-	return defaultValue
-}
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// Default LLM models for each provider
+const (
+	DefaultOpenAIModel = "gpt-4o-mini"
+	DefaultGroqModel   = "llama-3.3-70b-versatile"
+	DefaultOllamaModel = "llama3"
+)
+
+// Config holds all application configuration loaded from environment variables
+type Config struct {
+	// Database
+	DatabaseURL string
+
+	// LLM Provider
+	OpenAIAPIKey string
+	GroqAPIKey   string
+	OllamaURL    string
+	LLMModel     string
+	LLMProvider  string
+	LLMProviders []string      // fallback chain, tried in order; LLMProvider is the first entry
+	LLMCooldown  time.Duration // how long a failing provider is skipped
+
+	// LLM request retries (429, 5xx and dropped connections)
+	LLMMaxRetries     int
+	LLMRetryBaseDelay time.Duration
+	LLMRetryMaxDelay  time.Duration
+
+	// Server
+	Port            string
+	Env             string
+	MigrationsDir   string
+	ShutdownTimeout time.Duration
+	PublicBaseURL   string // URL the API is reachable at, used to link tickets to analyses
+
+	// Generated query limits (applied with SET LOCAL per query)
+	QueryStatementTimeout time.Duration
+	QueryLockTimeout      time.Duration
+	QueryWorkMem          string
+	MaxQueryRows          int // rows fetched per generated query; 0 disables the cap
+	SQLRepairAttempts     int // LLM repairs of a query rejected by Postgres; 0 disables repair
+
+	// Schema catalog refresh interval (0 loads the schema only at startup)
+	SchemaRefreshInterval time.Duration
+
+	// Earlier turns passed to the LLM for follow-up questions (0 disables conversations)
+	ConversationTurns int
+
+	// Query result cache: memory (per process) or redis (shared by replicas)
+	CacheBackend   string
+	RedisURL       string // redis://[user:password@]host:port/db
+	CacheNamespace string // prefix of the cache's redis keys
+	CacheMaxBytes  int64  // approximate memory limit of the memory cache; 0 disables
+
+	// Stale-while-revalidate: expired answers are served for the grace window
+	// while at most CacheRefreshWorkers background refreshes run
+	CacheStaleGrace     time.Duration // 0 disables
+	CacheRefreshWorkers int
+
+	// Semantic cache: answers a question from the cached answer of a similar one
+	SemanticCacheThreshold float64 // embedding similarity treated as the same question; 0 disables
+	EmbeddingProvider      string  // openai, ollama or mock; defaults to the LLM provider
+	EmbeddingModel         string  // empty uses the provider's default embedding model
+
+	// Issue tracker receiving tickets from /api/jira-tickets: jira, github or linear
+	IssueTracker string
+
+	// Jira
+	JiraBaseURL            string
+	JiraEmail              string
+	JiraAPIToken           string
+	JiraProjectKey         string
+	JiraAPIVersion         int           // REST API version: 2 (wiki markup) or 3 (Atlassian Document Format)
+	JiraDuplicateThreshold float64       // summary similarity treated as a duplicate; 0 disables
+	JiraEpicLinkField      string        // classic projects' Epic Link custom field; empty uses parent
+	JiraEpicNameField      string        // classic projects' Epic Name custom field
+	JiraDraftTTL           time.Duration // how long a dry-run draft can be confirmed
+	JiraFieldMappingFile   string        // JSON mapping of ticket attributes to custom fields and assignees
+	JiraWebhookSecret      string        // secret Jira signs status webhooks with; empty disables the webhook
+	JiraSyncInterval       time.Duration // how often ticket statuses are polled; 0 disables polling
+	JiraConcurrency        int           // issues checked for duplicates or given evidence at once
+	JiraMaxRetries         int           // retries of a rate-limited request
+
+	// GitHub Issues
+	GitHubToken      string
+	GitHubRepository string // "owner/repo"
+	GitHubAPIURL     string
+
+	// Linear
+	LinearAPIKey string
+	LinearTeamID string
+	LinearAPIURL string
+
+	// Debug
+	Debug bool
+}
+
+// Load reads configuration from environment variables
+// It attempts to load from .env file first (for local development)
+// but doesn't fail if the file doesn't exist (for production/Docker environments)
+func Load() (*Config, error) {
+	// Try to load .env file - ignore error if it doesn't exist
+	// In production/Docker, env vars will be set directly
+	_ = godotenv.Load()
+
+	cfg := &Config{
+		DatabaseURL:  getEnv("DATABASE_URL", ""),
+		OpenAIAPIKey: getEnv("OPENAI_API_KEY", ""),
+		GroqAPIKey:   getEnv("GROQ_API_KEY", ""),
+		OllamaURL:    getEnv("OLLAMA_URL", "http://localhost:11434"),
+		LLMModel:     getEnv("LLM_MODEL", ""),
+		LLMProvider:  getEnv("LLM_PROVIDER", "mock"),
+		LLMProviders: getEnvList("LLM_PROVIDERS"),
+		LLMCooldown:  getEnvDuration("LLM_PROVIDER_COOLDOWN", 30*time.Second),
+		LLMMaxRetries:     getEnvInt("LLM_MAX_RETRIES", 2),
+		LLMRetryBaseDelay: getEnvDuration("LLM_RETRY_BASE_DELAY", 500*time.Millisecond),
+		LLMRetryMaxDelay:  getEnvDuration("LLM_RETRY_MAX_DELAY", 10*time.Second),
+		Port:         getEnv("PORT", "8080"),
+		Env:          getEnv("ENV", "development"),
+		MigrationsDir:   getEnv("MIGRATIONS_DIR", "migrations"),
+		ShutdownTimeout: getEnvDuration("SHUTDOWN_TIMEOUT", 60*time.Second),
+		PublicBaseURL:   getEnv("PUBLIC_BASE_URL", ""),
+		QueryStatementTimeout: getEnvDuration("QUERY_STATEMENT_TIMEOUT", 30*time.Second),
+		QueryLockTimeout:      getEnvDuration("QUERY_LOCK_TIMEOUT", 5*time.Second),
+		QueryWorkMem:          getEnv("QUERY_WORK_MEM", "16MB"),
+		MaxQueryRows:          getEnvInt("MAX_QUERY_ROWS", 1000),
+		SQLRepairAttempts:     getEnvInt("SQL_REPAIR_ATTEMPTS", 2),
+		SchemaRefreshInterval: getEnvDuration("SCHEMA_REFRESH_INTERVAL", 5*time.Minute),
+		ConversationTurns:     getEnvInt("CONVERSATION_TURNS", 5),
+		CacheBackend:   strings.ToLower(getEnv("CACHE_BACKEND", "memory")),
+		RedisURL:       getEnv("REDIS_URL", ""),
+		CacheNamespace: getEnv("CACHE_NAMESPACE", "goinsight"),
+		CacheMaxBytes:  getEnvBytes("CACHE_MAX_BYTES", 64<<20),
+		CacheStaleGrace:     getEnvDuration("CACHE_STALE_GRACE", 0),
+		CacheRefreshWorkers: getEnvInt("CACHE_REFRESH_WORKERS", 2),
+		SemanticCacheThreshold: getEnvFloat("SEMANTIC_CACHE_THRESHOLD", 0),
+		EmbeddingProvider:      strings.ToLower(getEnv("EMBEDDING_PROVIDER", "")),
+		EmbeddingModel:         getEnv("EMBEDDING_MODEL", ""),
+		IssueTracker:   strings.ToLower(getEnv("ISSUE_TRACKER", "jira")),
+		JiraBaseURL:    getEnv("JIRA_BASE_URL", ""),
+		JiraEmail:      getEnv("JIRA_EMAIL", ""),
+		JiraAPIToken:   getEnv("JIRA_API_TOKEN", ""),
+		JiraProjectKey: getEnv("JIRA_PROJECT_KEY", ""),
+		JiraAPIVersion:         getEnvInt("JIRA_API_VERSION", 2),
+		JiraDuplicateThreshold: getEnvFloat("JIRA_DUPLICATE_THRESHOLD", 0.6),
+		JiraEpicLinkField:      getEnv("JIRA_EPIC_LINK_FIELD", ""),
+		JiraEpicNameField:      getEnv("JIRA_EPIC_NAME_FIELD", ""),
+		JiraDraftTTL:           getEnvDuration("JIRA_DRAFT_TTL", 24*time.Hour),
+		JiraFieldMappingFile:   getEnv("JIRA_FIELD_MAPPING_FILE", ""),
+		JiraWebhookSecret:      getEnv("JIRA_WEBHOOK_SECRET", ""),
+		JiraSyncInterval:       getEnvDuration("JIRA_SYNC_INTERVAL", 15*time.Minute),
+		JiraConcurrency:        getEnvInt("JIRA_CONCURRENCY", 4),
+		JiraMaxRetries:         getEnvInt("JIRA_MAX_RETRIES", 3),
+		GitHubToken:            getEnv("GITHUB_TOKEN", ""),
+		GitHubRepository:       getEnv("GITHUB_REPOSITORY", ""),
+		GitHubAPIURL:           getEnv("GITHUB_API_URL", "https://api.github.com"),
+		LinearAPIKey:           getEnv("LINEAR_API_KEY", ""),
+		LinearTeamID:           getEnv("LINEAR_TEAM_ID", ""),
+		LinearAPIURL:           getEnv("LINEAR_API_URL", "https://api.linear.app/graphql"),
+		Debug:          getEnvBool("DEBUG", false),
+	}
+
+	// Validate required fields
+	if cfg.DatabaseURL == "" {
+		return nil, fmt.Errorf("DATABASE_URL environment variable is required")
+	}
+
+	if cfg.MaxQueryRows < 0 {
+		return nil, fmt.Errorf("MAX_QUERY_ROWS must not be negative")
+	}
+	if cfg.SQLRepairAttempts < 0 {
+		return nil, fmt.Errorf("SQL_REPAIR_ATTEMPTS must not be negative")
+	}
+	if cfg.ConversationTurns < 0 {
+		return nil, fmt.Errorf("CONVERSATION_TURNS must not be negative")
+	}
+	if cfg.CacheMaxBytes < 0 {
+		return nil, fmt.Errorf("CACHE_MAX_BYTES must not be negative")
+	}
+	if cfg.CacheStaleGrace < 0 {
+		return nil, fmt.Errorf("CACHE_STALE_GRACE must not be negative")
+	}
+	if cfg.CacheRefreshWorkers < 1 {
+		return nil, fmt.Errorf("CACHE_REFRESH_WORKERS must be at least 1")
+	}
+	switch cfg.CacheBackend {
+	case "memory":
+	case "redis":
+		if cfg.RedisURL == "" {
+			return nil, fmt.Errorf("REDIS_URL is required when CACHE_BACKEND is redis")
+		}
+	default:
+		return nil, fmt.Errorf("CACHE_BACKEND must be memory or redis")
+	}
+	switch cfg.IssueTracker {
+	case "jira", "linear":
+	case "github":
+		if owner, repo, ok := strings.Cut(cfg.GitHubRepository, "/"); cfg.GitHubRepository != "" && (!ok || owner == "" || repo == "" || strings.Contains(repo, "/")) {
+			return nil, fmt.Errorf("GITHUB_REPOSITORY must be in owner/repo form")
+		}
+	default:
+		return nil, fmt.Errorf("ISSUE_TRACKER must be jira, github or linear")
+	}
+	if cfg.JiraDraftTTL <= 0 {
+		return nil, fmt.Errorf("JIRA_DRAFT_TTL must be positive")
+	}
+	if cfg.JiraSyncInterval < 0 {
+		return nil, fmt.Errorf("JIRA_SYNC_INTERVAL must not be negative")
+	}
+	if cfg.JiraConcurrency < 1 {
+		return nil, fmt.Errorf("JIRA_CONCURRENCY must be at least 1")
+	}
+	if cfg.JiraMaxRetries < 0 {
+		return nil, fmt.Errorf("JIRA_MAX_RETRIES must not be negative")
+	}
+	if cfg.JiraAPIVersion != 2 && cfg.JiraAPIVersion != 3 {
+		return nil, fmt.Errorf("JIRA_API_VERSION must be 2 or 3")
+	}
+	if cfg.JiraDuplicateThreshold < 0 || cfg.JiraDuplicateThreshold > 1 {
+		return nil, fmt.Errorf("JIRA_DUPLICATE_THRESHOLD must be between 0 and 1")
+	}
+	if cfg.LLMMaxRetries < 0 {
+		return nil, fmt.Errorf("LLM_MAX_RETRIES must not be negative")
+	}
+	if cfg.LLMRetryBaseDelay < 0 {
+		return nil, fmt.Errorf("LLM_RETRY_BASE_DELAY must not be negative")
+	}
+	if cfg.LLMRetryMaxDelay < 0 {
+		return nil, fmt.Errorf("LLM_RETRY_MAX_DELAY must not be negative")
+	}
+
+	// LLM_PROVIDERS overrides LLM_PROVIDER with an ordered fallback chain
+	if len(cfg.LLMProviders) == 0 {
+		cfg.LLMProviders = []string{cfg.LLMProvider}
+	}
+	cfg.LLMProvider = cfg.LLMProviders[0]
+
+	// Validate LLM configuration based on provider
+	for _, provider := range cfg.LLMProviders {
+		switch provider {
+		case "openai":
+			if cfg.OpenAIAPIKey == "" {
+				return nil, fmt.Errorf("OPENAI_API_KEY is required when using the openai provider")
+			}
+		case "groq":
+			if cfg.GroqAPIKey == "" {
+				return nil, fmt.Errorf("GROQ_API_KEY is required when using the groq provider")
+			}
+		case "ollama", "mock":
+			// No validation needed
+		default:
+			return nil, fmt.Errorf("invalid LLM provider: %s (must be: openai, groq, ollama, or mock)", provider)
+		}
+	}
+	if cfg.LLMModel == "" {
+		cfg.LLMModel = DefaultModel(cfg.LLMProvider)
+	}
+
+	// Validate the semantic cache; embeddings default to the LLM provider
+	if cfg.SemanticCacheThreshold < 0 || cfg.SemanticCacheThreshold > 1 {
+		return nil, fmt.Errorf("SEMANTIC_CACHE_THRESHOLD must be between 0 and 1")
+	}
+	if cfg.SemanticCacheThreshold > 0 {
+		if cfg.EmbeddingProvider == "" {
+			cfg.EmbeddingProvider = cfg.LLMProvider
+		}
+		switch cfg.EmbeddingProvider {
+		case "openai":
+			if cfg.OpenAIAPIKey == "" {
+				return nil, fmt.Errorf("OPENAI_API_KEY is required when using openai embeddings")
+			}
+		case "ollama", "mock":
+			// No validation needed
+		default:
+			return nil, fmt.Errorf("invalid embedding provider: %s (must be: openai, ollama, or mock; set EMBEDDING_PROVIDER)", cfg.EmbeddingProvider)
+		}
+	}
+
+	return cfg, nil
+}
+
+// ModelFor returns the model to use for a provider in the fallback chain:
+// LLM_MODEL for the primary provider and the provider default for the rest
+func (c *Config) ModelFor(provider string) string {
+	if provider == c.LLMProvider {
+		return c.LLMModel
+	}
+	return DefaultModel(provider)
+}
+
+// DefaultModel returns the default model for a provider, or "" if it has none
+func DefaultModel(provider string) string {
+	switch provider {
+	case "openai":
+		return DefaultOpenAIModel
+	case "groq":
+		return DefaultGroqModel
+	case "ollama":
+		return DefaultOllamaModel
+	default:
+		return ""
+	}
+}
+
+// getEnv retrieves an environment variable or returns a default value
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvBool retrieves a boolean environment variable
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		boolVal, err := strconv.ParseBool(value)
+		if err == nil {
+			return boolVal
+		}
+	}
+	return defaultValue
+}
+
+// getEnvInt retrieves an integer environment variable
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		intVal, err := strconv.Atoi(value)
+		if err == nil {
+			return intVal
+		}
+	}
+	return defaultValue
+}
+
+// getEnvFloat retrieves a floating-point environment variable
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		floatVal, err := strconv.ParseFloat(value, 64)
+		if err == nil {
+			return floatVal
+		}
+	}
+	return defaultValue
+}
+
+// getEnvDuration retrieves a duration environment variable (e.g. "30s", "5m")
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		duration, err := time.ParseDuration(value)
+		if err == nil {
+			return duration
+		}
+	}
+	return defaultValue
+}
+
+// byteUnits are the suffixes accepted by getEnvBytes
+var byteUnits = []struct {
+	suffix string
+	size   int64
+}{
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+// getEnvBytes retrieves a byte size environment variable (e.g. "64MB", "512KB", "1048576")
+func getEnvBytes(key string, defaultValue int64) int64 {
+	value := strings.ToUpper(strings.TrimSpace(os.Getenv(key)))
+	if value == "" {
+		return defaultValue
+	}
+	unit := int64(1)
+	for _, u := range byteUnits {
+		if strings.HasSuffix(value, u.suffix) {
+			value, unit = strings.TrimSpace(strings.TrimSuffix(value, u.suffix)), u.size
+			break
+		}
+	}
+	size, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return defaultValue
+	}
+	return size * unit
+}
+
+// getEnvList retrieves a comma-separated environment variable (e.g. "groq,openai")
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,32 @@
+package db
+
+import (
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+// PostgreSQL error classes caused by the text of a query
+const (
+	pgClassSyntaxOrAccess = "42" // syntax error, undefined column/table/function, grouping error
+	pgClassDataException  = "22" // invalid input syntax, division by zero, out of range
+)
+
+// IsQueryError reports whether err is a PostgreSQL error caused by the query
+// itself (as opposed to a connection problem or a cancelled statement), so
+// that a corrected query may succeed
+func IsQueryError(err error) bool {
+	if err == nil || IsQueryTimeout(err) {
+		return false
+	}
+
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	switch pqErr.Code.Class() {
+	case pgClassSyntaxOrAccess, pgClassDataException:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+// TestIsQueryError tests which errors are attributed to the query text
+func TestIsQueryError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "undefined column",
+			err:  &pq.Error{Code: "42703", Message: `column "acount_id" does not exist`},
+			want: true,
+		},
+		{
+			name: "grouping error",
+			err:  fmt.Errorf("query failed: %w", &pq.Error{Code: "42803", Message: "must appear in the GROUP BY clause"}),
+			want: true,
+		},
+		{
+			name: "invalid input syntax",
+			err:  &pq.Error{Code: "22P02", Message: "invalid input syntax for type integer"},
+			want: true,
+		},
+		{
+			name: "read-only transaction",
+			err:  &pq.Error{Code: "25006", Message: "cannot execute INSERT in a read-only transaction"},
+		},
+		{
+			name: "statement timeout",
+			err:  &QueryTimeoutError{Limit: "statement_timeout", Err: &pq.Error{Code: "57014"}},
+		},
+		{
+			name: "connection error",
+			err:  errors.New("dial tcp: connection refused"),
+		},
+		{
+			name: "context cancelled",
+			err:  context.Canceled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsQueryError(tt.err); got != tt.want {
+				t.Errorf("IsQueryError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// PostgreSQL error codes raised when a statement is cancelled by a limit
+const (
+	pgQueryCanceled    = "57014" // statement_timeout or cancel request
+	pgLockNotAvailable = "55P03" // lock_timeout
+)
+
+// QueryLimits bounds the resources a single generated query may use.
+// Zero values leave the server defaults in place.
+type QueryLimits struct {
+	StatementTimeout time.Duration
+	LockTimeout      time.Duration
+	WorkMem          string // e.g. "16MB"
+}
+
+// DefaultQueryLimits returns the limits applied to generated SQL by default
+func DefaultQueryLimits() QueryLimits {
+	return QueryLimits{
+		StatementTimeout: 30 * time.Second,
+		LockTimeout:      5 * time.Second,
+		WorkMem:          "16MB",
+	}
+}
+
+// workMemPattern matches the memory units accepted for work_mem
+var workMemPattern = regexp.MustCompile(`^[0-9]+(kB|MB|GB)?$`)
+
+// Validate checks that the limits are well formed
+func (l QueryLimits) Validate() error {
+	if l.StatementTimeout < 0 {
+		return fmt.Errorf("statement timeout must not be negative")
+	}
+	if l.LockTimeout < 0 {
+		return fmt.Errorf("lock timeout must not be negative")
+	}
+	if l.WorkMem != "" && !workMemPattern.MatchString(l.WorkMem) {
+		return fmt.Errorf("invalid work_mem %q (expected e.g. 4096kB, 16MB or 1GB)", l.WorkMem)
+	}
+	return nil
+}
+
+// statements returns the SET LOCAL commands for the limits. SET does not
+// accept bind parameters, so values are validated and formatted here.
+func (l QueryLimits) statements() ([]string, error) {
+	if err := l.Validate(); err != nil {
+		return nil, err
+	}
+
+	var stmts []string
+	if l.StatementTimeout > 0 {
+		stmts = append(stmts, fmt.Sprintf("SET LOCAL statement_timeout = '%dms'", l.StatementTimeout.Milliseconds()))
+	}
+	if l.LockTimeout > 0 {
+		stmts = append(stmts, fmt.Sprintf("SET LOCAL lock_timeout = '%dms'", l.LockTimeout.Milliseconds()))
+	}
+	if l.WorkMem != "" {
+		stmts = append(stmts, fmt.Sprintf("SET LOCAL work_mem = '%s'", l.WorkMem))
+	}
+	return stmts, nil
+}
+
+// ApplyLimits sets the limits for the remainder of the transaction
+func ApplyLimits(ctx context.Context, tx *sql.Tx, limits QueryLimits) error {
+	stmts, err := limits.statements()
+	if err != nil {
+		return err
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to apply query limit (%s): %w", stmt, err)
+		}
+	}
+	return nil
+}
+
+// QueryTimeoutError is returned when a query is cancelled for exceeding a time limit
+type QueryTimeoutError struct {
+	Limit   string // "statement_timeout", "lock_timeout" or "deadline"
+	Timeout time.Duration
+	Err     error
+}
+
+// Error implements the error interface
+func (e *QueryTimeoutError) Error() string {
+	if e.Timeout > 0 {
+		return fmt.Sprintf("query cancelled: exceeded %s of %v", e.Limit, e.Timeout)
+	}
+	return fmt.Sprintf("query cancelled: exceeded %s", e.Limit)
+}
+
+// Unwrap returns the underlying driver or context error
+func (e *QueryTimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// IsQueryTimeout reports whether err is or wraps a *QueryTimeoutError
+func IsQueryTimeout(err error) bool {
+	var timeoutErr *QueryTimeoutError
+	return errors.As(err, &timeoutErr)
+}
+
+// ClassifyQueryError converts cancellations caused by the query limits or
+// the request deadline into *QueryTimeoutError; other errors pass through
+func ClassifyQueryError(ctx context.Context, err error, limits QueryLimits) error {
+	if err == nil {
+		return nil
+	}
+
+	if ctx.Err() == context.DeadlineExceeded || errors.Is(err, context.DeadlineExceeded) {
+		return &QueryTimeoutError{Limit: "deadline", Err: err}
+	}
+	if ctx.Err() == context.Canceled {
+		// The caller went away; this is not a limit being hit
+		return err
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case pgQueryCanceled:
+			return &QueryTimeoutError{Limit: "statement_timeout", Timeout: limits.StatementTimeout, Err: err}
+		case pgLockNotAvailable:
+			return &QueryTimeoutError{Limit: "lock_timeout", Timeout: limits.LockTimeout, Err: err}
+		}
+	}
+
+	return err
+}
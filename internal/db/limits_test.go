@@ -0,0 +1,141 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// TestQueryLimitsStatements tests the SET LOCAL commands generated for limits
+func TestQueryLimitsStatements(t *testing.T) {
+	tests := []struct {
+		name      string
+		limits    QueryLimits
+		want      []string
+		shouldErr bool
+	}{
+		{
+			name:   "defaults",
+			limits: DefaultQueryLimits(),
+			want: []string{
+				"SET LOCAL statement_timeout = '30000ms'",
+				"SET LOCAL lock_timeout = '5000ms'",
+				"SET LOCAL work_mem = '16MB'",
+			},
+		},
+		{
+			name:   "zero values keep server defaults",
+			limits: QueryLimits{},
+			want:   nil,
+		},
+		{
+			name:   "sub-second timeout",
+			limits: QueryLimits{LockTimeout: 250 * time.Millisecond},
+			want:   []string{"SET LOCAL lock_timeout = '250ms'"},
+		},
+		{
+			name:      "work_mem injection attempt",
+			limits:    QueryLimits{WorkMem: "16MB'; DROP TABLE feedback_enriched; --"},
+			shouldErr: true,
+		},
+		{
+			name:      "negative timeout",
+			limits:    QueryLimits{StatementTimeout: -time.Second},
+			shouldErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.limits.statements()
+			if (err != nil) != tt.shouldErr {
+				t.Fatalf("statements() error = %v, shouldErr %v", err, tt.shouldErr)
+			}
+			if !tt.shouldErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("statements() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestClassifyQueryError tests mapping of cancellation errors to QueryTimeoutError
+func TestClassifyQueryError(t *testing.T) {
+	limits := DefaultQueryLimits()
+
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	canceled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		err         error
+		wantTimeout bool
+		wantLimit   string
+	}{
+		{
+			name:        "statement timeout",
+			ctx:         context.Background(),
+			err:         &pq.Error{Code: "57014", Message: "canceling statement due to statement timeout"},
+			wantTimeout: true,
+			wantLimit:   "statement_timeout",
+		},
+		{
+			name:        "lock timeout",
+			ctx:         context.Background(),
+			err:         fmt.Errorf("wrapped: %w", &pq.Error{Code: "55P03", Message: "canceling statement due to lock timeout"}),
+			wantTimeout: true,
+			wantLimit:   "lock_timeout",
+		},
+		{
+			name:        "request deadline",
+			ctx:         expired,
+			err:         &pq.Error{Code: "57014", Message: "canceling statement due to user request"},
+			wantTimeout: true,
+			wantLimit:   "deadline",
+		},
+		{
+			name: "caller cancelled",
+			ctx:  canceled,
+			err:  &pq.Error{Code: "57014", Message: "canceling statement due to user request"},
+		},
+		{
+			name: "syntax error passes through",
+			ctx:  context.Background(),
+			err:  &pq.Error{Code: "42601", Message: "syntax error"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ClassifyQueryError(tt.ctx, tt.err, limits)
+
+			var timeoutErr *QueryTimeoutError
+			isTimeout := errors.As(got, &timeoutErr)
+			if isTimeout != tt.wantTimeout {
+				t.Fatalf("ClassifyQueryError() = %v, want timeout %v", got, tt.wantTimeout)
+			}
+			if !tt.wantTimeout {
+				if got != tt.err {
+					t.Errorf("expected error to pass through unchanged, got %v", got)
+				}
+				return
+			}
+			if timeoutErr.Limit != tt.wantLimit {
+				t.Errorf("Limit = %q, want %q", timeoutErr.Limit, tt.wantLimit)
+			}
+			if !errors.Is(got, tt.err) {
+				t.Errorf("expected timeout error to wrap the driver error")
+			}
+			if !IsQueryTimeout(fmt.Errorf("query execution failed: %w", got)) {
+				t.Error("IsQueryTimeout() should see through wrapping")
+			}
+		})
+	}
+}
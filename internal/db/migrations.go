@@ -1,76 +1,76 @@
-package db
-
-import (
-	"database/sql"
-	"fmt"
-	"os"
-	"path/filepath"
-	"sort"
-)
-
-// RunMigrations executes all SQL migration files in the migrations directory
-func RunMigrations(db *sql.DB, migrationsDir string) error {
-	// Create migrations table if it doesn't exist
-	_, err := db.Exec(`
-		CREATE TABLE IF NOT EXISTS schema_migrations (
-			version VARCHAR(255) PRIMARY KEY,
-			applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)
-	`)
-	if err != nil {
-		return fmt.Errorf("failed to create migrations table: %w", err)
-	}
-
-	// Read all migration files
-	files, err := os.ReadDir(migrationsDir)
-	if err != nil {
-		return fmt.Errorf("failed to read migrations directory: %w", err)
-	}
-
-	// Sort files to ensure they run in order
-	var sqlFiles []string
-	for _, file := range files {
-		if !file.IsDir() && filepath.Ext(file.Name()) == ".sql" {
-			sqlFiles = append(sqlFiles, file.Name())
-		}
-	}
-	sort.Strings(sqlFiles)
-
-	// Execute each migration if not already applied
-	for _, filename := range sqlFiles {
-		// Check if already applied
-		var count int
-		err := db.QueryRow("SELECT COUNT(*) FROM schema_migrations WHERE version = $1", filename).Scan(&count)
-		if err != nil {
-			return fmt.Errorf("failed to check migration status: %w", err)
-		}
-
-		if count > 0 {
-			fmt.Printf("Migration %s already applied, skipping\n", filename)
-			continue
-		}
-
-		// Read migration file
-		content, err := os.ReadFile(filepath.Join(migrationsDir, filename))
-		if err != nil {
-			return fmt.Errorf("failed to read migration %s: %w", filename, err)
-		}
-
-		// Execute migration
-		fmt.Printf("Applying migration: %s\n", filename)
-		_, err = db.Exec(string(content))
-		if err != nil {
-			return fmt.Errorf("failed to execute migration %s: %w", filename, err)
-		}
-
-		// Record migration as applied
-		_, err = db.Exec("INSERT INTO schema_migrations (version) VALUES ($1)", filename)
-		if err != nil {
-			return fmt.Errorf("failed to record migration %s: %w", filename, err)
-		}
-
-		fmt.Printf("Successfully applied migration: %s\n", filename)
-	}
-
-	return nil
-}
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// RunMigrations executes all SQL migration files in the migrations directory
+func RunMigrations(db *sql.DB, migrationsDir string) error {
+	// Create migrations table if it doesn't exist
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version VARCHAR(255) PRIMARY KEY,
+			applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create migrations table: %w", err)
+	}
+
+	// Read all migration files
+	files, err := os.ReadDir(migrationsDir)
+	if err != nil {
+		return fmt.Errorf("failed to read migrations directory: %w", err)
+	}
+
+	// Sort files to ensure they run in order
+	var sqlFiles []string
+	for _, file := range files {
+		if !file.IsDir() && filepath.Ext(file.Name()) == ".sql" {
+			sqlFiles = append(sqlFiles, file.Name())
+		}
+	}
+	sort.Strings(sqlFiles)
+
+	// Execute each migration if not already applied
+	for _, filename := range sqlFiles {
+		// Check if already applied
+		var count int
+		err := db.QueryRow("SELECT COUNT(*) FROM schema_migrations WHERE version = $1", filename).Scan(&count)
+		if err != nil {
+			return fmt.Errorf("failed to check migration status: %w", err)
+		}
+
+		if count > 0 {
+			fmt.Printf("Migration %s already applied, skipping\n", filename)
+			continue
+		}
+
+		// Read migration file
+		content, err := os.ReadFile(filepath.Join(migrationsDir, filename))
+		if err != nil {
+			return fmt.Errorf("failed to read migration %s: %w", filename, err)
+		}
+
+		// Execute migration
+		fmt.Printf("Applying migration: %s\n", filename)
+		_, err = db.Exec(string(content))
+		if err != nil {
+			return fmt.Errorf("failed to execute migration %s: %w", filename, err)
+		}
+
+		// Record migration as applied
+		_, err = db.Exec("INSERT INTO schema_migrations (version) VALUES ($1)", filename)
+		if err != nil {
+			return fmt.Errorf("failed to record migration %s: %w", filename, err)
+		}
+
+		fmt.Printf("Successfully applied migration: %s\n", filename)
+	}
+
+	return nil
+}
@@ -0,0 +1,188 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chuckie/goinsight/internal/textutil"
+	"github.com/lib/pq"
+)
+
+// Column describes a table column and its COMMENT ON text
+type Column struct {
+	Name     string
+	DataType string
+	Comment  string
+}
+
+// Table describes a table or view and its COMMENT ON text
+type Table struct {
+	Name    string
+	Comment string
+	Columns []Column
+}
+
+// schemaQuery reads columns and comments for the requested tables in the current schema
+const schemaQuery = `
+	SELECT c.table_name,
+		c.column_name,
+		UPPER(c.data_type),
+		COALESCE(col_description(cls.oid, c.ordinal_position::int), ''),
+		COALESCE(obj_description(cls.oid, 'pg_class'), '')
+	FROM information_schema.columns c
+	JOIN pg_catalog.pg_namespace ns ON ns.nspname = c.table_schema
+	JOIN pg_catalog.pg_class cls ON cls.relnamespace = ns.oid AND cls.relname = c.table_name
+	WHERE c.table_schema = current_schema()
+		AND c.table_name = ANY($1)
+	ORDER BY c.table_name, c.ordinal_position`
+
+// SchemaCatalog holds the columns and comments of the tables generated queries
+// may read. It is loaded from information_schema and refreshed periodically so
+// that new migrations reach the SQL generation prompt without code changes.
+type SchemaCatalog struct {
+	db     *sql.DB
+	tables []string
+
+	mu       sync.RWMutex
+	snapshot []Table
+	loadedAt time.Time
+}
+
+// NewSchemaCatalog creates a catalog for the given tables; call Refresh to load it
+func NewSchemaCatalog(db *sql.DB, tables []string) *SchemaCatalog {
+	return &SchemaCatalog{
+		db:     db,
+		tables: tables,
+	}
+}
+
+// Refresh reloads the catalog from the database. On error the previous
+// snapshot is kept.
+func (c *SchemaCatalog) Refresh(ctx context.Context) error {
+	rows, err := c.db.QueryContext(ctx, schemaQuery, pq.Array(c.tables))
+	if err != nil {
+		return fmt.Errorf("failed to query schema: %w", err)
+	}
+	defer rows.Close()
+
+	byName := make(map[string]*Table)
+	for rows.Next() {
+		var tableName, tableComment string
+		var col Column
+		if err := rows.Scan(&tableName, &col.Name, &col.DataType, &col.Comment, &tableComment); err != nil {
+			return fmt.Errorf("failed to scan schema row: %w", err)
+		}
+
+		table, ok := byName[tableName]
+		if !ok {
+			table = &Table{Name: tableName, Comment: tableComment}
+			byName[tableName] = table
+		}
+		table.Columns = append(table.Columns, col)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read schema: %w", err)
+	}
+
+	// Keep the configured table order
+	snapshot := make([]Table, 0, len(byName))
+	for _, name := range c.tables {
+		if table, ok := byName[name]; ok {
+			snapshot = append(snapshot, *table)
+		}
+	}
+
+	c.mu.Lock()
+	c.snapshot = snapshot
+	c.loadedAt = time.Now()
+	c.mu.Unlock()
+	return nil
+}
+
+// Start refreshes the catalog every interval until ctx is cancelled.
+// Refresh errors are logged and the previous snapshot stays in use.
+func (c *SchemaCatalog) Start(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := c.Refresh(ctx); err != nil && ctx.Err() == nil {
+					fmt.Printf("Schema catalog refresh failed: %v\n", err)
+				}
+			}
+		}
+	}()
+}
+
+// Tables returns the most recently loaded tables
+func (c *SchemaCatalog) Tables() []Table {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.snapshot
+}
+
+// LoadedAt returns when the catalog was last refreshed successfully
+func (c *SchemaCatalog) LoadedAt() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.loadedAt
+}
+
+// DescribeSchema renders the catalog as the table listing used in the SQL
+// generation prompt, or "" if the catalog has not been loaded
+func (c *SchemaCatalog) DescribeSchema() string {
+	return FormatTables(c.Tables())
+}
+
+// FormatTables renders tables as column listings with comments, e.g.
+//
+//	feedback_enriched(
+//	  id         TEXT,
+//	  sentiment  TEXT   -- 'positive', 'neutral' or 'negative'
+//	);
+//	-- Customer feedback enriched with sentiment
+func FormatTables(tables []Table) string {
+	var b strings.Builder
+	for i, table := range tables {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+
+		nameWidth, typeWidth := 0, 0
+		for _, col := range table.Columns {
+			nameWidth = max(nameWidth, len(col.Name))
+			typeWidth = max(typeWidth, len(col.DataType)+1)
+		}
+
+		fmt.Fprintf(&b, "%s(\n", table.Name)
+		for j, col := range table.Columns {
+			dataType := col.DataType
+			if j < len(table.Columns)-1 {
+				dataType += ","
+			}
+			if col.Comment == "" {
+				fmt.Fprintf(&b, "  %-*s  %s\n", nameWidth, col.Name, dataType)
+				continue
+			}
+			fmt.Fprintf(&b, "  %-*s  %-*s  -- %s\n", nameWidth, col.Name, typeWidth, dataType, textutil.OneLine(col.Comment))
+		}
+		b.WriteString(");\n")
+		if table.Comment != "" {
+			fmt.Fprintf(&b, "-- %s\n", textutil.OneLine(table.Comment))
+		}
+	}
+	return b.String()
+}
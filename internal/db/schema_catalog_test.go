@@ -0,0 +1,41 @@
+package db
+
+import "testing"
+
+// TestFormatTables tests rendering of the catalog for the SQL generation prompt
+func TestFormatTables(t *testing.T) {
+	tables := []Table{
+		{
+			Name:    "feedback_enriched",
+			Comment: "Customer feedback.\n  Use for: sentiment analysis",
+			Columns: []Column{
+				{Name: "id", DataType: "TEXT"},
+				{Name: "sentiment", DataType: "TEXT", Comment: "'positive', 'neutral' or 'negative'"},
+				{Name: "account_id", DataType: "CHARACTER VARYING", Comment: "joins to account_risk_scores"},
+			},
+		},
+		{
+			Name:    "account_risk_scores",
+			Columns: []Column{{Name: "account_id", DataType: "CHARACTER VARYING"}},
+		},
+	}
+
+	want := `feedback_enriched(
+  id          TEXT,
+  sentiment   TEXT,               -- 'positive', 'neutral' or 'negative'
+  account_id  CHARACTER VARYING   -- joins to account_risk_scores
+);
+-- Customer feedback. Use for: sentiment analysis
+
+account_risk_scores(
+  account_id  CHARACTER VARYING
+);
+`
+	if got := FormatTables(tables); got != want {
+		t.Errorf("FormatTables() =\n%s\nwant\n%s", got, want)
+	}
+
+	if got := NewSchemaCatalog(nil, nil).DescribeSchema(); got != "" {
+		t.Errorf("DescribeSchema() before Refresh = %q, want empty", got)
+	}
+}
@@ -1,85 +1,145 @@
-package domain
-
-import "time"
-
-// FeedbackEnriched represents a row from the feedback_enriched table
-type FeedbackEnriched struct {
-	ID           string    `json:"id"`
-	CreatedAt    time.Time `json:"created_at"`
-	Source       string    `json:"source"`
-	ProductArea  string    `json:"product_area"`
-	Sentiment    string    `json:"sentiment"`
-	Priority     int       `json:"priority"`
-	Topic        string    `json:"topic"`
-	Region       string    `json:"region"`
-	CustomerTier string    `json:"customer_tier"`
-	Summary      string    `json:"summary"`
-}
-
-// AskRequest represents the incoming request to /api/ask
-type AskRequest struct {
-	Question string `json:"question"`
-}
-
-// AskResponse represents the final response from /api/ask
-type AskResponse struct {
-	Question        string              `json:"question"`
-	SQL             string              `json:"sql,omitempty"`
-	DataPreview     []map[string]any    `json:"data_preview"`
-	Summary         string              `json:"summary"`
-	Recommendations []string            `json:"recommendations"`
-	Actions         []ActionItem        `json:"actions"`
-}
-
-// ActionItem represents a proposed action/ticket
-type ActionItem struct {
-	Title       string  `json:"title"`
-	Description string  `json:"description"`
-	Magnitude   float64 `json:"magnitude,omitempty"`
-}
-
-// InsightResult is the parsed result from the LLM's insight generation
-type InsightResult struct {
-	Summary         string       `json:"summary"`
-	Recommendations []string     `json:"recommendations"`
-	Actions         []ActionItem `json:"actions"`
-}
-
-// AccountRiskScore represents ML-based churn predictions for an account (from tens-insight)
-type AccountRiskScore struct {
-	AccountID        string    `json:"account_id"`
-	ChurnProbability float64   `json:"churn_probability"`
-	HealthScore      float64   `json:"health_score"`
-	RiskCategory     string    `json:"risk_category"`
-	PredictedAt      time.Time `json:"predicted_at"`
-	ModelVersion     string    `json:"model_version"`
-}
-
-// ProductAreaImpact represents ML-based priority signals for product areas (from tens-insight)
-type ProductAreaImpact struct {
-	ProductArea       string    `json:"product_area"`
-	Segment           string    `json:"segment"`
-	PriorityScore     float64   `json:"priority_score"`
-	FeedbackCount     int       `json:"feedback_count"`
-	AvgSentimentScore float64   `json:"avg_sentiment_score"`
-	NegativeCount     int       `json:"negative_count"`
-	CriticalCount     int       `json:"critical_count"`
-	PredictedAt       time.Time `json:"predicted_at"`
-	ModelVersion      string    `json:"model_version"`
-}
-
-// AccountHealthResponse is the response for GET /api/accounts/{id}/health
-type AccountHealthResponse struct {
-	AccountID           string  `json:"account_id"`
-	ChurnProbability    float64 `json:"churn_probability"`
-	HealthScore         float64 `json:"health_score"`
-	RiskCategory        string  `json:"risk_category"`
-	RecentNegativeCount int     `json:"recent_negative_feedback_count"`
-	PredictedAt         string  `json:"predicted_at"`
-	ModelVersion        string  `json:"model_version"`
-}
-
-// ProductAreaPriorityResponse is the response for GET /api/priorities/product-areas
-type ProductAreaPriorityResponse struct {
-	ProductAreas []ProductAreaImpact `json:"product_areas"`
-}
+package domain
+
+import "time"
+
+// FeedbackEnriched represents a row from the feedback_enriched table
+type FeedbackEnriched struct {
+	ID           string    `json:"id"`
+	CreatedAt    time.Time `json:"created_at"`
+	Source       string    `json:"source"`
+	ProductArea  string    `json:"product_area"`
+	Sentiment    string    `json:"sentiment"`
+	Priority     int       `json:"priority"`
+	Topic        string    `json:"topic"`
+	Region       string    `json:"region"`
+	CustomerTier string    `json:"customer_tier"`
+	Summary      string    `json:"summary"`
+}
+
+// AskRequest represents the incoming request to /api/ask
+type AskRequest struct {
+	Question       string `json:"question"`
+	ConversationID string `json:"conversation_id,omitempty"` // continue an earlier conversation
+}
+
+// AskResponse represents the final response from /api/ask
+type AskResponse struct {
+	Question        string              `json:"question"`
+	ConversationID  string              `json:"conversation_id,omitempty"`
+	SQL             string              `json:"sql,omitempty"`
+	DataPreview     []map[string]any    `json:"data_preview"`
+	Summary         string              `json:"summary"`
+	Recommendations []string            `json:"recommendations"`
+	Actions         []ActionItem        `json:"actions"`
+	TotalRows       int                 `json:"total_rows"`
+	Truncated       bool                `json:"truncated"`
+	LimitApplied    int                 `json:"limit_applied"`
+	Attempts        []SQLAttempt        `json:"attempts"`
+	Metadata        *ResponseMetadata   `json:"metadata,omitempty"`
+	AnalysisID      string              `json:"analysis_id,omitempty"` // stored record of this answer
+	Stale           bool                `json:"stale,omitempty"`       // cached answer past its TTL, being refreshed
+}
+
+// ResponseMetadata describes how a response was produced
+type ResponseMetadata struct {
+	// LLMProviders maps each LLM operation (generate_sql, generate_insight,
+	// generate) to the provider that answered it
+	LLMProviders map[string]string `json:"llm_providers,omitempty"`
+	// CacheMatch is set when the response was served from the cache
+	CacheMatch *CacheMatch `json:"cache_match,omitempty"`
+}
+
+// Kinds of cache match
+const (
+	CacheMatchExact      = "exact"      // the same question, as written
+	CacheMatchNormalized = "normalized" // the same question once normalized
+	CacheMatchSemantic   = "semantic"   // a question with a similar embedding
+)
+
+// CacheMatch describes the cached question a response was served for
+type CacheMatch struct {
+	Type       string  `json:"type"`
+	Question   string  `json:"question"`             // the cached question as asked
+	Similarity float64 `json:"similarity,omitempty"` // cosine similarity, semantic matches only
+}
+
+// Analysis is a stored /api/ask answer. Tickets filed from the answer link
+// back to it, so its evidence can be checked without re-running the question.
+type Analysis struct {
+	ID          string           `json:"analysis_id"`
+	Question    string           `json:"question"`
+	SQL         string           `json:"sql"`
+	Summary     string           `json:"summary"`
+	DataPreview []map[string]any `json:"data_preview"`
+	TotalRows   int              `json:"total_rows"`
+	CreatedAt   time.Time        `json:"created_at"`
+}
+
+// ConversationTurn is one question asked within a conversation, with the SQL
+// that answered it and the insight summary
+type ConversationTurn struct {
+	Question  string    `json:"question"`
+	SQL       string    `json:"sql"`
+	Summary   string    `json:"summary"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SQLAttempt records one query tried for a question, executed or rejected
+// by the validator; failed attempts are sent back to the LLM for repair
+type SQLAttempt struct {
+	SQL   string `json:"sql"`
+	Error string `json:"error,omitempty"`
+}
+
+// ActionItem represents a proposed action/ticket
+type ActionItem struct {
+	Title       string  `json:"title"`
+	Description string  `json:"description"`
+	Magnitude   float64 `json:"magnitude,omitempty"`
+}
+
+// InsightResult is the parsed result from the LLM's insight generation
+type InsightResult struct {
+	Summary         string       `json:"summary"`
+	Recommendations []string     `json:"recommendations"`
+	Actions         []ActionItem `json:"actions"`
+}
+
+// AccountRiskScore represents ML-based churn predictions for an account (from tens-insight)
+type AccountRiskScore struct {
+	AccountID        string    `json:"account_id"`
+	ChurnProbability float64   `json:"churn_probability"`
+	HealthScore      float64   `json:"health_score"`
+	RiskCategory     string    `json:"risk_category"`
+	PredictedAt      time.Time `json:"predicted_at"`
+	ModelVersion     string    `json:"model_version"`
+}
+
+// ProductAreaImpact represents ML-based priority signals for product areas (from tens-insight)
+type ProductAreaImpact struct {
+	ProductArea       string    `json:"product_area"`
+	Segment           string    `json:"segment"`
+	PriorityScore     float64   `json:"priority_score"`
+	FeedbackCount     int       `json:"feedback_count"`
+	AvgSentimentScore float64   `json:"avg_sentiment_score"`
+	NegativeCount     int       `json:"negative_count"`
+	CriticalCount     int       `json:"critical_count"`
+	PredictedAt       time.Time `json:"predicted_at"`
+	ModelVersion      string    `json:"model_version"`
+}
+
+// AccountHealthResponse is the response for GET /api/accounts/{id}/health
+type AccountHealthResponse struct {
+	AccountID           string  `json:"account_id"`
+	ChurnProbability    float64 `json:"churn_probability"`
+	HealthScore         float64 `json:"health_score"`
+	RiskCategory        string  `json:"risk_category"`
+	RecentNegativeCount int     `json:"recent_negative_feedback_count"`
+	PredictedAt         string  `json:"predicted_at"`
+	ModelVersion        string  `json:"model_version"`
+}
+
+// ProductAreaPriorityResponse is the response for GET /api/priorities/product-areas
+type ProductAreaPriorityResponse struct {
+	ProductAreas []ProductAreaImpact `json:"product_areas"`
+}
@@ -0,0 +1,48 @@
+package domain
+
+import "time"
+
+// Sources of a recorded ticket status
+const (
+	TicketStatusSourceWebhook = "webhook"
+	TicketStatusSourcePoll    = "poll"
+)
+
+// InsightTicket is a ticket filed from an insight, tracked so its status can
+// be reported back against the question and action it came from
+type InsightTicket struct {
+	Key            string     `json:"key"`
+	Tracker        string     `json:"tracker"`
+	Question       string     `json:"question"`
+	Action         string     `json:"action,omitempty"`
+	Summary        string     `json:"summary"`
+	ProductArea    string     `json:"product_area,omitempty"`
+	AnalysisID     string     `json:"analysis_id,omitempty"`
+	Status         string     `json:"status,omitempty"` // e.g. In Review; empty until first synced
+	StatusCategory string     `json:"status_category"`  // a JiraStatusCategory key
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+}
+
+// Open reports whether the ticket is not done
+func (t InsightTicket) Open() bool {
+	return t.StatusCategory != JiraStatusCategoryDone
+}
+
+// InsightTicketFilter selects tracked tickets; zero values match everything
+type InsightTicketFilter struct {
+	ProductArea string
+	Open        *bool // only open (true) or only resolved (false) tickets
+	Limit       int
+}
+
+// ProductAreaTicketStats summarizes the tickets filed for a product area
+type ProductAreaTicketStats struct {
+	ProductArea        string  `json:"product_area"`
+	Total              int     `json:"total"`
+	Open               int     `json:"open"`
+	Resolved           int     `json:"resolved"`
+	ResolutionRate     float64 `json:"resolution_rate"`                // resolved / total
+	AvgResolutionHours float64 `json:"avg_resolution_hours,omitempty"` // from filing to done
+}
@@ -1,163 +1,402 @@
-package domain
-
-import "strings"
-
-// JiraTicketRequest represents the input to create Jira tickets from insights
-type JiraTicketRequest struct {
-	Question        string         `json:"question"`
-	Summary         string         `json:"summary"`
-	Recommendations []string       `json:"recommendations"`
-	Actions         []ActionItem   `json:"actions"`
-	Meta            JiraTicketMeta `json:"meta"`
-}
-
-// JiraTicketMeta contains Jira-specific configuration
-type JiraTicketMeta struct {
-	ProjectKey       string   `json:"project_key"`
-	DefaultIssueType string   `json:"default_issue_type"`
-	DefaultLabels    []string `json:"default_labels"`
-}
-
-// JiraTicketSpec represents a single Jira ticket specification
-type JiraTicketSpec struct {
-	ProjectKey  string   `json:"project_key"`
-	IssueType   string   `json:"issue_type"`
-	Summary     string   `json:"summary"`
-	Description string   `json:"description"`
-	Priority    string   `json:"priority"`
-	Labels      []string `json:"labels"`
-	Components  []string `json:"components"`
-	EpicLink    *string  `json:"epic_link"`
-}
-
-// JiraTicketsResponse is returned by the LLM
-type JiraTicketsResponse struct {
-	Tickets []JiraTicketSpec `json:"tickets"`
-}
-
-// JiraCreateRequest is the actual Jira Cloud API request format
-type JiraCreateRequest struct {
-	Fields JiraIssueFields `json:"fields"`
-}
-
-// JiraIssueFields matches Jira Cloud REST API structure
-type JiraIssueFields struct {
-	Project     JiraProject       `json:"project"`
-	Summary     string            `json:"summary"`
-	Description string            `json:"description"`
-	IssueType   JiraIssueType     `json:"issuetype"`
-	Priority    *JiraPriority     `json:"priority,omitempty"`
-	Labels      []string          `json:"labels,omitempty"`
-	Components  []JiraComponent   `json:"components,omitempty"`
-}
-
-// JiraProject represents a Jira project reference
-type JiraProject struct {
-	Key string `json:"key"`
-}
-
-// JiraIssueType represents a Jira issue type
-type JiraIssueType struct {
-	Name string `json:"name"`
-}
-
-// JiraPriority represents a Jira priority
-type JiraPriority struct {
-	Name string `json:"name"`
-}
-
-// JiraComponent represents a Jira component
-type JiraComponent struct {
-	Name string `json:"name"`
-}
-
-// JiraCreateResponse is returned by Jira after creating an issue
-type JiraCreateResponse struct {
-	ID   string `json:"id"`
-	Key  string `json:"key"`
-	Self string `json:"self"`
-}
-
-// JiraCreationResult tracks the result of creating multiple tickets
-type JiraCreationResult struct {
-	TicketSpecs    []JiraTicketSpec       `json:"ticket_specs"`
-	CreatedTickets []JiraCreateResponse   `json:"created_tickets"`
-	Errors         []string               `json:"errors,omitempty"`
-}
-
-// CalculateMagnitude computes a priority score (0-10) for an action item
-// based on keyword analysis and text characteristics
-func CalculateMagnitude(action ActionItem, summary string, recommendations []string) float64 {
-	var score float64 = 5.0 // baseline: Medium priority
-
-	// High urgency keywords in title or description
-	urgentKeywords := []string{
-		"critical", "urgent", "emergency", "blocker", "security", "data loss",
-		"crash", "down", "outage", "breach", "vulnerability", "exploit",
-	}
-	for _, keyword := range urgentKeywords {
-		if contains(action.Title, keyword) || contains(action.Description, keyword) {
-			score += 2.5
-			break
-		}
-	}
-
-	// High impact keywords
-	impactKeywords := []string{
-		"revenue", "customer", "payment", "billing", "refund", "loss",
-		"compliance", "legal", "audit", "performance", "scalability",
-	}
-	for _, keyword := range impactKeywords {
-		if contains(action.Title, keyword) || contains(action.Description, keyword) {
-			score += 1.5
-			break
-		}
-	}
-
-	// Investigation/analysis tasks (typically medium-high)
-	investigateKeywords := []string{"investigate", "analyze", "research", "identify"}
-	for _, keyword := range investigateKeywords {
-		if contains(action.Title, keyword) {
-			score += 1.0
-			break
-		}
-	}
-
-	// Documentation/update tasks (typically medium-low)
-	docKeywords := []string{"documentation", "update", "review", "document"}
-	for _, keyword := range docKeywords {
-		if contains(action.Title, keyword) {
-			score -= 1.0
-			break
-		}
-	}
-
-	// Check if action appears in multiple recommendations (higher priority)
-	mentionCount := 0
-	for _, rec := range recommendations {
-		for _, keyword := range []string{action.Title} {
-			if contains(rec, keyword) {
-				mentionCount++
-				break
-			}
-		}
-	}
-	if mentionCount > 0 {
-		score += float64(mentionCount) * 0.5
-	}
-
-	// Clamp score between 0 and 10
-	if score > 10 {
-		score = 10
-	}
-	if score < 0 {
-		score = 0
-	}
-
-	return score
-}
-
-// contains is a case-insensitive string contains check
-func contains(text, substr string) bool {
-	return strings.Contains(strings.ToLower(text), strings.ToLower(substr))
-}
+package domain
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// JiraTicketRequest represents the input to create Jira tickets from insights
+type JiraTicketRequest struct {
+	Question        string         `json:"question"`
+	Summary         string         `json:"summary"`
+	Recommendations []string       `json:"recommendations"`
+	Actions         []ActionItem   `json:"actions"`
+	Meta            JiraTicketMeta `json:"meta"`
+
+	// DataPreview holds the query rows supporting the insight, attached to
+	// each ticket as a table
+	DataPreview []map[string]any `json:"data_preview,omitempty"`
+
+	// SQL is the query that produced the insight, attached to each ticket
+	SQL string `json:"sql,omitempty"`
+
+	// AnalysisID references the stored /api/ask answer the insight came from.
+	// Its SQL and data preview are used when the request has none.
+	AnalysisID string `json:"analysis_id,omitempty"`
+
+	// DryRun returns the generated specs as a draft instead of filing them
+	DryRun bool `json:"dry_run,omitempty"`
+
+	// Attributes describe the insight, e.g. product_area: billing; they are
+	// mapped to Jira custom fields and assignees
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// JiraTicketMeta contains Jira-specific configuration
+type JiraTicketMeta struct {
+	ProjectKey       string   `json:"project_key"`
+	DefaultIssueType string   `json:"default_issue_type"`
+	DefaultLabels    []string `json:"default_labels"`
+	CreateEpic       bool     `json:"create_epic,omitempty"` // file the actions as children of a new epic for the insight
+	MatchStrategy    string   `json:"match_strategy,omitempty"`
+}
+
+// Strategies for actions matching an open ticket filed from an earlier insight
+const (
+	JiraMatchCreate         = "create"           // file a new ticket (the default)
+	JiraMatchCommentOnMatch = "comment_on_match" // comment on the open ticket with the new evidence
+)
+
+// JiraTicketSpec represents a single Jira ticket specification
+type JiraTicketSpec struct {
+	ProjectKey  string   `json:"project_key"`
+	IssueType   string   `json:"issue_type"`
+	Summary     string   `json:"summary"`
+	Description string   `json:"description"`
+	Priority    string   `json:"priority"`
+	Labels      []string `json:"labels"`
+	Components  []string `json:"components"`
+	EpicLink    *string  `json:"epic_link"`
+
+	// Fingerprint identifies the action and question the ticket was generated
+	// from; it is added as a label and used to detect duplicates
+	Fingerprint string `json:"fingerprint,omitempty"`
+
+	// Action is the title of the insight action the ticket was generated for
+	Action string `json:"action,omitempty"`
+
+	// DataPreview rows are rendered as a table after the description and,
+	// with SQL, attached to the issue as evidence
+	DataPreview []map[string]any `json:"-"`
+	SQL         string           `json:"-"`
+
+	// AnalysisID references the stored analysis the ticket was filed from
+	AnalysisID string `json:"-"`
+
+	// Attributes of the insight and action (see the JiraAttr constants),
+	// mapped to custom fields and an assignee by the Jira client
+	Attributes map[string]string `json:"attributes,omitempty"`
+
+	// CommentOn is the key of an open issue the spec matched; instead of
+	// filing a new issue, Comment is added to it with the data preview
+	CommentOn string `json:"comment_on,omitempty"`
+	Comment   string `json:"comment,omitempty"`
+}
+
+// Ticket attributes set from the insight and its actions
+const (
+	JiraAttrProductArea    = "product_area"
+	JiraAttrCustomerTier   = "customer_tier"
+	JiraAttrCustomerImpact = "customer_impact"
+)
+
+// JiraDraft holds generated ticket specs awaiting review. Nothing is filed in
+// Jira until the draft is confirmed, optionally with edited specs.
+type JiraDraft struct {
+	ID          string           `json:"draft_id"`
+	Question    string           `json:"question,omitempty"`
+	Tickets     []JiraTicketSpec `json:"tickets"`
+	Epic        *JiraTicketSpec  `json:"epic,omitempty"` // created as the tickets' parent on confirm
+	DataPreview []map[string]any `json:"-"`
+	SQL         string           `json:"-"`
+	AnalysisID  string           `json:"analysis_id,omitempty"`
+	CreatedAt   time.Time        `json:"created_at"`
+	ExpiresAt   time.Time        `json:"expires_at"`
+	ConfirmedAt *time.Time       `json:"confirmed_at,omitempty"`
+}
+
+// JiraDraftConfirmRequest confirms a draft. Tickets, if given, replace the
+// draft's specs, so tickets can be edited or dropped before they are filed.
+type JiraDraftConfirmRequest struct {
+	Tickets []JiraTicketSpec `json:"tickets,omitempty"`
+}
+
+// JiraTicketsResponse is returned by the LLM
+type JiraTicketsResponse struct {
+	Tickets []JiraTicketSpec `json:"tickets"`
+}
+
+// JiraCreateRequest is the actual Jira Cloud API request format
+type JiraCreateRequest struct {
+	Fields JiraIssueFields `json:"fields"`
+}
+
+// JiraBulkCreateRequest creates several issues in one call to /issue/bulk
+type JiraBulkCreateRequest struct {
+	IssueUpdates []JiraCreateRequest `json:"issueUpdates"`
+}
+
+// JiraBulkCreateResponse lists the issues created by a bulk request, in
+// request order, and an error for each element that failed
+type JiraBulkCreateResponse struct {
+	Issues []JiraCreateResponse `json:"issues"`
+	Errors []JiraBulkError      `json:"errors"`
+}
+
+// JiraBulkError is the failure of one element of a bulk request
+type JiraBulkError struct {
+	Status              int                 `json:"status"`
+	ElementErrors       JiraErrorCollection `json:"elementErrors"`
+	FailedElementNumber int                 `json:"failedElementNumber"` // index in IssueUpdates
+}
+
+// JiraErrorCollection is Jira's error body: general messages and errors by field
+type JiraErrorCollection struct {
+	ErrorMessages []string          `json:"errorMessages"`
+	Errors        map[string]string `json:"errors"`
+}
+
+// JiraIssueFields matches Jira Cloud REST API structure
+type JiraIssueFields struct {
+	Project     JiraProject       `json:"project"`
+	Summary     string            `json:"summary"`
+	Description any               `json:"description"` // wiki markup (v2) or an ADFNode document (v3)
+	IssueType   JiraIssueType     `json:"issuetype"`
+	Priority    *JiraPriority     `json:"priority,omitempty"`
+	Labels      []string          `json:"labels,omitempty"`
+	Components  []JiraComponent   `json:"components,omitempty"`
+	Parent      *JiraParent       `json:"parent,omitempty"`
+	Assignee    *JiraUser         `json:"assignee,omitempty"`
+
+	// CustomFields holds customfield_* values, keyed by field ID
+	CustomFields map[string]any `json:"-"`
+}
+
+// MarshalJSON adds CustomFields alongside the standard fields
+func (f JiraIssueFields) MarshalJSON() ([]byte, error) {
+	type standardFields JiraIssueFields
+	data, err := json.Marshal(standardFields(f))
+	if err != nil || len(f.CustomFields) == 0 {
+		return data, err
+	}
+
+	merged := make(map[string]any)
+	if err := json.Unmarshal(data, &merged); err != nil {
+		return nil, err
+	}
+	for id, value := range f.CustomFields {
+		merged[id] = value
+	}
+	return json.Marshal(merged)
+}
+
+// ADFNode is a node of an Atlassian Document Format document, the rich text
+// format of Jira REST API v3
+type ADFNode struct {
+	Type    string         `json:"type"`
+	Version int            `json:"version,omitempty"` // set on the root "doc" node only
+	Attrs   map[string]any `json:"attrs,omitempty"`
+	Content []ADFNode      `json:"content,omitempty"`
+	Text    string         `json:"text,omitempty"`
+	Marks   []ADFMark      `json:"marks,omitempty"`
+}
+
+// ADFMark is text formatting in an ADF document, e.g. strong or link
+type ADFMark struct {
+	Type  string         `json:"type"`
+	Attrs map[string]any `json:"attrs,omitempty"`
+}
+
+// JiraParent references the parent of an issue, e.g. its epic
+type JiraParent struct {
+	Key string `json:"key"`
+}
+
+// JiraUser references a Jira Cloud user by account ID
+type JiraUser struct {
+	AccountID string `json:"accountId"`
+}
+
+// JiraProject represents a Jira project reference
+type JiraProject struct {
+	Key string `json:"key"`
+}
+
+// JiraIssueType represents a Jira issue type
+type JiraIssueType struct {
+	Name string `json:"name"`
+}
+
+// JiraPriority represents a Jira priority
+type JiraPriority struct {
+	Name string `json:"name"`
+}
+
+// JiraComponent represents a Jira component
+type JiraComponent struct {
+	Name string `json:"name"`
+}
+
+// JiraCreateResponse is returned by Jira after creating an issue
+type JiraCreateResponse struct {
+	ID   string `json:"id"`
+	Key  string `json:"key"`
+	Self string `json:"self"`
+
+	// Hierarchy is set by the client when the issue is an epic or belongs to one
+	Hierarchy *JiraHierarchy `json:"hierarchy,omitempty"`
+}
+
+// Roles of an issue in a JiraHierarchy
+const (
+	JiraRoleEpic  = "epic"
+	JiraRoleChild = "child"
+)
+
+// JiraHierarchy describes where a created issue sits in an epic hierarchy
+type JiraHierarchy struct {
+	Role     string `json:"role"`                // JiraRoleEpic or JiraRoleChild
+	EpicKey  string `json:"epic_key"`            // the epic itself for JiraRoleEpic
+	LinkedBy string `json:"linked_by,omitempty"` // "parent" or the epic-link custom field ID
+}
+
+// JiraCreationResult tracks the result of creating multiple tickets
+type JiraCreationResult struct {
+	TicketSpecs    []JiraTicketSpec       `json:"ticket_specs"`
+	CreatedTickets []JiraCreateResponse   `json:"created_tickets"`
+	Epic           *JiraCreateResponse    `json:"epic,omitempty"`
+	Actions        []JiraActionResult     `json:"actions"`
+	Errors         []string               `json:"errors,omitempty"`
+}
+
+// Outcomes of a ticket spec in JiraActionResult.Status
+const (
+	JiraActionCreated          = "created"           // a new issue was created
+	JiraActionSkippedDuplicate = "skipped_duplicate" // an issue with the same fingerprint exists
+	JiraActionLinkedExisting   = "linked_existing"   // a similar open issue was tagged with the fingerprint
+	JiraActionCommented        = "commented"         // the new evidence was added to a matching open issue
+	JiraActionFailed           = "failed"
+)
+
+// JiraActionResult reports what happened to one ticket spec
+type JiraActionResult struct {
+	Summary     string `json:"summary"`
+	Status      string `json:"status"`
+	Key         string `json:"key,omitempty"` // created or matched issue
+	Fingerprint string `json:"fingerprint,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// JiraSearchResponse is returned by Jira's /search endpoint
+type JiraSearchResponse struct {
+	Total  int         `json:"total"`
+	Issues []JiraIssue `json:"issues"`
+}
+
+// JiraIssue is an issue returned by Jira's /search endpoint
+type JiraIssue struct {
+	ID     string          `json:"id"`
+	Key    string          `json:"key"`
+	Self   string          `json:"self"`
+	Fields JiraIssueDetail `json:"fields"`
+}
+
+// JiraIssueDetail holds the issue fields requested from /search
+type JiraIssueDetail struct {
+	Summary string      `json:"summary"`
+	Labels  []string    `json:"labels"`
+	Status  *JiraStatus `json:"status,omitempty"`
+}
+
+// Jira status category keys; every workflow status belongs to one
+const (
+	JiraStatusCategoryToDo       = "new"
+	JiraStatusCategoryInProgress = "indeterminate"
+	JiraStatusCategoryDone       = "done"
+)
+
+// JiraStatus is the workflow status of an issue
+type JiraStatus struct {
+	Name           string             `json:"name"`
+	StatusCategory JiraStatusCategory `json:"statusCategory"`
+}
+
+// JiraStatusCategory groups statuses into to do, in progress and done
+type JiraStatusCategory struct {
+	Key string `json:"key"`
+}
+
+// JiraWebhookEvent is the part of a Jira issue webhook used to sync statuses
+type JiraWebhookEvent struct {
+	WebhookEvent string    `json:"webhookEvent"` // e.g. jira:issue_updated
+	Timestamp    int64     `json:"timestamp"`    // milliseconds since the epoch
+	Issue        JiraIssue `json:"issue"`
+}
+
+// CalculateMagnitude computes a priority score (0-10) for an action item
+// based on keyword analysis and text characteristics
+func CalculateMagnitude(action ActionItem, summary string, recommendations []string) float64 {
+	var score float64 = 5.0 // baseline: Medium priority
+
+	// High urgency keywords in title or description
+	urgentKeywords := []string{
+		"critical", "urgent", "emergency", "blocker", "security", "data loss",
+		"crash", "down", "outage", "breach", "vulnerability", "exploit",
+	}
+	for _, keyword := range urgentKeywords {
+		if contains(action.Title, keyword) || contains(action.Description, keyword) {
+			score += 2.5
+			break
+		}
+	}
+
+	// High impact keywords
+	impactKeywords := []string{
+		"revenue", "customer", "payment", "billing", "refund", "loss",
+		"compliance", "legal", "audit", "performance", "scalability",
+	}
+	for _, keyword := range impactKeywords {
+		if contains(action.Title, keyword) || contains(action.Description, keyword) {
+			score += 1.5
+			break
+		}
+	}
+
+	// Investigation/analysis tasks (typically medium-high)
+	investigateKeywords := []string{"investigate", "analyze", "research", "identify"}
+	for _, keyword := range investigateKeywords {
+		if contains(action.Title, keyword) {
+			score += 1.0
+			break
+		}
+	}
+
+	// Documentation/update tasks (typically medium-low)
+	docKeywords := []string{"documentation", "update", "review", "document"}
+	for _, keyword := range docKeywords {
+		if contains(action.Title, keyword) {
+			score -= 1.0
+			break
+		}
+	}
+
+	// Check if action appears in multiple recommendations (higher priority)
+	mentionCount := 0
+	for _, rec := range recommendations {
+		for _, keyword := range []string{action.Title} {
+			if contains(rec, keyword) {
+				mentionCount++
+				break
+			}
+		}
+	}
+	if mentionCount > 0 {
+		score += float64(mentionCount) * 0.5
+	}
+
+	// Clamp score between 0 and 10
+	if score > 10 {
+		score = 10
+	}
+	if score < 0 {
+		score = 0
+	}
+
+	return score
+}
+
+// contains is a case-insensitive string contains check
+func contains(text, substr string) bool {
+	return strings.Contains(strings.ToLower(text), strings.ToLower(substr))
+}
@@ -1,617 +1,805 @@
-package http
-
-import (
-	"bytes"
-	"context"
-	"database/sql"
-	"encoding/json"
-	"fmt"
-	"net/http"
-	"net/http/httptest"
-	"testing"
-
-	"github.com/chuckie/goinsight/internal/domain"
-)
-
-// MockLLMClient is a mock LLM client for testing
-type MockLLMClient struct {
-	GenerateSQLFn     func(context.Context, string) (string, error)
-	GenerateInsightFn func(context.Context, string, []map[string]any) (string, error)
-	GenerateFn        func(context.Context, string) (string, error)
-}
-
-func (m *MockLLMClient) GenerateSQL(ctx context.Context, question string) (string, error) {
-	if m.GenerateSQLFn != nil {
-		return m.GenerateSQLFn(ctx, question)
-	}
-	return "SELECT * FROM feedback LIMIT 10", nil
-}
-
-func (m *MockLLMClient) GenerateInsight(ctx context.Context, question string, results []map[string]any) (string, error) {
-	if m.GenerateInsightFn != nil {
-		return m.GenerateInsightFn(ctx, question, results)
-	}
-	return "Analysis: feedback is positive", nil
-}
-
-func (m *MockLLMClient) Generate(ctx context.Context, prompt string) (string, error) {
-	if m.GenerateFn != nil {
-		return m.GenerateFn(ctx, prompt)
-	}
-	return "Generated response", nil
-}
-
-// MockDatabaseClient is a mock database client for testing
-type MockDatabaseClient struct {
-	ExecuteQueryFn                   func(query string) ([]map[string]any, error)
-	HealthCheckFn                    func() error
-	GetAccountRiskScoreFn            func(accountID string) (*sql.Row, error)
-	GetRecentNegativeFeedbackCountFn func(accountID string) (int, error)
-	GetProductAreaImpactsFn          func(segment string) ([]map[string]any, error)
-	CloseFn                          func() error
-}
-
-func (m *MockDatabaseClient) ExecuteQuery(query string) ([]map[string]any, error) {
-	if m.ExecuteQueryFn != nil {
-		return m.ExecuteQueryFn(query)
-	}
-	return []map[string]any{}, nil
-}
-
-func (m *MockDatabaseClient) HealthCheck() error {
-	if m.HealthCheckFn != nil {
-		return m.HealthCheckFn()
-	}
-	return nil
-}
-
-func (m *MockDatabaseClient) GetAccountRiskScore(accountID string) (*sql.Row, error) {
-	if m.GetAccountRiskScoreFn != nil {
-		return m.GetAccountRiskScoreFn(accountID)
-	}
-	return nil, nil
-}
-
-func (m *MockDatabaseClient) GetRecentNegativeFeedbackCount(accountID string) (int, error) {
-	if m.GetRecentNegativeFeedbackCountFn != nil {
-		return m.GetRecentNegativeFeedbackCountFn(accountID)
-	}
-	return 0, nil
-}
-
-func (m *MockDatabaseClient) GetProductAreaImpacts(segment string) ([]map[string]any, error) {
-	if m.GetProductAreaImpactsFn != nil {
-		return m.GetProductAreaImpactsFn(segment)
-	}
-	return []map[string]any{}, nil
-}
-
-func (m *MockDatabaseClient) Close() error {
-	if m.CloseFn != nil {
-		return m.CloseFn()
-	}
-	return nil
-}
-
-// TestHealthCheckSuccess tests successful health check
-func TestHealthCheckSuccess(t *testing.T) {
-	// Skip actual DB check - just test response structure
-	t.Skip("Database required for health check test")
-}
-
-// TestHealthCheckUnhealthy tests health check when database is unhealthy
-func TestHealthCheckUnhealthy(t *testing.T) {
-	// Skip - requires actual database for testing
-	t.Skip("Database required for health check test")
-}
-
-// TestAskInvalidRequest tests Ask endpoint with invalid request
-func TestAskInvalidRequest(t *testing.T) {
-	handler := &Handler{
-		llmClient: &MockLLMClient{},
-	}
-
-	tests := []struct {
-		name        string
-		body        string
-		expectError bool
-	}{
-		{
-			name:        "empty body",
-			body:        "",
-			expectError: true,
-		},
-		{
-			name:        "invalid JSON",
-			body:        "not json",
-			expectError: true,
-		},
-		{
-			name:        "empty question",
-			body:        `{"question":""}`,
-			expectError: true,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			req := httptest.NewRequest("POST", "/ask", bytes.NewBufferString(tt.body))
-			w := httptest.NewRecorder()
-
-			handler.Ask(w, req)
-
-			if !tt.expectError && w.Code == http.StatusBadRequest {
-				t.Errorf("Expected success, got error status %d", w.Code)
-			}
-			if tt.expectError && w.Code == http.StatusOK {
-				t.Errorf("Expected error, got success status %d", w.Code)
-			}
-		})
-	}
-}
-
-// TestAskValidRequest tests Ask endpoint with valid request
-func TestAskValidRequest(t *testing.T) {
-	mockDBClient := &MockDatabaseClient{
-		ExecuteQueryFn: func(query string) ([]map[string]any, error) {
-			return []map[string]any{
-				{"id": 1, "feedback": "excellent service", "sentiment": "positive"},
-			}, nil
-		},
-	}
-
-	handler := &Handler{
-		dbClient: mockDBClient,
-		llmClient: &MockLLMClient{
-			GenerateSQLFn: func(ctx context.Context, q string) (string, error) {
-				return "SELECT * FROM feedback WHERE sentiment = 'positive'", nil
-			},
-			GenerateInsightFn: func(ctx context.Context, feedback string, results []map[string]any) (string, error) {
-				return `{"summary": "Most customers are satisfied", "recommendations": ["improve service"], "actions": []}`, nil
-			},
-		},
-	}
-
-	reqBody := domain.AskRequest{
-		Question: "What is customer sentiment?",
-	}
-	body, _ := json.Marshal(reqBody)
-
-	req := httptest.NewRequest("POST", "/ask", bytes.NewBuffer(body))
-	req.Header.Set("Content-Type", "application/json")
-	w := httptest.NewRecorder()
-
-	handler.Ask(w, req)
-
-	// Should succeed
-	if w.Code != http.StatusOK {
-		t.Errorf("Expected status 200, got %d: %s", w.Code, w.Body.String())
-	}
-
-	// Verify response structure
-	var response domain.AskResponse
-	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
-		t.Errorf("Failed to unmarshal response: %v", err)
-	}
-
-	if response.Summary == "" {
-		t.Error("Expected summary in response")
-	}
-	if len(response.DataPreview) == 0 {
-		t.Error("Expected data preview in response")
-	}
-}
-
-// TestQueryEndpoint tests the Query endpoint
-func TestQueryEndpoint(t *testing.T) {
-	tests := []struct {
-		name        string
-		sqlQuery    string
-		expectError bool
-	}{
-		{
-			name:        "valid query",
-			sqlQuery:    "SELECT * FROM feedback LIMIT 10",
-			expectError: false,
-		},
-		{
-			name:        "empty query",
-			sqlQuery:    "",
-			expectError: true,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			req := httptest.NewRequest("POST", "/query", bytes.NewBufferString(`{"sql":"`+tt.sqlQuery+`"}`))
-			req.Header.Set("Content-Type", "application/json")
-			w := httptest.NewRecorder()
-
-			// Query endpoint should validate SQL
-			if tt.expectError && w.Code == http.StatusOK {
-				t.Logf("Empty query should have been rejected")
-			}
-		})
-	}
-}
-
-// TestContentType tests that endpoints return correct content type
-func TestContentType(t *testing.T) {
-	mockDBClient := &MockDatabaseClient{
-		HealthCheckFn: func() error {
-			return nil
-		},
-	}
-	handler := &Handler{
-		dbClient: mockDBClient,
-	}
-
-	req := httptest.NewRequest("GET", "/health", nil)
-	w := httptest.NewRecorder()
-
-	handler.HealthCheck(w, req)
-
-	contentType := w.Header().Get("Content-Type")
-	if contentType != "application/json" {
-		t.Errorf("Expected Content-Type 'application/json', got '%s'", contentType)
-	}
-}
-
-// TestCORSHeaders tests CORS headers in responses
-func TestCORSHeaders(t *testing.T) {
-	t.Skip("Database required for response test")
-}
-
-// TestRequestValidation tests request validation
-func TestRequestValidation(t *testing.T) {
-	tests := []struct {
-		name        string
-		method      string
-		path        string
-		expectError bool
-	}{
-		{
-			name:        "GET health check",
-			method:      "GET",
-			path:        "/health",
-			expectError: false,
-		},
-		{
-			name:        "POST ask",
-			method:      "POST",
-			path:        "/ask",
-			expectError: false,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if tt.method == "GET" && tt.path == "/health" {
-				t.Skip("Database required for health check test")
-			}
-		})
-	}
-}
-
-// TestResponseFormat tests response JSON format
-func TestResponseFormat(t *testing.T) {
-	t.Skip("Database required for response test")
-}
-
-// TestErrorResponse tests error response format
-func TestErrorResponse(t *testing.T) {
-	t.Skip("Database required for error response test")
-}
-
-// TestConcurrentRequests tests handling of concurrent requests
-func TestConcurrentRequests(t *testing.T) {
-	t.Skip("Database required for concurrent test")
-}
-
-// TestResponseStatusCodes tests various status code scenarios
-func TestResponseStatusCodes(t *testing.T) {
-	t.Skip("Database required for status code test")
-}
-
-// TestRequestHeaders tests custom request headers
-func TestRequestHeaders(t *testing.T) {
-	t.Skip("Database required for header test")
-}
-
-// TestJSONEncoding tests JSON encoding consistency
-func TestJSONEncoding(t *testing.T) {
-	mockDBClient := &MockDatabaseClient{
-		HealthCheckFn: func() error {
-			return nil
-		},
-	}
-	handler := &Handler{
-		dbClient: mockDBClient,
-	}
-
-	req := httptest.NewRequest("GET", "/health", nil)
-	w := httptest.NewRecorder()
-
-	handler.HealthCheck(w, req)
-
-	// Verify JSON can be decoded properly
-	var data map[string]interface{}
-	err := json.NewDecoder(w.Body).Decode(&data)
-
-	if err != nil {
-		t.Errorf("Failed to decode JSON: %v", err)
-	}
-}
-
-// BenchmarkHealthCheck benchmarks the health check endpoint
-func BenchmarkHealthCheck(b *testing.B) {
-	b.Skip("Database required for benchmark")
-}
-
-// BenchmarkAskRequest benchmarks the Ask endpoint
-func BenchmarkAskRequest(b *testing.B) {
-	mockDBClient := &MockDatabaseClient{
-		ExecuteQueryFn: func(query string) ([]map[string]any, error) {
-			return []map[string]any{
-				{"id": 1, "feedback": "excellent service"},
-			}, nil
-		},
-	}
-	
-	handler := &Handler{
-		dbClient: mockDBClient,
-		llmClient: &MockLLMClient{
-			GenerateSQLFn: func(ctx context.Context, q string) (string, error) {
-				return "SELECT * FROM feedback WHERE sentiment = 'positive'", nil
-			},
-			GenerateInsightFn: func(ctx context.Context, feedback string, results []map[string]any) (string, error) {
-				return `{"summary": "Good feedback", "recommendations": [], "actions": []}`, nil
-			},
-		},
-	}
-
-	reqBody := domain.AskRequest{
-		Question: "What is the customer sentiment?",
-	}
-	body, _ := json.Marshal(reqBody)
-
-	b.ReportAllocs()
-	b.ResetTimer()
-
-	for i := 0; i < b.N; i++ {
-		req := httptest.NewRequest("POST", "/ask", bytes.NewBuffer(body))
-		req.Header.Set("Content-Type", "application/json")
-		w := httptest.NewRecorder()
-
-		handler.Ask(w, req)
-	}
-}
-
-// BenchmarkResponseMarshal benchmarks JSON response marshaling
-func BenchmarkResponseMarshal(b *testing.B) {
-	resp := domain.AskResponse{
-		Question:    "Test question",
-		Summary:     "Test summary",
-		SQL:         "SELECT * FROM feedback",
-		DataPreview: []map[string]any{},
-	}
-
-	b.ReportAllocs()
-	b.ResetTimer()
-
-	for i := 0; i < b.N; i++ {
-		json.Marshal(resp)
-	}
-}
-
-// TestAskWithLLMError tests Ask endpoint when LLM SQL generation fails
-func TestAskWithLLMError(t *testing.T) {
-	mockDBClient := &MockDatabaseClient{}
-	handler := &Handler{
-		dbClient: mockDBClient,
-		llmClient: &MockLLMClient{
-			GenerateSQLFn: func(ctx context.Context, q string) (string, error) {
-				return "", fmt.Errorf("LLM service unavailable")
-			},
-		},
-	}
-
-	reqBody := domain.AskRequest{
-		Question: "What is customer sentiment?",
-	}
-	body, _ := json.Marshal(reqBody)
-
-	req := httptest.NewRequest("POST", "/ask", bytes.NewBuffer(body))
-	req.Header.Set("Content-Type", "application/json")
-	w := httptest.NewRecorder()
-
-	handler.Ask(w, req)
-
-	// Should return error
-	if w.Code != http.StatusInternalServerError {
-		t.Errorf("Expected status 500, got %d", w.Code)
-	}
-}
-
-// TestAskWithDatabaseError tests Ask endpoint when database query fails
-func TestAskWithDatabaseError(t *testing.T) {
-	mockDBClient := &MockDatabaseClient{
-		ExecuteQueryFn: func(query string) ([]map[string]any, error) {
-			return nil, fmt.Errorf("database connection failed")
-		},
-	}
-
-	handler := &Handler{
-		dbClient: mockDBClient,
-		llmClient: &MockLLMClient{
-			GenerateSQLFn: func(ctx context.Context, q string) (string, error) {
-				return "SELECT * FROM feedback WHERE sentiment = 'positive'", nil
-			},
-		},
-	}
-
-	reqBody := domain.AskRequest{
-		Question: "What is customer sentiment?",
-	}
-	body, _ := json.Marshal(reqBody)
-
-	req := httptest.NewRequest("POST", "/ask", bytes.NewBuffer(body))
-	req.Header.Set("Content-Type", "application/json")
-	w := httptest.NewRecorder()
-
-	handler.Ask(w, req)
-
-	// Should return error
-	if w.Code != http.StatusInternalServerError {
-		t.Errorf("Expected status 500, got %d", w.Code)
-	}
-}
-
-// TestAskWithInsightGenerationError tests Ask endpoint when insight generation fails
-func TestAskWithInsightGenerationError(t *testing.T) {
-	mockDBClient := &MockDatabaseClient{
-		ExecuteQueryFn: func(query string) ([]map[string]any, error) {
-			return []map[string]any{
-				{"id": 1, "feedback": "excellent service"},
-			}, nil
-		},
-	}
-
-	handler := &Handler{
-		dbClient: mockDBClient,
-		llmClient: &MockLLMClient{
-			GenerateSQLFn: func(ctx context.Context, q string) (string, error) {
-				return "SELECT * FROM feedback WHERE sentiment = 'positive'", nil
-			},
-			GenerateInsightFn: func(ctx context.Context, feedback string, results []map[string]any) (string, error) {
-				return "", fmt.Errorf("insight generation failed")
-			},
-		},
-	}
-
-	reqBody := domain.AskRequest{
-		Question: "What is customer sentiment?",
-	}
-	body, _ := json.Marshal(reqBody)
-
-	req := httptest.NewRequest("POST", "/ask", bytes.NewBuffer(body))
-	req.Header.Set("Content-Type", "application/json")
-	w := httptest.NewRecorder()
-
-	handler.Ask(w, req)
-
-	// Should return error
-	if w.Code != http.StatusInternalServerError {
-		t.Errorf("Expected status 500, got %d", w.Code)
-	}
-}
-
-// TestAskWithEmptyResults tests Ask endpoint when query returns no results
-func TestAskWithEmptyResults(t *testing.T) {
-	mockDBClient := &MockDatabaseClient{
-		ExecuteQueryFn: func(query string) ([]map[string]any, error) {
-			return []map[string]any{}, nil
-		},
-	}
-
-	handler := &Handler{
-		dbClient: mockDBClient,
-		llmClient: &MockLLMClient{
-			GenerateSQLFn: func(ctx context.Context, q string) (string, error) {
-				return "SELECT * FROM feedback WHERE sentiment = 'nonexistent'", nil
-			},
-			GenerateInsightFn: func(ctx context.Context, feedback string, results []map[string]any) (string, error) {
-				return `{"summary": "No data found", "recommendations": [], "actions": []}`, nil
-			},
-		},
-	}
-
-	reqBody := domain.AskRequest{
-		Question: "What is customer sentiment?",
-	}
-	body, _ := json.Marshal(reqBody)
-
-	req := httptest.NewRequest("POST", "/ask", bytes.NewBuffer(body))
-	req.Header.Set("Content-Type", "application/json")
-	w := httptest.NewRecorder()
-
-	handler.Ask(w, req)
-
-	// Should succeed even with empty results
-	if w.Code != http.StatusOK {
-		t.Errorf("Expected status 200, got %d", w.Code)
-	}
-
-	var response domain.AskResponse
-	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
-		t.Errorf("Failed to unmarshal response: %v", err)
-	}
-
-	if len(response.DataPreview) != 0 {
-		t.Error("Expected empty data preview")
-	}
-}
-
-// TestHealthCheckWithError tests health check when database is unhealthy
-func TestHealthCheckWithError(t *testing.T) {
-	mockDBClient := &MockDatabaseClient{
-		HealthCheckFn: func() error {
-			return fmt.Errorf("database connection failed")
-		},
-	}
-
-	handler := &Handler{
-		dbClient: mockDBClient,
-	}
-
-	req := httptest.NewRequest("GET", "/health", nil)
-	w := httptest.NewRecorder()
-
-	handler.HealthCheck(w, req)
-
-	// Should return unhealthy status
-	if w.Code != http.StatusServiceUnavailable {
-		t.Errorf("Expected status 503, got %d", w.Code)
-	}
-
-	var response map[string]string
-	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
-		t.Errorf("Failed to unmarshal response: %v", err)
-	}
-
-	if response["status"] != "unhealthy" {
-		t.Errorf("Expected status 'unhealthy', got '%s'", response["status"])
-	}
-}
-
-// TestHealthCheckSuccess tests successful health check
-func TestHealthCheckHealthy(t *testing.T) {
-	mockDBClient := &MockDatabaseClient{
-		HealthCheckFn: func() error {
-			return nil
-		},
-	}
-
-	handler := &Handler{
-		dbClient: mockDBClient,
-	}
-
-	req := httptest.NewRequest("GET", "/health", nil)
-	w := httptest.NewRecorder()
-
-	handler.HealthCheck(w, req)
-
-	// Should return healthy status
-	if w.Code != http.StatusOK {
-		t.Errorf("Expected status 200, got %d", w.Code)
-	}
-
-	var response map[string]string
-	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
-		t.Errorf("Failed to unmarshal response: %v", err)
-	}
-
-	if response["status"] != "healthy" {
-		t.Errorf("Expected status 'healthy', got '%s'", response["status"])
-	}
-}
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/chuckie/goinsight/internal/db"
+	"github.com/chuckie/goinsight/internal/domain"
+	"github.com/chuckie/goinsight/internal/llm"
+	"github.com/chuckie/goinsight/internal/service"
+	"github.com/chuckie/goinsight/internal/sqlguard"
+	"github.com/chuckie/goinsight/tests/mocks"
+)
+
+// MockLLMClient is a mock LLM client for testing
+type MockLLMClient struct {
+	GenerateSQLFn     func(context.Context, string) (string, error)
+	GenerateInsightFn func(context.Context, string, []map[string]any) (string, error)
+	GenerateFn        func(context.Context, string) (string, error)
+}
+
+func (m *MockLLMClient) GenerateSQL(ctx context.Context, question string) (string, error) {
+	if m.GenerateSQLFn != nil {
+		return m.GenerateSQLFn(ctx, question)
+	}
+	return "SELECT * FROM feedback_enriched LIMIT 10", nil
+}
+
+func (m *MockLLMClient) GenerateInsight(ctx context.Context, question string, results []map[string]any) (string, error) {
+	if m.GenerateInsightFn != nil {
+		return m.GenerateInsightFn(ctx, question, results)
+	}
+	return "Analysis: feedback is positive", nil
+}
+
+func (m *MockLLMClient) GenerateInsightStream(ctx context.Context, question string, results []map[string]any, onToken llm.TokenHandler) (string, error) {
+	response, err := m.GenerateInsight(ctx, question, results)
+	if err == nil && onToken != nil {
+		onToken(response)
+	}
+	return response, err
+}
+
+func (m *MockLLMClient) Generate(ctx context.Context, prompt string) (string, error) {
+	if m.GenerateFn != nil {
+		return m.GenerateFn(ctx, prompt)
+	}
+	return "Generated response", nil
+}
+
+// MockDatabaseClient is a mock database client for testing
+type MockDatabaseClient struct {
+	ExecuteQueryFn                   func(query string) ([]map[string]any, error)
+	HealthCheckFn                    func() error
+	GetAccountRiskScoreFn            func(accountID string) (*sql.Row, error)
+	GetRecentNegativeFeedbackCountFn func(accountID string) (int, error)
+	GetProductAreaImpactsFn          func(segment string) ([]map[string]any, error)
+	CloseFn                          func() error
+}
+
+func (m *MockDatabaseClient) ExecuteQuery(query string) ([]map[string]any, error) {
+	if m.ExecuteQueryFn != nil {
+		return m.ExecuteQueryFn(query)
+	}
+	return []map[string]any{}, nil
+}
+
+func (m *MockDatabaseClient) HealthCheck() error {
+	if m.HealthCheckFn != nil {
+		return m.HealthCheckFn()
+	}
+	return nil
+}
+
+func (m *MockDatabaseClient) GetAccountRiskScore(accountID string) (*sql.Row, error) {
+	if m.GetAccountRiskScoreFn != nil {
+		return m.GetAccountRiskScoreFn(accountID)
+	}
+	return nil, nil
+}
+
+func (m *MockDatabaseClient) GetRecentNegativeFeedbackCount(accountID string) (int, error) {
+	if m.GetRecentNegativeFeedbackCountFn != nil {
+		return m.GetRecentNegativeFeedbackCountFn(accountID)
+	}
+	return 0, nil
+}
+
+func (m *MockDatabaseClient) GetProductAreaImpacts(segment string) ([]map[string]any, error) {
+	if m.GetProductAreaImpactsFn != nil {
+		return m.GetProductAreaImpactsFn(segment)
+	}
+	return []map[string]any{}, nil
+}
+
+func (m *MockDatabaseClient) Close() error {
+	if m.CloseFn != nil {
+		return m.CloseFn()
+	}
+	return nil
+}
+
+// TestHealthCheckSuccess tests successful health check
+func TestHealthCheckSuccess(t *testing.T) {
+	// Skip actual DB check - just test response structure
+	t.Skip("Database required for health check test")
+}
+
+// TestHealthCheckUnhealthy tests health check when database is unhealthy
+func TestHealthCheckUnhealthy(t *testing.T) {
+	// Skip - requires actual database for testing
+	t.Skip("Database required for health check test")
+}
+
+// TestAskInvalidRequest tests Ask endpoint with invalid request
+func TestAskInvalidRequest(t *testing.T) {
+	handler := &Handler{
+		sqlValidator: sqlguard.NewDefaultValidator(),
+		llmClient:    &MockLLMClient{},
+	}
+
+	tests := []struct {
+		name        string
+		body        string
+		expectError bool
+	}{
+		{
+			name:        "empty body",
+			body:        "",
+			expectError: true,
+		},
+		{
+			name:        "invalid JSON",
+			body:        "not json",
+			expectError: true,
+		},
+		{
+			name:        "empty question",
+			body:        `{"question":""}`,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/ask", bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+
+			handler.Ask(w, req)
+
+			if !tt.expectError && w.Code == http.StatusBadRequest {
+				t.Errorf("Expected success, got error status %d", w.Code)
+			}
+			if tt.expectError && w.Code == http.StatusOK {
+				t.Errorf("Expected error, got success status %d", w.Code)
+			}
+		})
+	}
+}
+
+// TestAskValidRequest tests Ask endpoint with valid request
+func TestAskValidRequest(t *testing.T) {
+	mockDBClient := &MockDatabaseClient{
+		ExecuteQueryFn: func(query string) ([]map[string]any, error) {
+			return []map[string]any{
+				{"id": 1, "feedback": "excellent service", "sentiment": "positive"},
+			}, nil
+		},
+	}
+
+	handler := &Handler{
+		sqlValidator: sqlguard.NewDefaultValidator(),
+		dbClient:     mockDBClient,
+		llmClient: &MockLLMClient{
+			GenerateSQLFn: func(ctx context.Context, q string) (string, error) {
+				return "SELECT * FROM feedback_enriched WHERE sentiment = 'positive'", nil
+			},
+			GenerateInsightFn: func(ctx context.Context, feedback string, results []map[string]any) (string, error) {
+				return `{"summary": "Most customers are satisfied", "recommendations": ["improve service"], "actions": []}`, nil
+			},
+		},
+	}
+
+	reqBody := domain.AskRequest{
+		Question: "What is customer sentiment?",
+	}
+	body, _ := json.Marshal(reqBody)
+
+	req := httptest.NewRequest("POST", "/ask", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler.Ask(w, req)
+
+	// Should succeed
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// Verify response structure
+	var response domain.AskResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Errorf("Failed to unmarshal response: %v", err)
+	}
+
+	if response.Summary == "" {
+		t.Error("Expected summary in response")
+	}
+	if len(response.DataPreview) == 0 {
+		t.Error("Expected data preview in response")
+	}
+}
+
+// TestQueryEndpoint tests the Query endpoint
+func TestQueryEndpoint(t *testing.T) {
+	tests := []struct {
+		name        string
+		sqlQuery    string
+		expectError bool
+	}{
+		{
+			name:        "valid query",
+			sqlQuery:    "SELECT * FROM feedback_enriched LIMIT 10",
+			expectError: false,
+		},
+		{
+			name:        "empty query",
+			sqlQuery:    "",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/query", bytes.NewBufferString(`{"sql":"`+tt.sqlQuery+`"}`))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			// Query endpoint should validate SQL
+			if tt.expectError && w.Code == http.StatusOK {
+				t.Logf("Empty query should have been rejected")
+			}
+		})
+	}
+}
+
+// TestContentType tests that endpoints return correct content type
+func TestContentType(t *testing.T) {
+	mockDBClient := &MockDatabaseClient{
+		HealthCheckFn: func() error {
+			return nil
+		},
+	}
+	handler := &Handler{
+		sqlValidator: sqlguard.NewDefaultValidator(),
+		dbClient:     mockDBClient,
+	}
+
+	req := httptest.NewRequest("GET", "/health", nil)
+	w := httptest.NewRecorder()
+
+	handler.HealthCheck(w, req)
+
+	contentType := w.Header().Get("Content-Type")
+	if contentType != "application/json" {
+		t.Errorf("Expected Content-Type 'application/json', got '%s'", contentType)
+	}
+}
+
+// TestCORSHeaders tests CORS headers in responses
+func TestCORSHeaders(t *testing.T) {
+	t.Skip("Database required for response test")
+}
+
+// TestRequestValidation tests request validation
+func TestRequestValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		expectError bool
+	}{
+		{
+			name:        "GET health check",
+			method:      "GET",
+			path:        "/health",
+			expectError: false,
+		},
+		{
+			name:        "POST ask",
+			method:      "POST",
+			path:        "/ask",
+			expectError: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.method == "GET" && tt.path == "/health" {
+				t.Skip("Database required for health check test")
+			}
+		})
+	}
+}
+
+// TestResponseFormat tests response JSON format
+func TestResponseFormat(t *testing.T) {
+	t.Skip("Database required for response test")
+}
+
+// TestErrorResponse tests error response format
+func TestErrorResponse(t *testing.T) {
+	t.Skip("Database required for error response test")
+}
+
+// TestConcurrentRequests tests handling of concurrent requests
+func TestConcurrentRequests(t *testing.T) {
+	t.Skip("Database required for concurrent test")
+}
+
+// TestResponseStatusCodes tests various status code scenarios
+func TestResponseStatusCodes(t *testing.T) {
+	t.Skip("Database required for status code test")
+}
+
+// TestRequestHeaders tests custom request headers
+func TestRequestHeaders(t *testing.T) {
+	t.Skip("Database required for header test")
+}
+
+// TestJSONEncoding tests JSON encoding consistency
+func TestJSONEncoding(t *testing.T) {
+	mockDBClient := &MockDatabaseClient{
+		HealthCheckFn: func() error {
+			return nil
+		},
+	}
+	handler := &Handler{
+		sqlValidator: sqlguard.NewDefaultValidator(),
+		dbClient:     mockDBClient,
+	}
+
+	req := httptest.NewRequest("GET", "/health", nil)
+	w := httptest.NewRecorder()
+
+	handler.HealthCheck(w, req)
+
+	// Verify JSON can be decoded properly
+	var data map[string]interface{}
+	err := json.NewDecoder(w.Body).Decode(&data)
+
+	if err != nil {
+		t.Errorf("Failed to decode JSON: %v", err)
+	}
+}
+
+// BenchmarkHealthCheck benchmarks the health check endpoint
+func BenchmarkHealthCheck(b *testing.B) {
+	b.Skip("Database required for benchmark")
+}
+
+// BenchmarkAskRequest benchmarks the Ask endpoint
+func BenchmarkAskRequest(b *testing.B) {
+	mockDBClient := &MockDatabaseClient{
+		ExecuteQueryFn: func(query string) ([]map[string]any, error) {
+			return []map[string]any{
+				{"id": 1, "feedback": "excellent service"},
+			}, nil
+		},
+	}
+
+	handler := &Handler{
+		sqlValidator: sqlguard.NewDefaultValidator(),
+		dbClient:     mockDBClient,
+		llmClient: &MockLLMClient{
+			GenerateSQLFn: func(ctx context.Context, q string) (string, error) {
+				return "SELECT * FROM feedback_enriched WHERE sentiment = 'positive'", nil
+			},
+			GenerateInsightFn: func(ctx context.Context, feedback string, results []map[string]any) (string, error) {
+				return `{"summary": "Good feedback", "recommendations": [], "actions": []}`, nil
+			},
+		},
+	}
+
+	reqBody := domain.AskRequest{
+		Question: "What is the customer sentiment?",
+	}
+	body, _ := json.Marshal(reqBody)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		req := httptest.NewRequest("POST", "/ask", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		handler.Ask(w, req)
+	}
+}
+
+// BenchmarkResponseMarshal benchmarks JSON response marshaling
+func BenchmarkResponseMarshal(b *testing.B) {
+	resp := domain.AskResponse{
+		Question:    "Test question",
+		Summary:     "Test summary",
+		SQL:         "SELECT * FROM feedback_enriched",
+		DataPreview: []map[string]any{},
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		json.Marshal(resp)
+	}
+}
+
+// TestAskWithLLMError tests Ask endpoint when LLM SQL generation fails
+func TestAskWithLLMError(t *testing.T) {
+	mockDBClient := &MockDatabaseClient{}
+	handler := &Handler{
+		sqlValidator: sqlguard.NewDefaultValidator(),
+		dbClient:     mockDBClient,
+		llmClient: &MockLLMClient{
+			GenerateSQLFn: func(ctx context.Context, q string) (string, error) {
+				return "", fmt.Errorf("LLM service unavailable")
+			},
+		},
+	}
+
+	reqBody := domain.AskRequest{
+		Question: "What is customer sentiment?",
+	}
+	body, _ := json.Marshal(reqBody)
+
+	req := httptest.NewRequest("POST", "/ask", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler.Ask(w, req)
+
+	// Should return error
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", w.Code)
+	}
+}
+
+// TestAskWithDatabaseError tests Ask endpoint when database query fails
+func TestAskWithDatabaseError(t *testing.T) {
+	mockDBClient := &MockDatabaseClient{
+		ExecuteQueryFn: func(query string) ([]map[string]any, error) {
+			return nil, fmt.Errorf("database connection failed")
+		},
+	}
+
+	handler := &Handler{
+		sqlValidator: sqlguard.NewDefaultValidator(),
+		dbClient:     mockDBClient,
+		llmClient: &MockLLMClient{
+			GenerateSQLFn: func(ctx context.Context, q string) (string, error) {
+				return "SELECT * FROM feedback_enriched WHERE sentiment = 'positive'", nil
+			},
+		},
+	}
+
+	reqBody := domain.AskRequest{
+		Question: "What is customer sentiment?",
+	}
+	body, _ := json.Marshal(reqBody)
+
+	req := httptest.NewRequest("POST", "/ask", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler.Ask(w, req)
+
+	// Should return error
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", w.Code)
+	}
+}
+
+// TestAskWithInsightGenerationError tests Ask endpoint when insight generation fails
+func TestAskWithInsightGenerationError(t *testing.T) {
+	mockDBClient := &MockDatabaseClient{
+		ExecuteQueryFn: func(query string) ([]map[string]any, error) {
+			return []map[string]any{
+				{"id": 1, "feedback": "excellent service"},
+			}, nil
+		},
+	}
+
+	handler := &Handler{
+		sqlValidator: sqlguard.NewDefaultValidator(),
+		dbClient:     mockDBClient,
+		llmClient: &MockLLMClient{
+			GenerateSQLFn: func(ctx context.Context, q string) (string, error) {
+				return "SELECT * FROM feedback_enriched WHERE sentiment = 'positive'", nil
+			},
+			GenerateInsightFn: func(ctx context.Context, feedback string, results []map[string]any) (string, error) {
+				return "", fmt.Errorf("insight generation failed")
+			},
+		},
+	}
+
+	reqBody := domain.AskRequest{
+		Question: "What is customer sentiment?",
+	}
+	body, _ := json.Marshal(reqBody)
+
+	req := httptest.NewRequest("POST", "/ask", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler.Ask(w, req)
+
+	// Should return error
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", w.Code)
+	}
+}
+
+// TestAskWithEmptyResults tests Ask endpoint when query returns no results
+func TestAskWithEmptyResults(t *testing.T) {
+	mockDBClient := &MockDatabaseClient{
+		ExecuteQueryFn: func(query string) ([]map[string]any, error) {
+			return []map[string]any{}, nil
+		},
+	}
+
+	handler := &Handler{
+		sqlValidator: sqlguard.NewDefaultValidator(),
+		dbClient:     mockDBClient,
+		llmClient: &MockLLMClient{
+			GenerateSQLFn: func(ctx context.Context, q string) (string, error) {
+				return "SELECT * FROM feedback_enriched WHERE sentiment = 'nonexistent'", nil
+			},
+			GenerateInsightFn: func(ctx context.Context, feedback string, results []map[string]any) (string, error) {
+				return `{"summary": "No data found", "recommendations": [], "actions": []}`, nil
+			},
+		},
+	}
+
+	reqBody := domain.AskRequest{
+		Question: "What is customer sentiment?",
+	}
+	body, _ := json.Marshal(reqBody)
+
+	req := httptest.NewRequest("POST", "/ask", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler.Ask(w, req)
+
+	// Should succeed even with empty results
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+
+	var response domain.AskResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Errorf("Failed to unmarshal response: %v", err)
+	}
+
+	if len(response.DataPreview) != 0 {
+		t.Error("Expected empty data preview")
+	}
+}
+
+// TestHealthCheckWithError tests health check when database is unhealthy
+func TestHealthCheckWithError(t *testing.T) {
+	mockDBClient := &MockDatabaseClient{
+		HealthCheckFn: func() error {
+			return fmt.Errorf("database connection failed")
+		},
+	}
+
+	handler := &Handler{
+		sqlValidator: sqlguard.NewDefaultValidator(),
+		dbClient:     mockDBClient,
+	}
+
+	req := httptest.NewRequest("GET", "/health", nil)
+	w := httptest.NewRecorder()
+
+	handler.HealthCheck(w, req)
+
+	// Should return unhealthy status
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503, got %d", w.Code)
+	}
+
+	var response map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Errorf("Failed to unmarshal response: %v", err)
+	}
+
+	if response["status"] != "unhealthy" {
+		t.Errorf("Expected status 'unhealthy', got '%s'", response["status"])
+	}
+}
+
+// TestHealthCheckSuccess tests successful health check
+func TestHealthCheckHealthy(t *testing.T) {
+	mockDBClient := &MockDatabaseClient{
+		HealthCheckFn: func() error {
+			return nil
+		},
+	}
+
+	handler := &Handler{
+		sqlValidator: sqlguard.NewDefaultValidator(),
+		dbClient:     mockDBClient,
+	}
+
+	req := httptest.NewRequest("GET", "/health", nil)
+	w := httptest.NewRecorder()
+
+	handler.HealthCheck(w, req)
+
+	// Should return healthy status
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+
+	var response map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Errorf("Failed to unmarshal response: %v", err)
+	}
+
+	if response["status"] != "healthy" {
+		t.Errorf("Expected status 'healthy', got '%s'", response["status"])
+	}
+}
+
+// TestAskRejectsUnsafeSQL tests that unsafe generated SQL returns 400 with rejection reasons
+func TestAskRejectsUnsafeSQL(t *testing.T) {
+	tests := []struct {
+		name       string
+		sql        string
+		wantReason string
+	}{
+		{
+			name:       "stacked statements",
+			sql:        "SELECT * FROM feedback_enriched; DROP TABLE feedback_enriched",
+			wantReason: sqlguard.ReasonMultipleStatements,
+		},
+		{
+			name:       "writable CTE",
+			sql:        "WITH d AS (DELETE FROM feedback_enriched RETURNING *) SELECT * FROM d",
+			wantReason: sqlguard.ReasonWritableCTE,
+		},
+		{
+			name:       "disallowed function",
+			sql:        "SELECT pg_sleep(30)",
+			wantReason: sqlguard.ReasonFunctionNotAllowed,
+		},
+		{
+			name:       "disallowed table",
+			sql:        "SELECT * FROM pg_user",
+			wantReason: sqlguard.ReasonTableNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queried := false
+			handler := &Handler{
+				sqlValidator: sqlguard.NewDefaultValidator(),
+				dbClient: &MockDatabaseClient{
+					ExecuteQueryFn: func(query string) ([]map[string]any, error) {
+						queried = true
+						return nil, nil
+					},
+				},
+				llmClient: &MockLLMClient{
+					GenerateSQLFn: func(ctx context.Context, q string) (string, error) {
+						return tt.sql, nil
+					},
+				},
+			}
+
+			body, _ := json.Marshal(domain.AskRequest{Question: "What is customer sentiment?"})
+			req := httptest.NewRequest("POST", "/ask", bytes.NewBuffer(body))
+			w := httptest.NewRecorder()
+
+			handler.Ask(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("Expected status 400, got %d", w.Code)
+			}
+			if queried {
+				t.Error("Rejected SQL must not be executed")
+			}
+
+			var response struct {
+				Error   string            `json:"error"`
+				Reasons []sqlguard.Reason `json:"reasons"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			found := false
+			for _, r := range response.Reasons {
+				if r.Code == tt.wantReason {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("Expected reason %s, got %+v", tt.wantReason, response.Reasons)
+			}
+		})
+	}
+}
+
+// TestAskWithQueryTimeout tests that a query cancelled by its limits returns 504
+func TestAskWithQueryTimeout(t *testing.T) {
+	handler := &Handler{
+		sqlValidator: sqlguard.NewDefaultValidator(),
+		dbClient: &MockDatabaseClient{
+			ExecuteQueryFn: func(query string) ([]map[string]any, error) {
+				return nil, fmt.Errorf("query execution failed: %w", &db.QueryTimeoutError{
+					Limit:   "statement_timeout",
+					Timeout: 30 * time.Second,
+				})
+			},
+		},
+		llmClient: &MockLLMClient{
+			GenerateSQLFn: func(ctx context.Context, q string) (string, error) {
+				return "SELECT * FROM feedback_enriched", nil
+			},
+		},
+	}
+
+	body, _ := json.Marshal(domain.AskRequest{Question: "Show all feedback"})
+	req := httptest.NewRequest("POST", "/ask", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	handler.Ask(w, req)
+
+	if w.Code != http.StatusGatewayTimeout {
+		t.Errorf("Expected status 504, got %d", w.Code)
+	}
+}
+
+// TestJiraWebhookSignature tests that webhooks are only accepted with a valid signature
+func TestJiraWebhookSignature(t *testing.T) {
+	tickets := mocks.NewMockInsightTicketRepository()
+	tickets.Tickets = []domain.InsightTicket{{Key: "FB-1", Tracker: "jira", StatusCategory: domain.JiraStatusCategoryToDo}}
+	feedbackService := service.NewFeedbackService(mocks.NewMockFeedbackRepository(), &MockLLMClient{}, nil)
+	feedbackService.SetInsightTicketRepository(tickets)
+	handler := NewServiceHandler(feedbackService, nil)
+
+	body := []byte(`{"webhookEvent": "jira:issue_updated", "timestamp": 1760000000000,
+		"issue": {"key": "FB-1", "fields": {"status": {"name": "Done", "statusCategory": {"key": "done"}}}}}`)
+	sign := func(secret string) string {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	}
+
+	tests := []struct {
+		name      string
+		secret    string
+		signature string
+		want      int
+	}{
+		{"no secret configured", "", sign(""), http.StatusServiceUnavailable},
+		{"missing signature", "s3cret", "", http.StatusUnauthorized},
+		{"wrong secret", "s3cret", sign("other"), http.StatusUnauthorized},
+		{"valid signature", "s3cret", sign("s3cret"), http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler.SetJiraWebhookSecret(tt.secret)
+			req := httptest.NewRequest("POST", "/api/jira/webhook", bytes.NewReader(body))
+			if tt.signature != "" {
+				req.Header.Set("X-Hub-Signature", tt.signature)
+			}
+			w := httptest.NewRecorder()
+
+			handler.JiraWebhook(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	if tickets.Tickets[0].Open() {
+		t.Error("Expected the signed webhook to resolve FB-1")
+	}
+}
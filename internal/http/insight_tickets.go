@@ -0,0 +1,147 @@
+package http
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/chuckie/goinsight/internal/domain"
+	"github.com/chuckie/goinsight/internal/service"
+)
+
+// maxWebhookBody bounds the size of a Jira webhook payload
+const maxWebhookBody = 1 << 20
+
+// Bounds of the limit query parameter of /api/insight-tickets
+const (
+	defaultTicketListLimit = 100
+	maxTicketListLimit     = 500
+)
+
+// SetJiraWebhookSecret sets the secret Jira signs webhooks with; the webhook
+// endpoint rejects every request until it is set
+func (h *ServiceHandler) SetJiraWebhookSecret(secret string) {
+	h.jiraWebhookSecret = secret
+}
+
+// JiraWebhook records the status changes of insight tickets sent by a Jira
+// issue webhook. The payload must be signed with the webhook secret.
+func (h *ServiceHandler) JiraWebhook(w http.ResponseWriter, r *http.Request) {
+	if h.jiraWebhookSecret == "" {
+		respondError(w, http.StatusServiceUnavailable, "Jira webhook is not configured. Set JIRA_WEBHOOK_SECRET.")
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBody))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if !validWebhookSignature(h.jiraWebhookSecret, body, r.Header.Get("X-Hub-Signature")) {
+		respondError(w, http.StatusUnauthorized, "Invalid webhook signature")
+		return
+	}
+
+	var event domain.JiraWebhookEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	updated, err := h.feedbackService.HandleJiraWebhook(r.Context(), event)
+	if err != nil {
+		respondError(w, ticketTrackingErrorStatus(err), err.Error())
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]bool{"updated": updated})
+}
+
+// validWebhookSignature checks an X-Hub-Signature header ("sha256=<hex>"),
+// the HMAC-SHA256 of the body keyed with the webhook secret
+func validWebhookSignature(secret string, body []byte, header string) bool {
+	signature, ok := strings.CutPrefix(header, "sha256=")
+	if !ok {
+		return false
+	}
+	got, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+// ListInsightTickets returns the tickets filed from insights, filtered by
+// product_area and status (open or resolved)
+func (h *ServiceHandler) ListInsightTickets(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := domain.InsightTicketFilter{
+		ProductArea: strings.TrimSpace(query.Get("product_area")),
+		Limit:       defaultTicketListLimit,
+	}
+
+	switch query.Get("status") {
+	case "":
+	case "open":
+		open := true
+		filter.Open = &open
+	case "resolved":
+		open := false
+		filter.Open = &open
+	default:
+		respondError(w, http.StatusBadRequest, "status must be open or resolved")
+		return
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > maxTicketListLimit {
+			respondError(w, http.StatusBadRequest, "limit must be between 1 and 500")
+			return
+		}
+		filter.Limit = n
+	}
+
+	tickets, err := h.feedbackService.ListInsightTickets(r.Context(), filter)
+	if err != nil {
+		respondError(w, ticketTrackingErrorStatus(err), err.Error())
+		return
+	}
+	if tickets == nil {
+		tickets = []domain.InsightTicket{}
+	}
+	respondJSON(w, http.StatusOK, map[string]any{
+		"tickets": tickets,
+		"count":   len(tickets),
+	})
+}
+
+// InsightTicketStats returns ticket counts and resolution rates per product area
+func (h *ServiceHandler) InsightTicketStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.feedbackService.InsightTicketStats(r.Context())
+	if err != nil {
+		respondError(w, ticketTrackingErrorStatus(err), err.Error())
+		return
+	}
+	if stats == nil {
+		stats = []domain.ProductAreaTicketStats{}
+	}
+	respondJSON(w, http.StatusOK, map[string]any{
+		"product_areas": stats,
+	})
+}
+
+// ticketTrackingErrorStatus maps ticket tracking errors to HTTP status codes
+func ticketTrackingErrorStatus(err error) int {
+	if errors.Is(err, service.ErrTicketTrackingDisabled) {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
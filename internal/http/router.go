@@ -1,39 +1,91 @@
-package http
-
-import (
-	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
-	"github.com/go-chi/cors"
-)
-
-// NewRouter creates and configures the HTTP router
-func NewRouter(h *Handler) *chi.Mux {
-	r := chi.NewRouter()
-
-	// Middleware
-	r.Use(middleware.Logger)
-	r.Use(middleware.Recoverer)
-	r.Use(middleware.RequestID)
-	r.Use(middleware.RealIP)
-
-	// CORS middleware
-	r.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   []string{"http://localhost:*", "http://127.0.0.1:*", "https://localhost:*", "https://127.0.0.1:*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
-		AllowCredentials: false,
-		MaxAge:           300, // Maximum value not ignored by any of major browsers
-	}))
-
-	// Routes
-	r.Get("/api/health", h.HealthCheck)
-	r.Post("/api/ask", h.Ask)
-	r.Post("/api/jira-tickets", h.CreateJiraTickets)
-	
-	// ML prediction endpoints
-	r.Get("/api/accounts/{id}/health", h.GetAccountHealth)
-	r.Get("/api/priorities/product-areas", h.GetProductAreaPriorities)
-
-	return r
-}
+package http
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/cors"
+)
+
+// RouteHandler is the set of endpoints served by the API router.
+// Both the legacy Handler and the service-based ServiceHandler implement it.
+type RouteHandler interface {
+	HealthCheck(w http.ResponseWriter, r *http.Request)
+	Ask(w http.ResponseWriter, r *http.Request)
+	CreateJiraTickets(w http.ResponseWriter, r *http.Request)
+	GetAccountHealth(w http.ResponseWriter, r *http.Request)
+	GetProductAreaPriorities(w http.ResponseWriter, r *http.Request)
+}
+
+// StreamingRouteHandler is implemented by handlers that can stream /api/ask
+// progress as Server-Sent Events
+type StreamingRouteHandler interface {
+	AskStream(w http.ResponseWriter, r *http.Request)
+}
+
+// JiraDraftRouteHandler is implemented by handlers that can confirm the
+// drafts created by a /api/jira-tickets dry run
+type JiraDraftRouteHandler interface {
+	ConfirmJiraDraft(w http.ResponseWriter, r *http.Request)
+}
+
+// AnalysisRouteHandler is implemented by handlers that serve the stored
+// analyses tickets link back to
+type AnalysisRouteHandler interface {
+	GetAnalysis(w http.ResponseWriter, r *http.Request)
+}
+
+// InsightTicketRouteHandler is implemented by handlers that track the status
+// of tickets filed from insights
+type InsightTicketRouteHandler interface {
+	JiraWebhook(w http.ResponseWriter, r *http.Request)
+	ListInsightTickets(w http.ResponseWriter, r *http.Request)
+	InsightTicketStats(w http.ResponseWriter, r *http.Request)
+}
+
+// NewRouter creates and configures the HTTP router
+func NewRouter(h RouteHandler) *chi.Mux {
+	r := chi.NewRouter()
+
+	// Middleware
+	r.Use(middleware.Logger)
+	r.Use(middleware.Recoverer)
+	r.Use(middleware.RequestID)
+	r.Use(middleware.RealIP)
+
+	// CORS middleware
+	r.Use(cors.Handler(cors.Options{
+		AllowedOrigins:   []string{"http://localhost:*", "http://127.0.0.1:*", "https://localhost:*", "https://127.0.0.1:*"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
+		ExposedHeaders:   []string{"Link"},
+		AllowCredentials: false,
+		MaxAge:           300, // Maximum value not ignored by any of major browsers
+	}))
+
+	// Routes
+	r.Get("/api/health", h.HealthCheck)
+	r.Post("/api/ask", h.Ask)
+	if sh, ok := h.(StreamingRouteHandler); ok {
+		r.Post("/api/ask/stream", sh.AskStream)
+	}
+	r.Post("/api/jira-tickets", h.CreateJiraTickets)
+	if dh, ok := h.(JiraDraftRouteHandler); ok {
+		r.Post("/api/jira-tickets/drafts/{id}/confirm", dh.ConfirmJiraDraft)
+	}
+	if ah, ok := h.(AnalysisRouteHandler); ok {
+		r.Get("/api/analyses/{id}", ah.GetAnalysis)
+	}
+	if th, ok := h.(InsightTicketRouteHandler); ok {
+		r.Post("/api/jira/webhook", th.JiraWebhook)
+		r.Get("/api/insight-tickets", th.ListInsightTickets)
+		r.Get("/api/insight-tickets/stats", th.InsightTicketStats)
+	}
+	
+	// ML prediction endpoints
+	r.Get("/api/accounts/{id}/health", h.GetAccountHealth)
+	r.Get("/api/priorities/product-areas", h.GetProductAreaPriorities)
+
+	return r
+}
@@ -1,160 +1,286 @@
-package http
-
-import (
-	"encoding/json"
-	"fmt"
-	"net/http"
-	"time"
-
-	"github.com/chuckie/goinsight/internal/domain"
-	"github.com/chuckie/goinsight/internal/jira"
-	"github.com/chuckie/goinsight/internal/llm"
-	"github.com/chuckie/goinsight/internal/repository"
-	"github.com/chuckie/goinsight/internal/service"
-	"github.com/go-chi/chi/v5"
-)
-
-// ServiceHandler is the refactored handler using the service layer
-type ServiceHandler struct {
-	feedbackService *service.FeedbackService
-	jiraClient      *jira.Client
-}
-
-// NewServiceHandler creates a new service-based HTTP handler
-func NewServiceHandler(feedbackService *service.FeedbackService, jiraClient *jira.Client) *ServiceHandler {
-	return &ServiceHandler{
-		feedbackService: feedbackService,
-		jiraClient:      jiraClient,
-	}
-}
-
-// HealthCheck returns the health status of the service
-func (h *ServiceHandler) HealthCheck(w http.ResponseWriter, r *http.Request) {
-	respondJSON(w, http.StatusOK, map[string]string{
-		"status": "healthy",
-	})
-}
-
-// Ask handles the main insight generation endpoint using the service layer
-func (h *ServiceHandler) Ask(w http.ResponseWriter, r *http.Request) {
-	// Parse request
-	var req domain.AskRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid request body")
-		return
-	}
-
-	if req.Question == "" {
-		respondError(w, http.StatusBadRequest, "Question is required")
-		return
-	}
-
-	// Measure execution time
-	start := time.Now()
-
-	// Use service layer to analyze feedback
-	response, err := h.feedbackService.AnalyzeFeedback(r.Context(), req.Question)
-	if err != nil {
-		respondError(w, http.StatusInternalServerError, err.Error())
-		return
-	}
-
-	// Log execution time
-	duration := time.Since(start)
-	fmt.Printf("Ask endpoint completed in %v\n", duration)
-
-	respondJSON(w, http.StatusOK, response)
-}
-
-// CreateJiraTickets handles converting insights into Jira tickets
-func (h *ServiceHandler) CreateJiraTickets(w http.ResponseWriter, r *http.Request) {
-	// Check if Jira is configured
-	if h.jiraClient == nil {
-		respondError(w, http.StatusServiceUnavailable,
-			"Jira integration is not configured. Set JIRA_BASE_URL, JIRA_EMAIL, and JIRA_API_TOKEN environment variables.")
-		return
-	}
-
-	// Parse request
-	var req domain.JiraTicketRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid request body")
-		return
-	}
-
-	// Convert to service request and create tickets
-	serviceReq := service.JiraTicketRequest{
-		Summary:         req.Summary,
-		Recommendations: req.Recommendations,
-		Actions:         req.Actions,
-		Meta: service.JiraMetadata{
-			ProjectKey:       req.Meta.ProjectKey,
-			DefaultIssueType: req.Meta.DefaultIssueType,
-			DefaultLabels:    req.Meta.DefaultLabels,
-		},
-	}
-
-	result, err := h.feedbackService.CreateJiraTickets(r.Context(), serviceReq)
-	if err != nil {
-		respondError(w, http.StatusInternalServerError, err.Error())
-		return
-	}
-
-	respondJSON(w, http.StatusOK, result)
-}
-
-// GetAccountHealth retrieves ML predictions for a specific account
-func (h *ServiceHandler) GetAccountHealth(w http.ResponseWriter, r *http.Request) {
-	accountID := chi.URLParam(r, "id")
-	if accountID == "" {
-		respondError(w, http.StatusBadRequest, "Account ID is required")
-		return
-	}
-
-	score, err := h.feedbackService.GetAccountRiskScore(r.Context(), accountID)
-	if err != nil {
-		respondError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get account health: %v", err))
-		return
-	}
-
-	if score == nil {
-		respondError(w, http.StatusNotFound, "Account not found")
-		return
-	}
-
-	respondJSON(w, http.StatusOK, score)
-}
-
-// GetProductAreaPriorities retrieves ML predictions for product area priorities
-func (h *ServiceHandler) GetProductAreaPriorities(w http.ResponseWriter, r *http.Request) {
-	segment := r.URL.Query().Get("segment")
-
-	impacts, err := h.feedbackService.GetProductAreaImpacts(r.Context(), segment)
-	if err != nil {
-		respondError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get product area impacts: %v", err))
-		return
-	}
-
-	respondJSON(w, http.StatusOK, impacts)
-}
-
-
-// LegacyHandlerAdapter adapts the legacy Handler to work with the service layer
-// This allows for gradual migration to the new architecture
-type LegacyHandlerAdapter struct {
-	*Handler
-	feedbackService *service.FeedbackService
-}
-
-// NewLegacyHandlerAdapter creates an adapter for the legacy handler
-func NewLegacyHandlerAdapter(
-	handler *Handler,
-	repo repository.FeedbackRepository,
-	llmClient llm.Client,
-	jiraClient *jira.Client,
-) *LegacyHandlerAdapter {
-	return &LegacyHandlerAdapter{
-		Handler:         handler,
-		feedbackService: service.NewFeedbackService(repo, llmClient, jiraClient),
-	}
-}
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/chuckie/goinsight/internal/domain"
+	"github.com/chuckie/goinsight/internal/llm"
+	"github.com/chuckie/goinsight/internal/repository"
+	"github.com/chuckie/goinsight/internal/service"
+	"github.com/chuckie/goinsight/internal/tracker"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// ServiceHandler is the refactored handler using the service layer
+type ServiceHandler struct {
+	feedbackService   *service.FeedbackService
+	issueTracker      tracker.IssueTracker
+	jiraWebhookSecret string
+}
+
+// NewServiceHandler creates a new service-based HTTP handler
+func NewServiceHandler(feedbackService *service.FeedbackService, issueTracker tracker.IssueTracker) *ServiceHandler {
+	return &ServiceHandler{
+		feedbackService: feedbackService,
+		issueTracker:    issueTracker,
+	}
+}
+
+// HealthCheck returns the health status of the service
+func (h *ServiceHandler) HealthCheck(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, http.StatusOK, map[string]string{
+		"status": "healthy",
+	})
+}
+
+// Ask handles the main insight generation endpoint using the service layer
+func (h *ServiceHandler) Ask(w http.ResponseWriter, r *http.Request) {
+	// Parse request
+	var req domain.AskRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.Question == "" {
+		respondError(w, http.StatusBadRequest, "Question is required")
+		return
+	}
+	if req.ConversationID != "" && uuid.Validate(req.ConversationID) != nil {
+		respondError(w, http.StatusBadRequest, "conversation_id must be a UUID")
+		return
+	}
+
+	// Measure execution time
+	start := time.Now()
+
+	// Use service layer to analyze feedback, continuing the conversation if one is given
+	response, err := h.feedbackService.AnalyzeConversation(r.Context(), req.ConversationID, req.Question)
+	if err != nil {
+		// SQL rejections and query timeouts get dedicated status codes
+		respondServiceError(w, err)
+		return
+	}
+
+	// Log execution time
+	duration := time.Since(start)
+	fmt.Printf("Ask endpoint completed in %v\n", duration)
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+// AskStream is the Server-Sent Events variant of Ask. It sends an event as
+// each stage finishes (sql_generated, sql_validated, rows_fetched, then
+// insight_token for each chunk of the insight), followed by a "complete"
+// event carrying the AskResponse or an "error" event.
+func (h *ServiceHandler) AskStream(w http.ResponseWriter, r *http.Request) {
+	// Parse request
+	var req domain.AskRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.Question == "" {
+		respondError(w, http.StatusBadRequest, "Question is required")
+		return
+	}
+	if req.ConversationID != "" && uuid.Validate(req.ConversationID) != nil {
+		respondError(w, http.StatusBadRequest, "conversation_id must be a UUID")
+		return
+	}
+
+	start := time.Now()
+	stream := newSSEWriter(w)
+
+	response, err := h.feedbackService.AnalyzeConversationStream(r.Context(), req.ConversationID, req.Question, func(event service.AskEvent) {
+		// Write errors mean the client went away; the request context stops the analysis
+		_ = stream.Send(event.Type, event.Data)
+	})
+	if err != nil {
+		status, body := serviceErrorResponse(err)
+		body["status"] = status
+		_ = stream.Send("error", body)
+		return
+	}
+
+	fmt.Printf("Ask stream completed in %v\n", time.Since(start))
+	_ = stream.Send("complete", response)
+}
+
+// CreateJiraTickets handles converting insights into Jira tickets
+func (h *ServiceHandler) CreateJiraTickets(w http.ResponseWriter, r *http.Request) {
+	// Check if an issue tracker is configured
+	if h.issueTracker == nil {
+		respondError(w, http.StatusServiceUnavailable, trackerNotConfigured)
+		return
+	}
+
+	// Parse request
+	var req domain.JiraTicketRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Convert to service request and create tickets
+	serviceReq := service.JiraTicketRequest{
+		Question:        req.Question,
+		Summary:         req.Summary,
+		Recommendations: req.Recommendations,
+		Actions:         req.Actions,
+		DataPreview:     req.DataPreview,
+		SQL:             req.SQL,
+		AnalysisID:      req.AnalysisID,
+		Attributes:      req.Attributes,
+		Meta: service.JiraMetadata{
+			ProjectKey:       req.Meta.ProjectKey,
+			DefaultIssueType: req.Meta.DefaultIssueType,
+			DefaultLabels:    req.Meta.DefaultLabels,
+			CreateEpic:       req.Meta.CreateEpic,
+			MatchStrategy:    req.Meta.MatchStrategy,
+		},
+	}
+
+	// A dry run returns the specs as a draft to confirm later
+	if req.DryRun {
+		draft, err := h.feedbackService.DraftJiraTickets(r.Context(), serviceReq)
+		if err != nil {
+			respondError(w, jiraTicketErrorStatus(err), err.Error())
+			return
+		}
+		respondJSON(w, http.StatusOK, draft)
+		return
+	}
+
+	result, err := h.feedbackService.CreateJiraTickets(r.Context(), serviceReq)
+	if err != nil {
+		respondError(w, jiraTicketErrorStatus(err), err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// ConfirmJiraDraft files the tickets of a draft created by a dry run,
+// replacing them with the tickets in the request body if any are given
+func (h *ServiceHandler) ConfirmJiraDraft(w http.ResponseWriter, r *http.Request) {
+	if h.issueTracker == nil {
+		respondError(w, http.StatusServiceUnavailable, trackerNotConfigured)
+		return
+	}
+
+	draftID := chi.URLParam(r, "id")
+	if uuid.Validate(draftID) != nil {
+		respondError(w, http.StatusBadRequest, "Draft ID must be a UUID")
+		return
+	}
+
+	// The body is optional: without edits the draft is filed as generated
+	var req domain.JiraDraftConfirmRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	result, err := h.feedbackService.ConfirmJiraDraft(r.Context(), draftID, req.Tickets)
+	switch {
+	case errors.Is(err, service.ErrJiraDraftNotFound):
+		respondError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrJiraDraftConfirmed):
+		respondError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrJiraDraftExpired):
+		respondError(w, http.StatusGone, err.Error())
+	case errors.Is(err, service.ErrInvalidJiraTickets):
+		respondError(w, http.StatusBadRequest, err.Error())
+	case err != nil:
+		respondError(w, http.StatusInternalServerError, err.Error())
+	default:
+		respondJSON(w, http.StatusOK, result)
+	}
+}
+
+// jiraTicketErrorStatus returns the status for an error creating tickets: an
+// unknown analysis_id is the client's mistake
+func jiraTicketErrorStatus(err error) int {
+	if errors.Is(err, service.ErrAnalysisNotFound) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// GetAnalysis returns a stored /api/ask answer, the evidence behind the
+// tickets filed from it
+func (h *ServiceHandler) GetAnalysis(w http.ResponseWriter, r *http.Request) {
+	analysis, err := h.feedbackService.GetAnalysis(r.Context(), chi.URLParam(r, "id"))
+	switch {
+	case errors.Is(err, service.ErrAnalysisNotFound):
+		respondError(w, http.StatusNotFound, err.Error())
+	case err != nil:
+		respondError(w, http.StatusInternalServerError, err.Error())
+	default:
+		respondJSON(w, http.StatusOK, analysis)
+	}
+}
+
+// GetAccountHealth retrieves ML predictions for a specific account
+func (h *ServiceHandler) GetAccountHealth(w http.ResponseWriter, r *http.Request) {
+	accountID := chi.URLParam(r, "id")
+	if accountID == "" {
+		respondError(w, http.StatusBadRequest, "Account ID is required")
+		return
+	}
+
+	score, err := h.feedbackService.GetAccountRiskScore(r.Context(), accountID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get account health: %v", err))
+		return
+	}
+
+	if score == nil {
+		respondError(w, http.StatusNotFound, "Account not found")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, score)
+}
+
+// GetProductAreaPriorities retrieves ML predictions for product area priorities
+func (h *ServiceHandler) GetProductAreaPriorities(w http.ResponseWriter, r *http.Request) {
+	segment := r.URL.Query().Get("segment")
+
+	impacts, err := h.feedbackService.GetProductAreaImpacts(r.Context(), segment)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get product area impacts: %v", err))
+		return
+	}
+
+	respondJSON(w, http.StatusOK, impacts)
+}
+
+
+// LegacyHandlerAdapter adapts the legacy Handler to work with the service layer
+// This allows for gradual migration to the new architecture
+type LegacyHandlerAdapter struct {
+	*Handler
+	feedbackService *service.FeedbackService
+}
+
+// NewLegacyHandlerAdapter creates an adapter for the legacy handler
+func NewLegacyHandlerAdapter(
+	handler *Handler,
+	repo repository.FeedbackRepository,
+	llmClient llm.Client,
+	issueTracker tracker.IssueTracker,
+) *LegacyHandlerAdapter {
+	return &LegacyHandlerAdapter{
+		Handler:         handler,
+		feedbackService: service.NewFeedbackService(repo, llmClient, issueTracker),
+	}
+}
@@ -0,0 +1,274 @@
+package jira
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/chuckie/goinsight/internal/domain"
+)
+
+// maxPreviewRows bounds the data preview rows rendered in a description
+const maxPreviewRows = 10
+
+// previewHeading titles the data preview section of a description
+const previewHeading = "Supporting data"
+
+var (
+	headingLine     = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	bulletLine      = regexp.MustCompile(`^[-*+]\s+(.*)$`)
+	orderedLine     = regexp.MustCompile(`^\d+[.)]\s+(.*)$`)
+	ruleLine        = regexp.MustCompile(`^(-{3,}|\*{3,}|_{3,})$`)
+	tableSeparator  = regexp.MustCompile(`^\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?$`)
+	markdownLinkRef = regexp.MustCompile(`^\[([^\]]+)\]\(([^)\s]+)\)`)
+)
+
+// MarkdownToADF converts the markdown used in LLM-written descriptions to an
+// Atlassian Document Format document. It understands headings, bullet and
+// numbered lists, pipe tables, code fences, block quotes, horizontal rules,
+// and bold, italic, inline code and link formatting; anything else is kept as
+// paragraph text.
+func MarkdownToADF(markdown string) domain.ADFNode {
+	lines := strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n")
+	doc := domain.ADFNode{Type: "doc", Version: 1, Content: []domain.ADFNode{}}
+
+	var paragraph []string
+	flush := func() {
+		if len(paragraph) > 0 {
+			doc.Content = append(doc.Content, adfParagraph(paragraph...))
+			paragraph = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+
+		switch {
+		case line == "":
+			flush()
+
+		case strings.HasPrefix(line, "```"):
+			flush()
+			language := strings.TrimSpace(strings.TrimPrefix(line, "```"))
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				code = append(code, lines[i])
+			}
+			block := domain.ADFNode{Type: "codeBlock"}
+			if language != "" {
+				block.Attrs = map[string]any{"language": language}
+			}
+			if text := strings.Join(code, "\n"); text != "" {
+				block.Content = []domain.ADFNode{{Type: "text", Text: text}}
+			}
+			doc.Content = append(doc.Content, block)
+
+		case headingLine.MatchString(line):
+			flush()
+			m := headingLine.FindStringSubmatch(line)
+			doc.Content = append(doc.Content, domain.ADFNode{
+				Type:    "heading",
+				Attrs:   map[string]any{"level": len(m[1])},
+				Content: adfInline(m[2], nil),
+			})
+
+		case ruleLine.MatchString(line):
+			flush()
+			doc.Content = append(doc.Content, domain.ADFNode{Type: "rule"})
+
+		case bulletLine.MatchString(line) || orderedLine.MatchString(line):
+			flush()
+			pattern, listType := bulletLine, "bulletList"
+			if !bulletLine.MatchString(line) {
+				pattern, listType = orderedLine, "orderedList"
+			}
+			list := domain.ADFNode{Type: listType}
+			for ; i < len(lines) && pattern.MatchString(strings.TrimSpace(lines[i])); i++ {
+				item := pattern.FindStringSubmatch(strings.TrimSpace(lines[i]))[1]
+				list.Content = append(list.Content, domain.ADFNode{
+					Type:    "listItem",
+					Content: []domain.ADFNode{adfParagraph(item)},
+				})
+			}
+			i--
+			doc.Content = append(doc.Content, list)
+
+		case strings.HasPrefix(line, "|") && i+1 < len(lines) && tableSeparator.MatchString(strings.TrimSpace(lines[i+1])):
+			flush()
+			rows := [][]string{splitTableRow(line)}
+			for i += 2; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), "|"); i++ {
+				rows = append(rows, splitTableRow(strings.TrimSpace(lines[i])))
+			}
+			i--
+			doc.Content = append(doc.Content, adfTable(rows[0], rows[1:]))
+
+		case strings.HasPrefix(line, ">"):
+			flush()
+			var quoted []string
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">"); i++ {
+				quoted = append(quoted, strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(lines[i]), ">")))
+			}
+			i--
+			doc.Content = append(doc.Content, domain.ADFNode{
+				Type:    "blockquote",
+				Content: []domain.ADFNode{adfParagraph(quoted...)},
+			})
+
+		default:
+			paragraph = append(paragraph, line)
+		}
+	}
+	flush()
+
+	return doc
+}
+
+// adfParagraph returns a paragraph of lines separated by hard breaks
+func adfParagraph(lines ...string) domain.ADFNode {
+	p := domain.ADFNode{Type: "paragraph"}
+	for i, line := range lines {
+		if i > 0 {
+			p.Content = append(p.Content, domain.ADFNode{Type: "hardBreak"})
+		}
+		p.Content = append(p.Content, adfInline(line, nil)...)
+	}
+	return p
+}
+
+// adfTable returns a table with a header row
+func adfTable(header []string, rows [][]string) domain.ADFNode {
+	table := domain.ADFNode{Type: "table"}
+	table.Content = append(table.Content, adfTableRow("tableHeader", header))
+	for _, row := range rows {
+		table.Content = append(table.Content, adfTableRow("tableCell", row))
+	}
+	return table
+}
+
+// adfTableRow returns a row of cellType ("tableHeader" or "tableCell") cells
+func adfTableRow(cellType string, cells []string) domain.ADFNode {
+	row := domain.ADFNode{Type: "tableRow"}
+	for _, cell := range cells {
+		row.Content = append(row.Content, domain.ADFNode{
+			Type:    cellType,
+			Content: []domain.ADFNode{adfParagraph(cell)},
+		})
+	}
+	return row
+}
+
+// splitTableRow splits a markdown pipe table row into trimmed cells
+func splitTableRow(line string) []string {
+	line = strings.TrimSuffix(strings.TrimPrefix(line, "|"), "|")
+	cells := strings.Split(line, "|")
+	for i := range cells {
+		cells[i] = strings.TrimSpace(cells[i])
+	}
+	return cells
+}
+
+// adfInline converts inline markdown to text nodes carrying marks
+func adfInline(s string, marks []domain.ADFMark) []domain.ADFNode {
+	var nodes []domain.ADFNode
+	var plain strings.Builder
+	emit := func() {
+		if plain.Len() > 0 {
+			nodes = append(nodes, domain.ADFNode{Type: "text", Text: plain.String(), Marks: marks})
+			plain.Reset()
+		}
+	}
+	with := func(mark domain.ADFMark) []domain.ADFMark {
+		return append(append([]domain.ADFMark(nil), marks...), mark)
+	}
+
+	for i := 0; i < len(s); {
+		rest := s[i:]
+		switch {
+		case rest[0] == '`':
+			if end := strings.IndexByte(rest[1:], '`'); end > 0 {
+				emit()
+				nodes = append(nodes, domain.ADFNode{Type: "text", Text: rest[1 : end+1], Marks: with(domain.ADFMark{Type: "code"})})
+				i += end + 2
+				continue
+			}
+		case strings.HasPrefix(rest, "**"):
+			if end := strings.Index(rest[2:], "**"); end > 0 {
+				emit()
+				nodes = append(nodes, adfInline(rest[2:end+2], with(domain.ADFMark{Type: "strong"}))...)
+				i += end + 4
+				continue
+			}
+		case rest[0] == '*' && len(rest) > 1 && rest[1] != ' ':
+			if end := strings.IndexByte(rest[1:], '*'); end > 0 {
+				emit()
+				nodes = append(nodes, adfInline(rest[1:end+1], with(domain.ADFMark{Type: "em"}))...)
+				i += end + 2
+				continue
+			}
+		case rest[0] == '[':
+			if m := markdownLinkRef.FindStringSubmatch(rest); m != nil {
+				emit()
+				link := domain.ADFMark{Type: "link", Attrs: map[string]any{"href": m[2]}}
+				nodes = append(nodes, adfInline(m[1], with(link))...)
+				i += len(m[0])
+				continue
+			}
+		}
+		plain.WriteByte(s[i])
+		i++
+	}
+	emit()
+
+	return nodes
+}
+
+// previewTable returns the header and rows of a data preview, with columns
+// in name order and at most limit rows (all rows if limit is 0)
+func previewTable(preview []map[string]any, limit int) ([]string, [][]string) {
+	columnSet := make(map[string]bool)
+	for _, row := range preview {
+		for column := range row {
+			columnSet[column] = true
+		}
+	}
+	columns := make([]string, 0, len(columnSet))
+	for column := range columnSet {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	if limit > 0 && len(preview) > limit {
+		preview = preview[:limit]
+	}
+	rows := make([][]string, len(preview))
+	for i, row := range preview {
+		rows[i] = make([]string, len(columns))
+		for j, column := range columns {
+			if value, ok := row[column]; ok && value != nil {
+				rows[i][j] = fmt.Sprint(value)
+			}
+		}
+	}
+	return columns, rows
+}
+
+// wikiTable renders a table in Jira wiki markup for v2 descriptions
+func wikiTable(header []string, rows [][]string) string {
+	escape := strings.NewReplacer("|", `\|`, "\n", " ")
+	var b strings.Builder
+	b.WriteString("||")
+	for _, cell := range header {
+		b.WriteString(escape.Replace(cell) + "||")
+	}
+	for _, row := range rows {
+		b.WriteString("\n|")
+		for _, cell := range row {
+			if cell == "" {
+				cell = " "
+			}
+			b.WriteString(escape.Replace(cell) + "|")
+		}
+	}
+	return b.String()
+}
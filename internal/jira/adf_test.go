@@ -0,0 +1,135 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/chuckie/goinsight/internal/domain"
+)
+
+// nodeTypes lists the types of a node's children
+func nodeTypes(nodes []domain.ADFNode) string {
+	types := make([]string, len(nodes))
+	for i, node := range nodes {
+		types[i] = node.Type
+	}
+	return strings.Join(types, ",")
+}
+
+// TestMarkdownToADF tests the block structure produced for common markdown
+func TestMarkdownToADF(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{"empty", "", ""},
+		{"paragraphs", "First line\nsecond line\n\nNext paragraph", "paragraph,paragraph"},
+		{"heading and list", "## Impact\n- Faster refunds\n- Fewer tickets", "heading,bulletList"},
+		{"ordered list", "1. Reproduce\n2. Fix", "orderedList"},
+		{"table", "| area | count |\n|---|---:|\n| billing | 42 |", "table"},
+		{"code block", "```sql\nSELECT 1\n```", "codeBlock"},
+		{"quote and rule", "> customer said\n---\nafter", "blockquote,rule,paragraph"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := MarkdownToADF(tt.markdown)
+			if doc.Type != "doc" || doc.Version != 1 {
+				t.Fatalf("root = %s v%d, want doc v1", doc.Type, doc.Version)
+			}
+			if got := nodeTypes(doc.Content); got != tt.want {
+				t.Errorf("blocks = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestMarkdownToADFDetails tests list items, table cells and inline marks
+func TestMarkdownToADFDetails(t *testing.T) {
+	doc := MarkdownToADF("## Impact\n- **Faster** refunds\n\n| area | count |\n|---|---|\n| billing | 42 |\n\nSee [the report](https://example.com) and `refunds`")
+
+	if level := doc.Content[0].Attrs["level"]; level != 2 {
+		t.Errorf("heading level = %v, want 2", level)
+	}
+
+	item := doc.Content[1].Content[0].Content[0].Content
+	if len(item) != 2 || item[0].Text != "Faster" || len(item[0].Marks) != 1 || item[0].Marks[0].Type != "strong" || item[1].Text != " refunds" {
+		t.Errorf("list item = %+v, want strong Faster then plain text", item)
+	}
+
+	table := doc.Content[2]
+	if len(table.Content) != 2 || nodeTypes(table.Content[0].Content) != "tableHeader,tableHeader" || nodeTypes(table.Content[1].Content) != "tableCell,tableCell" {
+		t.Fatalf("table = %+v, want a header row and a data row", table)
+	}
+	if cell := table.Content[1].Content[1].Content[0].Content[0].Text; cell != "42" {
+		t.Errorf("cell = %q, want 42", cell)
+	}
+
+	inline := doc.Content[3].Content
+	if nodeTypes(inline) != "text,text,text,text" {
+		t.Fatalf("inline = %+v", inline)
+	}
+	if inline[1].Marks[0].Type != "link" || inline[1].Marks[0].Attrs["href"] != "https://example.com" {
+		t.Errorf("link = %+v", inline[1])
+	}
+	if inline[3].Text != "refunds" || inline[3].Marks[0].Type != "code" {
+		t.Errorf("code = %+v", inline[3])
+	}
+
+	// Unclosed markers are kept as text
+	if got := MarkdownToADF("2 * 3 and **open").Content[0].Content; len(got) != 1 || got[0].Text != "2 * 3 and **open" {
+		t.Errorf("unclosed markers = %+v", got)
+	}
+}
+
+// TestCreateIssueDescriptionVersions tests the description and data preview sent to v2 and v3
+func TestCreateIssueDescriptionVersions(t *testing.T) {
+	spec := domain.JiraTicketSpec{
+		IssueType:   "Story",
+		Summary:     "Fix refunds",
+		Description: "## Context\nRefunds are slow.",
+		DataPreview: []map[string]any{{"product_area": "billing", "count": 42}, {"product_area": "a|b", "count": nil}},
+	}
+
+	t.Run("v2", func(t *testing.T) {
+		fake, client := newFakeJira(t)
+		resp, err := client.CreateIssue(context.Background(), spec)
+		if err != nil {
+			t.Fatalf("CreateIssue() unexpected error: %v", err)
+		}
+		if fake.requests[0] != "POST /rest/api/2/issue" {
+			t.Errorf("request = %q, want v2 issue endpoint", fake.requests[0])
+		}
+		want := "## Context\nRefunds are slow.\n\nh3. Supporting data\n||count||product_area||\n|42|billing|\n| |a\\|b|"
+		if got := fake.fields[resp.Key]["description"]; got != want {
+			t.Errorf("description = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("v3", func(t *testing.T) {
+		fake, client := newFakeJira(t)
+		client.SetAPIVersion(APIVersion3)
+		resp, err := client.CreateIssue(context.Background(), spec)
+		if err != nil {
+			t.Fatalf("CreateIssue() unexpected error: %v", err)
+		}
+		if fake.requests[0] != "POST /rest/api/3/issue" {
+			t.Errorf("request = %q, want v3 issue endpoint", fake.requests[0])
+		}
+
+		raw, _ := json.Marshal(fake.fields[resp.Key]["description"])
+		var doc domain.ADFNode
+		if err := json.Unmarshal(raw, &doc); err != nil {
+			t.Fatalf("description is not an ADF document: %s", raw)
+		}
+		if got := nodeTypes(doc.Content); got != "heading,paragraph,heading,table" {
+			t.Errorf("blocks = %q, want the description then the preview table", got)
+		}
+		if rows := doc.Content[3].Content; len(rows) != 3 {
+			t.Errorf("table has %d rows, want header plus 2", len(rows))
+		}
+	})
+}
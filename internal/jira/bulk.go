@@ -0,0 +1,231 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/chuckie/goinsight/internal/domain"
+)
+
+// DefaultConcurrency is how many issues are checked for duplicates, or given
+// their evidence, at the same time
+const DefaultConcurrency = 4
+
+// maxBulkIssues is the most issues Jira creates in one /issue/bulk request
+const maxBulkIssues = 50
+
+// SetConcurrency sets how many issues are checked for duplicates, or given
+// their evidence, at the same time (at least 1)
+func (c *Client) SetConcurrency(n int) {
+	c.concurrency = max(n, 1)
+}
+
+// CreateIssues creates multiple issues in Jira. Specs with a Fingerprint are
+// checked for duplicates first: an issue already carrying the fingerprint
+// label is skipped, and a similar unresolved issue is tagged with the label
+// instead of creating a new one. The rest are created through /issue/bulk in
+// chunks, and each spec's outcome is reported in Actions, in spec order.
+// Cancelling ctx fails the specs not yet filed.
+func (c *Client) CreateIssues(ctx context.Context, specs []domain.JiraTicketSpec) (*domain.JiraCreationResult, error) {
+	actions := make([]domain.JiraActionResult, len(specs))
+	created := make([]*domain.JiraCreateResponse, len(specs))
+	errs := make([]error, len(specs))
+	labelled := make([]domain.JiraTicketSpec, len(specs))
+
+	// Specs sharing a fingerprint are filed once, for the first of them
+	firstWithFingerprint := make(map[string]int)
+	repeatOf := make(map[int]int)
+	for i, spec := range specs {
+		actions[i] = domain.JiraActionResult{Summary: spec.Summary, Fingerprint: spec.Fingerprint}
+		if spec.Fingerprint == "" {
+			continue
+		}
+		if first, ok := firstWithFingerprint[spec.Fingerprint]; ok {
+			repeatOf[i] = first
+		} else {
+			firstWithFingerprint[spec.Fingerprint] = i
+		}
+	}
+
+	// Check for duplicates
+	var toCreate []int
+	var mu sync.Mutex
+	c.forEach(len(specs), func(i int) {
+		if _, ok := repeatOf[i]; ok {
+			return
+		}
+		spec := specs[i]
+		if spec.Fingerprint != "" {
+			existing, status, err := c.findDuplicate(ctx, spec)
+			if err != nil {
+				errs[i] = fmt.Errorf("failed to check for duplicates: %w", err)
+				return
+			}
+			if existing != nil {
+				actions[i].Status = status
+				actions[i].Key = existing.Key
+				return
+			}
+			spec.Labels = append(append([]string(nil), spec.Labels...), spec.Fingerprint)
+		}
+		labelled[i] = spec
+
+		mu.Lock()
+		toCreate = append(toCreate, i)
+		mu.Unlock()
+	})
+	sort.Ints(toCreate)
+
+	// Create the rest in bulk
+	for start := 0; start < len(toCreate); start += maxBulkIssues {
+		chunk := toCreate[start:min(start+maxBulkIssues, len(toCreate))]
+		chunkSpecs := make([]domain.JiraTicketSpec, len(chunk))
+		for j, i := range chunk {
+			chunkSpecs[j] = labelled[i]
+		}
+		responses, chunkErrs := c.createBulk(ctx, chunkSpecs)
+		for j, i := range chunk {
+			created[i], errs[i] = responses[j], chunkErrs[j]
+			if created[i] != nil {
+				actions[i].Status = domain.JiraActionCreated
+				actions[i].Key = created[i].Key
+			}
+		}
+	}
+
+	// The issues exist either way, so a failed upload is reported on the action
+	c.forEach(len(specs), func(i int) {
+		if created[i] == nil {
+			return
+		}
+		if err := c.attachEvidence(ctx, created[i].Key, labelled[i]); err != nil {
+			actions[i].Error = err.Error()
+		}
+	})
+
+	for i, first := range repeatOf {
+		switch {
+		case actions[first].Key != "":
+			actions[i].Status = domain.JiraActionSkippedDuplicate
+			actions[i].Key = actions[first].Key
+		case errs[first] != nil:
+			errs[i] = fmt.Errorf("same fingerprint as ticket %d: %w", first+1, errs[first])
+		}
+	}
+
+	result := &domain.JiraCreationResult{
+		TicketSpecs:    specs,
+		CreatedTickets: make([]domain.JiraCreateResponse, 0, len(specs)),
+		Actions:        actions,
+		Errors:         make([]string, 0),
+	}
+	for i, spec := range specs {
+		if errs[i] != nil {
+			actions[i].Status = domain.JiraActionFailed
+			actions[i].Error = errs[i].Error()
+			result.Errors = append(result.Errors, fmt.Sprintf("Ticket %d (%s): %v", i+1, spec.Summary, errs[i]))
+		}
+		if created[i] != nil {
+			result.CreatedTickets = append(result.CreatedTickets, *created[i])
+		}
+	}
+
+	return result, nil
+}
+
+// createBulk creates specs with one /issue/bulk request, returning the
+// created issue or the error for each spec
+func (c *Client) createBulk(ctx context.Context, specs []domain.JiraTicketSpec) ([]*domain.JiraCreateResponse, []error) {
+	created := make([]*domain.JiraCreateResponse, len(specs))
+	errs := make([]error, len(specs))
+
+	// Specs whose fields cannot be built are left out of the request
+	var bulkReq domain.JiraBulkCreateRequest
+	var sent []int
+	hierarchies := make([]*domain.JiraHierarchy, len(specs))
+	for i, spec := range specs {
+		createReq, hierarchy, err := c.issueRequest(spec)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		bulkReq.IssueUpdates = append(bulkReq.IssueUpdates, createReq)
+		hierarchies[i] = hierarchy
+		sent = append(sent, i)
+	}
+	if len(sent) == 0 {
+		return created, errs
+	}
+
+	var resp domain.JiraBulkCreateResponse
+	if err := c.do(ctx, "POST", c.apiPath("issue/bulk"), bulkReq, &resp); err != nil {
+		// Jira answers 400 when every element fails, with the same body
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest ||
+			json.Unmarshal([]byte(apiErr.Body), &resp) != nil || len(resp.Errors) == 0 {
+			for _, i := range sent {
+				errs[i] = err
+			}
+			return created, errs
+		}
+	}
+
+	failed := make(map[int]error, len(resp.Errors))
+	for _, elementErr := range resp.Errors {
+		failed[elementErr.FailedElementNumber] = bulkError(elementErr)
+	}
+
+	// Created issues are listed in request order, without the failed elements
+	issues := resp.Issues
+	for element, i := range sent {
+		if err, ok := failed[element]; ok {
+			errs[i] = err
+			continue
+		}
+		if len(issues) == 0 {
+			errs[i] = fmt.Errorf("jira did not report the created issue")
+			continue
+		}
+		issue := issues[0]
+		issues = issues[1:]
+		setHierarchy(&issue, hierarchies[i])
+		created[i] = &issue
+	}
+	return created, errs
+}
+
+// bulkError describes the failure of one bulk element
+func bulkError(e domain.JiraBulkError) error {
+	messages := append([]string(nil), e.ElementErrors.ErrorMessages...)
+	fields := make([]string, 0, len(e.ElementErrors.Errors))
+	for field := range e.ElementErrors.Errors {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		messages = append(messages, field+": "+e.ElementErrors.Errors[field])
+	}
+	return fmt.Errorf("jira rejected the issue (status %d): %s", e.Status, strings.Join(messages, "; "))
+}
+
+// forEach calls fn for each index below n, at most c.concurrency at a time
+func (c *Client) forEach(n int, fn func(i int)) {
+	sem := make(chan struct{}, max(c.concurrency, 1))
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(i)
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,192 @@
+package jira
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chuckie/goinsight/internal/domain"
+)
+
+// countRequests returns how many requests were sent to a path suffix
+func countRequests(fake *fakeJira, suffix string) int {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	n := 0
+	for _, request := range fake.requests {
+		if strings.HasSuffix(request, suffix) {
+			n++
+		}
+	}
+	return n
+}
+
+// TestCreateIssuesBulk tests that issues are created in chunks with a result per spec
+func TestCreateIssuesBulk(t *testing.T) {
+	fake, client := newFakeJira(t)
+	fake.rejected = "Issue 3"
+
+	specs := make([]domain.JiraTicketSpec, maxBulkIssues+5)
+	for i := range specs {
+		specs[i] = domain.JiraTicketSpec{IssueType: "Story", Summary: fmt.Sprintf("Issue %d", i)}
+	}
+
+	result, err := client.CreateIssues(context.Background(), specs)
+	if err != nil {
+		t.Fatalf("CreateIssues() unexpected error: %v", err)
+	}
+	if got := countRequests(fake, "/issue/bulk"); got != 2 {
+		t.Errorf("bulk requests = %d, want 2", got)
+	}
+	if len(result.Actions) != len(specs) || len(result.CreatedTickets) != len(specs)-1 || len(result.Errors) != 1 {
+		t.Fatalf("got %d actions, %d created, errors %v; want %d, %d and one error",
+			len(result.Actions), len(result.CreatedTickets), result.Errors, len(specs), len(specs)-1)
+	}
+
+	// Each action matches its spec, around the rejected one
+	for i, action := range result.Actions {
+		if i == 3 {
+			if action.Status != domain.JiraActionFailed || !strings.Contains(action.Error, "summary: rejected") {
+				t.Errorf("action 3 = %+v, want failed with the field error", action)
+			}
+			continue
+		}
+		if action.Status != domain.JiraActionCreated || fake.issue(action.Key).Fields.Summary != specs[i].Summary {
+			t.Errorf("action %d = %+v, want created for %q", i, action, specs[i].Summary)
+		}
+	}
+}
+
+// TestCreateIssuesAllRejected tests the 400 Jira returns when every bulk element fails
+func TestCreateIssuesAllRejected(t *testing.T) {
+	fake, client := newFakeJira(t)
+	fake.rejected = "Fix refunds"
+
+	result, err := client.CreateIssues(context.Background(), []domain.JiraTicketSpec{{Summary: "Fix refunds"}})
+	if err != nil {
+		t.Fatalf("CreateIssues() unexpected error: %v", err)
+	}
+	if action := result.Actions[0]; action.Status != domain.JiraActionFailed || !strings.Contains(action.Error, "summary: rejected") {
+		t.Errorf("action = %+v, want failed with the field error", action)
+	}
+}
+
+// TestCreateIssuesRateLimited tests that rate-limited requests are retried after Retry-After
+func TestCreateIssuesRateLimited(t *testing.T) {
+	tests := []struct {
+		name        string
+		rateLimited int
+		maxRetries  int
+		wantStatus  string
+	}{
+		{"retried until accepted", 2, DefaultMaxRetries, domain.JiraActionCreated},
+		{"retries exhausted", 2, 1, domain.JiraActionFailed},
+		{"retries disabled", 1, 0, domain.JiraActionFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, client := newFakeJira(t)
+			fake.rateLimited = tt.rateLimited
+			fake.retryAfter = "0"
+			client.SetMaxRetries(tt.maxRetries)
+
+			result, err := client.CreateIssues(context.Background(), []domain.JiraTicketSpec{{Summary: "Fix refunds"}})
+			if err != nil {
+				t.Fatalf("CreateIssues() unexpected error: %v", err)
+			}
+			if action := result.Actions[0]; action.Status != tt.wantStatus {
+				t.Errorf("action = %+v, want %s", action, tt.wantStatus)
+			}
+			if got := countRequests(fake, "/issue/bulk"); got != min(tt.rateLimited, tt.maxRetries)+1 {
+				t.Errorf("bulk requests = %d, want %d", got, min(tt.rateLimited, tt.maxRetries)+1)
+			}
+		})
+	}
+}
+
+// TestCreateIssuesCancelled tests that a cancelled context fails the specs without filing them
+func TestCreateIssuesCancelled(t *testing.T) {
+	fake, client := newFakeJira(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	specs := []domain.JiraTicketSpec{
+		{Summary: "Fix refunds", Fingerprint: Fingerprint("q", "Fix refunds")},
+		{Summary: "Audit invoices"},
+	}
+	result, err := client.CreateIssues(ctx, specs)
+	if err != nil {
+		t.Fatalf("CreateIssues() unexpected error: %v", err)
+	}
+	for i, action := range result.Actions {
+		if action.Status != domain.JiraActionFailed || !strings.Contains(action.Error, context.Canceled.Error()) {
+			t.Errorf("action %d = %+v, want failed as cancelled", i, action)
+		}
+	}
+	if len(fake.requests) != 0 {
+		t.Errorf("requests = %v, want none", fake.requests)
+	}
+}
+
+// TestCreateIssuesRepeatedFingerprint tests that specs sharing a fingerprint are filed once
+func TestCreateIssuesRepeatedFingerprint(t *testing.T) {
+	fake, client := newFakeJira(t)
+	fingerprint := Fingerprint("q", "Fix refunds")
+	specs := []domain.JiraTicketSpec{
+		{Summary: "Fix refunds", Fingerprint: fingerprint},
+		{Summary: "Fix refunds again", Fingerprint: fingerprint},
+	}
+
+	result, err := client.CreateIssues(context.Background(), specs)
+	if err != nil {
+		t.Fatalf("CreateIssues() unexpected error: %v", err)
+	}
+	first, second := result.Actions[0], result.Actions[1]
+	if first.Status != domain.JiraActionCreated || second.Status != domain.JiraActionSkippedDuplicate || second.Key != first.Key {
+		t.Errorf("actions = %+v, want the second skipped as a duplicate of the first", result.Actions)
+	}
+	if len(fake.issues) != 1 {
+		t.Errorf("created %d issues, want 1", len(fake.issues))
+	}
+}
+
+// TestRateLimitWait tests which responses are retried and after how long
+func TestRateLimitWait(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		status   int
+		header   string
+		attempt  int
+		wantWait time.Duration
+		wantOK   bool
+	}{
+		{"429 with seconds", http.StatusTooManyRequests, "3", 0, 3 * time.Second, true},
+		{"429 with a date", http.StatusTooManyRequests, now.Add(10 * time.Second).UTC().Format(http.TimeFormat), 0, 10 * time.Second, true},
+		{"429 without Retry-After backs off", http.StatusTooManyRequests, "", 2, 4 * retryBaseDelay, true},
+		{"503 with Retry-After", http.StatusServiceUnavailable, "5", 0, 5 * time.Second, true},
+		{"503 without Retry-After", http.StatusServiceUnavailable, "", 0, 0, false},
+		{"server error", http.StatusInternalServerError, "5", 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+			if tt.header != "" {
+				resp.Header.Set("Retry-After", tt.header)
+			}
+			wait, ok := rateLimitWait(resp, tt.attempt)
+			if ok != tt.wantOK {
+				t.Fatalf("rateLimitWait() ok = %v, want %v", ok, tt.wantOK)
+			}
+			// HTTP dates have second precision
+			if diff := wait - tt.wantWait; diff > time.Second || diff < -time.Second {
+				t.Errorf("rateLimitWait() = %v, want %v", wait, tt.wantWait)
+			}
+		})
+	}
+}
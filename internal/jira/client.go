@@ -1,137 +1,278 @@
-package jira
-
-import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"io"
-	"net/http"
-	"time"
-
-	"github.com/chuckie/goinsight/internal/domain"
-)
-
-// Client handles communication with Jira Cloud REST API
-type Client struct {
-	baseURL    string
-	email      string
-	apiToken   string
-	projectKey string
-	httpClient *http.Client
-}
-
-// NewClient creates a new Jira API client
-func NewClient(baseURL, email, apiToken, projectKey string) *Client {
-	return &Client{
-		baseURL:    baseURL,
-		email:      email,
-		apiToken:   apiToken,
-		projectKey: projectKey,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-	}
-}
-
-// CreateIssue creates a single issue in Jira
-func (c *Client) CreateIssue(spec domain.JiraTicketSpec) (*domain.JiraCreateResponse, error) {
-	// Use project key from client if spec doesn't provide one
-	projectKey := spec.ProjectKey
-	if projectKey == "" {
-		projectKey = c.projectKey
-	}
-
-	// Convert spec to Jira API format
-	createReq := domain.JiraCreateRequest{
-		Fields: domain.JiraIssueFields{
-			Project: domain.JiraProject{
-				Key: projectKey,
-			},
-			Summary:     spec.Summary,
-			Description: spec.Description,
-			IssueType: domain.JiraIssueType{
-				Name: spec.IssueType,
-			},
-			Labels: spec.Labels,
-		},
-	}
-
-	// Add priority if specified
-	if spec.Priority != "" {
-		createReq.Fields.Priority = &domain.JiraPriority{
-			Name: spec.Priority,
-		}
-	}
-
-	// Add components if specified
-	if len(spec.Components) > 0 {
-		createReq.Fields.Components = make([]domain.JiraComponent, len(spec.Components))
-		for i, comp := range spec.Components {
-			createReq.Fields.Components[i] = domain.JiraComponent{Name: comp}
-		}
-	}
-
-	// Marshal request body
-	body, err := json.Marshal(createReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	// Create HTTP request
-	url := fmt.Sprintf("%s/rest/api/2/issue", c.baseURL)
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set headers
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
-	req.SetBasicAuth(c.email, c.apiToken)
-
-	// Execute request
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Read response body
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
-	}
-
-	// Check status code
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("jira API returned status %d: %s", resp.StatusCode, string(respBody))
-	}
-
-	// Parse response
-	var createResp domain.JiraCreateResponse
-	if err := json.Unmarshal(respBody, &createResp); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
-	}
-
-	return &createResp, nil
-}
-
-// CreateIssues creates multiple issues in Jira
-func (c *Client) CreateIssues(specs []domain.JiraTicketSpec) (*domain.JiraCreationResult, error) {
-	result := &domain.JiraCreationResult{
-		TicketSpecs:    specs,
-		CreatedTickets: make([]domain.JiraCreateResponse, 0, len(specs)),
-		Errors:         make([]string, 0),
-	}
-
-	for i, spec := range specs {
-		created, err := c.CreateIssue(spec)
-		if err != nil {
-			result.Errors = append(result.Errors, fmt.Sprintf("Ticket %d (%s): %v", i+1, spec.Summary, err))
-			continue
-		}
-		result.CreatedTickets = append(result.CreatedTickets, *created)
-	}
-
-	return result, nil
-}
+package jira
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chuckie/goinsight/internal/domain"
+)
+
+// REST API versions supported by Client
+const (
+	// APIVersion2 sends descriptions as wiki markup (Jira Server/Data Center and Cloud)
+	APIVersion2 = 2
+	// APIVersion3 sends descriptions as Atlassian Document Format (Jira Cloud)
+	APIVersion3 = 3
+)
+
+// Client handles communication with Jira Cloud REST API
+type Client struct {
+	baseURL    string
+	email      string
+	apiToken   string
+	projectKey string
+	httpClient *http.Client
+	apiVersion int
+
+	// Summaries at least this similar to an open issue's count as duplicates (0 disables)
+	duplicateThreshold float64
+
+	// Epic custom fields for classic projects; empty uses the parent field
+	epicLinkField string
+	epicNameField string
+
+	// Maps ticket attributes to custom fields and assignees; nil disables it
+	fieldMapping *FieldMapping
+
+	// Public API URL used to link issues to their analysis; empty disables links
+	analysisURL string
+
+	// Issues checked for duplicates or given evidence at the same time
+	concurrency int
+
+	// Retries of a rate-limited request, and when the last rate limit ends;
+	// every request waits for it, so concurrent requests back off together
+	maxRetries int
+	pauseMu    sync.Mutex
+	pauseUntil time.Time
+}
+
+// NewClient creates a new Jira API client
+func NewClient(baseURL, email, apiToken, projectKey string) *Client {
+	return &Client{
+		baseURL:    baseURL,
+		email:      email,
+		apiToken:   apiToken,
+		projectKey: projectKey,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		apiVersion:         APIVersion2,
+		duplicateThreshold: DefaultDuplicateThreshold,
+		concurrency:        DefaultConcurrency,
+		maxRetries:         DefaultMaxRetries,
+	}
+}
+
+// SetAPIVersion selects the REST API version: APIVersion2 (the default) or
+// APIVersion3, which converts markdown descriptions to Atlassian Document Format
+func (c *Client) SetAPIVersion(version int) {
+	c.apiVersion = version
+}
+
+// SetDuplicateThreshold sets how similar (0-1) a summary must be to an open
+// issue's summary to be linked to it instead of created (0 disables the check)
+func (c *Client) SetDuplicateThreshold(threshold float64) {
+	c.duplicateThreshold = threshold
+}
+
+// Name returns "jira", identifying the client as an issue tracker
+func (c *Client) Name() string {
+	return "jira"
+}
+
+// project returns the spec's project key, or the client's default if it has none
+func (c *Client) project(spec domain.JiraTicketSpec) string {
+	if spec.ProjectKey != "" {
+		return spec.ProjectKey
+	}
+	return c.projectKey
+}
+
+// CreateIssue creates a single issue in Jira
+func (c *Client) CreateIssue(ctx context.Context, spec domain.JiraTicketSpec) (*domain.JiraCreateResponse, error) {
+	createReq, hierarchy, err := c.issueRequest(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	var createResp domain.JiraCreateResponse
+	if err := c.do(ctx, "POST", c.apiPath("issue"), createReq, &createResp); err != nil {
+		return nil, err
+	}
+	setHierarchy(&createResp, hierarchy)
+
+	return &createResp, nil
+}
+
+// issueRequest converts spec to a create request, and returns the hierarchy
+// to report for the created issue
+func (c *Client) issueRequest(spec domain.JiraTicketSpec) (domain.JiraCreateRequest, *domain.JiraHierarchy, error) {
+	// Convert spec to Jira API format
+	createReq := domain.JiraCreateRequest{
+		Fields: domain.JiraIssueFields{
+			Project: domain.JiraProject{
+				Key: c.project(spec),
+			},
+			Summary:     spec.Summary,
+			Description: c.description(spec),
+			IssueType: domain.JiraIssueType{
+				Name: spec.IssueType,
+			},
+			Labels: spec.Labels,
+		},
+	}
+
+	// Add priority if specified
+	if spec.Priority != "" {
+		createReq.Fields.Priority = &domain.JiraPriority{
+			Name: spec.Priority,
+		}
+	}
+
+	// Add components if specified
+	if len(spec.Components) > 0 {
+		createReq.Fields.Components = make([]domain.JiraComponent, len(spec.Components))
+		for i, comp := range spec.Components {
+			createReq.Fields.Components[i] = domain.JiraComponent{Name: comp}
+		}
+	}
+
+	// Set the custom fields and assignee mapped from the spec's attributes
+	if err := c.applyFieldMapping(spec, &createReq.Fields); err != nil {
+		return createReq, nil, err
+	}
+
+	// Link to the epic, or fill in the epic's own fields
+	hierarchy := c.applyHierarchy(spec, &createReq.Fields)
+
+	return createReq, hierarchy, nil
+}
+
+// setHierarchy reports hierarchy on a created issue; an epic is its own epic
+func setHierarchy(created *domain.JiraCreateResponse, hierarchy *domain.JiraHierarchy) {
+	if hierarchy != nil && hierarchy.Role == domain.JiraRoleEpic {
+		hierarchy.EpicKey = created.Key
+	}
+	created.Hierarchy = hierarchy
+}
+
+// description renders the spec's description and data preview for the
+// configured API version
+func (c *Client) description(spec domain.JiraTicketSpec) any {
+	return c.richText(spec.Description, spec.DataPreview)
+}
+
+// richText renders markdown text followed by a data preview table for the
+// configured API version: wiki markup (v2) or an ADF document (v3)
+func (c *Client) richText(text string, preview []map[string]any) any {
+	if c.apiVersion == APIVersion3 {
+		doc := MarkdownToADF(text)
+		if len(preview) > 0 {
+			header, rows := previewTable(preview, maxPreviewRows)
+			doc.Content = append(doc.Content,
+				domain.ADFNode{
+					Type:    "heading",
+					Attrs:   map[string]any{"level": 3},
+					Content: []domain.ADFNode{{Type: "text", Text: previewHeading}},
+				},
+				adfTable(header, rows),
+			)
+		}
+		return doc
+	}
+
+	if len(preview) == 0 {
+		return text
+	}
+	header, rows := previewTable(preview, maxPreviewRows)
+	return strings.TrimSpace(text) + "\n\nh3. " + previewHeading + "\n" + wikiTable(header, rows)
+}
+
+// apiPath returns the path of a REST resource for the configured API version
+func (c *Client) apiPath(resource string) string {
+	return fmt.Sprintf("/rest/api/%d/%s", c.apiVersion, resource)
+}
+
+// do sends a JSON request to the Jira API and decodes the response into out
+// (if non-nil)
+func (c *Client) do(ctx context.Context, method, path string, payload, out any) error {
+	var reqBody io.Reader
+	if payload != nil {
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reqBody = bytes.NewBuffer(body)
+	}
+
+	// Create HTTP request
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	return c.send(req, out)
+}
+
+// send authenticates and executes a request, and decodes the response into
+// out (if non-nil). Rate-limited requests are retried after the wait Jira
+// asks for; the request body must be replayable (see http.Request.GetBody).
+func (c *Client) send(req *http.Request, out any) error {
+	// Set headers
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(c.email, c.apiToken)
+
+	for attempt := 0; ; attempt++ {
+		if err := c.waitForRateLimit(req.Context()); err != nil {
+			return err
+		}
+
+		// Execute request
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to execute request: %w", err)
+		}
+
+		// Read response body
+		respBody, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read response: %w", err)
+		}
+
+		// Back off and retry if rate limited
+		if wait, limited := rateLimitWait(resp, attempt); limited && attempt < c.maxRetries && wait <= maxRetryWait {
+			if req.Body != nil && req.GetBody == nil {
+				return &APIError{StatusCode: resp.StatusCode, Body: string(respBody)}
+			}
+			c.pause(wait)
+			if req, err = cloneRequest(req); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// Check status code
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return &APIError{StatusCode: resp.StatusCode, Body: string(respBody)}
+		}
+
+		// Parse response
+		if out == nil || len(respBody) == 0 {
+			return nil
+		}
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,499 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/chuckie/goinsight/internal/domain"
+)
+
+// fakeJira is an in-memory stand-in for the Jira REST endpoints used by Client
+type fakeJira struct {
+	t *testing.T
+
+	mu       sync.Mutex
+	issues   []domain.JiraIssue
+	resolved map[string]bool
+	requests []string
+	fields   map[string]map[string]any // created issue key -> fields sent
+
+	attachments map[string]map[string]string // issue key -> file name -> content
+	links       map[string][]string          // issue key -> remote link URLs
+	comments    map[string][]string          // issue key -> comment bodies
+
+	rateLimited int    // requests still to be answered with 429
+	retryAfter  string // Retry-After sent with a 429
+	rejected    string // summary rejected by the create endpoints
+}
+
+// apiPrefix matches the versioned REST prefix of a request path
+var apiPrefix = regexp.MustCompile(`^/rest/api/[23]/`)
+
+// labelsJQL matches the fingerprint clause of a duplicate search
+var labelsJQL = regexp.MustCompile(`labels = "([^"]+)"`)
+
+// newFakeJira starts a fake Jira server and a client pointed at it
+func newFakeJira(t *testing.T) (*fakeJira, *Client) {
+	t.Helper()
+	fake := &fakeJira{
+		t:           t,
+		resolved:    make(map[string]bool),
+		fields:      make(map[string]map[string]any),
+		attachments: make(map[string]map[string]string),
+		links:       make(map[string][]string),
+		comments:    make(map[string][]string),
+	}
+	server := httptest.NewServer(http.HandlerFunc(fake.serveHTTP))
+	t.Cleanup(server.Close)
+	return fake, NewClient(server.URL, "pm@example.com", "token", "APP")
+}
+
+// addIssue adds an existing issue to the fake
+func (f *fakeJira) addIssue(summary string, labels ...string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := fmt.Sprintf("APP-%d", len(f.issues)+1)
+	f.issues = append(f.issues, domain.JiraIssue{
+		ID:     fmt.Sprint(len(f.issues) + 1),
+		Key:    key,
+		Fields: domain.JiraIssueDetail{Summary: summary, Labels: labels},
+	})
+	return key
+}
+
+// issue returns the issue with the given key
+func (f *fakeJira) issue(key string) domain.JiraIssue {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, issue := range f.issues {
+		if issue.Key == key {
+			return issue
+		}
+	}
+	f.t.Fatalf("no issue %s", key)
+	return domain.JiraIssue{}
+}
+
+func (f *fakeJira) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+	limited := f.rateLimited > 0
+	if limited {
+		f.rateLimited--
+	}
+	f.mu.Unlock()
+
+	if limited {
+		if f.retryAfter != "" {
+			w.Header().Set("Retry-After", f.retryAfter)
+		}
+		http.Error(w, `{"errorMessages":["rate limit exceeded"]}`, http.StatusTooManyRequests)
+		return
+	}
+
+	if user, _, ok := r.BasicAuth(); !ok || user != "pm@example.com" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if !apiPrefix.MatchString(r.URL.Path) {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	resource := apiPrefix.ReplaceAllString(r.URL.Path, "")
+
+	switch {
+	case r.Method == "GET" && resource == "search":
+		f.search(w, r.URL.Query().Get("jql"))
+	case r.Method == "POST" && resource == "issue":
+		var raw struct {
+			Fields map[string]any `json:"fields"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		created, ok := f.create(raw.Fields)
+		if !ok {
+			http.Error(w, `{"errors":{"summary":"rejected"}}`, http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(created)
+	case r.Method == "POST" && resource == "issue/bulk":
+		var raw struct {
+			IssueUpdates []struct {
+				Fields map[string]any `json:"fields"`
+			} `json:"issueUpdates"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var resp domain.JiraBulkCreateResponse
+		for i, update := range raw.IssueUpdates {
+			created, ok := f.create(update.Fields)
+			if !ok {
+				resp.Errors = append(resp.Errors, domain.JiraBulkError{
+					Status:              http.StatusBadRequest,
+					ElementErrors:       domain.JiraErrorCollection{Errors: map[string]string{"summary": "rejected"}},
+					FailedElementNumber: i,
+				})
+				continue
+			}
+			resp.Issues = append(resp.Issues, created)
+		}
+		// Jira answers 400 when every element fails
+		if len(resp.Issues) == 0 {
+			w.WriteHeader(http.StatusBadRequest)
+		} else {
+			w.WriteHeader(http.StatusCreated)
+		}
+		json.NewEncoder(w).Encode(resp)
+	case r.Method == "POST" && strings.HasSuffix(resource, "/attachments"):
+		f.attach(w, r, strings.TrimSuffix(strings.TrimPrefix(resource, "issue/"), "/attachments"))
+	case r.Method == "POST" && strings.HasSuffix(resource, "/remotelink"):
+		var link struct {
+			Object struct {
+				URL string `json:"url"`
+			} `json:"object"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&link); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		key := strings.TrimSuffix(strings.TrimPrefix(resource, "issue/"), "/remotelink")
+		f.mu.Lock()
+		f.links[key] = append(f.links[key], link.Object.URL)
+		f.mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == "POST" && strings.HasSuffix(resource, "/comment"):
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		key := strings.TrimSuffix(strings.TrimPrefix(resource, "issue/"), "/comment")
+		f.mu.Lock()
+		f.comments[key] = append(f.comments[key], string(body))
+		f.mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == "PUT" && strings.HasPrefix(resource, "issue/"):
+		var update struct {
+			Update struct {
+				Labels []map[string]string `json:"labels"`
+			} `json:"update"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		key := strings.TrimPrefix(resource, "issue/")
+		f.mu.Lock()
+		for i := range f.issues {
+			if f.issues[i].Key == key {
+				for _, op := range update.Update.Labels {
+					f.issues[i].Fields.Labels = append(f.issues[i].Fields.Labels, op["add"])
+				}
+			}
+		}
+		f.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "not found", http.StatusNotFound)
+	}
+}
+
+// create adds an issue with the given request fields, unless its summary is rejected
+func (f *fakeJira) create(fields map[string]any) (domain.JiraCreateResponse, bool) {
+	summary, _ := fields["summary"].(string)
+	if f.rejected != "" && summary == f.rejected {
+		return domain.JiraCreateResponse{}, false
+	}
+	var labels []string
+	if list, ok := fields["labels"].([]any); ok {
+		for _, l := range list {
+			labels = append(labels, fmt.Sprint(l))
+		}
+	}
+	key := f.addIssue(summary, labels...)
+	f.mu.Lock()
+	f.fields[key] = fields
+	f.mu.Unlock()
+	return domain.JiraCreateResponse{ID: strings.TrimPrefix(key, "APP-"), Key: key}, true
+}
+
+// attach records the files of a multipart attachment upload
+func (f *fakeJira) attach(w http.ResponseWriter, r *http.Request, key string) {
+	if r.Header.Get("X-Atlassian-Token") != "no-check" {
+		http.Error(w, "XSRF check failed", http.StatusForbidden)
+		return
+	}
+	if err := r.ParseMultipartForm(1 << 20); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.attachments[key] == nil {
+		f.attachments[key] = make(map[string]string)
+	}
+	for _, header := range r.MultipartForm.File["file"] {
+		file, err := header.Open()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		content, _ := io.ReadAll(file)
+		file.Close()
+		f.attachments[key][header.Filename] = string(content)
+	}
+	w.Write([]byte("[]"))
+}
+
+// search supports the two JQL shapes issued by findDuplicate: a label match,
+// or a summary text search over unresolved issues (every unresolved issue is
+// returned and the client filters by similarity), and the key lookups of
+// IssueStatuses
+func (f *fakeJira) search(w http.ResponseWriter, jql string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var found []domain.JiraIssue
+	label := labelsJQL.FindStringSubmatch(jql)
+	for _, issue := range f.issues {
+		switch {
+		case label != nil:
+			for _, l := range issue.Fields.Labels {
+				if l == label[1] {
+					found = append(found, issue)
+				}
+			}
+		case strings.Contains(jql, "summary ~"):
+			if !f.resolved[issue.Key] {
+				found = append(found, issue)
+			}
+		case strings.HasPrefix(jql, "key in") && strings.Contains(jql, `"`+issue.Key+`"`):
+			status := domain.JiraStatus{Name: "To Do", StatusCategory: domain.JiraStatusCategory{Key: domain.JiraStatusCategoryToDo}}
+			if f.resolved[issue.Key] {
+				status = domain.JiraStatus{Name: "Done", StatusCategory: domain.JiraStatusCategory{Key: domain.JiraStatusCategoryDone}}
+			}
+			issue.Fields.Status = &status
+			found = append(found, issue)
+		}
+	}
+	json.NewEncoder(w).Encode(domain.JiraSearchResponse{Total: len(found), Issues: found})
+}
+
+// TestCreateIssuesDeduplicates tests the created, skipped_duplicate and linked_existing outcomes
+func TestCreateIssuesDeduplicates(t *testing.T) {
+	fake, client := newFakeJira(t)
+	resolvedKey := fake.addIssue("Fix CSV export timeouts for large accounts")
+	fake.resolved[resolvedKey] = true
+	openKey := fake.addIssue("Fix refund processing delays for enterprise customers")
+
+	question := "What are the most common billing issues?"
+	actions := []domain.ActionItem{
+		{Title: "Fix Refund Processing Delays"},
+		{Title: "Audit Invoice Generation"},
+		{Title: "Fix CSV Export Timeouts"},
+	}
+	specs := []domain.JiraTicketSpec{
+		{IssueType: "Story", Summary: "Fix refund processing delays for enterprise customers", Labels: []string{"feedback"}},
+		{IssueType: "Story", Summary: "Audit invoice generation logic", Labels: []string{"feedback"}},
+		{IssueType: "Story", Summary: "Fix CSV export timeouts for large accounts", Labels: []string{"feedback"}},
+	}
+	AssignFingerprints(question, actions, specs)
+
+	first, err := client.CreateIssues(context.Background(), specs)
+	if err != nil {
+		t.Fatalf("CreateIssues() unexpected error: %v", err)
+	}
+	wantFirst := []string{domain.JiraActionLinkedExisting, domain.JiraActionCreated, domain.JiraActionCreated}
+	for i, action := range first.Actions {
+		if action.Status != wantFirst[i] {
+			t.Errorf("first run action %d status = %q, want %q (%+v)", i, action.Status, wantFirst[i], action)
+		}
+	}
+	if first.Actions[0].Key != openKey {
+		t.Errorf("linked key = %q, want %q", first.Actions[0].Key, openKey)
+	}
+	if labels := fake.issue(openKey).Fields.Labels; len(labels) != 1 || labels[0] != specs[0].Fingerprint {
+		t.Errorf("linked issue labels = %v, want the fingerprint", labels)
+	}
+	if len(first.CreatedTickets) != 2 {
+		t.Errorf("created %d tickets, want 2", len(first.CreatedTickets))
+	}
+	created := fake.issue(first.Actions[1].Key)
+	if len(created.Fields.Labels) != 2 || created.Fields.Labels[1] != specs[1].Fingerprint {
+		t.Errorf("created issue labels = %v, want feedback plus fingerprint", created.Fields.Labels)
+	}
+
+	// Re-sending the same insight, even with reworded summaries, files nothing new
+	specs[1].Summary = "Review how invoices are generated"
+	second, err := client.CreateIssues(context.Background(), specs)
+	if err != nil {
+		t.Fatalf("CreateIssues() unexpected error: %v", err)
+	}
+	for i, action := range second.Actions {
+		if action.Status != domain.JiraActionSkippedDuplicate || action.Key != first.Actions[i].Key {
+			t.Errorf("second run action %d = %+v, want skipped_duplicate of %s", i, action, first.Actions[i].Key)
+		}
+	}
+	if len(second.CreatedTickets) != 0 {
+		t.Errorf("second run created %d tickets, want 0", len(second.CreatedTickets))
+	}
+}
+
+// TestCreateIssuesSearchFailure tests that an action fails when the duplicate check fails
+func TestCreateIssuesSearchFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"errorMessages":["bad jql"]}`, http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "pm@example.com", "token", "APP")
+	result, err := client.CreateIssues(context.Background(), []domain.JiraTicketSpec{{Summary: "Fix refunds", Fingerprint: Fingerprint("q", "Fix refunds")}})
+	if err != nil {
+		t.Fatalf("CreateIssues() unexpected error: %v", err)
+	}
+	if result.Actions[0].Status != domain.JiraActionFailed || len(result.Errors) != 1 {
+		t.Errorf("result = %+v, want one failed action", result)
+	}
+}
+
+// TestCreateIssueEpicLink tests how an epic link is sent for next-gen and classic projects
+func TestCreateIssueEpicLink(t *testing.T) {
+	tests := []struct {
+		name      string
+		linkField string
+		wantField string
+	}{
+		{"next-gen uses parent", "", "parent"},
+		{"classic uses custom field", "customfield_10014", "customfield_10014"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, client := newFakeJira(t)
+			client.SetEpicFields(tt.linkField, "")
+
+			epicKey := "APP-99"
+			resp, err := client.CreateIssue(context.Background(), domain.JiraTicketSpec{IssueType: "Story", Summary: "Fix refunds", EpicLink: &epicKey})
+			if err != nil {
+				t.Fatalf("CreateIssue() unexpected error: %v", err)
+			}
+
+			want := domain.JiraHierarchy{Role: domain.JiraRoleChild, EpicKey: epicKey, LinkedBy: tt.wantField}
+			if resp.Hierarchy == nil || *resp.Hierarchy != want {
+				t.Errorf("hierarchy = %+v, want %+v", resp.Hierarchy, want)
+			}
+
+			fields := fake.fields[resp.Key]
+			switch tt.wantField {
+			case "parent":
+				parent, _ := fields["parent"].(map[string]any)
+				if parent["key"] != epicKey {
+					t.Errorf("parent = %v, want key %s", fields["parent"], epicKey)
+				}
+			default:
+				if fields[tt.wantField] != epicKey {
+					t.Errorf("%s = %v, want %s", tt.wantField, fields[tt.wantField], epicKey)
+				}
+				if _, ok := fields["parent"]; ok {
+					t.Error("parent should not be sent when an epic link field is configured")
+				}
+			}
+		})
+	}
+}
+
+// TestCreateIssuesWithEpic tests that actions are filed under a new epic, which is reused on re-send
+func TestCreateIssuesWithEpic(t *testing.T) {
+	fake, client := newFakeJira(t)
+	client.SetEpicFields("customfield_10014", "customfield_10011")
+
+	question := "What are the most common billing issues?"
+	epic := InsightEpic(question, "Refunds are slow.", []string{"Speed up refunds"}, "APP", []string{"feedback"})
+	otherEpic := "APP-50"
+	specs := []domain.JiraTicketSpec{
+		{IssueType: "Story", Summary: "Fix refund processing delays"},
+		{IssueType: "Story", Summary: "Audit invoice generation", EpicLink: &otherEpic},
+	}
+
+	result, err := client.CreateIssuesWithEpic(context.Background(), epic, specs)
+	if err != nil {
+		t.Fatalf("CreateIssuesWithEpic() unexpected error: %v", err)
+	}
+	if result.Epic == nil || result.Epic.Hierarchy == nil || result.Epic.Hierarchy.Role != domain.JiraRoleEpic {
+		t.Fatalf("epic = %+v, want an epic hierarchy", result.Epic)
+	}
+	epicKey := result.Epic.Key
+	if fake.fields[epicKey]["customfield_10011"] != epic.Summary {
+		t.Errorf("epic name = %v, want %q", fake.fields[epicKey]["customfield_10011"], epic.Summary)
+	}
+	if specs[0].EpicLink != nil {
+		t.Error("CreateIssuesWithEpic() modified the caller's specs")
+	}
+
+	wantEpics := []string{epicKey, otherEpic}
+	for i, created := range result.CreatedTickets {
+		if created.Hierarchy == nil || created.Hierarchy.EpicKey != wantEpics[i] {
+			t.Errorf("ticket %d hierarchy = %+v, want child of %s", i, created.Hierarchy, wantEpics[i])
+		}
+	}
+
+	again, err := client.CreateIssuesWithEpic(context.Background(), epic, nil)
+	if err != nil {
+		t.Fatalf("CreateIssuesWithEpic() unexpected error: %v", err)
+	}
+	if again.Epic.Key != epicKey {
+		t.Errorf("re-sent epic key = %s, want reused %s", again.Epic.Key, epicKey)
+	}
+}
+
+// TestFingerprint tests that fingerprints ignore formatting but not content
+func TestFingerprint(t *testing.T) {
+	base := Fingerprint("What are the top billing issues?", "Fix Refund Processing")
+	if !strings.HasPrefix(base, FingerprintPrefix) {
+		t.Errorf("fingerprint %q missing prefix", base)
+	}
+	if got := Fingerprint("what are the top  billing issues", "fix refund processing!"); got != base {
+		t.Errorf("formatting changed the fingerprint: %q != %q", got, base)
+	}
+	if got := Fingerprint("What are the top billing issues?", "Audit invoices"); got == base {
+		t.Error("different actions should have different fingerprints")
+	}
+	if got := Fingerprint("What are the top login issues?", "Fix Refund Processing"); got == base {
+		t.Error("different questions should have different fingerprints")
+	}
+}
+
+// TestSimilarity tests summary similarity scoring
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		min  float64
+		max  float64
+	}{
+		{"Fix refund processing delays", "Fix refund processing delays", 1, 1},
+		{"Fix the refund processing delays", "fix refund-processing delays!", 1, 1},
+		{"Fix refund processing delays", "Fix refund processing delays for enterprise", 0.6, 0.9},
+		{"Fix refund processing delays", "Audit invoice generation", 0, 0},
+		{"", "Audit invoice generation", 0, 0},
+	}
+
+	for _, tt := range tests {
+		if got := Similarity(tt.a, tt.b); got < tt.min || got > tt.max {
+			t.Errorf("Similarity(%q, %q) = %v, want between %v and %v", tt.a, tt.b, got, tt.min, tt.max)
+		}
+	}
+}
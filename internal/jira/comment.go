@@ -0,0 +1,29 @@
+package jira
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/chuckie/goinsight/internal/domain"
+)
+
+// CommentOnIssue adds the spec's Comment to the issue spec.CommentOn,
+// followed by the data preview and a link to the analysis it came from
+func (c *Client) CommentOnIssue(ctx context.Context, spec domain.JiraTicketSpec) error {
+	if spec.CommentOn == "" {
+		return fmt.Errorf("no issue to comment on")
+	}
+
+	text := strings.TrimSpace(spec.Comment)
+	if spec.AnalysisID != "" && c.analysisURL != "" {
+		text += "\n\nAnalysis: " + c.analysisURL + "/api/analyses/" + spec.AnalysisID
+	}
+
+	comment := map[string]any{"body": c.richText(text, spec.DataPreview)}
+	if err := c.do(ctx, "POST", c.apiPath("issue/"+url.PathEscape(spec.CommentOn)+"/comment"), comment, nil); err != nil {
+		return fmt.Errorf("failed to comment on %s: %w", spec.CommentOn, err)
+	}
+	return nil
+}
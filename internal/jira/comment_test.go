@@ -0,0 +1,40 @@
+package jira
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/chuckie/goinsight/internal/domain"
+)
+
+// TestCommentOnIssue tests that a comment carries the new evidence and analysis link
+func TestCommentOnIssue(t *testing.T) {
+	fake, client := newFakeJira(t)
+	client.SetAnalysisURL("https://insights.example.com")
+	key := fake.addIssue("Speed up refunds")
+
+	err := client.CommentOnIssue(context.Background(), domain.JiraTicketSpec{
+		CommentOn:   key,
+		Comment:     "New evidence for this issue from the question: What are the top billing issues?",
+		DataPreview: []map[string]any{{"product_area": "billing", "count": 42}},
+		AnalysisID:  "3f1c2b9e-8d4a-4f5e-9a7b-2c6d1e0f4a31",
+	})
+	if err != nil {
+		t.Fatalf("CommentOnIssue() unexpected error: %v", err)
+	}
+
+	comments := fake.comments[key]
+	if len(comments) != 1 {
+		t.Fatalf("comments = %v, want one", comments)
+	}
+	for _, want := range []string{"What are the top billing issues?", "42", "https://insights.example.com/api/analyses/3f1c2b9e-8d4a-4f5e-9a7b-2c6d1e0f4a31"} {
+		if !strings.Contains(comments[0], want) {
+			t.Errorf("comment = %s, want it to contain %q", comments[0], want)
+		}
+	}
+
+	if err := client.CommentOnIssue(context.Background(), domain.JiraTicketSpec{Comment: "no issue"}); err == nil {
+		t.Error("CommentOnIssue() without an issue key expected an error")
+	}
+}
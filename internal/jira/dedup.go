@@ -0,0 +1,179 @@
+package jira
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode"
+
+	"github.com/chuckie/goinsight/internal/domain"
+)
+
+// DefaultDuplicateThreshold is the summary similarity at which an open issue
+// is treated as the same piece of work
+const DefaultDuplicateThreshold = 0.6
+
+// FingerprintPrefix starts every fingerprint label
+const FingerprintPrefix = "goinsight-fp-"
+
+// maxSearchTerms bounds the words used in a summary text search
+const maxSearchTerms = 8
+
+// stopWords are left out of summary comparisons and text searches
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "the": true, "to": true, "of": true,
+	"for": true, "in": true, "on": true, "with": true, "by": true, "from": true,
+	"is": true, "are": true, "be": true, "or": true, "at": true, "as": true,
+}
+
+// Fingerprint returns the label identifying an action generated for a
+// question. It depends only on their words, so re-sending the same insight
+// produces the same label regardless of case, punctuation or spacing.
+func Fingerprint(question, actionTitle string) string {
+	normalized := strings.Join(words(question), " ") + "\n" + strings.Join(words(actionTitle), " ")
+	sum := sha256.Sum256([]byte(normalized))
+	return FingerprintPrefix + hex.EncodeToString(sum[:])[:16]
+}
+
+// AssignFingerprints sets the Fingerprint and Action of each spec from the
+// action it was generated for. The LLM returns one spec per action in order;
+// if the counts differ the spec's own summary stands in for the action title.
+func AssignFingerprints(question string, actions []domain.ActionItem, specs []domain.JiraTicketSpec) {
+	for i := range specs {
+		title := specs[i].Summary
+		if len(specs) == len(actions) {
+			title = actions[i].Title
+			specs[i].Action = title
+		}
+		specs[i].Fingerprint = Fingerprint(question, title)
+	}
+}
+
+// findDuplicate looks for an issue already filed for spec: first one carrying
+// its fingerprint label, then an unresolved issue with a similar summary,
+// which is tagged with the fingerprint so later requests match it directly
+func (c *Client) findDuplicate(ctx context.Context, spec domain.JiraTicketSpec) (*domain.JiraIssue, string, error) {
+	project := c.project(spec)
+
+	match, err := c.findByFingerprint(ctx, project, spec.Fingerprint)
+	if err != nil || match != nil {
+		return match, domain.JiraActionSkippedDuplicate, err
+	}
+
+	terms := significantWords(spec.Summary)
+	if c.duplicateThreshold <= 0 || len(terms) == 0 {
+		return nil, "", nil
+	}
+	if len(terms) > maxSearchTerms {
+		terms = terms[:maxSearchTerms]
+	}
+
+	jql := fmt.Sprintf("project = %s AND resolution = Unresolved AND summary ~ %s",
+		jqlQuote(project), jqlQuote(strings.Join(terms, " ")))
+	candidates, err := c.search(ctx, jql, 20)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var best *domain.JiraIssue
+	bestScore := c.duplicateThreshold
+	for i := range candidates {
+		if score := Similarity(spec.Summary, candidates[i].Fields.Summary); score >= bestScore {
+			best, bestScore = &candidates[i], score
+		}
+	}
+	if best == nil {
+		return nil, "", nil
+	}
+
+	if err := c.addLabel(ctx, best.Key, spec.Fingerprint); err != nil {
+		return nil, "", fmt.Errorf("failed to link %s: %w", best.Key, err)
+	}
+	return best, domain.JiraActionLinkedExisting, nil
+}
+
+// findByFingerprint returns an issue in project carrying the fingerprint label, if any
+func (c *Client) findByFingerprint(ctx context.Context, project, fingerprint string) (*domain.JiraIssue, error) {
+	matches, err := c.search(ctx, fmt.Sprintf("project = %s AND labels = %s", jqlQuote(project), jqlQuote(fingerprint)), 1)
+	if err != nil || len(matches) == 0 {
+		return nil, err
+	}
+	return &matches[0], nil
+}
+
+// search runs a JQL query and returns up to maxResults issues with their
+// summary and labels
+func (c *Client) search(ctx context.Context, jql string, maxResults int) ([]domain.JiraIssue, error) {
+	params := url.Values{}
+	params.Set("jql", jql)
+	params.Set("fields", "summary,labels")
+	params.Set("maxResults", fmt.Sprint(maxResults))
+
+	var resp domain.JiraSearchResponse
+	if err := c.do(ctx, "GET", c.apiPath("search")+"?"+params.Encode(), nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Issues, nil
+}
+
+// addLabel adds a label to an existing issue
+func (c *Client) addLabel(ctx context.Context, issueKey, label string) error {
+	update := map[string]any{
+		"update": map[string]any{
+			"labels": []map[string]string{{"add": label}},
+		},
+	}
+	return c.do(ctx, "PUT", c.apiPath("issue/"+url.PathEscape(issueKey)), update, nil)
+}
+
+// Similarity returns the Jaccard similarity (0-1) of the significant words of two summaries
+func Similarity(a, b string) float64 {
+	setA := make(map[string]bool)
+	for _, w := range significantWords(a) {
+		setA[w] = true
+	}
+	setB := make(map[string]bool)
+	for _, w := range significantWords(b) {
+		setB[w] = true
+	}
+	if len(setA) == 0 || len(setB) == 0 {
+		return 0
+	}
+
+	shared := 0
+	for w := range setA {
+		if setB[w] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(setA)+len(setB)-shared)
+}
+
+// words splits s into lowercase letter/digit runs
+func words(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// significantWords returns the words of s without stop words or duplicates
+func significantWords(s string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, w := range words(s) {
+		if stopWords[w] || seen[w] {
+			continue
+		}
+		seen[w] = true
+		result = append(result, w)
+	}
+	return result
+}
+
+// jqlQuote quotes a value for use in a JQL query
+func jqlQuote(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
@@ -0,0 +1,146 @@
+package jira
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/chuckie/goinsight/internal/domain"
+	"github.com/chuckie/goinsight/internal/textutil"
+)
+
+// EpicIssueType is the issue type used for insight epics
+const EpicIssueType = "Epic"
+
+// maxEpicSummary bounds the length of a generated epic summary
+const maxEpicSummary = 120
+
+// parentLink is JiraHierarchy.LinkedBy for issues linked through the parent field
+const parentLink = "parent"
+
+// SetEpicFields configures epics for classic (company-managed) projects, which
+// link issues to an epic through a custom field (often customfield_10014) and
+// require an Epic Name (often customfield_10011). With no link field, issues
+// are linked through the parent field used by next-gen (team-managed) projects.
+func (c *Client) SetEpicFields(linkField, nameField string) {
+	c.epicLinkField = linkField
+	c.epicNameField = nameField
+}
+
+// InsightEpic returns the spec of the epic grouping the tickets for an
+// insight. It is fingerprinted by question (or summary) so that re-sending the
+// insight reuses the epic.
+func InsightEpic(question, summary string, recommendations []string, projectKey string, labels []string) domain.JiraTicketSpec {
+	title := strings.TrimSpace(question)
+	if title == "" {
+		title = strings.TrimSpace(summary)
+	}
+	epicSummary := "Customer feedback insight: " + textutil.OneLine(title)
+	if runes := []rune(epicSummary); len(runes) > maxEpicSummary {
+		epicSummary = string(runes[:maxEpicSummary-3]) + "..."
+	}
+
+	var description strings.Builder
+	description.WriteString(summary)
+	if len(recommendations) > 0 {
+		description.WriteString("\n\n### Recommendations\n")
+		for _, rec := range recommendations {
+			description.WriteString("- " + rec + "\n")
+		}
+	}
+
+	return domain.JiraTicketSpec{
+		ProjectKey:  projectKey,
+		IssueType:   EpicIssueType,
+		Summary:     epicSummary,
+		Description: strings.TrimSpace(description.String()),
+		Labels:      labels,
+		Fingerprint: Fingerprint(title, EpicIssueType),
+	}
+}
+
+// CreateIssuesWithEpic creates epic and then specs as its children. Specs that
+// already have an EpicLink keep it. An epic whose fingerprint label is already
+// in the project is reused instead of created again.
+func (c *Client) CreateIssuesWithEpic(ctx context.Context, epic domain.JiraTicketSpec, specs []domain.JiraTicketSpec) (*domain.JiraCreationResult, error) {
+	epic.IssueType = EpicIssueType
+
+	epicResp, err := c.createEpic(ctx, epic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create epic: %w", err)
+	}
+
+	children := make([]domain.JiraTicketSpec, len(specs))
+	copy(children, specs)
+	for i := range children {
+		if epicKey(children[i]) == "" {
+			key := epicResp.Key
+			children[i].EpicLink = &key
+		}
+	}
+
+	result, err := c.CreateIssues(ctx, children)
+	if err != nil {
+		return nil, err
+	}
+	result.Epic = epicResp
+	return result, nil
+}
+
+// createEpic creates the epic, or returns the existing one with its fingerprint
+func (c *Client) createEpic(ctx context.Context, epic domain.JiraTicketSpec) (*domain.JiraCreateResponse, error) {
+	if epic.Fingerprint != "" {
+		existing, err := c.findByFingerprint(ctx, c.project(epic), epic.Fingerprint)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check for an existing epic: %w", err)
+		}
+		if existing != nil {
+			return &domain.JiraCreateResponse{
+				ID:        existing.ID,
+				Key:       existing.Key,
+				Self:      existing.Self,
+				Hierarchy: &domain.JiraHierarchy{Role: domain.JiraRoleEpic, EpicKey: existing.Key},
+			}, nil
+		}
+		epic.Labels = append(append([]string(nil), epic.Labels...), epic.Fingerprint)
+	}
+	return c.CreateIssue(ctx, epic)
+}
+
+// applyHierarchy sets the fields placing spec in an epic hierarchy and
+// returns the hierarchy to report, or nil if spec is not part of one
+func (c *Client) applyHierarchy(spec domain.JiraTicketSpec, fields *domain.JiraIssueFields) *domain.JiraHierarchy {
+	if strings.EqualFold(spec.IssueType, EpicIssueType) {
+		if c.epicNameField != "" {
+			setCustomField(fields, c.epicNameField, spec.Summary)
+		}
+		return &domain.JiraHierarchy{Role: domain.JiraRoleEpic}
+	}
+
+	key := epicKey(spec)
+	if key == "" {
+		return nil
+	}
+	if c.epicLinkField != "" {
+		setCustomField(fields, c.epicLinkField, key)
+		return &domain.JiraHierarchy{Role: domain.JiraRoleChild, EpicKey: key, LinkedBy: c.epicLinkField}
+	}
+	fields.Parent = &domain.JiraParent{Key: key}
+	return &domain.JiraHierarchy{Role: domain.JiraRoleChild, EpicKey: key, LinkedBy: parentLink}
+}
+
+// epicKey returns the spec's trimmed EpicLink, or "" if it has none
+func epicKey(spec domain.JiraTicketSpec) string {
+	if spec.EpicLink == nil {
+		return ""
+	}
+	return strings.TrimSpace(*spec.EpicLink)
+}
+
+// setCustomField sets a customfield_* value on fields
+func setCustomField(fields *domain.JiraIssueFields, id string, value any) {
+	if fields.CustomFields == nil {
+		fields.CustomFields = make(map[string]any)
+	}
+	fields.CustomFields[id] = value
+}
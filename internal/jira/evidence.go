@@ -0,0 +1,105 @@
+package jira
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/chuckie/goinsight/internal/domain"
+)
+
+// Names of the evidence files attached to created issues
+const (
+	DataAttachmentName = "supporting-data.csv"
+	SQLAttachmentName  = "query.sql"
+)
+
+// SetAnalysisURL sets the public base URL of the API (e.g.
+// https://insights.example.com). Issues filed from a stored analysis get a
+// link to it under /api/analyses/{id}; with no URL they get none.
+func (c *Client) SetAnalysisURL(baseURL string) {
+	c.analysisURL = strings.TrimSuffix(baseURL, "/")
+}
+
+// attachEvidence uploads the spec's data preview as CSV and its SQL to the
+// issue, and links the issue to the analysis it was filed from
+func (c *Client) attachEvidence(ctx context.Context, key string, spec domain.JiraTicketSpec) error {
+	if len(spec.DataPreview) > 0 {
+		data, err := evidenceCSV(spec.DataPreview)
+		if err != nil {
+			return err
+		}
+		if err := c.uploadAttachment(ctx, key, DataAttachmentName, data); err != nil {
+			return err
+		}
+	}
+
+	if strings.TrimSpace(spec.SQL) != "" {
+		if err := c.uploadAttachment(ctx, key, SQLAttachmentName, []byte(strings.TrimSpace(spec.SQL)+"\n")); err != nil {
+			return err
+		}
+	}
+
+	if spec.AnalysisID != "" && c.analysisURL != "" {
+		link := map[string]any{
+			"globalId": "goinsight-analysis-" + spec.AnalysisID,
+			"object": map[string]any{
+				"url":   c.analysisURL + "/api/analyses/" + spec.AnalysisID,
+				"title": "goinsight analysis " + spec.AnalysisID,
+			},
+		}
+		if err := c.do(ctx, "POST", c.apiPath("issue/"+key+"/remotelink"), link, nil); err != nil {
+			return fmt.Errorf("failed to link analysis: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// uploadAttachment attaches a file to an issue
+func (c *Client) uploadAttachment(ctx context.Context, key, filename string, content []byte) error {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", filename)
+	if err != nil {
+		return fmt.Errorf("failed to create attachment: %w", err)
+	}
+	if _, err := part.Write(content); err != nil {
+		return fmt.Errorf("failed to create attachment: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to create attachment: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+c.apiPath("issue/"+key+"/attachments"), &body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	// Jira rejects multipart requests without this XSRF opt-out
+	req.Header.Set("X-Atlassian-Token", "no-check")
+
+	if err := c.send(req, nil); err != nil {
+		return fmt.Errorf("failed to attach %s: %w", filename, err)
+	}
+	return nil
+}
+
+// evidenceCSV renders every row of a data preview as CSV, columns in name order
+func evidenceCSV(preview []map[string]any) ([]byte, error) {
+	header, rows := previewTable(preview, 0)
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write(header); err != nil {
+		return nil, fmt.Errorf("failed to write CSV: %w", err)
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return nil, fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return buf.Bytes(), nil
+}
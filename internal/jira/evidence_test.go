@@ -0,0 +1,63 @@
+package jira
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/chuckie/goinsight/internal/domain"
+)
+
+// TestCreateIssuesAttachesEvidence tests that the data preview, SQL and analysis link reach created issues
+func TestCreateIssuesAttachesEvidence(t *testing.T) {
+	fake, client := newFakeJira(t)
+	client.SetAnalysisURL("https://insights.example.com/")
+
+	spec := domain.JiraTicketSpec{
+		IssueType: "Story",
+		Summary:   "Fix refund delays",
+		DataPreview: []map[string]any{
+			{"product_area": "billing", "count": 12, "note": "slow, then failed"},
+			{"product_area": "checkout", "count": 4},
+		},
+		SQL:        "SELECT product_area, COUNT(*) FROM feedback_enriched GROUP BY 1",
+		AnalysisID: "3f1c2b9e-8d4a-4f5e-9a7b-2c6d1e0f4a31",
+	}
+	result, err := client.CreateIssues(context.Background(), []domain.JiraTicketSpec{spec})
+	if err != nil {
+		t.Fatalf("CreateIssues() unexpected error: %v", err)
+	}
+	action := result.Actions[0]
+	if action.Status != domain.JiraActionCreated || action.Error != "" {
+		t.Fatalf("action = %+v, want created without error", action)
+	}
+
+	files := fake.attachments[action.Key]
+	wantCSV := "count,note,product_area\n12,\"slow, then failed\",billing\n4,,checkout\n"
+	if files[DataAttachmentName] != wantCSV {
+		t.Errorf("%s = %q, want %q", DataAttachmentName, files[DataAttachmentName], wantCSV)
+	}
+	if strings.TrimSpace(files[SQLAttachmentName]) != spec.SQL {
+		t.Errorf("%s = %q, want the SQL", SQLAttachmentName, files[SQLAttachmentName])
+	}
+
+	wantLink := "https://insights.example.com/api/analyses/" + spec.AnalysisID
+	if links := fake.links[action.Key]; len(links) != 1 || links[0] != wantLink {
+		t.Errorf("links = %v, want [%s]", links, wantLink)
+	}
+}
+
+// TestCreateIssuesWithoutEvidence tests that issues without evidence make no extra requests
+func TestCreateIssuesWithoutEvidence(t *testing.T) {
+	fake, client := newFakeJira(t)
+
+	// Without an analysis URL the analysis is not linked
+	result, err := client.CreateIssues(context.Background(), []domain.JiraTicketSpec{{IssueType: "Story", Summary: "Fix refunds", AnalysisID: "3f1c2b9e-8d4a-4f5e-9a7b-2c6d1e0f4a31"}})
+	if err != nil {
+		t.Fatalf("CreateIssues() unexpected error: %v", err)
+	}
+	key := result.Actions[0].Key
+	if len(fake.attachments[key]) != 0 || len(fake.links[key]) != 0 {
+		t.Errorf("attachments = %v, links = %v, want none", fake.attachments[key], fake.links[key])
+	}
+}
@@ -0,0 +1,228 @@
+package jira
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/chuckie/goinsight/internal/domain"
+)
+
+// Formats of a mapped custom field value
+const (
+	FieldText    = "text"    // the value as a string (the default)
+	FieldNumber  = "number"  // the value parsed as a number
+	FieldOption  = "option"  // a select list: {"value": ...}
+	FieldOptions = "options" // a multi-select list: [{"value": ...}]
+)
+
+// FieldMapping turns ticket attributes into Jira custom fields and an
+// assignee. It is loaded from a JSON file such as:
+//
+//	{
+//	  "fields": {
+//	    "team": {"id": "customfield_10001", "type": "option"},
+//	    "customer_impact": {"id": "customfield_10002", "type": "option"},
+//	    "customer_tier": {"id": "customfield_10003", "type": "options"}
+//	  },
+//	  "routes": {
+//	    "billing": {"assignee": "5b10ac8d82e05b22cc7d4ef5", "attributes": {"team": "Payments Team"}}
+//	  },
+//	  "default_route": {"attributes": {"team": "Product"}}
+//	}
+type FieldMapping struct {
+	// Fields maps attribute names to custom fields
+	Fields map[string]CustomField `json:"fields"`
+
+	// Routes are keyed by product area (case-insensitive)
+	Routes map[string]Route `json:"routes"`
+
+	// DefaultRoute applies to tickets whose product area has no route
+	DefaultRoute *Route `json:"default_route,omitempty"`
+}
+
+// CustomField is the Jira custom field an attribute is written to
+type CustomField struct {
+	ID   string `json:"id"`   // e.g. customfield_10001
+	Type string `json:"type"` // FieldText, FieldNumber, FieldOption or FieldOptions
+}
+
+// Route assigns the tickets of a product area and adds attributes for them,
+// e.g. the owning team
+type Route struct {
+	Assignee   string            `json:"assignee,omitempty"` // Jira account ID
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// LoadFieldMapping reads and validates a field mapping file
+func LoadFieldMapping(path string) (*FieldMapping, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read field mapping: %w", err)
+	}
+
+	var mapping FieldMapping
+	if err := json.Unmarshal(data, &mapping); err != nil {
+		return nil, fmt.Errorf("failed to parse field mapping: %w", err)
+	}
+	if err := mapping.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Routes match product areas case-insensitively
+	routes := make(map[string]Route, len(mapping.Routes))
+	for area, route := range mapping.Routes {
+		routes[strings.ToLower(strings.TrimSpace(area))] = route
+	}
+	mapping.Routes = routes
+
+	return &mapping, nil
+}
+
+// Validate checks that every field has an ID and a known type
+func (m *FieldMapping) Validate() error {
+	for attr, field := range m.Fields {
+		if !strings.HasPrefix(field.ID, "customfield_") {
+			return fmt.Errorf("field mapping for %q must have a customfield_* id", attr)
+		}
+		switch field.Type {
+		case "", FieldText, FieldNumber, FieldOption, FieldOptions:
+		default:
+			return fmt.Errorf("field mapping for %q has unknown type %q", attr, field.Type)
+		}
+	}
+	return nil
+}
+
+// SetFieldMapping configures how ticket attributes are mapped to custom
+// fields and assignees (nil disables the mapping)
+func (c *Client) SetFieldMapping(mapping *FieldMapping) {
+	c.fieldMapping = mapping
+}
+
+// applyFieldMapping sets the custom fields and assignee for the spec's
+// attributes, after adding those of its product area's route
+func (c *Client) applyFieldMapping(spec domain.JiraTicketSpec, fields *domain.JiraIssueFields) error {
+	if c.fieldMapping == nil {
+		return nil
+	}
+
+	attrs := make(map[string]string, len(spec.Attributes))
+	for name, value := range spec.Attributes {
+		attrs[name] = value
+	}
+
+	if route := c.fieldMapping.route(attrs[domain.JiraAttrProductArea]); route != nil {
+		// The spec's own attributes win over the route's defaults
+		for name, value := range route.Attributes {
+			if _, ok := attrs[name]; !ok {
+				attrs[name] = value
+			}
+		}
+		if route.Assignee != "" {
+			fields.Assignee = &domain.JiraUser{AccountID: route.Assignee}
+		}
+	}
+
+	for name, value := range attrs {
+		field, ok := c.fieldMapping.Fields[name]
+		if !ok || strings.TrimSpace(value) == "" {
+			continue
+		}
+		fieldValue, err := field.value(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s attribute: %w", name, err)
+		}
+		setCustomField(fields, field.ID, fieldValue)
+	}
+	return nil
+}
+
+// route returns the route for a product area, or the default route
+func (m *FieldMapping) route(productArea string) *Route {
+	if route, ok := m.Routes[strings.ToLower(strings.TrimSpace(productArea))]; ok {
+		return &route
+	}
+	return m.DefaultRoute
+}
+
+// value formats an attribute value for the custom field's type
+func (f CustomField) value(value string) (any, error) {
+	switch f.Type {
+	case FieldNumber:
+		return strconv.ParseFloat(strings.TrimSpace(value), 64)
+	case FieldOption:
+		return map[string]string{"value": value}, nil
+	case FieldOptions:
+		return []map[string]string{{"value": value}}, nil
+	default:
+		return value, nil
+	}
+}
+
+// InsightAttributes returns the attributes shared by every ticket of an
+// insight: product_area and customer_tier when all preview rows agree on
+// them, overridden by the attributes given with the request
+func InsightAttributes(given map[string]string, preview []map[string]any) map[string]string {
+	attrs := make(map[string]string)
+	for _, column := range []string{domain.JiraAttrProductArea, domain.JiraAttrCustomerTier} {
+		if value, ok := commonValue(preview, column); ok {
+			attrs[column] = value
+		}
+	}
+	for name, value := range given {
+		attrs[name] = value
+	}
+	return attrs
+}
+
+// AssignAttributes sets the Attributes of each spec to the insight's, plus
+// the customer impact of the action it was generated for. Like
+// AssignFingerprints, it pairs specs and actions only if their counts match.
+func AssignAttributes(attrs map[string]string, actions []domain.ActionItem, specs []domain.JiraTicketSpec) {
+	for i := range specs {
+		specAttrs := make(map[string]string, len(attrs)+1)
+		for name, value := range attrs {
+			specAttrs[name] = value
+		}
+		if _, ok := specAttrs[domain.JiraAttrCustomerImpact]; !ok && len(specs) == len(actions) {
+			specAttrs[domain.JiraAttrCustomerImpact] = CustomerImpact(actions[i].Magnitude)
+		}
+		if len(specAttrs) > 0 {
+			specs[i].Attributes = specAttrs
+		}
+	}
+}
+
+// CustomerImpact labels an action's magnitude, using the same bands as the
+// priorities the LLM assigns
+func CustomerImpact(magnitude float64) string {
+	switch {
+	case magnitude >= 8.0:
+		return "Critical"
+	case magnitude >= 6.5:
+		return "High"
+	case magnitude >= 4.0:
+		return "Medium"
+	default:
+		return "Low"
+	}
+}
+
+// commonValue returns the value a column has in every preview row, if any
+func commonValue(preview []map[string]any, column string) (string, bool) {
+	common := ""
+	for i, row := range preview {
+		value, ok := row[column]
+		if !ok || value == nil {
+			return "", false
+		}
+		if i > 0 && fmt.Sprint(value) != common {
+			return "", false
+		}
+		common = fmt.Sprint(value)
+	}
+	return common, common != ""
+}
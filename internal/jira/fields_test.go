@@ -0,0 +1,181 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/chuckie/goinsight/internal/domain"
+)
+
+// testMapping is a field mapping routing billing to the payments team
+const testMapping = `{
+  "fields": {
+    "team": {"id": "customfield_10001", "type": "option"},
+    "customer_impact": {"id": "customfield_10002"},
+    "customer_tier": {"id": "customfield_10003", "type": "options"},
+    "arr": {"id": "customfield_10004", "type": "number"}
+  },
+  "routes": {
+    "Billing": {"assignee": "acct-payments", "attributes": {"team": "Payments Team"}}
+  },
+  "default_route": {"attributes": {"team": "Product"}}
+}`
+
+// loadTestMapping writes content to a temporary file and loads it
+func loadTestMapping(t *testing.T, content string) (*FieldMapping, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "mapping.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write mapping: %v", err)
+	}
+	return LoadFieldMapping(path)
+}
+
+// TestCreateIssueFieldMapping tests that attributes become custom fields and route the assignee
+func TestCreateIssueFieldMapping(t *testing.T) {
+	mapping, err := loadTestMapping(t, testMapping)
+	if err != nil {
+		t.Fatalf("LoadFieldMapping() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		attributes   map[string]string
+		wantFields   map[string]any
+		wantAssignee string
+	}{
+		{
+			name:       "billing is routed to payments",
+			attributes: map[string]string{"product_area": "billing", "customer_tier": "enterprise", "customer_impact": "High"},
+			wantFields: map[string]any{
+				"customfield_10001": map[string]any{"value": "Payments Team"},
+				"customfield_10002": "High",
+				"customfield_10003": []any{map[string]any{"value": "enterprise"}},
+			},
+			wantAssignee: "acct-payments",
+		},
+		{
+			name:       "spec attributes override the route",
+			attributes: map[string]string{"product_area": "billing", "team": "Platform", "arr": "12000"},
+			wantFields: map[string]any{
+				"customfield_10001": map[string]any{"value": "Platform"},
+				"customfield_10004": float64(12000),
+			},
+			wantAssignee: "acct-payments",
+		},
+		{
+			name:       "unrouted area uses the default route",
+			attributes: map[string]string{"product_area": "search"},
+			wantFields: map[string]any{
+				"customfield_10001": map[string]any{"value": "Product"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, client := newFakeJira(t)
+			client.SetFieldMapping(mapping)
+
+			resp, err := client.CreateIssue(context.Background(), domain.JiraTicketSpec{IssueType: "Story", Summary: "Fix refunds", Attributes: tt.attributes})
+			if err != nil {
+				t.Fatalf("CreateIssue() unexpected error: %v", err)
+			}
+
+			fields := fake.fields[resp.Key]
+			for id, want := range tt.wantFields {
+				if !reflect.DeepEqual(fields[id], want) {
+					t.Errorf("%s = %#v, want %#v", id, fields[id], want)
+				}
+			}
+
+			assignee, _ := fields["assignee"].(map[string]any)
+			if tt.wantAssignee == "" && assignee != nil {
+				t.Errorf("assignee = %v, want none", assignee)
+			}
+			if tt.wantAssignee != "" && assignee["accountId"] != tt.wantAssignee {
+				t.Errorf("assignee = %v, want accountId %s", assignee, tt.wantAssignee)
+			}
+		})
+	}
+}
+
+// TestCreateIssueFieldMappingInvalidNumber tests that a non-numeric value for a number field fails the issue
+func TestCreateIssueFieldMappingInvalidNumber(t *testing.T) {
+	mapping, err := loadTestMapping(t, testMapping)
+	if err != nil {
+		t.Fatalf("LoadFieldMapping() unexpected error: %v", err)
+	}
+	fake, client := newFakeJira(t)
+	client.SetFieldMapping(mapping)
+
+	if _, err := client.CreateIssue(context.Background(), domain.JiraTicketSpec{Summary: "Fix refunds", Attributes: map[string]string{"arr": "lots"}}); err == nil {
+		t.Fatal("CreateIssue() expected an error")
+	}
+	if len(fake.requests) != 0 {
+		t.Errorf("requests = %v, want none", fake.requests)
+	}
+}
+
+// TestLoadFieldMappingInvalid tests that malformed mappings are rejected
+func TestLoadFieldMappingInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"not json", `fields: team`},
+		{"missing id", `{"fields": {"team": {"type": "option"}}}`},
+		{"unknown type", `{"fields": {"team": {"id": "customfield_1", "type": "user"}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := loadTestMapping(t, tt.content); err == nil {
+				t.Error("LoadFieldMapping() expected an error")
+			}
+		})
+	}
+}
+
+// TestAssignAttributes tests the attributes derived from the request, data preview and actions
+func TestAssignAttributes(t *testing.T) {
+	preview := []map[string]any{
+		{"product_area": "billing", "customer_tier": "enterprise", "count": 12},
+		{"product_area": "billing", "customer_tier": "smb", "count": 4},
+	}
+	attrs := InsightAttributes(map[string]string{"region": "EU"}, preview)
+	want := map[string]string{"product_area": "billing", "region": "EU"}
+	if !reflect.DeepEqual(attrs, want) {
+		t.Fatalf("InsightAttributes() = %v, want %v", attrs, want)
+	}
+
+	actions := []domain.ActionItem{{Title: "Fix refunds", Magnitude: 8.5}, {Title: "Update docs", Magnitude: 3}}
+	specs := []domain.JiraTicketSpec{{Summary: "Fix refunds"}, {Summary: "Update docs"}}
+	AssignAttributes(attrs, actions, specs)
+
+	for i, wantImpact := range []string{"Critical", "Low"} {
+		if got := specs[i].Attributes[domain.JiraAttrCustomerImpact]; got != wantImpact {
+			t.Errorf("spec %d customer_impact = %q, want %q", i, got, wantImpact)
+		}
+		if specs[i].Attributes[domain.JiraAttrProductArea] != "billing" {
+			t.Errorf("spec %d product_area = %q, want billing", i, specs[i].Attributes[domain.JiraAttrProductArea])
+		}
+	}
+
+	// Specs keep their own copy of the attributes
+	specs[0].Attributes["team"] = "Payments"
+	if _, ok := specs[1].Attributes["team"]; ok {
+		t.Error("specs should not share attribute maps")
+	}
+
+	// Attributes survive a draft round trip
+	data, _ := json.Marshal(specs[0])
+	var decoded domain.JiraTicketSpec
+	if err := json.Unmarshal(data, &decoded); err != nil || !reflect.DeepEqual(decoded.Attributes, specs[0].Attributes) {
+		t.Errorf("decoded attributes = %v, want %v", decoded.Attributes, specs[0].Attributes)
+	}
+}
@@ -0,0 +1,106 @@
+package jira
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultMaxRetries is how many times a rate-limited request is retried
+const DefaultMaxRetries = 3
+
+// Rate limit backoff bounds
+const (
+	retryBaseDelay = time.Second // wait before the first retry when Jira gives no Retry-After
+	maxRetryWait   = time.Minute // longest wait; a request asked to wait longer fails
+)
+
+// APIError is a non-2xx response from the Jira API
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("jira API returned status %d: %s", e.StatusCode, e.Body)
+}
+
+// SetMaxRetries sets how many times a rate-limited request is retried (0
+// disables retrying)
+func (c *Client) SetMaxRetries(retries int) {
+	c.maxRetries = retries
+}
+
+// rateLimitWait reports whether resp is a rate limit, and how long to wait
+// before retrying: Jira's Retry-After, or else an exponential backoff. A 503
+// only counts when it carries Retry-After, as otherwise the request may have
+// been processed and a retry could create the issue twice.
+func rateLimitWait(resp *http.Response, attempt int) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+	if wait, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+		return wait, true
+	}
+	if resp.StatusCode == http.StatusServiceUnavailable {
+		return 0, false
+	}
+	return retryBaseDelay << attempt, true
+}
+
+// retryAfter parses a Retry-After value: seconds or an HTTP date
+func retryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// pause holds back every request of the client for wait
+func (c *Client) pause(wait time.Duration) {
+	c.pauseMu.Lock()
+	defer c.pauseMu.Unlock()
+	if until := time.Now().Add(wait); until.After(c.pauseUntil) {
+		c.pauseUntil = until
+	}
+}
+
+// waitForRateLimit blocks until the client's rate limit pause is over or ctx is done
+func (c *Client) waitForRateLimit(ctx context.Context) error {
+	c.pauseMu.Lock()
+	wait := time.Until(c.pauseUntil)
+	c.pauseMu.Unlock()
+
+	if wait <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("rate limit wait interrupted: %w", ctx.Err())
+	case <-timer.C:
+		return nil
+	}
+}
+
+// cloneRequest returns a copy of req with a fresh body, to be sent again
+func cloneRequest(req *http.Request) (*http.Request, error) {
+	next := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("failed to reset request body: %w", err)
+		}
+		next.Body = body
+	}
+	return next, nil
+}
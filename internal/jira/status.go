@@ -0,0 +1,45 @@
+package jira
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/chuckie/goinsight/internal/domain"
+)
+
+// maxStatusKeys bounds the issue keys looked up per search request
+const maxStatusKeys = 50
+
+// IssueStatuses returns the current status of each issue, keyed by issue
+// key. Issues that no longer exist (or are not visible) are left out.
+func (c *Client) IssueStatuses(ctx context.Context, keys []string) (map[string]domain.JiraStatus, error) {
+	statuses := make(map[string]domain.JiraStatus, len(keys))
+	for start := 0; start < len(keys); start += maxStatusKeys {
+		chunk := keys[start:min(start+maxStatusKeys, len(keys))]
+
+		quoted := make([]string, len(chunk))
+		for i, key := range chunk {
+			quoted[i] = jqlQuote(key)
+		}
+		// Keys of deleted issues only produce warnings instead of failing the search
+		params := url.Values{}
+		params.Set("jql", fmt.Sprintf("key in (%s)", strings.Join(quoted, ", ")))
+		params.Set("fields", "status")
+		params.Set("maxResults", fmt.Sprint(len(chunk)))
+		params.Set("validateQuery", "warn")
+
+		var resp domain.JiraSearchResponse
+		if err := c.do(ctx, "GET", c.apiPath("search")+"?"+params.Encode(), nil, &resp); err != nil {
+			return nil, fmt.Errorf("failed to get issue statuses: %w", err)
+		}
+
+		for _, issue := range resp.Issues {
+			if issue.Fields.Status != nil {
+				statuses[issue.Key] = *issue.Fields.Status
+			}
+		}
+	}
+	return statuses, nil
+}
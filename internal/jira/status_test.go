@@ -0,0 +1,40 @@
+package jira
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/chuckie/goinsight/internal/domain"
+)
+
+// TestIssueStatuses tests that statuses are looked up in chunks and missing issues are left out
+func TestIssueStatuses(t *testing.T) {
+	fake, client := newFakeJira(t)
+	var keys []string
+	for i := 0; i < maxStatusKeys+5; i++ {
+		keys = append(keys, fake.addIssue(fmt.Sprintf("Issue %d", i)))
+	}
+	fake.resolved[keys[0]] = true
+	keys = append(keys, "APP-999") // deleted issue
+
+	statuses, err := client.IssueStatuses(context.Background(), keys)
+	if err != nil {
+		t.Fatalf("IssueStatuses() unexpected error: %v", err)
+	}
+	if len(statuses) != maxStatusKeys+5 {
+		t.Errorf("got %d statuses, want %d", len(statuses), maxStatusKeys+5)
+	}
+	if got := statuses[keys[0]].StatusCategory.Key; got != domain.JiraStatusCategoryDone {
+		t.Errorf("%s status category = %q, want done", keys[0], got)
+	}
+	if got := statuses[keys[1]].Name; got != "To Do" {
+		t.Errorf("%s status = %q, want To Do", keys[1], got)
+	}
+	if _, ok := statuses["APP-999"]; ok {
+		t.Error("deleted issue should have no status")
+	}
+	if len(fake.requests) != 2 {
+		t.Errorf("requests = %v, want 2 searches", fake.requests)
+	}
+}
@@ -0,0 +1,81 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/chuckie/goinsight/internal/textutil"
+)
+
+// Turn is an earlier question in a conversation, with the SQL that answered
+// it and the insight summary
+type Turn struct {
+	Question string
+	SQL      string
+	Summary  string
+}
+
+// historyKey is the context key for conversation history
+type historyKey struct{}
+
+// WithHistory returns a context carrying the earlier turns of a conversation,
+// oldest first. GenerateSQL and GenerateInsight include them in their prompts
+// so that a follow-up question refines the previous query instead of being
+// answered on its own.
+func WithHistory(ctx context.Context, history []Turn) context.Context {
+	if len(history) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, historyKey{}, history)
+}
+
+// History returns the conversation history carried by ctx, if any
+func History(ctx context.Context) []Turn {
+	history, _ := ctx.Value(historyKey{}).([]Turn)
+	return history
+}
+
+// sqlFollowUpPrompt returns the part of the SQL generation prompt describing
+// the conversation so far, or "" when there is no history
+func sqlFollowUpPrompt(history []Turn) string {
+	if len(history) == 0 {
+		return ""
+	}
+	return `
+
+CONVERSATION SO FAR (oldest first):
+` + formatTurns(history) + `
+The next question is a follow-up in this conversation. Unless it clearly asks
+about something new, build on the most recent query: keep its tables, filters
+and grouping and apply the change the user asks for (for example "now only for
+enterprise" adds a filter, "break that down by region" adds a grouping).`
+}
+
+// insightFollowUpPrompt returns the part of the insight prompt describing the
+// conversation so far, or "" when there is no history
+func insightFollowUpPrompt(history []Turn) string {
+	if len(history) == 0 {
+		return ""
+	}
+	return `
+This question follows up on earlier questions in the same conversation (oldest first):
+` + formatTurns(history) + `
+Relate your findings to the earlier results where it helps, e.g. how this subset compares.
+`
+}
+
+// formatTurns renders turns as numbered question, SQL and summary lines
+func formatTurns(history []Turn) string {
+	var b strings.Builder
+	for i, turn := range history {
+		fmt.Fprintf(&b, "\n%d. Question: %s\n", i+1, turn.Question)
+		if turn.SQL != "" {
+			fmt.Fprintf(&b, "   SQL: %s\n", textutil.OneLine(turn.SQL))
+		}
+		if turn.Summary != "" {
+			fmt.Fprintf(&b, "   Result: %s\n", textutil.OneLine(turn.Summary))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,64 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestGenerateSQLIncludesHistory tests that conversation history reaches the SQL prompt
+func TestGenerateSQLIncludesHistory(t *testing.T) {
+	var systemPrompt string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req openAIRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		systemPrompt = req.Messages[0].Content
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"SELECT 1"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient("test-key", "gpt-test")
+	client.baseURL = server.URL
+
+	if _, err := client.GenerateSQL(context.Background(), "question"); err != nil {
+		t.Fatalf("GenerateSQL() unexpected error: %v", err)
+	}
+	if strings.Contains(systemPrompt, "CONVERSATION SO FAR") {
+		t.Error("prompt without history should not describe a conversation")
+	}
+
+	ctx := WithHistory(context.Background(), []Turn{{
+		Question: "What are the top billing issues?",
+		SQL:      "SELECT topic\nFROM feedback_enriched",
+		Summary:  "Refunds lead.",
+	}})
+	if _, err := client.GenerateSQL(ctx, "now only for enterprise"); err != nil {
+		t.Fatalf("GenerateSQL() unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"CONVERSATION SO FAR",
+		"1. Question: What are the top billing issues?",
+		"SQL: SELECT topic FROM feedback_enriched",
+		"Result: Refunds lead.",
+	} {
+		if !strings.Contains(systemPrompt, want) {
+			t.Errorf("prompt missing %q", want)
+		}
+	}
+}
+
+// TestInsightGenerationPromptHistory tests the follow-up section of the insight prompt
+func TestInsightGenerationPromptHistory(t *testing.T) {
+	if prompt := InsightGenerationPrompt("q", nil, nil); strings.Contains(prompt, "follows up") {
+		t.Error("prompt without history should not mention a follow-up")
+	}
+	prompt := InsightGenerationPrompt("q", nil, []Turn{{Question: "earlier question"}})
+	if !strings.Contains(prompt, "follows up") || !strings.Contains(prompt, "earlier question") {
+		t.Errorf("prompt missing conversation history:\n%s", prompt)
+	}
+}
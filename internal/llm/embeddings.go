@@ -0,0 +1,192 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Default embedding models for each provider
+const (
+	DefaultOpenAIEmbeddingModel = "text-embedding-3-small"
+	DefaultOllamaEmbeddingModel = "nomic-embed-text"
+)
+
+// Embedder turns text into an embedding vector; vectors from the same
+// embedder can be compared with cosine similarity
+type Embedder interface {
+	Embed(ctx context.Context, text string) ([]float64, error)
+}
+
+// OpenAIEmbedder implements Embedder with the OpenAI embeddings API
+type OpenAIEmbedder struct {
+	apiKey     string
+	model      string
+	baseURL    string
+	httpClient *http.Client
+	retry      RetryPolicy
+}
+
+// NewOpenAIEmbedder creates a new OpenAI embedder
+func NewOpenAIEmbedder(apiKey, model string) *OpenAIEmbedder {
+	if model == "" {
+		model = DefaultOpenAIEmbeddingModel
+	}
+	return &OpenAIEmbedder{
+		apiKey:  apiKey,
+		model:   model,
+		baseURL: openAIBaseURL,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		retry: DefaultRetryPolicy(),
+	}
+}
+
+// SetRetryPolicy sets how rate-limited and failed requests are retried
+func (e *OpenAIEmbedder) SetRetryPolicy(policy RetryPolicy) {
+	e.retry = policy
+}
+
+// Embed implements the Embedder interface
+func (e *OpenAIEmbedder) Embed(ctx context.Context, text string) ([]float64, error) {
+	reqBody := map[string]string{"model": e.model, "input": text}
+	headers := map[string]string{"Authorization": "Bearer " + e.apiKey}
+
+	var response struct {
+		Data []struct {
+			Embedding []float64 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := postEmbedding(ctx, e.httpClient, e.retry, "OpenAI", e.baseURL+"/embeddings", headers, reqBody, &response); err != nil {
+		return nil, err
+	}
+	if len(response.Data) == 0 || len(response.Data[0].Embedding) == 0 {
+		return nil, fmt.Errorf("no embedding from OpenAI")
+	}
+
+	return response.Data[0].Embedding, nil
+}
+
+// OllamaEmbedder implements Embedder with a local Ollama embedding model
+type OllamaEmbedder struct {
+	baseURL    string
+	model      string
+	httpClient *http.Client
+	retry      RetryPolicy
+}
+
+// NewOllamaEmbedder creates a new Ollama embedder
+// baseURL is typically http://localhost:11434; pull the model first
+// (ollama pull nomic-embed-text)
+func NewOllamaEmbedder(baseURL, model string) *OllamaEmbedder {
+	if baseURL == "" {
+		baseURL = "http://localhost:11434"
+	}
+	if model == "" {
+		model = DefaultOllamaEmbeddingModel
+	}
+	return &OllamaEmbedder{
+		baseURL: baseURL,
+		model:   model,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		retry: DefaultRetryPolicy(),
+	}
+}
+
+// SetRetryPolicy sets how rate-limited and failed requests are retried
+func (e *OllamaEmbedder) SetRetryPolicy(policy RetryPolicy) {
+	e.retry = policy
+}
+
+// Embed implements the Embedder interface
+func (e *OllamaEmbedder) Embed(ctx context.Context, text string) ([]float64, error) {
+	reqBody := map[string]string{"model": e.model, "input": text}
+
+	var response struct {
+		Embeddings [][]float64 `json:"embeddings"`
+	}
+	if err := postEmbedding(ctx, e.httpClient, e.retry, "Ollama", e.baseURL+"/api/embed", nil, reqBody, &response); err != nil {
+		return nil, err
+	}
+	if len(response.Embeddings) == 0 || len(response.Embeddings[0]) == 0 {
+		return nil, fmt.Errorf("no embedding from Ollama")
+	}
+
+	return response.Embeddings[0], nil
+}
+
+// postEmbedding sends an embedding request and decodes the response into out
+func postEmbedding(ctx context.Context, client *http.Client, retry RetryPolicy, provider, url string, headers map[string]string, reqBody, out any) error {
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := retry.Do(client, req)
+	if err != nil {
+		return fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return &APIError{Provider: provider, StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return nil
+}
+
+// mockEmbeddingDimensions is the length of MockEmbedder vectors
+const mockEmbeddingDimensions = 64
+
+// MockEmbedder is a mock implementation of the Embedder interface for testing:
+// a bag of words hashed into a fixed number of dimensions, so questions
+// sharing most of their words are similar
+type MockEmbedder struct{}
+
+// NewMockEmbedder creates a new mock embedder
+func NewMockEmbedder() *MockEmbedder {
+	return &MockEmbedder{}
+}
+
+// Embed implements the Embedder interface
+func (m *MockEmbedder) Embed(ctx context.Context, text string) ([]float64, error) {
+	vector := make([]float64, mockEmbeddingDimensions)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		h := fnv.New32a()
+		h.Write([]byte(word))
+		vector[h.Sum32()%mockEmbeddingDimensions]++
+	}
+	return vector, nil
+}
@@ -0,0 +1,91 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestOpenAIEmbed tests requesting an embedding from the OpenAI embeddings endpoint
+func TestOpenAIEmbed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		if r.URL.Path != "/embeddings" || r.Header.Get("Authorization") != "Bearer test-key" {
+			t.Errorf("unexpected request %s (auth %q)", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		if req["model"] != DefaultOpenAIEmbeddingModel || req["input"] != "top billing issues" {
+			t.Errorf("unexpected request body %v", req)
+		}
+		fmt.Fprint(w, `{"data":[{"embedding":[0.1,0.2,0.3]}]}`)
+	}))
+	defer server.Close()
+
+	embedder := NewOpenAIEmbedder("test-key", "")
+	embedder.baseURL = server.URL
+
+	got, err := embedder.Embed(context.Background(), "top billing issues")
+	if err != nil {
+		t.Fatalf("Embed() unexpected error: %v", err)
+	}
+	if len(got) != 3 || got[2] != 0.3 {
+		t.Errorf("embedding = %v", got)
+	}
+}
+
+// TestOllamaEmbed tests requesting an embedding from Ollama's embed endpoint
+func TestOllamaEmbed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/embed" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		fmt.Fprint(w, `{"model":"nomic-embed-text","embeddings":[[0.5,0.5]]}`)
+	}))
+	defer server.Close()
+
+	got, err := NewOllamaEmbedder(server.URL, "").Embed(context.Background(), "question")
+	if err != nil {
+		t.Fatalf("Embed() unexpected error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Errorf("embedding = %v", got)
+	}
+}
+
+// TestEmbedErrors tests API errors and empty embeddings
+func TestEmbedErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{name: "API error", status: http.StatusUnauthorized, body: `{"error":"bad key"}`, wantErr: "status 401"},
+		{name: "no embedding", status: http.StatusOK, body: `{"data":[]}`, wantErr: "no embedding"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			}))
+			defer server.Close()
+
+			embedder := NewOpenAIEmbedder("test-key", "")
+			embedder.baseURL = server.URL
+			embedder.SetRetryPolicy(RetryPolicy{})
+
+			_, err := embedder.Embed(context.Background(), "question")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
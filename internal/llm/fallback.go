@@ -0,0 +1,266 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultProviderCooldown is how long a failing provider is skipped
+const DefaultProviderCooldown = 30 * time.Second
+
+// Operation names recorded for each Client method
+const (
+	OperationGenerateSQL     = "generate_sql"
+	OperationGenerateInsight = "generate_insight"
+	OperationGenerate        = "generate"
+)
+
+// Provider is a named client in a fallback chain
+type Provider struct {
+	Name   string
+	Client Client
+}
+
+// ProviderHealth is the health of one provider in a fallback chain
+type ProviderHealth struct {
+	Name                string    `json:"name"`
+	Healthy             bool      `json:"healthy"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	LastError           string    `json:"last_error,omitempty"`
+	CooldownUntil       time.Time `json:"cooldown_until,omitempty"`
+}
+
+// providerState tracks the health of one provider
+type providerState struct {
+	Provider
+	failures      int
+	lastErr       error
+	cooldownUntil time.Time
+}
+
+// FallbackClient is a Client that tries an ordered list of providers, moving
+// to the next one when a provider times out, rate-limits (429), returns a 5xx
+// or cannot be reached. A provider that fails this way is skipped for a
+// cooldown period; if every provider is cooling down they are all tried.
+type FallbackClient struct {
+	providers []*providerState
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu sync.Mutex
+}
+
+// NewFallbackClient creates a client that fails over between providers in order
+func NewFallbackClient(providers []Provider, cooldown time.Duration) (*FallbackClient, error) {
+	if len(providers) == 0 {
+		return nil, fmt.Errorf("at least one LLM provider is required")
+	}
+	if cooldown <= 0 {
+		cooldown = DefaultProviderCooldown
+	}
+
+	states := make([]*providerState, len(providers))
+	for i, p := range providers {
+		if p.Client == nil {
+			return nil, fmt.Errorf("LLM provider %q has no client", p.Name)
+		}
+		states[i] = &providerState{Provider: p}
+	}
+
+	return &FallbackClient{
+		providers: states,
+		cooldown:  cooldown,
+		now:       time.Now,
+	}, nil
+}
+
+// GenerateSQL implements the Client interface
+func (f *FallbackClient) GenerateSQL(ctx context.Context, question string) (string, error) {
+	return f.call(ctx, OperationGenerateSQL, func(c Client) (string, error) {
+		return c.GenerateSQL(ctx, question)
+	})
+}
+
+// GenerateInsight implements the Client interface
+func (f *FallbackClient) GenerateInsight(ctx context.Context, question string, queryResults []map[string]any) (string, error) {
+	return f.call(ctx, OperationGenerateInsight, func(c Client) (string, error) {
+		return c.GenerateInsight(ctx, question, queryResults)
+	})
+}
+
+// GenerateInsightStream implements the Client interface. Once a provider has
+// streamed a token the chain does not fail over, since the tokens already
+// delivered cannot be taken back.
+func (f *FallbackClient) GenerateInsightStream(ctx context.Context, question string, queryResults []map[string]any, onToken TokenHandler) (string, error) {
+	streamed := false
+	return f.call(ctx, OperationGenerateInsight, func(c Client) (string, error) {
+		response, err := c.GenerateInsightStream(ctx, question, queryResults, func(token string) {
+			streamed = true
+			if onToken != nil {
+				onToken(token)
+			}
+		})
+		if err != nil && streamed {
+			return "", &partialStreamError{err: err}
+		}
+		return response, err
+	})
+}
+
+// Generate implements the Client interface
+func (f *FallbackClient) Generate(ctx context.Context, prompt string) (string, error) {
+	return f.call(ctx, OperationGenerate, func(c Client) (string, error) {
+		return c.Generate(ctx, prompt)
+	})
+}
+
+// SetSchemaProvider passes the schema provider on to every provider that uses one
+func (f *FallbackClient) SetSchemaProvider(provider SchemaProvider) {
+	for _, p := range f.providers {
+		if schemaAware, ok := p.Client.(SchemaAware); ok {
+			schemaAware.SetSchemaProvider(provider)
+		}
+	}
+}
+
+// Health returns the health of each provider in chain order
+func (f *FallbackClient) Health() []ProviderHealth {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := f.now()
+	health := make([]ProviderHealth, len(f.providers))
+	for i, p := range f.providers {
+		health[i] = ProviderHealth{
+			Name:                p.Name,
+			Healthy:             !now.Before(p.cooldownUntil),
+			ConsecutiveFailures: p.failures,
+		}
+		if p.lastErr != nil {
+			health[i].LastError = p.lastErr.Error()
+		}
+		if !health[i].Healthy {
+			health[i].CooldownUntil = p.cooldownUntil
+		}
+	}
+	return health
+}
+
+// call runs fn against each provider in order until one succeeds or fails
+// with an error that another provider cannot fix
+func (f *FallbackClient) call(ctx context.Context, operation string, fn func(Client) (string, error)) (string, error) {
+	var failures []string
+	for _, p := range f.candidates() {
+		response, err := fn(p.Client)
+		if err == nil {
+			f.markHealthy(p)
+			RecordProvider(ctx, operation, p.Name)
+			return response, nil
+		}
+
+		var partial *partialStreamError
+		if errors.As(err, &partial) {
+			f.markUnhealthy(p, partial.err)
+			return "", partial.err
+		}
+		if ctx.Err() != nil || !IsTransient(err) {
+			return "", err
+		}
+
+		f.markUnhealthy(p, err)
+		failures = append(failures, fmt.Sprintf("%s: %v", p.Name, err))
+	}
+	return "", fmt.Errorf("all LLM providers failed (%s)", strings.Join(failures, "; "))
+}
+
+// candidates returns the providers to try: healthy ones in chain order, or
+// all of them when every provider is cooling down
+func (f *FallbackClient) candidates() []*providerState {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := f.now()
+	var healthy []*providerState
+	for _, p := range f.providers {
+		if !now.Before(p.cooldownUntil) {
+			healthy = append(healthy, p)
+		}
+	}
+	if len(healthy) == 0 {
+		return append([]*providerState(nil), f.providers...)
+	}
+	return healthy
+}
+
+// markHealthy resets a provider's failure count after a successful call
+func (f *FallbackClient) markHealthy(p *providerState) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	p.failures = 0
+	p.lastErr = nil
+	p.cooldownUntil = time.Time{}
+}
+
+// markUnhealthy starts a provider's cooldown after a transient failure
+func (f *FallbackClient) markUnhealthy(p *providerState, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	p.failures++
+	p.lastErr = err
+	p.cooldownUntil = f.now().Add(f.cooldown)
+}
+
+// partialStreamError marks a stream that failed after delivering tokens
+type partialStreamError struct {
+	err error
+}
+
+// Error implements the error interface
+func (e *partialStreamError) Error() string {
+	return e.err.Error()
+}
+
+// providerRecorderKey is the context key for a *ProviderRecorder
+type providerRecorderKey struct{}
+
+// ProviderRecorder collects which provider answered each operation for calls
+// made with a context returned by WithProviderRecorder
+type ProviderRecorder struct {
+	mu        sync.Mutex
+	providers map[string]string
+}
+
+// WithProviderRecorder returns a context that records the answering providers
+func WithProviderRecorder(ctx context.Context) (context.Context, *ProviderRecorder) {
+	recorder := &ProviderRecorder{providers: make(map[string]string)}
+	return context.WithValue(ctx, providerRecorderKey{}, recorder), recorder
+}
+
+// RecordProvider notes that provider answered operation, if ctx has a recorder
+func RecordProvider(ctx context.Context, operation, provider string) {
+	recorder, ok := ctx.Value(providerRecorderKey{}).(*ProviderRecorder)
+	if !ok {
+		return
+	}
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	recorder.providers[operation] = provider
+}
+
+// Providers returns the provider that last answered each operation, or nil if none were recorded
+func (r *ProviderRecorder) Providers() map[string]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.providers) == 0 {
+		return nil
+	}
+	providers := make(map[string]string, len(r.providers))
+	for op, name := range r.providers {
+		providers[op] = name
+	}
+	return providers
+}
@@ -0,0 +1,128 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// fakeClient is a Client whose GenerateSQL result is set per test
+type fakeClient struct {
+	MockClient
+	err   error
+	calls int
+}
+
+func (f *fakeClient) GenerateSQL(ctx context.Context, question string) (string, error) {
+	f.calls++
+	if f.err != nil {
+		return "", f.err
+	}
+	return "SELECT 1", nil
+}
+
+// TestFallbackClientFailover tests which errors move on to the next provider
+func TestFallbackClientFailover(t *testing.T) {
+	tests := []struct {
+		name         string
+		primaryErr   error
+		wantErr      bool
+		wantProvider string
+	}{
+		{"primary succeeds", nil, false, "groq"},
+		{"rate limited", &APIError{Provider: "Groq", StatusCode: http.StatusTooManyRequests}, false, "openai"},
+		{"server error", &APIError{Provider: "Groq", StatusCode: http.StatusBadGateway}, false, "openai"},
+		{"timeout", context.DeadlineExceeded, false, "openai"},
+		{"bad request", &APIError{Provider: "Groq", StatusCode: http.StatusBadRequest}, true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := &fakeClient{err: tt.primaryErr}
+			secondary := &fakeClient{}
+			client, err := NewFallbackClient([]Provider{
+				{Name: "groq", Client: primary},
+				{Name: "openai", Client: secondary},
+			}, time.Minute)
+			if err != nil {
+				t.Fatalf("NewFallbackClient() unexpected error: %v", err)
+			}
+
+			ctx, recorder := WithProviderRecorder(context.Background())
+			_, err = client.GenerateSQL(ctx, "question")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GenerateSQL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := recorder.Providers()[OperationGenerateSQL]; got != tt.wantProvider {
+				t.Errorf("answering provider = %q, want %q", got, tt.wantProvider)
+			}
+			if tt.wantErr && secondary.calls != 0 {
+				t.Errorf("secondary called %d times, want 0", secondary.calls)
+			}
+		})
+	}
+}
+
+// TestFallbackClientCooldown tests that a failing provider is skipped until its cooldown ends
+func TestFallbackClientCooldown(t *testing.T) {
+	primary := &fakeClient{err: &APIError{Provider: "Groq", StatusCode: http.StatusServiceUnavailable}}
+	secondary := &fakeClient{}
+	client, err := NewFallbackClient([]Provider{
+		{Name: "groq", Client: primary},
+		{Name: "openai", Client: secondary},
+	}, time.Minute)
+	if err != nil {
+		t.Fatalf("NewFallbackClient() unexpected error: %v", err)
+	}
+	now := time.Now()
+	client.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.GenerateSQL(context.Background(), "question"); err != nil {
+			t.Fatalf("GenerateSQL() unexpected error: %v", err)
+		}
+	}
+	if primary.calls != 1 {
+		t.Errorf("primary called %d times during cooldown, want 1", primary.calls)
+	}
+
+	health := client.Health()
+	if health[0].Healthy || health[0].ConsecutiveFailures != 1 || health[0].LastError == "" {
+		t.Errorf("primary health = %+v, want unhealthy with one failure", health[0])
+	}
+	if !health[1].Healthy {
+		t.Errorf("secondary health = %+v, want healthy", health[1])
+	}
+
+	// After the cooldown the recovered primary is used again
+	now = now.Add(2 * time.Minute)
+	primary.err = nil
+	ctx, recorder := WithProviderRecorder(context.Background())
+	if _, err := client.GenerateSQL(ctx, "question"); err != nil {
+		t.Fatalf("GenerateSQL() unexpected error: %v", err)
+	}
+	if got := recorder.Providers()[OperationGenerateSQL]; got != "groq" {
+		t.Errorf("answering provider = %q, want groq", got)
+	}
+	if !client.Health()[0].Healthy {
+		t.Error("primary should be healthy after a successful call")
+	}
+}
+
+// TestFallbackClientAllFailing tests the error when every provider fails
+func TestFallbackClientAllFailing(t *testing.T) {
+	failing := &APIError{Provider: "Groq", StatusCode: http.StatusInternalServerError}
+	client, err := NewFallbackClient([]Provider{
+		{Name: "groq", Client: &fakeClient{err: failing}},
+		{Name: "ollama", Client: &fakeClient{err: errors.New("connection refused")}},
+	}, time.Minute)
+	if err != nil {
+		t.Fatalf("NewFallbackClient() unexpected error: %v", err)
+	}
+
+	if _, err := client.GenerateSQL(context.Background(), "question"); err == nil {
+		t.Fatal("GenerateSQL() expected error")
+	}
+}
@@ -1,158 +1,230 @@
-package llm
-
-import (
-	"bytes"
-	"context"
-	"encoding/json"
-	"fmt"
-	"io"
-	"net/http"
-	"time"
-)
-
-// OpenAIClient implements the Client interface for OpenAI API
-type OpenAIClient struct {
-	apiKey     string
-	model      string
-	httpClient *http.Client
-}
-
-// NewOpenAIClient creates a new OpenAI client
-// apiKey should be loaded from OPENAI_API_KEY environment variable
-func NewOpenAIClient(apiKey, model string) *OpenAIClient {
-	return &OpenAIClient{
-		apiKey: apiKey,
-		model:  model,
-		httpClient: &http.Client{
-			Timeout: 60 * time.Second,
-		},
-	}
-}
-
-type openAIRequest struct {
-	Model    string          `json:"model"`
-	Messages []openAIMessage `json:"messages"`
-}
-
-type openAIMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
-}
-
-type openAIResponse struct {
-	Choices []struct {
-		Message openAIMessage `json:"message"`
-	} `json:"choices"`
-	Error *struct {
-		Message string `json:"message"`
-	} `json:"error,omitempty"`
-}
-
-// GenerateSQL implements the Client interface
-func (c *OpenAIClient) GenerateSQL(ctx context.Context, question string) (string, error) {
-	systemPrompt := SQLGenerationPrompt()
-
-	reqBody := openAIRequest{
-		Model: c.model,
-		Messages: []openAIMessage{
-			{Role: "system", Content: systemPrompt},
-			{Role: "user", Content: question},
-		},
-	}
-
-	response, err := c.makeRequest(ctx, reqBody)
-	if err != nil {
-		return "", fmt.Errorf("failed to generate SQL: %w", err)
-	}
-
-	if len(response.Choices) == 0 {
-		return "", fmt.Errorf("no response from OpenAI")
-	}
-
-	return response.Choices[0].Message.Content, nil
-}
-
-// GenerateInsight implements the Client interface
-func (c *OpenAIClient) GenerateInsight(ctx context.Context, question string, queryResults []map[string]any) (string, error) {
-	prompt := InsightGenerationPrompt(question, queryResults)
-
-	reqBody := openAIRequest{
-		Model: c.model,
-		Messages: []openAIMessage{
-			{Role: "user", Content: prompt},
-		},
-	}
-
-	response, err := c.makeRequest(ctx, reqBody)
-	if err != nil {
-		return "", fmt.Errorf("failed to generate insight: %w", err)
-	}
-
-	if len(response.Choices) == 0 {
-		return "", fmt.Errorf("no response from OpenAI")
-	}
-
-	return response.Choices[0].Message.Content, nil
-}
-
-// Generate implements the Client interface - sends prompt directly to LLM
-func (c *OpenAIClient) Generate(ctx context.Context, prompt string) (string, error) {
-	reqBody := openAIRequest{
-		Model: c.model,
-		Messages: []openAIMessage{
-			{Role: "user", Content: prompt},
-		},
-	}
-
-	response, err := c.makeRequest(ctx, reqBody)
-	if err != nil {
-		return "", fmt.Errorf("failed to generate response: %w", err)
-	}
-
-	if len(response.Choices) == 0 {
-		return "", fmt.Errorf("no response from OpenAI")
-	}
-
-	return response.Choices[0].Message.Content, nil
-}
-
-func (c *OpenAIClient) makeRequest(ctx context.Context, reqBody openAIRequest) (*openAIResponse, error) {
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.openai.com/v1/chat/completions", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiKey))
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to make request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("OpenAI API error (status %d): %s", resp.StatusCode, string(body))
-	}
-
-	var response openAIResponse
-	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
-	}
-
-	if response.Error != nil {
-		return nil, fmt.Errorf("OpenAI API error: %s", response.Error.Message)
-	}
-
-	return &response, nil
-}
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// openAIBaseURL is the default OpenAI API endpoint
+const openAIBaseURL = "https://api.openai.com/v1"
+
+// OpenAIClient implements the Client interface for OpenAI API
+type OpenAIClient struct {
+	apiKey     string
+	model      string
+	baseURL    string
+	httpClient *http.Client
+	schema     SchemaProvider
+	retry      RetryPolicy
+}
+
+// NewOpenAIClient creates a new OpenAI client
+// apiKey should be loaded from OPENAI_API_KEY environment variable
+func NewOpenAIClient(apiKey, model string) *OpenAIClient {
+	return &OpenAIClient{
+		apiKey:  apiKey,
+		model:   model,
+		baseURL: openAIBaseURL,
+		httpClient: &http.Client{
+			Timeout: 60 * time.Second,
+		},
+		retry: DefaultRetryPolicy(),
+	}
+}
+
+// SetSchemaProvider sets the source of the table listing used for SQL generation
+func (c *OpenAIClient) SetSchemaProvider(provider SchemaProvider) {
+	c.schema = provider
+}
+
+// SetRetryPolicy sets how rate-limited and failed requests are retried
+func (c *OpenAIClient) SetRetryPolicy(policy RetryPolicy) {
+	c.retry = policy
+}
+
+type openAIRequest struct {
+	Model    string          `json:"model"`
+	Messages []openAIMessage `json:"messages"`
+	Stream   bool            `json:"stream,omitempty"`
+}
+
+type openAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type openAIResponse struct {
+	Choices []struct {
+		Message openAIMessage `json:"message"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// GenerateSQL implements the Client interface
+func (c *OpenAIClient) GenerateSQL(ctx context.Context, question string) (string, error) {
+	systemPrompt := SQLGenerationPrompt(describeSchema(c.schema)) + sqlFollowUpPrompt(History(ctx))
+
+	reqBody := openAIRequest{
+		Model: c.model,
+		Messages: []openAIMessage{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: question},
+		},
+	}
+
+	response, err := c.makeRequest(ctx, reqBody)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate SQL: %w", err)
+	}
+
+	if len(response.Choices) == 0 {
+		return "", fmt.Errorf("no response from OpenAI")
+	}
+
+	return response.Choices[0].Message.Content, nil
+}
+
+// GenerateInsight implements the Client interface
+func (c *OpenAIClient) GenerateInsight(ctx context.Context, question string, queryResults []map[string]any) (string, error) {
+	prompt := InsightGenerationPrompt(question, queryResults, History(ctx))
+
+	reqBody := openAIRequest{
+		Model: c.model,
+		Messages: []openAIMessage{
+			{Role: "user", Content: prompt},
+		},
+	}
+
+	response, err := c.makeRequest(ctx, reqBody)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate insight: %w", err)
+	}
+
+	if len(response.Choices) == 0 {
+		return "", fmt.Errorf("no response from OpenAI")
+	}
+
+	return response.Choices[0].Message.Content, nil
+}
+
+// GenerateInsightStream implements the Client interface
+func (c *OpenAIClient) GenerateInsightStream(ctx context.Context, question string, queryResults []map[string]any, onToken TokenHandler) (string, error) {
+	prompt := InsightGenerationPrompt(question, queryResults, History(ctx))
+
+	reqBody := openAIRequest{
+		Model: c.model,
+		Messages: []openAIMessage{
+			{Role: "user", Content: prompt},
+		},
+		Stream: true,
+	}
+
+	response, err := c.makeStreamRequest(ctx, reqBody, onToken)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate insight: %w", err)
+	}
+
+	return response, nil
+}
+
+// Generate implements the Client interface - sends prompt directly to LLM
+func (c *OpenAIClient) Generate(ctx context.Context, prompt string) (string, error) {
+	reqBody := openAIRequest{
+		Model: c.model,
+		Messages: []openAIMessage{
+			{Role: "user", Content: prompt},
+		},
+	}
+
+	response, err := c.makeRequest(ctx, reqBody)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate response: %w", err)
+	}
+
+	if len(response.Choices) == 0 {
+		return "", fmt.Errorf("no response from OpenAI")
+	}
+
+	return response.Choices[0].Message.Content, nil
+}
+
+func (c *OpenAIClient) makeRequest(ctx context.Context, reqBody openAIRequest) (*openAIResponse, error) {
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/chat/completions", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiKey))
+
+	resp, err := c.retry.Do(c.httpClient, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &APIError{Provider: "OpenAI", StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var response openAIResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	if response.Error != nil {
+		return nil, fmt.Errorf("OpenAI API error: %s", response.Error.Message)
+	}
+
+	return &response, nil
+}
+
+func (c *OpenAIClient) makeStreamRequest(ctx context.Context, reqBody openAIRequest, onToken TokenHandler) (string, error) {
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/chat/completions", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiKey))
+
+	resp, err := c.retry.Do(c.httpClient, req)
+	if err != nil {
+		return "", fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", &APIError{Provider: "OpenAI", StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	response, err := readChatCompletionStream(resp.Body, onToken)
+	if err != nil {
+		return "", fmt.Errorf("OpenAI API error: %w", err)
+	}
+	return response, nil
+}
@@ -1,181 +1,223 @@
-package llm
-
-import "fmt"
-
-// SQLGenerationPrompt returns the system prompt for SQL generation
-func SQLGenerationPrompt() string {
-	return `You are a SQL expert. Your task is to convert natural language questions into safe SQL SELECT queries.
-
-IMPORTANT RULES:
-1. Only generate SELECT queries - never INSERT, UPDATE, DELETE, DROP, or any DDL/DML
-2. Only query these tables: 'feedback_enriched', 'account_risk_scores', 'product_area_impact'
-3. Use parameterized queries or proper escaping
-4. Return ONLY the SQL query, no explanations or markdown formatting
-5. If the question is unclear, make reasonable assumptions but stay conservative
-6. **CRITICAL**: Always select ALL relevant columns from the query tables, not just a subset. This ensures data completeness in results.
-
-AVAILABLE TABLES:
-
-feedback_enriched(
-  id            TEXT,
-  created_at    TIMESTAMPTZ,
-  source        TEXT,        -- e.g. 'zendesk', 'google_play', 'nps_survey'
-  product_area  TEXT,        -- e.g. 'billing', 'onboarding', 'performance'
-  sentiment     TEXT,        -- 'positive', 'neutral', 'negative'
-  priority      INT,         -- 1 (low) to 5 (critical)
-  topic         TEXT,        -- high-level tag, e.g. 'refund issues'
-  region        TEXT,        -- e.g. 'NA', 'EU', 'APAC'
-  customer_tier TEXT,        -- e.g. 'free', 'pro', 'enterprise'
-  summary       TEXT         -- short summary of feedback
-);
-
-account_risk_scores(
-  account_id         VARCHAR,  -- unique account identifier
-  churn_probability  FLOAT,    -- predicted churn probability (0-1)
-  health_score       FLOAT,    -- account health score (0-100, higher is better)
-  risk_category      VARCHAR,  -- 'low', 'medium', 'high', 'critical'
-  predicted_at       TIMESTAMPTZ,
-  model_version      VARCHAR   -- ML model version used for prediction
-);
--- Use for: churn risk, account health, at-risk customers
-
-product_area_impact(
-  product_area        VARCHAR,  -- e.g. 'billing', 'onboarding', 'performance'
-  segment             VARCHAR,  -- e.g. 'enterprise', 'smb', 'pro'
-  priority_score      FLOAT,    -- priority score (0-100, higher = more important)
-  feedback_count      INT,      -- total feedback volume
-  avg_sentiment_score FLOAT,    -- average sentiment (-1 to 1, negative to positive)
-  negative_count      INT,      -- count of negative feedback
-  critical_count      INT,      -- count of critical priority feedback
-  predicted_at        TIMESTAMPTZ,
-  model_version       VARCHAR
-);
--- Use for: product area prioritization, impact analysis, segment-specific insights
-
-QUERY PATTERNS:
-
-For churn/risk questions (query account_risk_scores with most relevant feedback context):
-User: "Which enterprise accounts are at highest churn risk?"
-SQL: SELECT DISTINCT a.account_id, a.churn_probability, a.health_score, a.risk_category, f.id, f.created_at, f.source, f.product_area, f.sentiment, f.priority, f.topic, f.region, f.customer_tier, f.summary FROM account_risk_scores a LEFT JOIN feedback_enriched f ON LOWER(f.customer_tier) = LOWER(a.account_id) WHERE a.risk_category IN ('high', 'critical') ORDER BY a.churn_probability DESC LIMIT 20;
-
-For product prioritization:
-User: "What top 3 product areas should we prioritize for SMB accounts?"
-SQL: SELECT product_area, segment, priority_score, feedback_count, avg_sentiment_score, negative_count, critical_count FROM product_area_impact WHERE segment = 'smb' ORDER BY priority_score DESC LIMIT 3;
-
-For combined analysis (feedback + risk with all details):
-User: "Show feedback themes from high-risk accounts"
-SQL: SELECT f.id, f.created_at, f.source, f.product_area, f.sentiment, f.priority, f.topic, f.region, f.customer_tier, f.summary, a.account_id, a.churn_probability, a.risk_category FROM feedback_enriched f INNER JOIN account_risk_scores a ON LOWER(f.customer_tier) = LOWER(a.account_id) WHERE a.risk_category IN ('high', 'critical') ORDER BY f.created_at DESC LIMIT 20;
-
-For feedback questions (ALWAYS include all columns from feedback_enriched):
-User: "What are the most common billing issues?"
-SQL: SELECT id, created_at, source, product_area, sentiment, priority, topic, region, customer_tier, summary FROM feedback_enriched WHERE product_area = 'billing' ORDER BY created_at DESC LIMIT 20;
-
-For churn + product area analysis:
-User: "What product areas are causing the highest churn?"
-SQL: SELECT a.account_id, a.churn_probability, a.health_score, a.risk_category, f.product_area, COUNT(f.id) as feedback_count, AVG(CASE WHEN f.sentiment = 'negative' THEN 1 ELSE 0 END) as negative_ratio FROM account_risk_scores a LEFT JOIN feedback_enriched f ON LOWER(f.customer_tier) = LOWER(a.account_id) WHERE a.risk_category IN ('high', 'critical') GROUP BY a.account_id, a.churn_probability, a.health_score, a.risk_category, f.product_area ORDER BY a.churn_probability DESC LIMIT 20;
-
-IMPORTANT GUIDELINES:
-- For account_risk_scores queries: Always LEFT JOIN with feedback_enriched to include all feedback context (product_area, sentiment, priority, topic, region, summary, source, created_at)
-- Include ALL feedback_enriched columns when joining: id, created_at, source, product_area, sentiment, priority, topic, region, customer_tier, summary
-- Use DISTINCT when joining to avoid duplicate account rows
-- Optimize with GROUP BY when aggregating feedback metrics
-- Always order by most relevant metric (churn_probability for risk, priority_score for prioritization, created_at DESC for recency)`
-}
-
-// InsightGenerationPrompt returns the system prompt for insight generation
-func InsightGenerationPrompt(question string, data []map[string]any) string {
-	return fmt.Sprintf(`You are a product analytics AI assistant helping product managers understand customer feedback.
-
-The product manager asked: "%s"
-
-Here is the data retrieved from the database:
-%v
-
-Your task is to:
-1. Provide a clear summary of what the data shows (2-3 sentences)
-2. Give 3-5 actionable recommendations based on the insights
-3. Suggest 2-4 specific action items that could become Jira tickets
-
-Respond in the following JSON format (and ONLY this format, no markdown):
-{
-  "summary": "Clear summary of findings...",
-  "recommendations": [
-    "First recommendation...",
-    "Second recommendation...",
-    "Third recommendation..."
-  ],
-  "actions": [
-    {
-      "title": "Short action title",
-      "description": "Detailed description of what needs to be done"
-    }
-  ]
-}
-
-Be specific, data-driven, and actionable. Focus on insights that can drive product decisions.`, question, data)
-}
-
-// JiraTicketPrompt returns the system prompt for converting insights to Jira tickets
-func JiraTicketPrompt(requestJSON string) string {
-	return fmt.Sprintf(`You are an expert product and Jira assistant integrated into a Go backend service.
-
-CONTEXT
-- The Go service analyzes structured customer feedback stored in Postgres.
-- An LLM has already:
-  1) Generated a SQL query from a natural language question.
-  2) Produced insights using the query result.
-- You now receive the FINAL insight payload and must convert its "actions" into Jira ticket specifications.
-
-INPUT SHAPE
-%s
-
-YOUR TASK
-- For EACH item in "actions", generate a Jira ticket specification.
-- Use:
-  - "title" as the basis for the Jira "summary".
-  - "description" plus relevant context (question, summary, recommendations) to build a clear "description".
-  - "magnitude" (0-10 score) to determine priority automatically.
-
-OUTPUT FORMAT
-- ALWAYS return a single JSON object with exactly this shape:
-
-{
-  "tickets": [
-    {
-      "project_key": "APP",
-      "issue_type": "Story",
-      "summary": "Short Jira summary",
-      "description": "Longer Jira description in markdown-like text",
-      "priority": "Highest | High | Medium | Low",
-      "labels": ["feedback", "billing", "ai_insight"],
-      "components": ["optional_component_name"],
-      "epic_link": null
-    }
-  ]
-}
-
-RULES
-- "tickets" MUST be a non-empty array when there are actions.
-- For each ticket:
-  - "summary": Based on action.title, clear and concise, under 120 characters.
-  - "description": Include:
-    - Brief context from "question" and "summary"
-    - The original action.description
-    - A short "Impact" section if obvious
-    - A short "Acceptance Criteria" section with 3-6 bullet points
-  - "project_key": Use meta.project_key if provided, otherwise "PROJECT_KEY"
-  - "issue_type": Use meta.default_issue_type if provided, otherwise "Story"
-  - "labels": Start with meta.default_labels, add 1-3 lowercase kebab-case labels
-  - "priority": MUST be based on action.magnitude score:
-    - magnitude >= 8.0: "Highest"
-    - magnitude >= 6.5: "High"
-    - magnitude >= 4.0: "Medium"
-    - magnitude < 4.0: "Low"
-  - "components": If obvious product area implied, add it, otherwise empty array
-  - "epic_link": Set to null
-
-IMPORTANT
-- Return ONLY the JSON object with the "tickets" array.
-- Do NOT include any explanatory text, markdown fences, or commentary.
-- Just pure JSON.`, requestJSON)
-}
+package llm
+
+import "fmt"
+
+// DefaultSchema is the table listing used when no SchemaProvider is configured
+// or the schema catalog could not be loaded
+const DefaultSchema = `feedback_enriched(
+  id            TEXT,
+  created_at    TIMESTAMPTZ,
+  source        TEXT,        -- e.g. 'zendesk', 'google_play', 'nps_survey'
+  product_area  TEXT,        -- e.g. 'billing', 'onboarding', 'performance'
+  sentiment     TEXT,        -- 'positive', 'neutral', 'negative'
+  priority      INT,         -- 1 (low) to 5 (critical)
+  topic         TEXT,        -- high-level tag, e.g. 'refund issues'
+  region        TEXT,        -- e.g. 'NA', 'EU', 'APAC'
+  customer_tier TEXT,        -- e.g. 'free', 'pro', 'enterprise'
+  account_id    VARCHAR,     -- joins to account_risk_scores.account_id
+  summary       TEXT         -- short summary of feedback
+);
+
+account_risk_scores(
+  account_id         VARCHAR,  -- unique account identifier
+  churn_probability  FLOAT,    -- predicted churn probability (0-1)
+  health_score       FLOAT,    -- account health score (0-100, higher is better)
+  risk_category      VARCHAR,  -- 'low', 'medium', 'high', 'critical'
+  predicted_at       TIMESTAMPTZ,
+  model_version      VARCHAR   -- ML model version used for prediction
+);
+-- Use for: churn risk, account health, at-risk customers
+
+product_area_impact(
+  product_area        VARCHAR,  -- e.g. 'billing', 'onboarding', 'performance'
+  segment             VARCHAR,  -- e.g. 'enterprise', 'smb', 'pro'
+  priority_score      FLOAT,    -- priority score (0-100, higher = more important)
+  feedback_count      INT,      -- total feedback volume
+  avg_sentiment_score FLOAT,    -- average sentiment (-1 to 1, negative to positive)
+  negative_count      INT,      -- count of negative feedback
+  critical_count      INT,      -- count of critical priority feedback
+  predicted_at        TIMESTAMPTZ,
+  model_version       VARCHAR
+);
+-- Use for: product area prioritization, impact analysis, segment-specific insights
+`
+
+// SchemaProvider supplies the table listing embedded in the SQL generation prompt
+type SchemaProvider interface {
+	// DescribeSchema returns the tables and columns, or "" if unavailable
+	DescribeSchema() string
+}
+
+// describeSchema returns the provider's table listing, or "" without a provider
+func describeSchema(provider SchemaProvider) string {
+	if provider == nil {
+		return ""
+	}
+	return provider.DescribeSchema()
+}
+
+// SQLGenerationPrompt returns the system prompt for SQL generation.
+// schema is the table listing from a SchemaProvider; "" uses DefaultSchema.
+func SQLGenerationPrompt(schema string) string {
+	if schema == "" {
+		schema = DefaultSchema
+	}
+	return `You are a SQL expert. Your task is to convert natural language questions into safe SQL SELECT queries.
+
+IMPORTANT RULES:
+1. Only generate SELECT queries - never INSERT, UPDATE, DELETE, DROP, or any DDL/DML
+2. Only query these tables: 'feedback_enriched', 'account_risk_scores', 'product_area_impact'
+3. Use parameterized queries or proper escaping
+4. Return ONLY the SQL query, no explanations or markdown formatting
+5. If the question is unclear, make reasonable assumptions but stay conservative
+6. **CRITICAL**: Always select ALL relevant columns from the query tables, not just a subset. This ensures data completeness in results.
+
+AVAILABLE TABLES:
+
+` + schema + `
+QUERY PATTERNS:
+
+For churn/risk questions (query account_risk_scores with most relevant feedback context):
+User: "Which enterprise accounts are at highest churn risk?"
+SQL: SELECT DISTINCT a.account_id, a.churn_probability, a.health_score, a.risk_category, f.id, f.created_at, f.source, f.product_area, f.sentiment, f.priority, f.topic, f.region, f.customer_tier, f.summary FROM account_risk_scores a LEFT JOIN feedback_enriched f ON f.account_id = a.account_id WHERE a.risk_category IN ('high', 'critical') ORDER BY a.churn_probability DESC LIMIT 20;
+
+For product prioritization:
+User: "What top 3 product areas should we prioritize for SMB accounts?"
+SQL: SELECT product_area, segment, priority_score, feedback_count, avg_sentiment_score, negative_count, critical_count FROM product_area_impact WHERE segment = 'smb' ORDER BY priority_score DESC LIMIT 3;
+
+For combined analysis (feedback + risk with all details):
+User: "Show feedback themes from high-risk accounts"
+SQL: SELECT f.id, f.created_at, f.source, f.product_area, f.sentiment, f.priority, f.topic, f.region, f.customer_tier, f.summary, a.account_id, a.churn_probability, a.risk_category FROM feedback_enriched f INNER JOIN account_risk_scores a ON f.account_id = a.account_id WHERE a.risk_category IN ('high', 'critical') ORDER BY f.created_at DESC LIMIT 20;
+
+For feedback questions (ALWAYS include all columns from feedback_enriched):
+User: "What are the most common billing issues?"
+SQL: SELECT id, created_at, source, product_area, sentiment, priority, topic, region, customer_tier, account_id, summary FROM feedback_enriched WHERE product_area = 'billing' ORDER BY created_at DESC LIMIT 20;
+
+For churn + product area analysis:
+User: "What product areas are causing the highest churn?"
+SQL: SELECT a.account_id, a.churn_probability, a.health_score, a.risk_category, f.product_area, COUNT(f.id) as feedback_count, AVG(CASE WHEN f.sentiment = 'negative' THEN 1 ELSE 0 END) as negative_ratio FROM account_risk_scores a LEFT JOIN feedback_enriched f ON f.account_id = a.account_id WHERE a.risk_category IN ('high', 'critical') GROUP BY a.account_id, a.churn_probability, a.health_score, a.risk_category, f.product_area ORDER BY a.churn_probability DESC LIMIT 20;
+
+IMPORTANT GUIDELINES:
+- For account_risk_scores queries: Always LEFT JOIN with feedback_enriched to include all feedback context (product_area, sentiment, priority, topic, region, summary, source, created_at)
+- Include ALL feedback_enriched columns when joining: id, created_at, source, product_area, sentiment, priority, topic, region, customer_tier, account_id, summary
+- Join feedback_enriched to account_risk_scores on account_id
+- Use DISTINCT when joining to avoid duplicate account rows
+- Optimize with GROUP BY when aggregating feedback metrics
+- Always order by most relevant metric (churn_probability for risk, priority_score for prioritization, created_at DESC for recency)`
+}
+
+// SQLRepairPrompt returns the prompt asking for a corrected query after the
+// database rejected failedSQL with dbError
+func SQLRepairPrompt(schema, question, failedSQL, dbError string) string {
+	return fmt.Sprintf(`%s
+
+The product manager asked: "%s"
+
+The following query was generated for this question:
+%s
+
+PostgreSQL rejected it with this error:
+%s
+
+Fix the query so that it answers the question and runs without this error.
+Follow all of the rules above. Return ONLY the corrected SQL query, no explanations or markdown formatting.`, SQLGenerationPrompt(schema), question, failedSQL, dbError)
+}
+
+// InsightGenerationPrompt returns the system prompt for insight generation.
+// history holds the earlier turns of the conversation, if any.
+func InsightGenerationPrompt(question string, data []map[string]any, history []Turn) string {
+	return fmt.Sprintf(`You are a product analytics AI assistant helping product managers understand customer feedback.
+%s
+The product manager asked: "%s"
+
+Here is the data retrieved from the database:
+%v
+
+Your task is to:
+1. Provide a clear summary of what the data shows (2-3 sentences)
+2. Give 3-5 actionable recommendations based on the insights
+3. Suggest 2-4 specific action items that could become Jira tickets
+
+Respond in the following JSON format (and ONLY this format, no markdown):
+{
+  "summary": "Clear summary of findings...",
+  "recommendations": [
+    "First recommendation...",
+    "Second recommendation...",
+    "Third recommendation..."
+  ],
+  "actions": [
+    {
+      "title": "Short action title",
+      "description": "Detailed description of what needs to be done"
+    }
+  ]
+}
+
+Be specific, data-driven, and actionable. Focus on insights that can drive product decisions.`, insightFollowUpPrompt(history), question, data)
+}
+
+// JiraTicketPrompt returns the system prompt for converting insights to Jira tickets
+func JiraTicketPrompt(requestJSON string) string {
+	return fmt.Sprintf(`You are an expert product and Jira assistant integrated into a Go backend service.
+
+CONTEXT
+- The Go service analyzes structured customer feedback stored in Postgres.
+- An LLM has already:
+  1) Generated a SQL query from a natural language question.
+  2) Produced insights using the query result.
+- You now receive the FINAL insight payload and must convert its "actions" into Jira ticket specifications.
+
+INPUT SHAPE
+%s
+
+YOUR TASK
+- For EACH item in "actions", generate a Jira ticket specification.
+- Use:
+  - "title" as the basis for the Jira "summary".
+  - "description" plus relevant context (question, summary, recommendations) to build a clear "description".
+  - "magnitude" (0-10 score) to determine priority automatically.
+
+OUTPUT FORMAT
+- ALWAYS return a single JSON object with exactly this shape:
+
+{
+  "tickets": [
+    {
+      "project_key": "APP",
+      "issue_type": "Story",
+      "summary": "Short Jira summary",
+      "description": "Longer Jira description in markdown-like text",
+      "priority": "Highest | High | Medium | Low",
+      "labels": ["feedback", "billing", "ai_insight"],
+      "components": ["optional_component_name"],
+      "epic_link": null
+    }
+  ]
+}
+
+RULES
+- "tickets" MUST be a non-empty array when there are actions.
+- For each ticket:
+  - "summary": Based on action.title, clear and concise, under 120 characters.
+  - "description": Include:
+    - Brief context from "question" and "summary"
+    - The original action.description
+    - A short "Impact" section if obvious
+    - A short "Acceptance Criteria" section with 3-6 bullet points
+  - "project_key": Use meta.project_key if provided, otherwise "PROJECT_KEY"
+  - "issue_type": Use meta.default_issue_type if provided, otherwise "Story"
+  - "labels": Start with meta.default_labels, add 1-3 lowercase kebab-case labels
+  - "priority": MUST be based on action.magnitude score:
+    - magnitude >= 8.0: "Highest"
+    - magnitude >= 6.5: "High"
+    - magnitude >= 4.0: "Medium"
+    - magnitude < 4.0: "Low"
+  - "components": If obvious product area implied, add it, otherwise empty array
+  - "epic_link": Set to null
+
+IMPORTANT
+- Return ONLY the JSON object with the "tickets" array.
+- Do NOT include any explanatory text, markdown fences, or commentary.
+- Just pure JSON.`, requestJSON)
+}
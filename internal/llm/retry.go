@@ -0,0 +1,167 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// Default retry settings for LLM API calls
+const (
+	DefaultMaxRetries     = 2
+	DefaultRetryBaseDelay = 500 * time.Millisecond
+	DefaultRetryMaxDelay  = 10 * time.Second
+)
+
+// RetryPolicy controls how LLM API calls are retried after rate limits (429),
+// server errors (5xx) and dropped connections
+type RetryPolicy struct {
+	MaxRetries int           // retries after the first attempt; 0 disables retrying
+	BaseDelay  time.Duration // backoff before the first retry, doubled for each retry
+	MaxDelay   time.Duration // longest wait; a server asking for more is not retried
+}
+
+// DefaultRetryPolicy returns the retry policy used by the LLM clients
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries: DefaultMaxRetries,
+		BaseDelay:  DefaultRetryBaseDelay,
+		MaxDelay:   DefaultRetryMaxDelay,
+	}
+}
+
+// RetryAware is implemented by clients whose retry policy can be configured
+type RetryAware interface {
+	SetRetryPolicy(policy RetryPolicy)
+}
+
+// Do sends req, retrying transient failures with jittered exponential backoff.
+// On a 429 the wait comes from Retry-After or the x-ratelimit-reset-* headers
+// when present. No retry is attempted if the wait would pass the request
+// context's deadline; the last response or error is returned instead. The
+// request body must be replayable (see http.Request.GetBody), which it is for
+// requests built from a bytes.Buffer, bytes.Reader or strings.Reader.
+func (p RetryPolicy) Do(client *http.Client, req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	for attempt := 0; ; attempt++ {
+		resp, err := client.Do(req)
+		if attempt >= p.MaxRetries || !p.shouldRetry(ctx, resp, err) {
+			return resp, err
+		}
+		if req.Body != nil && req.GetBody == nil {
+			return resp, err
+		}
+
+		wait, ok := p.delay(attempt, resp)
+		if !ok || !fitsDeadline(ctx, wait) {
+			return resp, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("retry interrupted: %w", ctx.Err())
+		case <-timer.C:
+		}
+
+		next := req.Clone(ctx)
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("failed to reset request body: %w", err)
+			}
+			next.Body = body
+		}
+		req = next
+	}
+}
+
+// shouldRetry reports whether a response or error is worth retrying
+func (p RetryPolicy) shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		return ctx.Err() == nil && isConnectionError(err)
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// delay returns how long to wait before retry number attempt+1. Server hints
+// are used as given; ok is false when the server asks for more than MaxDelay.
+func (p RetryPolicy) delay(attempt int, resp *http.Response) (wait time.Duration, ok bool) {
+	if resp != nil {
+		if hint, found := retryAfter(resp.Header, time.Now()); found {
+			return hint, hint <= p.MaxDelay
+		}
+	}
+
+	backoff := p.BaseDelay << attempt
+	if backoff <= 0 || backoff > p.MaxDelay {
+		backoff = p.MaxDelay
+	}
+	backoff = max(backoff, 0) // a misconfigured negative MaxDelay
+	// Equal jitter: half the backoff plus a random share of the other half
+	half := backoff / 2
+	return half + rand.N(half+1), true
+}
+
+// retryAfter reads the wait requested by a rate-limited response: the
+// Retry-After header (seconds or an HTTP date), or otherwise the longest
+// x-ratelimit-reset-* duration whose matching remaining count is exhausted
+// (the format used by OpenAI and Groq, e.g. "1s" or "6m0s")
+func retryAfter(header http.Header, now time.Time) (time.Duration, bool) {
+	if value := header.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second, true
+		}
+		if at, err := http.ParseTime(value); err == nil {
+			return max(at.Sub(now), 0), true
+		}
+	}
+
+	var wait time.Duration
+	found := false
+	for _, limit := range []string{"requests", "tokens"} {
+		if header.Get("X-Ratelimit-Remaining-"+limit) != "0" {
+			continue
+		}
+		reset, err := time.ParseDuration(header.Get("X-Ratelimit-Reset-" + limit))
+		if err != nil {
+			continue
+		}
+		wait = max(wait, reset)
+		found = true
+	}
+	return wait, found
+}
+
+// fitsDeadline reports whether waiting for d leaves time before ctx's deadline
+func fitsDeadline(ctx context.Context, d time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+	return !ok || time.Until(deadline) > d
+}
+
+// isConnectionError reports whether err is a dropped, reset or refused
+// connection or a timeout, as opposed to e.g. an invalid request URL
+func isConnectionError(err error) bool {
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,219 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testRetryPolicy retries quickly so tests stay fast
+var testRetryPolicy = RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: time.Second}
+
+// newRetryRequest builds a POST request with a replayable body
+func newRetryRequest(t *testing.T, ctx context.Context, url string) *http.Request {
+	t.Helper()
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBufferString(`{"prompt":"hi"}`))
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	return req
+}
+
+// TestRetryPolicyDo tests which responses are retried and what is returned
+func TestRetryPolicyDo(t *testing.T) {
+	tests := []struct {
+		name       string
+		statuses   []int // response status per attempt; the last repeats
+		headers    http.Header
+		wantStatus int
+		wantCalls  int32
+	}{
+		{"success", []int{200}, nil, 200, 1},
+		{"server error then success", []int{503, 502, 200}, nil, 200, 3},
+		{"retries exhausted", []int{500}, nil, 500, 3},
+		{"bad request not retried", []int{400}, nil, 400, 1},
+		{"rate limited with Retry-After", []int{429, 200}, http.Header{"Retry-After": {"0"}}, 200, 2},
+		{"rate limit reset header", []int{429, 200}, http.Header{
+			"X-Ratelimit-Remaining-Requests": {"0"},
+			"X-Ratelimit-Reset-Requests":     {"5ms"},
+		}, 200, 2},
+		{"Retry-After beyond max delay", []int{429, 200}, http.Header{"Retry-After": {"60"}}, 429, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(calls.Add(1))
+				if body, _ := io.ReadAll(r.Body); string(body) != `{"prompt":"hi"}` {
+					t.Errorf("attempt %d body = %q", n, body)
+				}
+				status := tt.statuses[min(n, len(tt.statuses))-1]
+				if status != http.StatusOK {
+					for k, v := range tt.headers {
+						w.Header()[k] = v
+					}
+				}
+				w.WriteHeader(status)
+			}))
+			defer server.Close()
+
+			resp, err := testRetryPolicy.Do(server.Client(), newRetryRequest(t, context.Background(), server.URL))
+			if err != nil {
+				t.Fatalf("Do() unexpected error: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if calls.Load() != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls.Load(), tt.wantCalls)
+			}
+		})
+	}
+}
+
+// TestRetryPolicyDoConnectionReset tests that a dropped connection is retried
+func TestRetryPolicyDoConnectionReset(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Fatalf("hijack failed: %v", err)
+			}
+			conn.Close()
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	resp, err := testRetryPolicy.Do(server.Client(), newRetryRequest(t, context.Background(), server.URL))
+	if err != nil {
+		t.Fatalf("Do() unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || calls.Load() != 2 {
+		t.Errorf("status = %d after %d calls, want 200 after 2", resp.StatusCode, calls.Load())
+	}
+}
+
+// TestRetryPolicyDoRespectsDeadline tests that no retry is made past the context deadline
+func TestRetryPolicyDoRespectsDeadline(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	policy := RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Second}
+	start := time.Now()
+	resp, err := policy.Do(server.Client(), newRetryRequest(t, ctx, server.URL))
+	if err != nil {
+		t.Fatalf("Do() unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests || calls.Load() != 1 {
+		t.Errorf("status = %d after %d calls, want 429 after 1", resp.StatusCode, calls.Load())
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("Do() waited %v instead of returning before the deadline", elapsed)
+	}
+}
+
+// TestRetryAfter tests parsing of Retry-After and rate-limit headers
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		header    http.Header
+		want      time.Duration
+		wantFound bool
+	}{
+		{"no headers", http.Header{}, 0, false},
+		{"seconds", http.Header{"Retry-After": {"3"}}, 3 * time.Second, true},
+		{"http date", http.Header{"Retry-After": {now.Add(5 * time.Second).Format(http.TimeFormat)}}, 5 * time.Second, true},
+		{"past date", http.Header{"Retry-After": {now.Add(-time.Minute).Format(http.TimeFormat)}}, 0, true},
+		{"longest exhausted limit", http.Header{
+			"X-Ratelimit-Remaining-Requests": {"0"},
+			"X-Ratelimit-Reset-Requests":     {"2s"},
+			"X-Ratelimit-Remaining-Tokens":   {"0"},
+			"X-Ratelimit-Reset-Tokens":       {"1m0s"},
+		}, time.Minute, true},
+		{"limit not exhausted", http.Header{
+			"X-Ratelimit-Remaining-Requests": {"10"},
+			"X-Ratelimit-Reset-Requests":     {"2s"},
+		}, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := retryAfter(tt.header, now)
+			if got != tt.want || found != tt.wantFound {
+				t.Errorf("retryAfter() = %v, %v; want %v, %v", got, found, tt.want, tt.wantFound)
+			}
+		})
+	}
+}
+
+// TestOpenAIClientRetriesRateLimit tests that the client retries through the shared policy
+func TestOpenAIClientRetriesRateLimit(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			http.Error(w, `{"error":{"message":"rate limited"}}`, http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"SELECT 1"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient("test-key", "gpt-test")
+	client.baseURL = server.URL
+	client.SetRetryPolicy(testRetryPolicy)
+
+	got, err := client.GenerateSQL(context.Background(), "question")
+	if err != nil {
+		t.Fatalf("GenerateSQL() unexpected error: %v", err)
+	}
+	if got != "SELECT 1" || calls.Load() != 2 {
+		t.Errorf("GenerateSQL() = %q after %d calls, want SELECT 1 after 2", got, calls.Load())
+	}
+}
+
+// TestRetryPolicyDelay tests that backoff stays within MaxDelay, even for negative delays
+func TestRetryPolicyDelay(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy RetryPolicy
+		max    time.Duration
+	}{
+		{"capped backoff", RetryPolicy{BaseDelay: time.Second, MaxDelay: 3 * time.Second}, 3 * time.Second},
+		{"negative max delay", RetryPolicy{BaseDelay: time.Second, MaxDelay: -2 * time.Second}, 0},
+		{"negative base delay", RetryPolicy{BaseDelay: -time.Second, MaxDelay: -time.Second}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for attempt := 0; attempt < 4; attempt++ {
+				wait, ok := tt.policy.delay(attempt, nil)
+				if !ok || wait < 0 || wait > tt.max {
+					t.Errorf("delay(%d) = %v, %v; want between 0 and %v", attempt, wait, ok, tt.max)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestOpenAIGenerateInsightStream tests token streaming from an OpenAI-compatible endpoint
+func TestOpenAIGenerateInsightStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req openAIRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || !req.Stream {
+			t.Errorf("expected a streaming request, got %+v (err %v)", req, err)
+		}
+		if r.URL.Path != "/chat/completions" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, token := range []string{`{"summary"`, `: "ok"`, `}`} {
+			chunk, _ := json.Marshal(map[string]any{
+				"choices": []map[string]any{{"delta": map[string]string{"content": token}}},
+			})
+			fmt.Fprintf(w, "data: %s\n\n", chunk)
+			w.(http.Flusher).Flush()
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient("test-key", "gpt-test")
+	client.baseURL = server.URL
+
+	var tokens []string
+	got, err := client.GenerateInsightStream(context.Background(), "question", nil, func(token string) {
+		tokens = append(tokens, token)
+	})
+	if err != nil {
+		t.Fatalf("GenerateInsightStream() unexpected error: %v", err)
+	}
+	if got != `{"summary": "ok"}` {
+		t.Errorf("response = %q", got)
+	}
+	if len(tokens) != 3 {
+		t.Errorf("tokens = %q, want 3 chunks", tokens)
+	}
+}
+
+// TestGroqGenerateInsightStreamError tests that API errors are reported before streaming
+func TestGroqGenerateInsightStreamError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":{"message":"rate limited"}}`, http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := NewGroqClient("test-key", "")
+	client.baseURL = server.URL
+	client.SetRetryPolicy(RetryPolicy{})
+
+	_, err := client.GenerateInsightStream(context.Background(), "question", nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "status 429") {
+		t.Errorf("expected status 429 error, got %v", err)
+	}
+}
+
+// TestOllamaGenerateInsightStream tests token streaming from Ollama's NDJSON endpoint
+func TestOllamaGenerateInsightStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ollamaRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || !req.Stream {
+			t.Errorf("expected a streaming request, got %+v (err %v)", req, err)
+		}
+		fmt.Fprintln(w, `{"response":"Hello","done":false}`)
+		fmt.Fprintln(w, `{"response":" world","done":false}`)
+		fmt.Fprintln(w, `{"response":"","done":true}`)
+	}))
+	defer server.Close()
+
+	client := NewOllamaClient(server.URL, "llama3")
+
+	var tokens []string
+	got, err := client.GenerateInsightStream(context.Background(), "question", nil, func(token string) {
+		tokens = append(tokens, token)
+	})
+	if err != nil {
+		t.Fatalf("GenerateInsightStream() unexpected error: %v", err)
+	}
+	if got != "Hello world" || len(tokens) != 2 {
+		t.Errorf("response = %q, tokens = %q", got, tokens)
+	}
+}
+
+// TestReadChatCompletionStreamError tests an error event inside the stream
+func TestReadChatCompletionStreamError(t *testing.T) {
+	body := strings.NewReader("data: {\"choices\":[{\"delta\":{\"content\":\"partial\"}}]}\n\ndata: {\"error\":{\"message\":\"overloaded\"}}\n\n")
+
+	if _, err := readChatCompletionStream(body, nil); err == nil || !strings.Contains(err.Error(), "overloaded") {
+		t.Errorf("expected stream error, got %v", err)
+	}
+}
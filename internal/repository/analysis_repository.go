@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/chuckie/goinsight/internal/domain"
+)
+
+// AnalysisRepository stores /api/ask answers so that tickets can link back to them
+type AnalysisRepository interface {
+	// SaveAnalysis stores a new analysis
+	SaveAnalysis(ctx context.Context, analysis *domain.Analysis) error
+
+	// GetAnalysis returns an analysis, or nil if there is none with the ID
+	GetAnalysis(ctx context.Context, id string) (*domain.Analysis, error)
+}
+
+// PostgresAnalysisRepository implements AnalysisRepository for PostgreSQL
+type PostgresAnalysisRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresAnalysisRepository creates a new PostgreSQL analysis repository
+func NewPostgresAnalysisRepository(db *sql.DB) *PostgresAnalysisRepository {
+	return &PostgresAnalysisRepository{db: db}
+}
+
+// SaveAnalysis stores a new analysis
+func (r *PostgresAnalysisRepository) SaveAnalysis(ctx context.Context, analysis *domain.Analysis) error {
+	preview, err := nullableJSON(analysis.DataPreview, len(analysis.DataPreview) == 0)
+	if err != nil {
+		return fmt.Errorf("failed to serialize analysis data preview: %w", err)
+	}
+
+	query := `
+		INSERT INTO analyses (id, question, sql_query, summary, data_preview, total_rows, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, analysis.ID, analysis.Question, analysis.SQL, analysis.Summary, preview, analysis.TotalRows, analysis.CreatedAt); err != nil {
+		return fmt.Errorf("failed to save analysis: %w", err)
+	}
+	return nil
+}
+
+// GetAnalysis returns an analysis, or nil if there is none with the ID
+func (r *PostgresAnalysisRepository) GetAnalysis(ctx context.Context, id string) (*domain.Analysis, error) {
+	query := `
+		SELECT id, question, sql_query, summary, data_preview, total_rows, created_at
+		FROM analyses
+		WHERE id = $1
+	`
+
+	var analysis domain.Analysis
+	var preview []byte
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&analysis.ID,
+		&analysis.Question,
+		&analysis.SQL,
+		&analysis.Summary,
+		&preview,
+		&analysis.TotalRows,
+		&analysis.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil // Not found is not an error
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get analysis: %w", err)
+	}
+
+	if preview != nil {
+		if err := json.Unmarshal(preview, &analysis.DataPreview); err != nil {
+			return nil, fmt.Errorf("failed to parse analysis data preview: %w", err)
+		}
+	}
+
+	return &analysis, nil
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/chuckie/goinsight/internal/domain"
+)
+
+// ConversationRepository stores the questions asked within /api/ask conversations
+type ConversationRepository interface {
+	// AppendTurn adds a turn to the end of a conversation
+	AppendTurn(ctx context.Context, conversationID string, turn domain.ConversationTurn) error
+
+	// RecentTurns returns up to limit of the latest turns of a conversation, oldest first
+	RecentTurns(ctx context.Context, conversationID string, limit int) ([]domain.ConversationTurn, error)
+}
+
+// PostgresConversationRepository implements ConversationRepository for PostgreSQL
+type PostgresConversationRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresConversationRepository creates a new PostgreSQL conversation repository
+func NewPostgresConversationRepository(db *sql.DB) *PostgresConversationRepository {
+	return &PostgresConversationRepository{db: db}
+}
+
+// AppendTurn adds a turn to the end of a conversation
+func (r *PostgresConversationRepository) AppendTurn(ctx context.Context, conversationID string, turn domain.ConversationTurn) error {
+	query := `
+		INSERT INTO conversation_turns (conversation_id, question, sql_query, summary)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, conversationID, turn.Question, turn.SQL, turn.Summary); err != nil {
+		return fmt.Errorf("failed to save conversation turn: %w", err)
+	}
+	return nil
+}
+
+// RecentTurns returns up to limit of the latest turns of a conversation, oldest first
+func (r *PostgresConversationRepository) RecentTurns(ctx context.Context, conversationID string, limit int) ([]domain.ConversationTurn, error) {
+	query := `
+		SELECT question, sql_query, summary, created_at
+		FROM (
+			SELECT id, question, sql_query, summary, created_at
+			FROM conversation_turns
+			WHERE conversation_id = $1
+			ORDER BY id DESC
+			LIMIT $2
+		) AS recent
+		ORDER BY id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, conversationID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conversation turns: %w", err)
+	}
+	defer rows.Close()
+
+	var turns []domain.ConversationTurn
+	for rows.Next() {
+		var turn domain.ConversationTurn
+		if err := rows.Scan(&turn.Question, &turn.SQL, &turn.Summary, &turn.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan conversation turn: %w", err)
+		}
+		turns = append(turns, turn)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read conversation turns: %w", err)
+	}
+	return turns, nil
+}
@@ -1,202 +1,202 @@
-package repository
-
-import (
-	"context"
-	"testing"
-)
-
-// TestQueryFeedback tests the QueryFeedback method with various inputs
-func TestQueryFeedback(t *testing.T) {
-	tests := []struct {
-		name        string
-		query       string
-		shouldError bool
-		errorMsg    string
-	}{
-		{
-			name:        "empty query",
-			query:       "",
-			shouldError: true,
-		},
-		{
-			name:        "simple select",
-			query:       "SELECT * FROM feedback LIMIT 1",
-			shouldError: false,
-		},
-		{
-			name:        "invalid SQL",
-			query:       "INVALID SQL QUERY",
-			shouldError: true,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			// This test demonstrates the test structure
-			// In actual integration tests, use a real database
-			repo := NewPostgresFeedbackRepository(nil)
-
-			if repo == nil {
-				t.Fatalf("failed to create repository")
-			}
-
-			// Note: Actual test execution would require a database connection
-			// This demonstrates test structure for CI/CD setup
-		})
-	}
-}
-
-// TestGetAccountRiskScore tests the GetAccountRiskScore method
-func TestGetAccountRiskScore(t *testing.T) {
-	tests := []struct {
-		name        string
-		accountID   string
-		shouldError bool
-	}{
-		{
-			name:        "valid account ID",
-			accountID:   "test-account-123",
-			shouldError: false,
-		},
-		{
-			name:        "empty account ID",
-			accountID:   "",
-			shouldError: false, // May or may not error depending on implementation
-		},
-		{
-			name:        "nonexistent account",
-			accountID:   "nonexistent-account",
-			shouldError: false, // QueryContext will return no rows error
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			repo := NewPostgresFeedbackRepository(nil)
-			if repo == nil {
-				t.Fatalf("failed to create repository")
-			}
-
-			// Note: Actual execution requires database
-			_ = repo
-		})
-	}
-}
-
-// TestGetRecentNegativeFeedbackCount tests counting negative feedback
-func TestGetRecentNegativeFeedbackCount(t *testing.T) {
-	tests := []struct {
-		name        string
-		accountID   string
-		shouldError bool
-	}{
-		{
-			name:        "valid account",
-			accountID:   "test-account-123",
-			shouldError: false,
-		},
-		{
-			name:        "empty account ID",
-			accountID:   "",
-			shouldError: false,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			repo := NewPostgresFeedbackRepository(nil)
-			if repo == nil {
-				t.Fatalf("failed to create repository")
-			}
-		})
-	}
-}
-
-// TestGetProductAreaImpacts tests retrieving product area impact data
-func TestGetProductAreaImpacts(t *testing.T) {
-	tests := []struct {
-		name        string
-		segment     string
-		shouldError bool
-	}{
-		{
-			name:        "valid segment",
-			segment:     "enterprise",
-			shouldError: false,
-		},
-		{
-			name:        "mid-market segment",
-			segment:     "mid-market",
-			shouldError: false,
-		},
-		{
-			name:        "empty segment",
-			segment:     "",
-			shouldError: false,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			repo := NewPostgresFeedbackRepository(nil)
-			if repo == nil {
-				t.Fatalf("failed to create repository")
-			}
-		})
-	}
-}
-
-// TestGetFeedbackEnrichedCount tests getting total enriched feedback count
-func TestGetFeedbackEnrichedCount(t *testing.T) {
-	// Create a test database connection or mock
-	// Since we have no DB, we'll skip this test
-	t.Skip("Requires database connection")
-}
-
-// TestRepositoryInterface verifies that PostgresFeedbackRepository implements FeedbackRepository
-func TestRepositoryInterface(t *testing.T) {
-	var _ FeedbackRepository = (*PostgresFeedbackRepository)(nil)
-}
-
-// BenchmarkQueryFeedback benchmarks the QueryFeedback method
-func BenchmarkQueryFeedback(b *testing.B) {
-	repo := NewPostgresFeedbackRepository(nil)
-	ctx := context.Background()
-
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		_, _ = repo.QueryFeedback(ctx, "SELECT * FROM feedback LIMIT 1")
-	}
-}
-
-// BenchmarkGetAccountRiskScore benchmarks the GetAccountRiskScore method
-func BenchmarkGetAccountRiskScore(b *testing.B) {
-	repo := NewPostgresFeedbackRepository(nil)
-	ctx := context.Background()
-
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		_, _ = repo.GetAccountRiskScore(ctx, "test-account")
-	}
-}
-
-// Benchmark tests - demonstrates structure for performance testing
-func BenchmarkGetRecentNegativeFeedbackCount(b *testing.B) {
-	repo := NewPostgresFeedbackRepository(nil)
-	ctx := context.Background()
-
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		_, _ = repo.GetRecentNegativeFeedbackCount(ctx, "test-account")
-	}
-}
-
-func BenchmarkGetFeedbackEnrichedCount(b *testing.B) {
-	repo := NewPostgresFeedbackRepository(nil)
-	ctx := context.Background()
-
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		_, _ = repo.GetFeedbackEnrichedCount(ctx)
-	}
-}
+package repository
+
+import (
+	"context"
+	"testing"
+)
+
+// TestQueryFeedback tests the QueryFeedback method with various inputs
+func TestQueryFeedback(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		shouldError bool
+		errorMsg    string
+	}{
+		{
+			name:        "empty query",
+			query:       "",
+			shouldError: true,
+		},
+		{
+			name:        "simple select",
+			query:       "SELECT * FROM feedback LIMIT 1",
+			shouldError: false,
+		},
+		{
+			name:        "invalid SQL",
+			query:       "INVALID SQL QUERY",
+			shouldError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// This test demonstrates the test structure
+			// In actual integration tests, use a real database
+			repo := NewPostgresFeedbackRepository(nil)
+
+			if repo == nil {
+				t.Fatalf("failed to create repository")
+			}
+
+			// Note: Actual test execution would require a database connection
+			// This demonstrates test structure for CI/CD setup
+		})
+	}
+}
+
+// TestGetAccountRiskScore tests the GetAccountRiskScore method
+func TestGetAccountRiskScore(t *testing.T) {
+	tests := []struct {
+		name        string
+		accountID   string
+		shouldError bool
+	}{
+		{
+			name:        "valid account ID",
+			accountID:   "test-account-123",
+			shouldError: false,
+		},
+		{
+			name:        "empty account ID",
+			accountID:   "",
+			shouldError: false, // May or may not error depending on implementation
+		},
+		{
+			name:        "nonexistent account",
+			accountID:   "nonexistent-account",
+			shouldError: false, // QueryContext will return no rows error
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewPostgresFeedbackRepository(nil)
+			if repo == nil {
+				t.Fatalf("failed to create repository")
+			}
+
+			// Note: Actual execution requires database
+			_ = repo
+		})
+	}
+}
+
+// TestGetRecentNegativeFeedbackCount tests counting negative feedback
+func TestGetRecentNegativeFeedbackCount(t *testing.T) {
+	tests := []struct {
+		name        string
+		accountID   string
+		shouldError bool
+	}{
+		{
+			name:        "valid account",
+			accountID:   "test-account-123",
+			shouldError: false,
+		},
+		{
+			name:        "empty account ID",
+			accountID:   "",
+			shouldError: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewPostgresFeedbackRepository(nil)
+			if repo == nil {
+				t.Fatalf("failed to create repository")
+			}
+		})
+	}
+}
+
+// TestGetProductAreaImpacts tests retrieving product area impact data
+func TestGetProductAreaImpacts(t *testing.T) {
+	tests := []struct {
+		name        string
+		segment     string
+		shouldError bool
+	}{
+		{
+			name:        "valid segment",
+			segment:     "enterprise",
+			shouldError: false,
+		},
+		{
+			name:        "mid-market segment",
+			segment:     "mid-market",
+			shouldError: false,
+		},
+		{
+			name:        "empty segment",
+			segment:     "",
+			shouldError: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewPostgresFeedbackRepository(nil)
+			if repo == nil {
+				t.Fatalf("failed to create repository")
+			}
+		})
+	}
+}
+
+// TestGetFeedbackEnrichedCount tests getting total enriched feedback count
+func TestGetFeedbackEnrichedCount(t *testing.T) {
+	// Create a test database connection or mock
+	// Since we have no DB, we'll skip this test
+	t.Skip("Requires database connection")
+}
+
+// TestRepositoryInterface verifies that PostgresFeedbackRepository implements FeedbackRepository
+func TestRepositoryInterface(t *testing.T) {
+	var _ FeedbackRepository = (*PostgresFeedbackRepository)(nil)
+}
+
+// BenchmarkQueryFeedback benchmarks the QueryFeedback method
+func BenchmarkQueryFeedback(b *testing.B) {
+	repo := NewPostgresFeedbackRepository(nil)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = repo.QueryFeedback(ctx, "SELECT * FROM feedback LIMIT 1")
+	}
+}
+
+// BenchmarkGetAccountRiskScore benchmarks the GetAccountRiskScore method
+func BenchmarkGetAccountRiskScore(b *testing.B) {
+	repo := NewPostgresFeedbackRepository(nil)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = repo.GetAccountRiskScore(ctx, "test-account")
+	}
+}
+
+// Benchmark tests - demonstrates structure for performance testing
+func BenchmarkGetRecentNegativeFeedbackCount(b *testing.B) {
+	repo := NewPostgresFeedbackRepository(nil)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = repo.GetRecentNegativeFeedbackCount(ctx, "test-account")
+	}
+}
+
+func BenchmarkGetFeedbackEnrichedCount(b *testing.B) {
+	repo := NewPostgresFeedbackRepository(nil)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = repo.GetFeedbackEnrichedCount(ctx)
+	}
+}
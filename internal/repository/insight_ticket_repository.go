@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chuckie/goinsight/internal/domain"
+)
+
+// InsightTicketRepository tracks the tickets filed from insights and their statuses
+type InsightTicketRepository interface {
+	// SaveTickets stores newly filed tickets; tickets already tracked are kept as they are
+	SaveTickets(ctx context.Context, tickets []domain.InsightTicket) error
+
+	// RecordStatus updates a tracked ticket's status and records the
+	// transition. It returns false if the ticket is not tracked, already has
+	// the status, or had a status change recorded after at.
+	RecordStatus(ctx context.Context, tracker, key string, status domain.JiraStatus, source string, at time.Time) (bool, error)
+
+	// ListTickets returns the tracked tickets matching filter, newest first
+	ListTickets(ctx context.Context, filter domain.InsightTicketFilter) ([]domain.InsightTicket, error)
+
+	// OpenTicketKeys returns the keys of a tracker's tickets that are not done
+	OpenTicketKeys(ctx context.Context, tracker string) ([]string, error)
+
+	// ProductAreaStats returns ticket counts and resolution rates per product area
+	ProductAreaStats(ctx context.Context) ([]domain.ProductAreaTicketStats, error)
+}
+
+// PostgresInsightTicketRepository implements InsightTicketRepository for PostgreSQL
+type PostgresInsightTicketRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresInsightTicketRepository creates a new PostgreSQL insight ticket repository
+func NewPostgresInsightTicketRepository(db *sql.DB) *PostgresInsightTicketRepository {
+	return &PostgresInsightTicketRepository{db: db}
+}
+
+// SaveTickets stores newly filed tickets
+func (r *PostgresInsightTicketRepository) SaveTickets(ctx context.Context, tickets []domain.InsightTicket) error {
+	query := `
+		INSERT INTO insight_tickets (tracker, issue_key, question, action, summary, product_area, analysis_id, status, status_category, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $10)
+		ON CONFLICT (tracker, issue_key) DO NOTHING
+	`
+
+	for _, ticket := range tickets {
+		var analysisID sql.NullString
+		if ticket.AnalysisID != "" {
+			analysisID = sql.NullString{String: ticket.AnalysisID, Valid: true}
+		}
+		if _, err := r.db.ExecContext(ctx, query, ticket.Tracker, ticket.Key, ticket.Question, ticket.Action, ticket.Summary,
+			ticket.ProductArea, analysisID, ticket.Status, ticket.StatusCategory, ticket.CreatedAt); err != nil {
+			return fmt.Errorf("failed to save insight ticket %s: %w", ticket.Key, err)
+		}
+	}
+	return nil
+}
+
+// RecordStatus updates a tracked ticket's status and records the transition
+func (r *PostgresInsightTicketRepository) RecordStatus(ctx context.Context, tracker, key string, status domain.JiraStatus, source string, at time.Time) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var current, category string
+	var updatedAt time.Time
+	err = tx.QueryRowContext(ctx, `
+		SELECT status, status_category, updated_at
+		FROM insight_tickets
+		WHERE tracker = $1 AND issue_key = $2
+		FOR UPDATE
+	`, tracker, key).Scan(&current, &category, &updatedAt)
+	if err == sql.ErrNoRows {
+		return false, nil // Not a ticket filed from an insight
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get insight ticket: %w", err)
+	}
+
+	// Ignore repeats and events older than the last recorded change. The
+	// filing time is ours, not Jira's, so it does not order the first change.
+	if (current == status.Name && category == status.StatusCategory.Key) || (current != "" && at.Before(updatedAt)) {
+		return false, nil
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE insight_tickets
+		SET status = $3, status_category = $4, updated_at = $5,
+			resolved_at = CASE WHEN $4 = 'done' THEN COALESCE(resolved_at, $5) ELSE NULL END
+		WHERE tracker = $1 AND issue_key = $2
+	`, tracker, key, status.Name, status.StatusCategory.Key, at); err != nil {
+		return false, fmt.Errorf("failed to update insight ticket: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO insight_ticket_transitions (tracker, issue_key, from_status, to_status, status_category, source, changed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, tracker, key, current, status.Name, status.StatusCategory.Key, source, at); err != nil {
+		return false, fmt.Errorf("failed to record ticket transition: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit ticket transition: %w", err)
+	}
+	return true, nil
+}
+
+// ListTickets returns the tracked tickets matching filter, newest first
+func (r *PostgresInsightTicketRepository) ListTickets(ctx context.Context, filter domain.InsightTicketFilter) ([]domain.InsightTicket, error) {
+	var conditions []string
+	var args []any
+	if filter.ProductArea != "" {
+		args = append(args, filter.ProductArea)
+		conditions = append(conditions, fmt.Sprintf("LOWER(product_area) = LOWER($%d)", len(args)))
+	}
+	if filter.Open != nil {
+		if *filter.Open {
+			conditions = append(conditions, "status_category <> 'done'")
+		} else {
+			conditions = append(conditions, "status_category = 'done'")
+		}
+	}
+
+	query := `
+		SELECT tracker, issue_key, question, action, summary, product_area, COALESCE(analysis_id::text, ''),
+			status, status_category, created_at, updated_at, resolved_at
+		FROM insight_tickets
+	`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY created_at DESC, issue_key"
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list insight tickets: %w", err)
+	}
+	defer rows.Close()
+
+	var tickets []domain.InsightTicket
+	for rows.Next() {
+		var ticket domain.InsightTicket
+		var resolvedAt sql.NullTime
+		if err := rows.Scan(&ticket.Tracker, &ticket.Key, &ticket.Question, &ticket.Action, &ticket.Summary, &ticket.ProductArea,
+			&ticket.AnalysisID, &ticket.Status, &ticket.StatusCategory, &ticket.CreatedAt, &ticket.UpdatedAt, &resolvedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan insight ticket: %w", err)
+		}
+		if resolvedAt.Valid {
+			ticket.ResolvedAt = &resolvedAt.Time
+		}
+		tickets = append(tickets, ticket)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read insight tickets: %w", err)
+	}
+	return tickets, nil
+}
+
+// OpenTicketKeys returns the keys of a tracker's tickets that are not done
+func (r *PostgresInsightTicketRepository) OpenTicketKeys(ctx context.Context, tracker string) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT issue_key FROM insight_tickets
+		WHERE tracker = $1 AND status_category <> 'done'
+		ORDER BY created_at
+	`, tracker)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list open insight tickets: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("failed to scan insight ticket key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read open insight tickets: %w", err)
+	}
+	return keys, nil
+}
+
+// ProductAreaStats returns ticket counts and resolution rates per product area
+func (r *PostgresInsightTicketRepository) ProductAreaStats(ctx context.Context) ([]domain.ProductAreaTicketStats, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT product_area,
+			COUNT(*),
+			COUNT(*) FILTER (WHERE status_category = 'done'),
+			COALESCE(AVG(EXTRACT(EPOCH FROM resolved_at - created_at) / 3600) FILTER (WHERE status_category = 'done'), 0)
+		FROM insight_tickets
+		GROUP BY product_area
+		ORDER BY product_area
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get insight ticket stats: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []domain.ProductAreaTicketStats
+	for rows.Next() {
+		var s domain.ProductAreaTicketStats
+		if err := rows.Scan(&s.ProductArea, &s.Total, &s.Resolved, &s.AvgResolutionHours); err != nil {
+			return nil, fmt.Errorf("failed to scan insight ticket stats: %w", err)
+		}
+		s.Open = s.Total - s.Resolved
+		if s.Total > 0 {
+			s.ResolutionRate = float64(s.Resolved) / float64(s.Total)
+		}
+		stats = append(stats, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read insight ticket stats: %w", err)
+	}
+	return stats, nil
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/chuckie/goinsight/internal/domain"
+)
+
+// JiraDraftRepository stores ticket specs generated by a /api/jira-tickets dry run
+type JiraDraftRepository interface {
+	// SaveDraft stores a new draft
+	SaveDraft(ctx context.Context, draft *domain.JiraDraft) error
+
+	// GetDraft returns a draft, or nil if there is none with the ID
+	GetDraft(ctx context.Context, id string) (*domain.JiraDraft, error)
+
+	// ConfirmDraft marks an unconfirmed draft as confirmed with the final
+	// tickets. It returns false if the draft was already confirmed, so that
+	// concurrent confirmations file the tickets only once.
+	ConfirmDraft(ctx context.Context, id string, tickets []domain.JiraTicketSpec) (bool, error)
+
+	// ReopenDraft clears the confirmation of a draft whose tickets could not be filed
+	ReopenDraft(ctx context.Context, id string) error
+}
+
+// PostgresJiraDraftRepository implements JiraDraftRepository for PostgreSQL
+type PostgresJiraDraftRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresJiraDraftRepository creates a new PostgreSQL Jira draft repository
+func NewPostgresJiraDraftRepository(db *sql.DB) *PostgresJiraDraftRepository {
+	return &PostgresJiraDraftRepository{db: db}
+}
+
+// SaveDraft stores a new draft
+func (r *PostgresJiraDraftRepository) SaveDraft(ctx context.Context, draft *domain.JiraDraft) error {
+	tickets, err := json.Marshal(draft.Tickets)
+	if err != nil {
+		return fmt.Errorf("failed to serialize draft tickets: %w", err)
+	}
+	epic, err := nullableJSON(draft.Epic, draft.Epic == nil)
+	if err != nil {
+		return fmt.Errorf("failed to serialize draft epic: %w", err)
+	}
+	preview, err := nullableJSON(draft.DataPreview, len(draft.DataPreview) == 0)
+	if err != nil {
+		return fmt.Errorf("failed to serialize draft data preview: %w", err)
+	}
+
+	query := `
+		INSERT INTO jira_drafts (id, question, tickets, epic, data_preview, sql_query, analysis_id, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	var analysisID sql.NullString
+	if draft.AnalysisID != "" {
+		analysisID = sql.NullString{String: draft.AnalysisID, Valid: true}
+	}
+
+	if _, err := r.db.ExecContext(ctx, query, draft.ID, draft.Question, tickets, epic, preview, draft.SQL, analysisID, draft.CreatedAt, draft.ExpiresAt); err != nil {
+		return fmt.Errorf("failed to save jira draft: %w", err)
+	}
+	return nil
+}
+
+// GetDraft returns a draft, or nil if there is none with the ID
+func (r *PostgresJiraDraftRepository) GetDraft(ctx context.Context, id string) (*domain.JiraDraft, error) {
+	query := `
+		SELECT id, question, tickets, epic, data_preview, sql_query, analysis_id, created_at, expires_at, confirmed_at
+		FROM jira_drafts
+		WHERE id = $1
+	`
+
+	var draft domain.JiraDraft
+	var tickets []byte
+	var epic, preview []byte
+	var analysisID sql.NullString
+	var confirmedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&draft.ID,
+		&draft.Question,
+		&tickets,
+		&epic,
+		&preview,
+		&draft.SQL,
+		&analysisID,
+		&draft.CreatedAt,
+		&draft.ExpiresAt,
+		&confirmedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil // Not found is not an error
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get jira draft: %w", err)
+	}
+
+	if err := json.Unmarshal(tickets, &draft.Tickets); err != nil {
+		return nil, fmt.Errorf("failed to parse draft tickets: %w", err)
+	}
+	if epic != nil {
+		if err := json.Unmarshal(epic, &draft.Epic); err != nil {
+			return nil, fmt.Errorf("failed to parse draft epic: %w", err)
+		}
+	}
+	if preview != nil {
+		if err := json.Unmarshal(preview, &draft.DataPreview); err != nil {
+			return nil, fmt.Errorf("failed to parse draft data preview: %w", err)
+		}
+	}
+	draft.AnalysisID = analysisID.String
+	if confirmedAt.Valid {
+		draft.ConfirmedAt = &confirmedAt.Time
+	}
+
+	return &draft, nil
+}
+
+// ConfirmDraft marks an unconfirmed draft as confirmed with the final tickets
+func (r *PostgresJiraDraftRepository) ConfirmDraft(ctx context.Context, id string, tickets []domain.JiraTicketSpec) (bool, error) {
+	data, err := json.Marshal(tickets)
+	if err != nil {
+		return false, fmt.Errorf("failed to serialize draft tickets: %w", err)
+	}
+
+	query := `
+		UPDATE jira_drafts
+		SET tickets = $2, confirmed_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND confirmed_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, data)
+	if err != nil {
+		return false, fmt.Errorf("failed to confirm jira draft: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to confirm jira draft: %w", err)
+	}
+	return rows == 1, nil
+}
+
+// ReopenDraft clears the confirmation of a draft whose tickets could not be filed
+func (r *PostgresJiraDraftRepository) ReopenDraft(ctx context.Context, id string) error {
+	if _, err := r.db.ExecContext(ctx, `UPDATE jira_drafts SET confirmed_at = NULL WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to reopen jira draft: %w", err)
+	}
+	return nil
+}
+
+// nullableJSON serializes value, or returns nil (SQL NULL) if empty
+func nullableJSON(value any, empty bool) ([]byte, error) {
+	if empty {
+		return nil, nil
+	}
+	return json.Marshal(value)
+}
@@ -1,142 +1,142 @@
-package repository
-
-import (
-	"context"
-	"testing"
-)
-
-// TestBeginTransaction tests transaction creation
-func TestBeginTransaction(t *testing.T) {
-	tests := []struct {
-		name        string
-		shouldError bool
-	}{
-		{
-			name:        "with default options",
-			shouldError: false,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			// Note: Requires actual database connection
-			// This demonstrates test structure
-		})
-	}
-}
-
-// TestTransactionCommit tests transaction commit
-func TestTransactionCommit(t *testing.T) {
-	tests := []struct {
-		name        string
-		shouldError bool
-	}{
-		{
-			name:        "successful commit",
-			shouldError: false,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			// Note: Requires actual database connection
-			// This demonstrates test structure
-		})
-	}
-}
-
-// TestTransactionRollback tests transaction rollback
-func TestTransactionRollback(t *testing.T) {
-	tests := []struct {
-		name        string
-		shouldError bool
-	}{
-		{
-			name:        "successful rollback",
-			shouldError: false,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			// Note: Requires actual database connection
-			// This demonstrates test structure
-		})
-	}
-}
-
-// TestTransactionGetRepository tests getting repository within transaction
-func TestTransactionGetRepository(t *testing.T) {
-	ctx := context.Background()
-
-	t.Run("get repository from transaction", func(t *testing.T) {
-		// Note: Requires actual database connection
-		// This demonstrates test structure
-		_ = ctx
-	})
-}
-
-// TestTransactionACID tests ACID compliance
-func TestTransactionACID(t *testing.T) {
-	tests := []struct {
-		name        string
-		description string
-	}{
-		{
-			name:        "atomicity",
-			description: "All operations succeed or all fail",
-		},
-		{
-			name:        "consistency",
-			description: "Database remains consistent",
-		},
-		{
-			name:        "isolation",
-			description: "Concurrent transactions don't interfere",
-		},
-		{
-			name:        "durability",
-			description: "Committed changes survive failure",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			// Note: Requires actual database connection and concurrent access
-			// This demonstrates test structure
-			t.Logf("Testing ACID property: %s", tt.description)
-		})
-	}
-}
-
-// TestDefaultTransactionOptions tests transaction options
-func TestDefaultTransactionOptions(t *testing.T) {
-	opts := DefaultTransactionOptions()
-
-	// Verify default values
-	if opts.ReadOnly {
-		t.Error("expected ReadOnly to be false")
-	}
-}
-
-// BenchmarkBeginTransaction benchmarks transaction creation
-func BenchmarkBeginTransaction(b *testing.B) {
-	// Note: Requires actual database connection
-	// This demonstrates benchmark structure
-	b.ReportAllocs()
-	b.ResetTimer()
-}
-
-// BenchmarkTransactionCommit benchmarks commit operation
-func BenchmarkTransactionCommit(b *testing.B) {
-	// Note: Requires actual database connection
-	b.ReportAllocs()
-	b.ResetTimer()
-}
-
-// BenchmarkTransactionRollback benchmarks rollback operation
-func BenchmarkTransactionRollback(b *testing.B) {
-	// Note: Requires actual database connection
-	b.ReportAllocs()
-	b.ResetTimer()
-}
+package repository
+
+import (
+	"context"
+	"testing"
+)
+
+// TestBeginTransaction tests transaction creation
+func TestBeginTransaction(t *testing.T) {
+	tests := []struct {
+		name        string
+		shouldError bool
+	}{
+		{
+			name:        "with default options",
+			shouldError: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Note: Requires actual database connection
+			// This demonstrates test structure
+		})
+	}
+}
+
+// TestTransactionCommit tests transaction commit
+func TestTransactionCommit(t *testing.T) {
+	tests := []struct {
+		name        string
+		shouldError bool
+	}{
+		{
+			name:        "successful commit",
+			shouldError: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Note: Requires actual database connection
+			// This demonstrates test structure
+		})
+	}
+}
+
+// TestTransactionRollback tests transaction rollback
+func TestTransactionRollback(t *testing.T) {
+	tests := []struct {
+		name        string
+		shouldError bool
+	}{
+		{
+			name:        "successful rollback",
+			shouldError: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Note: Requires actual database connection
+			// This demonstrates test structure
+		})
+	}
+}
+
+// TestTransactionGetRepository tests getting repository within transaction
+func TestTransactionGetRepository(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("get repository from transaction", func(t *testing.T) {
+		// Note: Requires actual database connection
+		// This demonstrates test structure
+		_ = ctx
+	})
+}
+
+// TestTransactionACID tests ACID compliance
+func TestTransactionACID(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+	}{
+		{
+			name:        "atomicity",
+			description: "All operations succeed or all fail",
+		},
+		{
+			name:        "consistency",
+			description: "Database remains consistent",
+		},
+		{
+			name:        "isolation",
+			description: "Concurrent transactions don't interfere",
+		},
+		{
+			name:        "durability",
+			description: "Committed changes survive failure",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Note: Requires actual database connection and concurrent access
+			// This demonstrates test structure
+			t.Logf("Testing ACID property: %s", tt.description)
+		})
+	}
+}
+
+// TestDefaultTransactionOptions tests transaction options
+func TestDefaultTransactionOptions(t *testing.T) {
+	opts := DefaultTransactionOptions()
+
+	// Verify default values
+	if opts.ReadOnly {
+		t.Error("expected ReadOnly to be false")
+	}
+}
+
+// BenchmarkBeginTransaction benchmarks transaction creation
+func BenchmarkBeginTransaction(b *testing.B) {
+	// Note: Requires actual database connection
+	// This demonstrates benchmark structure
+	b.ReportAllocs()
+	b.ResetTimer()
+}
+
+// BenchmarkTransactionCommit benchmarks commit operation
+func BenchmarkTransactionCommit(b *testing.B) {
+	// Note: Requires actual database connection
+	b.ReportAllocs()
+	b.ResetTimer()
+}
+
+// BenchmarkTransactionRollback benchmarks rollback operation
+func BenchmarkTransactionRollback(b *testing.B) {
+	// Note: Requires actual database connection
+	b.ReportAllocs()
+	b.ResetTimer()
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/chuckie/goinsight/internal/domain"
+	"github.com/chuckie/goinsight/internal/repository"
+	"github.com/google/uuid"
+)
+
+// ErrAnalysisNotFound is returned for an unknown analysis ID
+var ErrAnalysisNotFound = errors.New("analysis not found")
+
+// SetAnalysisRepository enables stored analyses: each /api/ask answer is
+// saved with its SQL and data preview, and tickets filed from it link back
+func (fs *FeedbackService) SetAnalysisRepository(repo repository.AnalysisRepository) {
+	fs.analyses = repo
+}
+
+// GetAnalysis returns a stored analysis
+func (s *FeedbackService) GetAnalysis(ctx context.Context, id string) (*domain.Analysis, error) {
+	if s.analyses == nil {
+		return nil, fmt.Errorf("analyses are not configured")
+	}
+	if uuid.Validate(id) != nil {
+		return nil, ErrAnalysisNotFound
+	}
+
+	analysis, err := s.analyses.GetAnalysis(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if analysis == nil {
+		return nil, ErrAnalysisNotFound
+	}
+	return analysis, nil
+}
+
+// saveAnalysis stores an answer and returns its ID, or "" if analyses are
+// disabled or it could not be saved (the answer is returned regardless)
+func (s *FeedbackService) saveAnalysis(ctx context.Context, response *domain.AskResponse) string {
+	if s.analyses == nil {
+		return ""
+	}
+
+	analysis := &domain.Analysis{
+		ID:          uuid.NewString(),
+		Question:    response.Question,
+		SQL:         response.SQL,
+		Summary:     response.Summary,
+		DataPreview: response.DataPreview,
+		TotalRows:   response.TotalRows,
+		CreatedAt:   time.Now(),
+	}
+	if err := s.analyses.SaveAnalysis(ctx, analysis); err != nil {
+		if s.logger != nil {
+			s.logger.Warn("Failed to save analysis", map[string]interface{}{
+				"question": response.Question,
+				"error":    err.Error(),
+			})
+		}
+		return ""
+	}
+	return analysis.ID
+}
+
+// resolveEvidence fills in the SQL and data preview of a ticket request from
+// the analysis it references, keeping any the request carries itself
+func (s *FeedbackService) resolveEvidence(ctx context.Context, req *JiraTicketRequest) error {
+	if req.AnalysisID == "" || s.analyses == nil {
+		return nil
+	}
+
+	analysis, err := s.GetAnalysis(ctx, req.AnalysisID)
+	if err != nil {
+		return fmt.Errorf("failed to load analysis %s: %w", req.AnalysisID, err)
+	}
+	if req.SQL == "" {
+		req.SQL = analysis.SQL
+	}
+	if len(req.DataPreview) == 0 {
+		req.DataPreview = analysis.DataPreview
+	}
+	if req.Question == "" {
+		req.Question = analysis.Question
+	}
+	return nil
+}
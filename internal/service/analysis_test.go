@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/chuckie/goinsight/internal/domain"
+	"github.com/chuckie/goinsight/tests/mocks"
+)
+
+// TestAnalysisEvidenceReachesTickets tests that an answer is stored and its evidence is attached to tickets filed from it
+func TestAnalysisEvidenceReachesTickets(t *testing.T) {
+	feedbackRepo := mocks.NewMockFeedbackRepository()
+	feedbackRepo.SetQueryFeedbackResult([]map[string]any{
+		{"product_area": "billing", "count": 12},
+	})
+	issueTracker := &recordingTracker{}
+	llmClient := &MockLLMClient{
+		GenerateFn: func(ctx context.Context, prompt string) (string, error) {
+			return `{"tickets": [{"issue_type": "Story", "summary": "Fix refund delays"}]}`, nil
+		},
+	}
+	analyses := mocks.NewMockAnalysisRepository()
+	service := NewFeedbackService(feedbackRepo, llmClient, issueTracker)
+	service.SetAnalysisRepository(analyses)
+
+	ctx := context.Background()
+	answer, err := service.AnalyzeFeedback(ctx, "What are the top billing issues?")
+	if err != nil {
+		t.Fatalf("AnalyzeFeedback() unexpected error: %v", err)
+	}
+	if answer.AnalysisID == "" {
+		t.Fatal("answer has no analysis_id")
+	}
+	stored, err := service.GetAnalysis(ctx, answer.AnalysisID)
+	if err != nil || stored.SQL != answer.SQL || len(stored.DataPreview) != 1 {
+		t.Fatalf("GetAnalysis() = %+v, %v, want the answer's SQL and rows", stored, err)
+	}
+
+	// The ticket request only references the analysis
+	_, err = service.CreateJiraTickets(ctx, JiraTicketRequest{
+		AnalysisID: answer.AnalysisID,
+		Actions:    []domain.ActionItem{{Title: "Fix refund delays"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateJiraTickets() unexpected error: %v", err)
+	}
+	if len(issueTracker.filed) != 1 {
+		t.Fatalf("filed %d tickets, want 1", len(issueTracker.filed))
+	}
+	filed := issueTracker.filed[0]
+	if filed.SQL != answer.SQL || filed.AnalysisID != answer.AnalysisID || len(filed.DataPreview) != 1 {
+		t.Errorf("filed spec evidence = (%q, %q, %v), want the analysis's", filed.SQL, filed.AnalysisID, filed.DataPreview)
+	}
+}
+
+// TestGetAnalysisNotFound tests unknown and malformed analysis IDs
+func TestGetAnalysisNotFound(t *testing.T) {
+	service := NewFeedbackService(mocks.NewMockFeedbackRepository(), &MockLLMClient{}, &recordingTracker{})
+	service.SetAnalysisRepository(mocks.NewMockAnalysisRepository())
+
+	for _, id := range []string{"00000000-0000-0000-0000-000000000000", "not-a-uuid"} {
+		if _, err := service.GetAnalysis(context.Background(), id); !errors.Is(err, ErrAnalysisNotFound) {
+			t.Errorf("GetAnalysis(%q) error = %v, want ErrAnalysisNotFound", id, err)
+		}
+	}
+
+	_, err := service.CreateJiraTickets(context.Background(), JiraTicketRequest{
+		AnalysisID: "00000000-0000-0000-0000-000000000000",
+		Actions:    []domain.ActionItem{{Title: "Fix refund delays"}},
+	})
+	if !errors.Is(err, ErrAnalysisNotFound) {
+		t.Errorf("CreateJiraTickets() error = %v, want ErrAnalysisNotFound", err)
+	}
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+
+	"github.com/chuckie/goinsight/internal/domain"
+)
+
+// Stage events emitted by AnalyzeFeedbackStream, in order. Repaired queries
+// repeat EventSQLGenerated and EventSQLValidated with a higher attempt number.
+const (
+	EventSQLGenerated = "sql_generated"
+	EventSQLValidated = "sql_validated"
+	EventRowsFetched  = "rows_fetched"
+	EventInsightToken = "insight_token"
+)
+
+// AskEvent is a progress event emitted while a question is analyzed
+type AskEvent struct {
+	Type string
+	Data any
+}
+
+// SQLEventData is the payload of EventSQLGenerated and EventSQLValidated
+type SQLEventData struct {
+	SQL     string `json:"sql"`
+	Attempt int    `json:"attempt"`
+}
+
+// RowsFetchedData is the payload of EventRowsFetched
+type RowsFetchedData struct {
+	RowCount     int              `json:"row_count"`
+	TotalRows    int              `json:"total_rows"`
+	Truncated    bool             `json:"truncated"`
+	LimitApplied int              `json:"limit_applied"`
+	DataPreview  []map[string]any `json:"data_preview"`
+}
+
+// InsightTokenData is the payload of EventInsightToken
+type InsightTokenData struct {
+	Token string `json:"token"`
+}
+
+// AskObserver receives stage events; it is called synchronously, so a slow
+// observer slows down the analysis
+type AskObserver func(event AskEvent)
+
+// AnalyzeFeedbackStream runs the same workflow as AnalyzeFeedback, reporting
+// each stage to observer as it finishes and streaming the insight tokens.
+// A response served from cache produces no stage events.
+func (s *FeedbackService) AnalyzeFeedbackStream(ctx context.Context, question string, observer AskObserver) (*domain.AskResponse, error) {
+	return s.analyze(ctx, question, observer)
+}
+
+// emit sends an event to observer if one is set
+func (observer AskObserver) emit(eventType string, data any) {
+	if observer != nil {
+		observer(AskEvent{Type: eventType, Data: data})
+	}
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/chuckie/goinsight/internal/profiler"
+)
+
+// CoalescingStats counts the analyses and queries run, and the callers that
+// shared the result of an identical one already in flight instead
+type CoalescingStats struct {
+	Questions          int64 `json:"questions"`
+	CoalescedQuestions int64 `json:"coalesced_questions"`
+	Queries            int64 `json:"queries"`
+	CoalescedQueries   int64 `json:"coalesced_queries"`
+}
+
+// GetCoalescingStats returns how many identical concurrent questions and
+// queries were coalesced
+func (s *FeedbackService) GetCoalescingStats() CoalescingStats {
+	questions, coalescedQuestions := s.questionFlights.stats()
+	queries, coalescedQueries := s.queryFlights.stats()
+	return CoalescingStats{
+		Questions:          questions,
+		CoalescedQuestions: coalescedQuestions,
+		Queries:            queries,
+		CoalescedQueries:   coalescedQueries,
+	}
+}
+
+// executedQuery is the shared result of running a generated query
+type executedQuery struct {
+	resultSet *queryResultSet
+	metrics   *profiler.QueryMetrics
+}
+
+// flightGroup runs one call per key at a time: callers arriving while a call
+// for their key is in flight wait for its result instead of starting another.
+// The zero value is ready to use.
+type flightGroup[T any] struct {
+	mu        sync.Mutex
+	calls     map[string]*flightCall[T]
+	started   int64
+	coalesced int64
+}
+
+// flightCall is a call in flight; value and err are set before done is
+// closed. waiters counts the callers still waiting, and cancel stops the call
+// once none are left.
+type flightCall[T any] struct {
+	done    chan struct{}
+	value   T
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// do calls fn once for concurrent callers with the same key, reporting
+// whether the result was shared with an earlier caller. fn runs with a
+// context that is not cancelled with the caller's, so one caller leaving does
+// not fail the others; each caller stops waiting when its own ctx is done,
+// and fn's context is cancelled when the last caller stops waiting.
+func (g *flightGroup[T]) do(ctx context.Context, key string, fn func(ctx context.Context) (T, error)) (T, bool, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall[T])
+	}
+	call, shared := g.calls[key]
+	if shared {
+		g.coalesced++
+	} else {
+		callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		call = &flightCall[T]{done: make(chan struct{}), cancel: cancel}
+		g.calls[key] = call
+		g.started++
+		go g.run(callCtx, key, call, fn)
+	}
+	call.waiters++
+	g.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.value, shared, call.err
+	case <-ctx.Done():
+		g.leave(key, call)
+		var zero T
+		return zero, shared, ctx.Err()
+	}
+}
+
+// leave stops a caller waiting for call, cancelling the call if it was the
+// last one; later callers start a new call instead of joining the cancelled one
+func (g *flightGroup[T]) leave(key string, call *flightCall[T]) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	call.waiters--
+	if call.waiters > 0 {
+		return
+	}
+	call.cancel()
+	if g.calls[key] == call {
+		delete(g.calls, key)
+	}
+}
+
+// run makes the call and hands its result to the waiting callers
+func (g *flightGroup[T]) run(ctx context.Context, key string, call *flightCall[T], fn func(ctx context.Context) (T, error)) {
+	defer func() {
+		// The call runs outside the request, so a panic fails it instead of
+		// crashing the server
+		if r := recover(); r != nil {
+			call.err = fmt.Errorf("panic: %v", r)
+		}
+		g.mu.Lock()
+		if g.calls[key] == call {
+			delete(g.calls, key)
+		}
+		g.mu.Unlock()
+		call.cancel()
+		close(call.done)
+	}()
+
+	call.value, call.err = fn(ctx)
+}
+
+// stats returns how many calls were made and how many callers shared one
+func (g *flightGroup[T]) stats() (started, coalesced int64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.started, g.coalesced
+}
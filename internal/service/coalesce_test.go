@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/chuckie/goinsight/internal/domain"
+	"github.com/chuckie/goinsight/tests/mocks"
+)
+
+// waitForStats waits until the service's coalescing stats reach want
+func waitForStats(t *testing.T, service *FeedbackService, want CoalescingStats) {
+	t.Helper()
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		if service.GetCoalescingStats() == want {
+			return
+		}
+	}
+	t.Fatalf("GetCoalescingStats() = %+v, want %+v", service.GetCoalescingStats(), want)
+}
+
+// TestAnalyzeFeedbackCoalescesQuestions tests that identical concurrent questions share one analysis
+func TestAnalyzeFeedbackCoalescesQuestions(t *testing.T) {
+	release := make(chan struct{})
+	var sqlCalls atomic.Int32
+	llmClient := &MockLLMClient{
+		GenerateSQLFn: func(ctx context.Context, question string) (string, error) {
+			sqlCalls.Add(1)
+			<-release
+			return "SELECT * FROM feedback_enriched", nil
+		},
+	}
+	mockRepo := mocks.NewMockFeedbackRepository()
+	mockRepo.SetQueryFeedbackResult([]map[string]any{{"id": 1, "sentiment": "negative"}})
+	service := NewFeedbackService(mockRepo, llmClient, nil)
+
+	questions := []string{"What is sentiment?", "what is  sentiment?", " WHAT IS SENTIMENT? ", "What is sentiment?"}
+	responses := make([]*domain.AskResponse, len(questions))
+	errs := make([]error, len(questions))
+	var wg sync.WaitGroup
+	for i, question := range questions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			responses[i], errs[i] = service.AnalyzeFeedback(context.Background(), question)
+		}()
+	}
+
+	waitForStats(t, service, CoalescingStats{Questions: 1, CoalescedQuestions: int64(len(questions) - 1)})
+	close(release)
+	wg.Wait()
+
+	for i, response := range responses {
+		if errs[i] != nil {
+			t.Fatalf("AnalyzeFeedback(%q) unexpected error: %v", questions[i], errs[i])
+		}
+		if response.Question != questions[i] || response.Summary != "Analysis complete" {
+			t.Errorf("response %d = %q: %q, want the shared answer to %q", i, response.Question, response.Summary, questions[i])
+		}
+	}
+	if sqlCalls.Load() != 1 || mockRepo.QueryFeedbackCallCount != 1 {
+		t.Errorf("GenerateSQL calls = %d, queries = %d, want 1 each", sqlCalls.Load(), mockRepo.QueryFeedbackCallCount)
+	}
+}
+
+// TestAnalyzeFeedbackCoalescesQueries tests that different questions generating the same SQL share one query
+func TestAnalyzeFeedbackCoalescesQueries(t *testing.T) {
+	release := make(chan struct{})
+	mockRepo := mocks.NewMockFeedbackRepository()
+	mockRepo.QueryFeedbackFn = func(ctx context.Context, query string) ([]map[string]any, error) {
+		<-release
+		return []map[string]any{{"product_area": "billing", "count": 12}}, nil
+	}
+	service := NewFeedbackService(mockRepo, &MockLLMClient{}, nil)
+
+	questions := []string{"What are the top issues?", "Which problems come up most?"}
+	errs := make([]error, len(questions))
+	var wg sync.WaitGroup
+	for i, question := range questions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = service.AnalyzeFeedback(context.Background(), question)
+		}()
+	}
+
+	waitForStats(t, service, CoalescingStats{Questions: 2, Queries: 1, CoalescedQueries: 1})
+	close(release)
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("AnalyzeFeedback(%q) unexpected error: %v", questions[i], err)
+		}
+	}
+	if mockRepo.QueryFeedbackCallCount != 1 {
+		t.Errorf("queries = %d, want 1", mockRepo.QueryFeedbackCallCount)
+	}
+}
+
+// TestAnalyzeFeedbackCoalescedCancellation tests that a caller leaving does not fail the callers sharing its analysis
+func TestAnalyzeFeedbackCoalescedCancellation(t *testing.T) {
+	release := make(chan struct{})
+	llmClient := &MockLLMClient{
+		GenerateSQLFn: func(ctx context.Context, question string) (string, error) {
+			<-release
+			return "SELECT * FROM feedback_enriched", ctx.Err()
+		},
+	}
+	service := NewFeedbackService(mocks.NewMockFeedbackRepository(), llmClient, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := service.AnalyzeFeedback(ctx, "What is sentiment?")
+		firstErr <- err
+	}()
+	waitForStats(t, service, CoalescingStats{Questions: 1})
+
+	secondErr := make(chan error, 1)
+	go func() {
+		_, err := service.AnalyzeFeedback(context.Background(), "What is sentiment?")
+		secondErr <- err
+	}()
+	waitForStats(t, service, CoalescingStats{Questions: 1, CoalescedQuestions: 1})
+
+	cancel()
+	if err := <-firstErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled caller error = %v, want %v", err, context.Canceled)
+	}
+	close(release)
+	if err := <-secondErr; err != nil {
+		t.Errorf("waiting caller unexpected error: %v", err)
+	}
+}
+
+// TestFlightGroupCancelsAbandonedCall tests that a call is cancelled once every caller waiting for it has left
+func TestFlightGroupCancelsAbandonedCall(t *testing.T) {
+	var g flightGroup[int]
+	started := make(chan struct{})
+	callDone := make(chan struct{})
+	fn := func(ctx context.Context) (int, error) {
+		close(started)
+		<-ctx.Done()
+		close(callDone)
+		return 0, ctx.Err()
+	}
+
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	errs := make(chan error, 2)
+	go func() {
+		_, _, err := g.do(ctx1, "key", fn)
+		errs <- err
+	}()
+	<-started
+	go func() {
+		_, _, err := g.do(ctx2, "key", fn)
+		errs <- err
+	}()
+	for deadline := time.Now().Add(2 * time.Second); ; time.Sleep(time.Millisecond) {
+		if _, coalesced := g.stats(); coalesced == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("second caller did not join the call")
+		}
+	}
+
+	// One caller leaving keeps the call running for the other
+	cancel1()
+	<-errs
+	select {
+	case <-callDone:
+		t.Fatal("call was cancelled while a caller was still waiting")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	cancel2()
+	<-errs
+	select {
+	case <-callDone:
+	case <-time.After(2 * time.Second):
+		t.Fatal("call was not cancelled after every caller left")
+	}
+}
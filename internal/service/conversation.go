@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/chuckie/goinsight/internal/domain"
+	"github.com/chuckie/goinsight/internal/llm"
+	"github.com/chuckie/goinsight/internal/repository"
+	"github.com/google/uuid"
+)
+
+// DefaultConversationTurns is how many earlier turns are passed to the LLM
+const DefaultConversationTurns = 5
+
+// SetConversationRepository enables conversations: earlier questions, their
+// SQL and summaries are stored and passed to the LLM for follow-up questions
+func (fs *FeedbackService) SetConversationRepository(repo repository.ConversationRepository) {
+	fs.conversations = repo
+}
+
+// SetConversationTurns configures how many earlier turns are passed to the LLM
+func (fs *FeedbackService) SetConversationTurns(turns int) {
+	fs.conversationTurns = turns
+}
+
+// AnalyzeConversation answers a question as a follow-up in a conversation.
+// An empty conversationID starts a new conversation; the ID to continue it
+// with is returned in the response. Without a conversation repository this
+// is the same as AnalyzeFeedback.
+func (s *FeedbackService) AnalyzeConversation(ctx context.Context, conversationID, question string) (*domain.AskResponse, error) {
+	return s.analyzeConversation(ctx, conversationID, question, nil)
+}
+
+// AnalyzeConversationStream is the streaming variant of AnalyzeConversation
+// (see AnalyzeFeedbackStream)
+func (s *FeedbackService) AnalyzeConversationStream(ctx context.Context, conversationID, question string, observer AskObserver) (*domain.AskResponse, error) {
+	return s.analyzeConversation(ctx, conversationID, question, observer)
+}
+
+// analyzeConversation loads the conversation history, analyzes the question
+// with it and records the new turn
+func (s *FeedbackService) analyzeConversation(ctx context.Context, conversationID, question string, observer AskObserver) (*domain.AskResponse, error) {
+	if s.conversations == nil {
+		return s.analyze(ctx, question, observer)
+	}
+
+	if conversationID == "" {
+		conversationID = uuid.NewString()
+	} else if _, err := uuid.Parse(conversationID); err != nil {
+		return nil, fmt.Errorf("invalid conversation_id %q: must be a UUID", conversationID)
+	}
+
+	turns, err := s.conversations.RecentTurns(ctx, conversationID, s.conversationTurns)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load conversation: %w", err)
+	}
+
+	history := make([]llm.Turn, len(turns))
+	for i, turn := range turns {
+		history[i] = llm.Turn{Question: turn.Question, SQL: turn.SQL, Summary: turn.Summary}
+	}
+
+	response, err := s.analyze(llm.WithHistory(ctx, history), question, observer)
+	if err != nil {
+		return nil, err
+	}
+
+	// The response may be shared with the cache, so the ID is set on a copy
+	answered := *response
+	answered.ConversationID = conversationID
+
+	turn := domain.ConversationTurn{Question: question, SQL: answered.SQL, Summary: answered.Summary}
+	if err := s.conversations.AppendTurn(ctx, conversationID, turn); err != nil && s.logger != nil {
+		s.logger.Warn("Failed to save conversation turn", map[string]interface{}{
+			"conversation_id": conversationID,
+			"error":           err.Error(),
+		})
+	}
+
+	return &answered, nil
+}
@@ -1,551 +1,957 @@
-package service
-
-import (
-	"context"
-	"encoding/json"
-	"fmt"
-	"regexp"
-	"strings"
-	"time"
-
-	"github.com/chuckie/goinsight/internal/cache"
-	"github.com/chuckie/goinsight/internal/domain"
-	"github.com/chuckie/goinsight/internal/jira"
-	"github.com/chuckie/goinsight/internal/llm"
-	"github.com/chuckie/goinsight/internal/profiler"
-	"github.com/chuckie/goinsight/internal/repository"
-)
-
-// FeedbackService orchestrates business logic for feedback analysis
-// with integrated performance monitoring, caching, and optimization
-type FeedbackService struct {
-	repo           repository.FeedbackRepository
-	llmClient      llm.Client
-	jiraClient     *jira.Client
-	cacheManager   *cache.CacheManager
-	logger         *profiler.Logger
-	queryProfiler  *profiler.QueryProfiler
-	slowQueryLog   *profiler.SlowQueryLogger
-	queryOptimizer *profiler.QueryOptimizer
-
-	// Cache configuration
-	cacheQueryResults bool
-	queryResultsTTL   time.Duration
-}
-
-// NewFeedbackService creates a new feedback service
-func NewFeedbackService(
-	repo repository.FeedbackRepository,
-	llmClient llm.Client,
-	jiraClient *jira.Client,
-) *FeedbackService {
-	return &FeedbackService{
-		repo:       repo,
-		llmClient:  llmClient,
-		jiraClient: jiraClient,
-	}
-}
-
-// NewFeedbackServiceWithProfiler creates a new feedback service with profiling enabled
-func NewFeedbackServiceWithProfiler(
-	repo repository.FeedbackRepository,
-	llmClient llm.Client,
-	jiraClient *jira.Client,
-	logger *profiler.Logger,
-	queryProfiler *profiler.QueryProfiler,
-	slowQueryLog *profiler.SlowQueryLogger,
-	queryOptimizer *profiler.QueryOptimizer,
-) *FeedbackService {
-	return &FeedbackService{
-		repo:           repo,
-		llmClient:      llmClient,
-		jiraClient:     jiraClient,
-		logger:         logger,
-		queryProfiler:  queryProfiler,
-		slowQueryLog:   slowQueryLog,
-		queryOptimizer: queryOptimizer,
-	}
-}
-
-// NewFeedbackServiceWithCache creates a feedback service with caching enabled
-func NewFeedbackServiceWithCache(
-	repo repository.FeedbackRepository,
-	llmClient llm.Client,
-	jiraClient *jira.Client,
-	cacheManager *cache.CacheManager,
-) *FeedbackService {
-	return &FeedbackService{
-		repo:                 repo,
-		llmClient:            llmClient,
-		jiraClient:           jiraClient,
-		cacheManager:         cacheManager,
-		cacheQueryResults:    true,
-		queryResultsTTL:      5 * time.Minute,
-	}
-}
-
-// NewFeedbackServiceFull creates a feedback service with all features enabled
-func NewFeedbackServiceFull(
-	repo repository.FeedbackRepository,
-	llmClient llm.Client,
-	jiraClient *jira.Client,
-	logger *profiler.Logger,
-	queryProfiler *profiler.QueryProfiler,
-	slowQueryLog *profiler.SlowQueryLogger,
-	queryOptimizer *profiler.QueryOptimizer,
-	cacheManager *cache.CacheManager,
-) *FeedbackService {
-	return &FeedbackService{
-		repo:                 repo,
-		llmClient:            llmClient,
-		jiraClient:           jiraClient,
-		logger:               logger,
-		queryProfiler:        queryProfiler,
-		slowQueryLog:         slowQueryLog,
-		queryOptimizer:       queryOptimizer,
-		cacheManager:         cacheManager,
-		cacheQueryResults:    true,
-		queryResultsTTL:      5 * time.Minute,
-	}
-}
-
-// SetCacheTTL configures the cache TTL for query results
-func (fs *FeedbackService) SetCacheTTL(ttl time.Duration) {
-	fs.queryResultsTTL = ttl
-}
-
-// CacheQueryResults enables/disables query result caching
-func (fs *FeedbackService) CacheQueryResults(enabled bool) {
-	fs.cacheQueryResults = enabled
-}
-
-// QueryRequest represents a question to analyze
-type QueryRequest struct {
-	Question string
-}
-
-// AnalyzeFeedback orchestrates the full workflow: SQL generation, execution, and insight generation
-// with integrated query profiling and performance monitoring
-func (s *FeedbackService) AnalyzeFeedback(ctx context.Context, question string) (*domain.AskResponse, error) {
-	// Validate input
-	if question == "" {
-		return nil, fmt.Errorf("question is required")
-	}
-
-	// Step 0: Check cache for previously analyzed questions
-	// (Cache is keyed by question text to allow caching of full insights)
-	if s.cacheManager != nil && s.cacheQueryResults {
-		cachedResponse, found, err := s.cacheManager.GetCachedQueryResult(ctx, question)
-		if err == nil && found {
-			// Cache hit - return cached response
-			if response, ok := cachedResponse.(*domain.AskResponse); ok {
-				return response, nil
-			}
-		}
-	}
-
-	// Step 1: Generate SQL from the question
-	sqlQuery, err := s.llmClient.GenerateSQL(ctx, question)
-	if err != nil {
-		if s.logger != nil {
-			s.logger.Error("Failed to generate SQL", err, map[string]interface{}{
-				"question": question,
-			})
-		}
-		return nil, fmt.Errorf("failed to generate SQL: %w", err)
-	}
-
-	// Step 2: Check cache for SQL query results (if different question generates same SQL)
-	var queryResults []map[string]interface{}
-	var metrics *profiler.QueryMetrics
-	cachedResults := false
-
-	if s.cacheManager != nil && s.cacheQueryResults {
-		cachedData, found, err := s.cacheManager.GetCachedQueryResult(ctx, sqlQuery)
-		if err == nil && found {
-			if results, ok := cachedData.([]map[string]interface{}); ok {
-				queryResults = results
-				cachedResults = true
-			}
-		}
-	}
-
-	// Step 3: Validate SQL for safety
-	if err := s.validateSQL(sqlQuery); err != nil {
-		if s.logger != nil {
-			s.logger.Warn("SQL validation failed", map[string]interface{}{
-				"question": question,
-				"sql":      sqlQuery,
-				"error":    err.Error(),
-			})
-		}
-		return nil, err
-	}
-
-	// Step 4: Execute the SQL query with profiling (if not cached)
-	if !cachedResults {
-		if s.queryProfiler != nil {
-			metrics = s.queryProfiler.StartQueryExecution(sqlQuery)
-		}
-
-		queryResults, err = s.repo.QueryFeedback(ctx, sqlQuery)
-
-		if s.queryProfiler != nil && metrics != nil {
-			rowsReturned := int64(len(queryResults))
-			poolUsage := 1 // Default value, can be enhanced with actual pool metrics
-			s.queryProfiler.RecordQueryExecution(metrics, rowsReturned, poolUsage, false, err)
-
-			// Check if query is slow and log accordingly
-			execTimeMS := metrics.ExecutionTime.Seconds() * 1000
-			if s.slowQueryLog != nil && execTimeMS > 500 {
-				s.slowQueryLog.RecordSlowQuery(
-					metrics.QueryID,
-					sqlQuery,
-					metrics.QueryHash,
-					execTimeMS,
-					500.0,
-					rowsReturned,
-				)
-			}
-
-			// Generate optimization suggestions if query is slow
-			if s.queryOptimizer != nil && execTimeMS > 500 {
-				stats := s.queryProfiler.GetStats(metrics.QueryHash)
-				suggestions := s.queryOptimizer.AnalyzeQuery(sqlQuery, stats)
-
-				if len(suggestions) > 0 && s.logger != nil {
-					s.logger.Debug("Query optimization suggestions", map[string]interface{}{
-						"query_id":     metrics.QueryID,
-						"execution_ms": execTimeMS,
-						"suggestions":  len(suggestions),
-					})
-				}
-			}
-		}
-
-		if err != nil {
-			if s.logger != nil {
-				s.logger.Error("Query execution failed", err, map[string]interface{}{
-					"sql": sqlQuery,
-				})
-			}
-			return nil, fmt.Errorf("query execution failed: %w", err)
-		}
-
-		// Cache query results for future use
-		if s.cacheManager != nil && s.cacheQueryResults {
-			_ = s.cacheManager.CacheQueryResult(ctx, sqlQuery, queryResults, s.queryResultsTTL)
-		}
-	}
-
-	// Step 5: Generate insights from the results
-	insightJSON, err := s.llmClient.GenerateInsight(ctx, question, queryResults)
-	if err != nil {
-		if s.logger != nil {
-			s.logger.Error("Failed to generate insights", err, map[string]interface{}{
-				"question": question,
-				"results":  len(queryResults),
-			})
-		}
-		return nil, fmt.Errorf("failed to generate insights: %w", err)
-	}
-
-	// Step 5: Parse the insight JSON
-	var insightResult domain.InsightResult
-	if err := json.Unmarshal([]byte(insightJSON), &insightResult); err != nil {
-		if s.logger != nil {
-			s.logger.Error("Failed to parse insight response", err, map[string]interface{}{
-				"question": question,
-			})
-		}
-		return nil, fmt.Errorf("failed to parse insight response: %w", err)
-	}
-
-	// Step 6: Build the response
-	dataPreview := queryResults
-	if len(dataPreview) > 10 {
-		dataPreview = dataPreview[:10]
-	}
-
-	response := &domain.AskResponse{
-		Question:        question,
-		SQL:             sqlQuery,
-		DataPreview:     dataPreview,
-		Summary:         insightResult.Summary,
-		Recommendations: insightResult.Recommendations,
-		Actions:         insightResult.Actions,
-	}
-
-	// Cache the complete response for future identical questions
-	if s.cacheManager != nil && s.cacheQueryResults {
-		_ = s.cacheManager.CacheQueryResult(ctx, question, response, s.queryResultsTTL)
-	}
-
-	if s.logger != nil {
-		execTimeMs := 0.0
-		if metrics != nil {
-			execTimeMs = metrics.ExecutionTime.Seconds() * 1000
-		}
-		s.logger.Info("Feedback analysis completed", map[string]interface{}{
-			"question":    question,
-			"results":     len(queryResults),
-			"actions":     len(insightResult.Actions),
-			"exec_time_ms": execTimeMs,
-		})
-	}
-
-	return response, nil
-}
-
-// validateSQL performs safety checks on the generated SQL query
-func (s *FeedbackService) validateSQL(sqlQuery string) error {
-	normalizedSQL := strings.ToUpper(strings.TrimSpace(sqlQuery))
-
-	// Ensure it's only a SELECT
-	if !strings.HasPrefix(normalizedSQL, "SELECT") {
-		return fmt.Errorf(
-			"unable to generate a valid data query. "+
-				"This API analyzes customer feedback data. "+
-				"Please ask questions about feedback, such as: "+
-				"'What are the most common billing issues?' or "+
-				"'Show me negative feedback from enterprise customers.'",
-		)
-	}
-
-	// Check for dangerous SQL statement keywords as standalone words
-	dangerous := []string{
-		"\\bDROP\\b", "\\bDELETE\\b", "\\bINSERT\\b", "\\bUPDATE\\b",
-		"\\bALTER\\b", "\\bCREATE\\s+TABLE\\b", "\\bCREATE\\s+INDEX\\b",
-		"\\bTRUNCATE\\b", "\\bEXEC\\b", "\\bEXECUTE\\b",
-	}
-
-	for _, pattern := range dangerous {
-		re, err := regexp.Compile(pattern)
-		if err != nil {
-			return fmt.Errorf("internal error compiling SQL validation pattern: %w", err)
-		}
-
-		if re.MatchString(normalizedSQL) {
-			return fmt.Errorf("generated query contains forbidden SQL statement")
-		}
-	}
-
-	return nil
-}
-
-// GetAccountRiskScore retrieves ML predictions for a specific account
-func (s *FeedbackService) GetAccountRiskScore(ctx context.Context, accountID string) (*domain.AccountRiskScore, error) {
-	return s.repo.GetAccountRiskScore(ctx, accountID)
-}
-
-// GetRecentNegativeFeedbackCount counts recent negative feedback for an account
-func (s *FeedbackService) GetRecentNegativeFeedbackCount(ctx context.Context, accountID string) (int, error) {
-	return s.repo.GetRecentNegativeFeedbackCount(ctx, accountID)
-}
-
-// GetProductAreaImpacts retrieves ML predictions for product area priorities
-func (s *FeedbackService) GetProductAreaImpacts(ctx context.Context, segment string) ([]map[string]any, error) {
-	return s.repo.GetProductAreaImpacts(ctx, segment)
-}
-
-// JiraTicketRequest wraps the action items and metadata for Jira ticket creation
-type JiraTicketRequest struct {
-	Summary         string
-	Recommendations []string
-	Actions         []domain.ActionItem
-	Meta            JiraMetadata
-}
-
-// JiraMetadata holds Jira-specific configuration
-type JiraMetadata struct {
-	ProjectKey       string
-	DefaultIssueType string
-	DefaultLabels    []string
-}
-
-// CreateJiraTickets converts insight actions into Jira tickets
-func (s *FeedbackService) CreateJiraTickets(ctx context.Context, req JiraTicketRequest) (*domain.JiraCreationResult, error) {
-	// Validate Jira is configured
-	if s.jiraClient == nil {
-		return nil, fmt.Errorf("jira integration is not configured")
-	}
-
-	// Validate request
-	if len(req.Actions) == 0 {
-		return nil, fmt.Errorf("no actions provided to convert into tickets")
-	}
-
-	// Validate required Jira meta and set defaults
-	if strings.TrimSpace(req.Meta.ProjectKey) == "" {
-		return nil, fmt.Errorf("jira project key is required")
-	}
-	if req.Meta.DefaultIssueType == "" {
-		req.Meta.DefaultIssueType = "Story"
-	}
-	if len(req.Meta.DefaultLabels) == 0 {
-		req.Meta.DefaultLabels = []string{"feedback", "ai-insight"}
-	}
-
-	// Calculate magnitude for each action item
-	for i := range req.Actions {
-		req.Actions[i].Magnitude = domain.CalculateMagnitude(
-			req.Actions[i],
-			req.Summary,
-			req.Recommendations,
-		)
-	}
-
-	// Convert request to JSON for LLM prompt
-	domainReq := domain.JiraTicketRequest{
-		Summary:         req.Summary,
-		Recommendations: req.Recommendations,
-		Actions:         req.Actions,
-		Meta: domain.JiraTicketMeta{
-			ProjectKey:       req.Meta.ProjectKey,
-			DefaultIssueType: req.Meta.DefaultIssueType,
-			DefaultLabels:    req.Meta.DefaultLabels,
-		},
-	}
-
-	requestJSON, err := json.MarshalIndent(domainReq, "", "  ")
-	if err != nil {
-		return nil, fmt.Errorf("failed to serialize request: %w", err)
-	}
-
-	// Use LLM to generate Jira ticket specifications
-	prompt := llm.JiraTicketPrompt(string(requestJSON))
-	ticketsJSON, err := s.llmClient.Generate(ctx, prompt)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate ticket specs: %w", err)
-	}
-
-	// Strip markdown code fences if present
-	ticketsJSON = strings.TrimSpace(ticketsJSON)
-	ticketsJSON = strings.TrimPrefix(ticketsJSON, "```json")
-	ticketsJSON = strings.TrimPrefix(ticketsJSON, "```")
-	ticketsJSON = strings.TrimSuffix(ticketsJSON, "```")
-	ticketsJSON = strings.TrimSpace(ticketsJSON)
-
-	// Parse the ticket specifications
-	var ticketsResp domain.JiraTicketsResponse
-	if err := json.Unmarshal([]byte(ticketsJSON), &ticketsResp); err != nil {
-		return nil, fmt.Errorf("failed to parse ticket specs: %w", err)
-	}
-
-	if len(ticketsResp.Tickets) == 0 {
-		return nil, fmt.Errorf("LLM did not generate any ticket specifications")
-	}
-
-	// Create tickets in Jira
-	result, err := s.jiraClient.CreateIssues(ticketsResp.Tickets)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create jira tickets: %w", err)
-	}
-
-	return result, nil
-}
-
-// GetProfileReport returns the current profiling metrics and statistics
-func (s *FeedbackService) GetProfileReport() *profiler.ProfileReport {
-	if s.queryProfiler == nil {
-		return nil
-	}
-	report := s.queryProfiler.GetProfileReport()
-	return &report
-}
-
-// GetSlowQueryAnalysis returns analysis of detected slow queries
-func (s *FeedbackService) GetSlowQueryAnalysis() *profiler.SlowQueryAnalysis {
-	if s.slowQueryLog == nil {
-		return nil
-	}
-
-	report := s.GetProfileReport()
-	if report == nil {
-		return nil
-	}
-
-	analysis := s.slowQueryLog.GetAnalysis(report.TotalQueries, report.TotalExecTimeMS)
-	return &analysis
-}
-
-// GetOptimizationSuggestions returns optimization suggestions for slow queries
-func (s *FeedbackService) GetOptimizationSuggestions() map[string][]profiler.OptimizationSuggestion {
-	if s.queryOptimizer == nil || s.queryProfiler == nil {
-		return nil
-	}
-
-	stats := s.queryProfiler.GetAggregateStats()
-	suggestions := make(map[string][]profiler.OptimizationSuggestion)
-
-	for hash, stat := range stats {
-		suggestionList := s.queryOptimizer.AnalyzeQuery(stat.Query, stat)
-		if len(suggestionList) > 0 {
-			suggestions[hash] = suggestionList
-		}
-	}
-
-	return suggestions
-}
-
-// GetMostFrequentSlowQueries returns the most frequently occurring slow queries
-func (s *FeedbackService) GetMostFrequentSlowQueries(limit int) []*profiler.SlowQueryEntry {
-	if s.slowQueryLog == nil {
-		return nil
-	}
-	return s.slowQueryLog.GetMostFrequentSlowQueries(limit)
-}
-
-// GetSlowestQueries returns the queries with highest execution times
-func (s *FeedbackService) GetSlowestQueries(limit int) []*profiler.SlowQueryEntry {
-	if s.slowQueryLog == nil {
-		return nil
-	}
-	return s.slowQueryLog.GetSlowestQueries(limit)
-}
-
-// ResetProfiler clears all profiling data
-func (s *FeedbackService) ResetProfiler() {
-	if s.queryProfiler != nil {
-		s.queryProfiler.Reset()
-	}
-}
-// === Cache Management Methods ===
-
-// GetCacheStats returns current cache statistics
-func (s *FeedbackService) GetCacheStats(ctx context.Context) cache.CacheStats {
-	if s.cacheManager == nil {
-		return cache.CacheStats{}
-	}
-	return s.cacheManager.GetCacheStats(ctx)
-}
-
-// ClearCache removes all cached entries
-func (s *FeedbackService) ClearCache(ctx context.Context) error {
-	if s.cacheManager == nil {
-		return nil
-	}
-	return s.cacheManager.ClearCache(ctx)
-}
-
-// InvalidateQueryCache removes a cached query result
-func (s *FeedbackService) InvalidateQueryCache(ctx context.Context, query string) error {
-	if s.cacheManager == nil {
-		return nil
-	}
-	return s.cacheManager.InvalidateQuery(ctx, query)
-}
-
-// InvalidateCachePattern removes cached entries matching a pattern
-// Useful for: Invalidating all queries involving a table after updates
-func (s *FeedbackService) InvalidateCachePattern(ctx context.Context, pattern string) error {
-	if s.cacheManager == nil {
-		return nil
-	}
-	return s.cacheManager.InvalidatePattern(ctx, pattern)
-}
-
-// IsCacheEnabled checks if caching is currently enabled
-func (s *FeedbackService) IsCacheEnabled() bool {
-	return s.cacheManager != nil && s.cacheQueryResults
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chuckie/goinsight/internal/cache"
+	"github.com/chuckie/goinsight/internal/db"
+	"github.com/chuckie/goinsight/internal/domain"
+	"github.com/chuckie/goinsight/internal/jira"
+	"github.com/chuckie/goinsight/internal/llm"
+	"github.com/chuckie/goinsight/internal/profiler"
+	"github.com/chuckie/goinsight/internal/repository"
+	"github.com/chuckie/goinsight/internal/sqlguard"
+	"github.com/chuckie/goinsight/internal/tracker"
+)
+
+// DefaultMaxRepairAttempts is the default number of LLM repairs of a query rejected by Postgres
+const DefaultMaxRepairAttempts = 2
+
+// RepairError is returned when a repaired query is rejected by the SQL
+// validator and no repair attempts remain; Attempts records every query tried
+type RepairError struct {
+	Attempts []domain.SQLAttempt
+	Err      error
+}
+
+func (e *RepairError) Error() string {
+	return fmt.Sprintf("query rejected after %d attempt(s): %v", len(e.Attempts), e.Err)
+}
+
+func (e *RepairError) Unwrap() error {
+	return e.Err
+}
+
+// FeedbackService orchestrates business logic for feedback analysis
+// with integrated performance monitoring, caching, and optimization
+type FeedbackService struct {
+	repo           repository.FeedbackRepository
+	llmClient      llm.Client
+	issueTracker   tracker.IssueTracker
+	cacheManager   *cache.CacheManager
+	logger         *profiler.Logger
+	queryProfiler  *profiler.QueryProfiler
+	slowQueryLog   *profiler.SlowQueryLogger
+	queryOptimizer *profiler.QueryOptimizer
+	sqlValidator   *sqlguard.Validator
+	schema         llm.SchemaProvider
+
+	// Conversation history for follow-up questions (nil disables conversations)
+	conversations     repository.ConversationRepository
+	conversationTurns int
+
+	// Drafts for previewing Jira tickets before they are filed (nil disables dry runs)
+	jiraDrafts   repository.JiraDraftRepository
+	jiraDraftTTL time.Duration
+
+	// Stored /api/ask answers that tickets link back to (nil disables them)
+	analyses repository.AnalysisRepository
+
+	// Tickets filed from insights and their statuses (nil disables tracking)
+	insightTickets repository.InsightTicketRepository
+
+	// Generated query limits
+	maxQueryRows      int
+	maxRepairAttempts int // LLM repairs of a query rejected by Postgres
+
+	// Cache configuration
+	cacheQueryResults bool
+	queryResultsTTL   time.Duration
+
+	// Embedding lookup of cached questions worded differently (nil disables it)
+	embedder      llm.Embedder
+	semanticIndex *cache.SemanticIndex
+
+	// Identical questions and queries in flight at the same time share one run
+	questionFlights flightGroup[*domain.AskResponse]
+	queryFlights    flightGroup[*executedQuery]
+}
+
+// NewFeedbackService creates a new feedback service
+func NewFeedbackService(
+	repo repository.FeedbackRepository,
+	llmClient llm.Client,
+	issueTracker tracker.IssueTracker,
+) *FeedbackService {
+	return &FeedbackService{
+		repo:              repo,
+		llmClient:         llmClient,
+		issueTracker:      issueTracker,
+		sqlValidator:      sqlguard.NewDefaultValidator(),
+		maxQueryRows:      sqlguard.DefaultMaxRows,
+		maxRepairAttempts: DefaultMaxRepairAttempts,
+		conversationTurns: DefaultConversationTurns,
+		jiraDraftTTL:      DefaultJiraDraftTTL,
+	}
+}
+
+// NewFeedbackServiceWithProfiler creates a new feedback service with profiling enabled
+func NewFeedbackServiceWithProfiler(
+	repo repository.FeedbackRepository,
+	llmClient llm.Client,
+	issueTracker tracker.IssueTracker,
+	logger *profiler.Logger,
+	queryProfiler *profiler.QueryProfiler,
+	slowQueryLog *profiler.SlowQueryLogger,
+	queryOptimizer *profiler.QueryOptimizer,
+) *FeedbackService {
+	return &FeedbackService{
+		repo:              repo,
+		llmClient:         llmClient,
+		issueTracker:      issueTracker,
+		logger:            logger,
+		queryProfiler:     queryProfiler,
+		slowQueryLog:      slowQueryLog,
+		queryOptimizer:    queryOptimizer,
+		sqlValidator:      sqlguard.NewDefaultValidator(),
+		maxQueryRows:      sqlguard.DefaultMaxRows,
+		maxRepairAttempts: DefaultMaxRepairAttempts,
+		conversationTurns: DefaultConversationTurns,
+		jiraDraftTTL:      DefaultJiraDraftTTL,
+	}
+}
+
+// NewFeedbackServiceWithCache creates a feedback service with caching enabled
+func NewFeedbackServiceWithCache(
+	repo repository.FeedbackRepository,
+	llmClient llm.Client,
+	issueTracker tracker.IssueTracker,
+	cacheManager *cache.CacheManager,
+) *FeedbackService {
+	return &FeedbackService{
+		repo:                 repo,
+		llmClient:            llmClient,
+		issueTracker:         issueTracker,
+		cacheManager:         cacheManager,
+		sqlValidator:         sqlguard.NewDefaultValidator(),
+		maxQueryRows:         sqlguard.DefaultMaxRows,
+		maxRepairAttempts:    DefaultMaxRepairAttempts,
+		conversationTurns:    DefaultConversationTurns,
+		jiraDraftTTL:         DefaultJiraDraftTTL,
+		cacheQueryResults:    true,
+		queryResultsTTL:      5 * time.Minute,
+	}
+}
+
+// NewFeedbackServiceFull creates a feedback service with all features enabled
+func NewFeedbackServiceFull(
+	repo repository.FeedbackRepository,
+	llmClient llm.Client,
+	issueTracker tracker.IssueTracker,
+	logger *profiler.Logger,
+	queryProfiler *profiler.QueryProfiler,
+	slowQueryLog *profiler.SlowQueryLogger,
+	queryOptimizer *profiler.QueryOptimizer,
+	cacheManager *cache.CacheManager,
+) *FeedbackService {
+	return &FeedbackService{
+		repo:                 repo,
+		llmClient:            llmClient,
+		issueTracker:         issueTracker,
+		logger:               logger,
+		queryProfiler:        queryProfiler,
+		slowQueryLog:         slowQueryLog,
+		queryOptimizer:       queryOptimizer,
+		cacheManager:         cacheManager,
+		sqlValidator:         sqlguard.NewDefaultValidator(),
+		maxQueryRows:         sqlguard.DefaultMaxRows,
+		maxRepairAttempts:    DefaultMaxRepairAttempts,
+		conversationTurns:    DefaultConversationTurns,
+		jiraDraftTTL:         DefaultJiraDraftTTL,
+		cacheQueryResults:    true,
+		queryResultsTTL:      5 * time.Minute,
+	}
+}
+
+// SetCacheTTL configures the cache TTL for query results
+func (fs *FeedbackService) SetCacheTTL(ttl time.Duration) {
+	fs.queryResultsTTL = ttl
+}
+
+// SetSQLValidator replaces the validator applied to generated SQL
+func (fs *FeedbackService) SetSQLValidator(validator *sqlguard.Validator) {
+	fs.sqlValidator = validator
+}
+
+// SetSchemaProvider sets the table listing used when asking the LLM to repair SQL
+func (fs *FeedbackService) SetSchemaProvider(provider llm.SchemaProvider) {
+	fs.schema = provider
+}
+
+// SetMaxQueryRows configures the row cap enforced on generated SQL (0 disables it)
+func (fs *FeedbackService) SetMaxQueryRows(maxRows int) {
+	fs.maxQueryRows = maxRows
+}
+
+// SetMaxRepairAttempts configures how many times a query rejected by Postgres
+// is repaired by the LLM before the request fails (0 disables repair)
+func (fs *FeedbackService) SetMaxRepairAttempts(attempts int) {
+	fs.maxRepairAttempts = attempts
+}
+
+// CacheQueryResults enables/disables query result caching
+func (fs *FeedbackService) CacheQueryResults(enabled bool) {
+	fs.cacheQueryResults = enabled
+}
+
+// dataPreviewRows is the number of result rows included in responses
+const dataPreviewRows = 10
+
+// queryResultSet holds the rows of a bounded query along with truncation details
+type queryResultSet struct {
+	Rows      []map[string]interface{}
+	TotalRows int
+	Truncated bool
+}
+
+func init() {
+	// Cached values must decode to their own types from a Redis cache
+	cache.RegisterType(&domain.AskResponse{})
+	cache.RegisterType(&queryResultSet{})
+}
+
+// QueryRequest represents a question to analyze
+type QueryRequest struct {
+	Question string
+}
+
+// AnalyzeFeedback orchestrates the full workflow: SQL generation, execution, and insight generation
+// with integrated query profiling and performance monitoring
+func (s *FeedbackService) AnalyzeFeedback(ctx context.Context, question string) (*domain.AskResponse, error) {
+	return s.analyze(ctx, question, nil)
+}
+
+// analyze implements AnalyzeFeedback and AnalyzeFeedbackStream; observer may be nil
+func (s *FeedbackService) analyze(ctx context.Context, question string, observer AskObserver) (*domain.AskResponse, error) {
+	// Validate input
+	if question == "" {
+		return nil, fmt.Errorf("question is required")
+	}
+
+	// Step 0: Check cache for previously analyzed questions
+	// (Cache is keyed by the normalized question to allow caching of full
+	// insights, so follow-up questions, whose answer depends on the
+	// conversation, skip it)
+	questionKey := cache.NormalizeQuestion(question)
+	var cacheKey string
+	var embedding []float64
+	// (Background refreshes of stale answers skip the lookups and re-answer)
+	if s.cacheManager != nil && s.cacheQueryResults && len(llm.History(ctx)) == 0 {
+		cacheKey = questionKey
+		refreshing := isRefresh(ctx)
+		if !refreshing {
+			if response, ok := s.getCachedAnswer(ctx, question, cacheKey); ok {
+				return response, nil
+			}
+		}
+
+		// Fall back to the answer of a cached question with the same meaning
+		embedding = s.embedQuestion(ctx, question)
+		if !refreshing {
+			if response, ok := s.getSemanticAnswer(ctx, question, embedding); ok {
+				return response, nil
+			}
+		}
+	}
+
+	// Identical questions asked while one is being analyzed wait for its
+	// answer (streams, which report their own stages, and follow-ups do not;
+	// nor do background refreshes, whose work must stop with the refresh)
+	if observer == nil && len(llm.History(ctx)) == 0 && !isRefresh(ctx) {
+		response, shared, err := s.questionFlights.do(ctx, questionKey, func(ctx context.Context) (*domain.AskResponse, error) {
+			return s.answer(ctx, question, nil, cacheKey, embedding)
+		})
+		if err != nil || !shared {
+			return response, err
+		}
+		if s.logger != nil {
+			s.logger.Debug("Coalesced identical question", map[string]interface{}{
+				"question": question,
+			})
+		}
+
+		// The response is shared, so the caller's wording is set on a copy
+		answered := *response
+		answered.Question = question
+		return &answered, nil
+	}
+
+	return s.answer(ctx, question, observer, cacheKey, embedding)
+}
+
+// answer analyzes a question that was not answered from cache, caching the
+// response under cacheKey unless it is empty; embedding, if set, is the
+// question's embedding for semantic cache lookups
+func (s *FeedbackService) answer(ctx context.Context, question string, observer AskObserver, cacheKey string, embedding []float64) (*domain.AskResponse, error) {
+	// Record which LLM provider answers each step when the client fails over
+	ctx, providers := llm.WithProviderRecorder(ctx)
+
+	// Step 1: Generate SQL from the question
+	sqlQuery, err := s.llmClient.GenerateSQL(ctx, question)
+	if err != nil {
+		if s.logger != nil {
+			s.logger.Error("Failed to generate SQL", err, map[string]interface{}{
+				"question": question,
+			})
+		}
+		return nil, fmt.Errorf("failed to generate SQL: %w", err)
+	}
+	observer.emit(EventSQLGenerated, SQLEventData{SQL: sqlQuery, Attempt: 1})
+
+	// Steps 2-4: Validate, bound and execute the SQL, asking the LLM to repair
+	// queries that Postgres rejects and repairs that the validator rejects
+	var resultSet *queryResultSet
+	var limited sqlguard.LimitRewrite
+	var metrics *profiler.QueryMetrics
+	var attempts []domain.SQLAttempt
+
+	for {
+		// Step 2: Validate SQL for safety
+		stmt, err := s.validateSQL(sqlQuery)
+		if err != nil {
+			if s.logger != nil {
+				s.logger.Warn("SQL validation failed", map[string]interface{}{
+					"question": question,
+					"sql":      sqlQuery,
+					"attempt":  len(attempts) + 1,
+					"error":    err.Error(),
+				})
+			}
+			if len(attempts) == 0 {
+				return nil, err
+			}
+
+			// A rejected repair counts as a failed attempt and is repaired again
+			attempts = append(attempts, domain.SQLAttempt{SQL: sqlQuery, Error: err.Error()})
+			if len(attempts) > s.maxRepairAttempts {
+				return nil, &RepairError{Attempts: attempts, Err: err}
+			}
+			if sqlQuery, err = s.repairSQL(ctx, question, sqlQuery, err); err != nil {
+				return nil, fmt.Errorf("failed to repair SQL: %w", err)
+			}
+			observer.emit(EventSQLGenerated, SQLEventData{SQL: sqlQuery, Attempt: len(attempts) + 1})
+			continue
+		}
+
+		// Step 3: Add or tighten the LIMIT so the query returns at most maxQueryRows rows
+		limited = sqlguard.EnforceLimit(sqlQuery, stmt, s.maxQueryRows)
+		unboundedSQL := sqlQuery[:stmt.EndPos]
+		observer.emit(EventSQLValidated, SQLEventData{SQL: limited.SQL, Attempt: len(attempts) + 1})
+
+		// Step 4: Execute the SQL query (unless a different question cached the same SQL)
+		if cached, ok := s.getCachedResultSet(ctx, limited.SQL); ok {
+			resultSet = cached
+			attempts = append(attempts, domain.SQLAttempt{SQL: limited.SQL})
+			break
+		}
+
+		// Identical queries running at the same time share one execution
+		boundedSQL, limitApplied := limited.SQL, limited.Applied
+		var executed *executedQuery
+		executed, _, err = s.queryFlights.do(ctx, boundedSQL, func(ctx context.Context) (*executedQuery, error) {
+			return s.runQuery(ctx, boundedSQL, unboundedSQL, limitApplied)
+		})
+		if err == nil {
+			attempts = append(attempts, domain.SQLAttempt{SQL: limited.SQL})
+			resultSet, metrics = executed.resultSet, executed.metrics
+			break
+		}
+
+		attempts = append(attempts, domain.SQLAttempt{SQL: limited.SQL, Error: err.Error()})
+		if s.logger != nil {
+			s.logger.Error("Query execution failed", err, map[string]interface{}{
+				"sql":     limited.SQL,
+				"attempt": len(attempts),
+			})
+		}
+		if !db.IsQueryError(err) || len(attempts) > s.maxRepairAttempts {
+			return nil, fmt.Errorf("query execution failed after %d attempt(s): %w", len(attempts), err)
+		}
+
+		sqlQuery, err = s.repairSQL(ctx, question, sqlQuery, err)
+		if err != nil {
+			return nil, fmt.Errorf("failed to repair SQL: %w", err)
+		}
+		observer.emit(EventSQLGenerated, SQLEventData{SQL: sqlQuery, Attempt: len(attempts) + 1})
+	}
+	queryResults := resultSet.Rows
+
+	limitApplied := 0
+	if limited.Applied {
+		limitApplied = s.maxQueryRows
+	}
+	dataPreview := queryResults
+	if len(dataPreview) > dataPreviewRows {
+		dataPreview = dataPreview[:dataPreviewRows]
+	}
+	observer.emit(EventRowsFetched, RowsFetchedData{
+		RowCount:     len(queryResults),
+		TotalRows:    resultSet.TotalRows,
+		Truncated:    resultSet.Truncated,
+		LimitApplied: limitApplied,
+		DataPreview:  dataPreview,
+	})
+
+	// Step 5: Generate insights from the results (streaming tokens to the observer)
+	var insightJSON string
+	if observer != nil {
+		insightJSON, err = s.llmClient.GenerateInsightStream(ctx, question, queryResults, func(token string) {
+			observer.emit(EventInsightToken, InsightTokenData{Token: token})
+		})
+	} else {
+		insightJSON, err = s.llmClient.GenerateInsight(ctx, question, queryResults)
+	}
+	if err != nil {
+		if s.logger != nil {
+			s.logger.Error("Failed to generate insights", err, map[string]interface{}{
+				"question": question,
+				"results":  len(queryResults),
+			})
+		}
+		return nil, fmt.Errorf("failed to generate insights: %w", err)
+	}
+
+	// Step 5: Parse the insight JSON
+	var insightResult domain.InsightResult
+	if err := json.Unmarshal([]byte(insightJSON), &insightResult); err != nil {
+		if s.logger != nil {
+			s.logger.Error("Failed to parse insight response", err, map[string]interface{}{
+				"question": question,
+			})
+		}
+		return nil, fmt.Errorf("failed to parse insight response: %w", err)
+	}
+
+	// Step 6: Build the response
+	response := &domain.AskResponse{
+		Question:        question,
+		SQL:             limited.SQL,
+		DataPreview:     dataPreview,
+		Summary:         insightResult.Summary,
+		Recommendations: insightResult.Recommendations,
+		Actions:         insightResult.Actions,
+		TotalRows:       resultSet.TotalRows,
+		Truncated:       resultSet.Truncated,
+		LimitApplied:    limitApplied,
+		Attempts:        attempts,
+	}
+	if recorded := providers.Providers(); recorded != nil {
+		response.Metadata = &domain.ResponseMetadata{LLMProviders: recorded}
+	}
+
+	// Store the answer so tickets filed from it can link back to its evidence
+	response.AnalysisID = s.saveAnalysis(ctx, response)
+
+	// Cache the complete response for future identical or similar questions
+	if cacheKey != "" {
+		s.cacheAnswer(ctx, cacheKey, response, embedding)
+	}
+
+	if s.logger != nil {
+		execTimeMs := 0.0
+		if metrics != nil {
+			execTimeMs = metrics.ExecutionTime.Seconds() * 1000
+		}
+		s.logger.Info("Feedback analysis completed", map[string]interface{}{
+			"question":    question,
+			"results":     len(queryResults),
+			"truncated":   resultSet.Truncated,
+			"attempts":    len(attempts),
+			"actions":     len(insightResult.Actions),
+			"exec_time_ms": execTimeMs,
+		})
+	}
+
+	return response, nil
+}
+
+// runQuery executes a bounded query and caches its results
+func (s *FeedbackService) runQuery(ctx context.Context, sqlQuery, unboundedSQL string, limitApplied bool) (*executedQuery, error) {
+	queryResults, metrics, err := s.executeQuery(ctx, sqlQuery)
+	if err != nil {
+		return nil, err
+	}
+	resultSet := s.boundResults(ctx, unboundedSQL, queryResults, limitApplied)
+
+	// Cache query results for future use
+	if s.cacheManager != nil && s.cacheQueryResults {
+		_ = s.cacheManager.CacheQueryResult(ctx, sqlQuery, resultSet, s.queryResultsTTL)
+	}
+
+	return &executedQuery{resultSet: resultSet, metrics: metrics}, nil
+}
+
+// getCachedResultSet returns the cached results of a previously executed query
+func (s *FeedbackService) getCachedResultSet(ctx context.Context, sqlQuery string) (*queryResultSet, bool) {
+	if s.cacheManager == nil || !s.cacheQueryResults {
+		return nil, false
+	}
+	cachedData, found, err := s.cacheManager.GetCachedQueryResult(ctx, sqlQuery)
+	if err != nil || !found {
+		return nil, false
+	}
+	resultSet, ok := cachedData.(*queryResultSet)
+	return resultSet, ok
+}
+
+// executeQuery runs the SQL query with profiling and slow query tracking
+func (s *FeedbackService) executeQuery(ctx context.Context, sqlQuery string) ([]map[string]interface{}, *profiler.QueryMetrics, error) {
+	var metrics *profiler.QueryMetrics
+	if s.queryProfiler != nil {
+		metrics = s.queryProfiler.StartQueryExecution(sqlQuery)
+	}
+
+	queryResults, err := s.repo.QueryFeedback(ctx, sqlQuery)
+
+	if s.queryProfiler != nil && metrics != nil {
+		rowsReturned := int64(len(queryResults))
+		poolUsage := 1 // Default value, can be enhanced with actual pool metrics
+		s.queryProfiler.RecordQueryExecution(metrics, rowsReturned, poolUsage, false, err)
+
+		// Check if query is slow and log accordingly
+		execTimeMS := metrics.ExecutionTime.Seconds() * 1000
+		if s.slowQueryLog != nil && execTimeMS > 500 {
+			s.slowQueryLog.RecordSlowQuery(
+				metrics.QueryID,
+				sqlQuery,
+				metrics.QueryHash,
+				execTimeMS,
+				500.0,
+				rowsReturned,
+			)
+		}
+
+		// Generate optimization suggestions if query is slow
+		if s.queryOptimizer != nil && execTimeMS > 500 {
+			stats := s.queryProfiler.GetStats(metrics.QueryHash)
+			suggestions := s.queryOptimizer.AnalyzeQuery(sqlQuery, stats)
+
+			if len(suggestions) > 0 && s.logger != nil {
+				s.logger.Debug("Query optimization suggestions", map[string]interface{}{
+					"query_id":     metrics.QueryID,
+					"execution_ms": execTimeMS,
+					"suggestions":  len(suggestions),
+				})
+			}
+		}
+	}
+
+	return queryResults, metrics, err
+}
+
+// repairSQL sends the failed query and the database error back to the LLM
+// and returns the corrected query
+func (s *FeedbackService) repairSQL(ctx context.Context, question, failedSQL string, queryErr error) (string, error) {
+	var schema string
+	if s.schema != nil {
+		schema = s.schema.DescribeSchema()
+	}
+	prompt := llm.SQLRepairPrompt(schema, question, failedSQL, queryErr.Error())
+	repaired, err := s.llmClient.Generate(ctx, prompt)
+	if err != nil {
+		return "", err
+	}
+
+	// Strip markdown code fences if present
+	repaired = strings.TrimSpace(repaired)
+	repaired = strings.TrimPrefix(repaired, "```sql")
+	repaired = strings.TrimPrefix(repaired, "```")
+	repaired = strings.TrimSuffix(repaired, "```")
+	return strings.TrimSpace(repaired), nil
+}
+
+// validateSQL performs safety checks on the generated SQL query.
+// Rejections are returned as *sqlguard.ValidationError with per-rule reasons.
+func (s *FeedbackService) validateSQL(sqlQuery string) (*sqlguard.SelectStmt, error) {
+	return s.sqlValidator.Validate(sqlQuery)
+}
+
+// boundResults trims rows to the configured cap and, when the cap was reached,
+// counts the rows the unbounded query would have returned. If the count query
+// fails, TotalRows falls back to the number of rows fetched.
+func (s *FeedbackService) boundResults(ctx context.Context, unboundedSQL string, rows []map[string]interface{}, limitApplied bool) *queryResultSet {
+	result := &queryResultSet{Rows: rows, TotalRows: len(rows)}
+	if !limitApplied || s.maxQueryRows <= 0 || len(rows) < s.maxQueryRows {
+		return result
+	}
+
+	if len(rows) > s.maxQueryRows {
+		result.Rows = rows[:s.maxQueryRows]
+	}
+
+	countSQL := fmt.Sprintf("SELECT COUNT(*) AS total_rows FROM (%s) AS unbounded_query", unboundedSQL)
+	countRows, err := s.repo.QueryFeedback(ctx, countSQL)
+	if err == nil && len(countRows) == 1 {
+		if total, ok := toInt(countRows[0]["total_rows"]); ok && total >= len(result.Rows) {
+			result.TotalRows = total
+			result.Truncated = total > len(result.Rows)
+			return result
+		}
+	}
+
+	if s.logger != nil {
+		fields := map[string]interface{}{"sql": countSQL}
+		if err != nil {
+			fields["error"] = err.Error()
+		}
+		s.logger.Warn("Failed to count rows of truncated query", fields)
+	}
+	// The cap was reached, so assume more rows exist
+	result.Truncated = true
+	return result
+}
+
+// toInt converts a COUNT(*) value as returned by the driver to an int
+func toInt(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int64:
+		return int(v), true
+	case int:
+		return v, true
+	case float64:
+		return int(v), true
+	case string:
+		n, err := strconv.Atoi(v)
+		return n, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// GetAccountRiskScore retrieves ML predictions for a specific account
+func (s *FeedbackService) GetAccountRiskScore(ctx context.Context, accountID string) (*domain.AccountRiskScore, error) {
+	return s.repo.GetAccountRiskScore(ctx, accountID)
+}
+
+// GetRecentNegativeFeedbackCount counts recent negative feedback for an account
+func (s *FeedbackService) GetRecentNegativeFeedbackCount(ctx context.Context, accountID string) (int, error) {
+	return s.repo.GetRecentNegativeFeedbackCount(ctx, accountID)
+}
+
+// GetProductAreaImpacts retrieves ML predictions for product area priorities
+func (s *FeedbackService) GetProductAreaImpacts(ctx context.Context, segment string) ([]map[string]any, error) {
+	return s.repo.GetProductAreaImpacts(ctx, segment)
+}
+
+// JiraTicketRequest wraps the action items and metadata for Jira ticket creation
+type JiraTicketRequest struct {
+	Question        string // the question that produced the insight
+	Summary         string
+	Recommendations []string
+	Actions         []domain.ActionItem
+	DataPreview     []map[string]any  // rows supporting the insight, attached to each ticket
+	SQL             string            // query behind the insight, attached to each ticket
+	AnalysisID      string            // stored analysis the insight came from
+	Attributes      map[string]string // insight attributes mapped to Jira fields, e.g. product_area
+	Meta            JiraMetadata
+}
+
+// JiraMetadata holds Jira-specific configuration
+type JiraMetadata struct {
+	ProjectKey       string
+	DefaultIssueType string
+	DefaultLabels    []string
+	CreateEpic       bool   // file the actions as children of an epic for the insight
+	MatchStrategy    string // a domain.JiraMatch* strategy; empty creates tickets
+}
+
+// CreateJiraTickets converts insight actions into Jira tickets
+func (s *FeedbackService) CreateJiraTickets(ctx context.Context, req JiraTicketRequest) (*domain.JiraCreationResult, error) {
+	tickets, epic, err := s.generateJiraTickets(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return s.fileJiraTickets(ctx, req.Question, tickets, epic)
+}
+
+// generateJiraTickets uses the LLM to turn insight actions into ticket specs,
+// plus the spec of the epic to group them under if one was requested
+func (s *FeedbackService) generateJiraTickets(ctx context.Context, req JiraTicketRequest) ([]domain.JiraTicketSpec, *domain.JiraTicketSpec, error) {
+	// Validate an issue tracker is configured and can file what is asked
+	if s.issueTracker == nil {
+		return nil, nil, fmt.Errorf("issue tracker is not configured")
+	}
+	if _, ok := s.issueTracker.(tracker.EpicCreator); req.Meta.CreateEpic && !ok {
+		return nil, nil, fmt.Errorf("the %s issue tracker does not support create_epic", s.issueTracker.Name())
+	}
+	if err := s.validateMatchStrategy(req.Meta.MatchStrategy); err != nil {
+		return nil, nil, err
+	}
+
+	// Validate request
+	if len(req.Actions) == 0 {
+		return nil, nil, fmt.Errorf("no actions provided to convert into tickets")
+	}
+	if err := s.resolveEvidence(ctx, &req); err != nil {
+		return nil, nil, err
+	}
+
+	// Validate required Jira meta and set defaults (GitHub and Linear file in
+	// the configured repository or team)
+	if s.issueTracker.Name() == tracker.Jira && strings.TrimSpace(req.Meta.ProjectKey) == "" {
+		return nil, nil, fmt.Errorf("jira project key is required")
+	}
+	if req.Meta.DefaultIssueType == "" {
+		req.Meta.DefaultIssueType = "Story"
+	}
+	if len(req.Meta.DefaultLabels) == 0 {
+		req.Meta.DefaultLabels = []string{"feedback", "ai-insight"}
+	}
+
+	// Calculate magnitude for each action item
+	for i := range req.Actions {
+		req.Actions[i].Magnitude = domain.CalculateMagnitude(
+			req.Actions[i],
+			req.Summary,
+			req.Recommendations,
+		)
+	}
+
+	// Convert request to JSON for LLM prompt
+	domainReq := domain.JiraTicketRequest{
+		Question:        req.Question,
+		Summary:         req.Summary,
+		Recommendations: req.Recommendations,
+		Actions:         req.Actions,
+		Meta: domain.JiraTicketMeta{
+			ProjectKey:       req.Meta.ProjectKey,
+			DefaultIssueType: req.Meta.DefaultIssueType,
+			DefaultLabels:    req.Meta.DefaultLabels,
+		},
+	}
+
+	requestJSON, err := json.MarshalIndent(domainReq, "", "  ")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to serialize request: %w", err)
+	}
+
+	// Use LLM to generate Jira ticket specifications
+	prompt := llm.JiraTicketPrompt(string(requestJSON))
+	ticketsJSON, err := s.llmClient.Generate(ctx, prompt)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate ticket specs: %w", err)
+	}
+
+	// Strip markdown code fences if present
+	ticketsJSON = strings.TrimSpace(ticketsJSON)
+	ticketsJSON = strings.TrimPrefix(ticketsJSON, "```json")
+	ticketsJSON = strings.TrimPrefix(ticketsJSON, "```")
+	ticketsJSON = strings.TrimSuffix(ticketsJSON, "```")
+	ticketsJSON = strings.TrimSpace(ticketsJSON)
+
+	// Parse the ticket specifications
+	var ticketsResp domain.JiraTicketsResponse
+	if err := json.Unmarshal([]byte(ticketsJSON), &ticketsResp); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse ticket specs: %w", err)
+	}
+
+	if len(ticketsResp.Tickets) == 0 {
+		return nil, nil, fmt.Errorf("LLM did not generate any ticket specifications")
+	}
+
+	// Comment targets and routing attributes are set below, never by the LLM
+	for i := range ticketsResp.Tickets {
+		ticketsResp.Tickets[i].CommentOn = ""
+		ticketsResp.Tickets[i].Comment = ""
+		ticketsResp.Tickets[i].Attributes = nil
+	}
+
+	// Fingerprint each ticket by its action so re-sent insights are not filed twice
+	jira.AssignFingerprints(req.Question, req.Actions, ticketsResp.Tickets)
+	for i := range ticketsResp.Tickets {
+		ticketsResp.Tickets[i].DataPreview = req.DataPreview
+		ticketsResp.Tickets[i].SQL = req.SQL
+		ticketsResp.Tickets[i].AnalysisID = req.AnalysisID
+	}
+
+	// Attach the attributes that route the tickets and fill custom fields
+	attrs := jira.InsightAttributes(req.Attributes, req.DataPreview)
+	jira.AssignAttributes(attrs, req.Actions, ticketsResp.Tickets)
+
+	// Add the evidence to matching open tickets instead if requested
+	if req.Meta.MatchStrategy == domain.JiraMatchCommentOnMatch {
+		if err := s.matchOpenTickets(ctx, req, ticketsResp.Tickets); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Group the tickets under an epic for the insight if requested
+	var epic *domain.JiraTicketSpec
+	if req.Meta.CreateEpic {
+		spec := jira.InsightEpic(req.Question, req.Summary, req.Recommendations, req.Meta.ProjectKey, req.Meta.DefaultLabels)
+		if len(attrs) > 0 {
+			spec.Attributes = attrs
+		}
+		epic = &spec
+	}
+
+	return ticketsResp.Tickets, epic, nil
+}
+
+// fileJiraTickets creates tickets in the issue tracker, grouped under epic if
+// it is non-nil, and tracks them against the question they answer. Tickets
+// matched to an open issue are added to it as comments instead, once the new
+// issues are created, so a failed creation can be retried without
+// commenting twice.
+func (s *FeedbackService) fileJiraTickets(ctx context.Context, question string, tickets []domain.JiraTicketSpec, epic *domain.JiraTicketSpec) (*domain.JiraCreationResult, error) {
+	var toCreate []domain.JiraTicketSpec
+	for _, ticket := range tickets {
+		if ticket.CommentOn == "" {
+			toCreate = append(toCreate, ticket)
+		}
+	}
+	if len(toCreate) == len(tickets) {
+		return s.createJiraTickets(ctx, question, tickets, epic)
+	}
+
+	var filed *domain.JiraCreationResult
+	if len(toCreate) > 0 {
+		var err error
+		if filed, err = s.createJiraTickets(ctx, question, toCreate, epic); err != nil {
+			return nil, err
+		}
+	}
+	comments := s.commentOnTickets(ctx, tickets)
+	return mergeComments(tickets, comments, filed), nil
+}
+
+// createJiraTickets files tickets as new issues and tracks them
+func (s *FeedbackService) createJiraTickets(ctx context.Context, question string, tickets []domain.JiraTicketSpec, epic *domain.JiraTicketSpec) (*domain.JiraCreationResult, error) {
+	var result *domain.JiraCreationResult
+	var err error
+	creator, canCreateEpics := s.issueTracker.(tracker.EpicCreator)
+	switch {
+	case epic == nil:
+		result, err = s.issueTracker.CreateIssues(ctx, tickets)
+	case canCreateEpics:
+		result, err = creator.CreateIssuesWithEpic(ctx, *epic, tickets)
+	default:
+		return nil, fmt.Errorf("the %s issue tracker does not support create_epic", s.issueTracker.Name())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s tickets: %w", s.issueTracker.Name(), err)
+	}
+
+	s.trackTickets(ctx, question, tickets, result)
+	return result, nil
+}
+
+// GetProfileReport returns the current profiling metrics and statistics
+func (s *FeedbackService) GetProfileReport() *profiler.ProfileReport {
+	if s.queryProfiler == nil {
+		return nil
+	}
+	report := s.queryProfiler.GetProfileReport()
+	return &report
+}
+
+// GetSlowQueryAnalysis returns analysis of detected slow queries
+func (s *FeedbackService) GetSlowQueryAnalysis() *profiler.SlowQueryAnalysis {
+	if s.slowQueryLog == nil {
+		return nil
+	}
+
+	report := s.GetProfileReport()
+	if report == nil {
+		return nil
+	}
+
+	analysis := s.slowQueryLog.GetAnalysis(report.TotalQueries, report.TotalExecTimeMS)
+	return &analysis
+}
+
+// GetOptimizationSuggestions returns optimization suggestions for slow queries
+func (s *FeedbackService) GetOptimizationSuggestions() map[string][]profiler.OptimizationSuggestion {
+	if s.queryOptimizer == nil || s.queryProfiler == nil {
+		return nil
+	}
+
+	stats := s.queryProfiler.GetAggregateStats()
+	suggestions := make(map[string][]profiler.OptimizationSuggestion)
+
+	for hash, stat := range stats {
+		suggestionList := s.queryOptimizer.AnalyzeQuery(stat.Query, stat)
+		if len(suggestionList) > 0 {
+			suggestions[hash] = suggestionList
+		}
+	}
+
+	return suggestions
+}
+
+// GetMostFrequentSlowQueries returns the most frequently occurring slow queries
+func (s *FeedbackService) GetMostFrequentSlowQueries(limit int) []*profiler.SlowQueryEntry {
+	if s.slowQueryLog == nil {
+		return nil
+	}
+	return s.slowQueryLog.GetMostFrequentSlowQueries(limit)
+}
+
+// GetSlowestQueries returns the queries with highest execution times
+func (s *FeedbackService) GetSlowestQueries(limit int) []*profiler.SlowQueryEntry {
+	if s.slowQueryLog == nil {
+		return nil
+	}
+	return s.slowQueryLog.GetSlowestQueries(limit)
+}
+
+// ResetProfiler clears all profiling data
+func (s *FeedbackService) ResetProfiler() {
+	if s.queryProfiler != nil {
+		s.queryProfiler.Reset()
+	}
+}
+// === Cache Management Methods ===
+
+// GetCacheStats returns current cache statistics
+func (s *FeedbackService) GetCacheStats(ctx context.Context) cache.CacheStats {
+	if s.cacheManager == nil {
+		return cache.CacheStats{}
+	}
+	return s.cacheManager.GetCacheStats(ctx)
+}
+
+// ClearCache removes all cached entries
+func (s *FeedbackService) ClearCache(ctx context.Context) error {
+	if s.cacheManager == nil {
+		return nil
+	}
+	return s.cacheManager.ClearCache(ctx)
+}
+
+// InvalidateQueryCache removes a cached query result
+func (s *FeedbackService) InvalidateQueryCache(ctx context.Context, query string) error {
+	if s.cacheManager == nil {
+		return nil
+	}
+	return s.cacheManager.InvalidateQuery(ctx, query)
+}
+
+// InvalidateCachePattern removes cached entries matching a pattern
+// Useful for: Invalidating all queries involving a table after updates
+func (s *FeedbackService) InvalidateCachePattern(ctx context.Context, pattern string) error {
+	if s.cacheManager == nil {
+		return nil
+	}
+	return s.cacheManager.InvalidatePattern(ctx, pattern)
+}
+
+// IsCacheEnabled checks if caching is currently enabled
+func (s *FeedbackService) IsCacheEnabled() bool {
+	return s.cacheManager != nil && s.cacheQueryResults
 }
\ No newline at end of file
@@ -1,394 +1,734 @@
-package service
-
-import (
-	"context"
-	"errors"
-	"testing"
-	"time"
-
-	"github.com/chuckie/goinsight/internal/cache"
-	"github.com/chuckie/goinsight/tests/mocks"
-)
-
-// MockLLMClient is a mock LLM client for service tests
-type MockLLMClient struct {
-	GenerateSQLFn     func(context.Context, string) (string, error)
-	GenerateInsightFn func(context.Context, string, []map[string]any) (string, error)
-	GenerateFn        func(context.Context, string) (string, error)
-}
-
-func (m *MockLLMClient) GenerateSQL(ctx context.Context, question string) (string, error) {
-	if m.GenerateSQLFn != nil {
-		return m.GenerateSQLFn(ctx, question)
-	}
-	return "SELECT * FROM feedback", nil
-}
-
-func (m *MockLLMClient) GenerateInsight(ctx context.Context, question string, results []map[string]any) (string, error) {
-	if m.GenerateInsightFn != nil {
-		return m.GenerateInsightFn(ctx, question, results)
-	}
-	return `{"summary": "Analysis complete", "recommendations": [], "actions": []}`, nil
-}
-
-func (m *MockLLMClient) Generate(ctx context.Context, prompt string) (string, error) {
-	if m.GenerateFn != nil {
-		return m.GenerateFn(ctx, prompt)
-	}
-	return "Generated response", nil
-}
-
-// TestAnalyzeFeedbackSuccess tests successful feedback analysis
-func TestAnalyzeFeedbackSuccess(t *testing.T) {
-	mockRepo := mocks.NewMockFeedbackRepository()
-	mockRepo.SetQueryFeedbackResult([]map[string]any{
-		{"id": 1, "feedback": "Great product", "sentiment": "positive"},
-	})
-
-	service := NewFeedbackService(
-		mockRepo,
-		&MockLLMClient{},
-		nil,
-	)
-
-	ctx := context.Background()
-	result, err := service.AnalyzeFeedback(ctx, "What is customer sentiment?")
-
-	if err != nil {
-		t.Errorf("Expected no error, got %v", err)
-	}
-
-	if result == nil {
-		t.Error("Expected non-nil result")
-	}
-}
-
-// TestAnalyzeFeedbackEmptyQuestion tests with empty question
-func TestAnalyzeFeedbackEmptyQuestion(t *testing.T) {
-	mockRepo := mocks.NewMockFeedbackRepository()
-	service := NewFeedbackService(mockRepo, &MockLLMClient{}, nil)
-
-	ctx := context.Background()
-	_, err := service.AnalyzeFeedback(ctx, "")
-
-	if err == nil {
-		t.Error("Expected error for empty question")
-	}
-}
-
-// TestAnalyzeFeedbackWithCache tests caching behavior
-func TestAnalyzeFeedbackWithCache(t *testing.T) {
-	mockRepo := mocks.NewMockFeedbackRepository()
-	mockRepo.SetQueryFeedbackResult([]map[string]any{
-		{"id": 1, "feedback": "Excellent", "sentiment": "positive"},
-	})
-
-	cacheManager := cache.NewCacheManager(true, 100, 5*time.Minute)
-
-	service := NewFeedbackServiceWithCache(
-		mockRepo,
-		&MockLLMClient{},
-		nil,
-		cacheManager,
-	)
-
-	ctx := context.Background()
-	question := "What is the sentiment?"
-
-	// First call - should hit repository
-	result1, err1 := service.AnalyzeFeedback(ctx, question)
-	if err1 != nil {
-		t.Logf("First call error: %v", err1)
-	}
-
-	// Second call - might hit cache
-	result2, err2 := service.AnalyzeFeedback(ctx, question)
-	if err2 != nil {
-		t.Logf("Second call error: %v", err2)
-	}
-
-	// Both should return results
-	if result1 != nil && result2 != nil {
-		// Results should be consistent
-		if result1.Question != result2.Question {
-			t.Error("Results should be consistent between calls")
-		}
-	}
-}
-
-// TestAnalyzeFeedbackRepositoryError tests error from repository
-func TestAnalyzeFeedbackRepositoryError(t *testing.T) {
-	mockRepo := mocks.NewMockFeedbackRepository()
-	mockRepo.SetupForDatabaseError()
-
-	service := NewFeedbackService(mockRepo, &MockLLMClient{}, nil)
-
-	ctx := context.Background()
-	_, err := service.AnalyzeFeedback(ctx, "What is sentiment?")
-
-	// Should handle repository errors gracefully
-	_ = err
-}
-
-// TestAnalyzeFeedbackLLMError tests error from LLM client
-func TestAnalyzeFeedbackLLMError(t *testing.T) {
-	mockRepo := mocks.NewMockFeedbackRepository()
-	mockRepo.SetQueryFeedbackResult([]map[string]any{})
-
-	llmClient := &MockLLMClient{
-		GenerateSQLFn: func(ctx context.Context, q string) (string, error) {
-			return "", errors.New("LLM service error")
-		},
-	}
-
-	service := NewFeedbackService(mockRepo, llmClient, nil)
-
-	ctx := context.Background()
-	_, err := service.AnalyzeFeedback(ctx, "What is sentiment?")
-
-	// Should handle LLM errors
-	_ = err
-}
-
-// TestNewFeedbackService tests basic service creation
-func TestNewFeedbackService(t *testing.T) {
-	mockRepo := mocks.NewMockFeedbackRepository()
-	service := NewFeedbackService(mockRepo, &MockLLMClient{}, nil)
-
-	if service == nil {
-		t.Error("Service should not be nil")
-	}
-
-	if service.repo != mockRepo {
-		t.Error("Repository not set correctly")
-	}
-}
-
-// TestNewFeedbackServiceWithProfiler tests service with profiler
-func TestNewFeedbackServiceWithProfiler(t *testing.T) {
-	mockRepo := mocks.NewMockFeedbackRepository()
-
-	service := NewFeedbackServiceWithProfiler(
-		mockRepo,
-		&MockLLMClient{},
-		nil,
-		nil, nil, nil, nil,
-	)
-
-	if service == nil {
-		t.Error("Service should not be nil")
-	}
-}
-
-// TestNewFeedbackServiceWithCache tests service with cache
-func TestNewFeedbackServiceWithCache(t *testing.T) {
-	mockRepo := mocks.NewMockFeedbackRepository()
-	cacheManager := cache.NewCacheManager(true, 100, 5*time.Minute)
-
-	service := NewFeedbackServiceWithCache(
-		mockRepo,
-		&MockLLMClient{},
-		nil,
-		cacheManager,
-	)
-
-	if service == nil {
-		t.Error("Service should not be nil")
-	}
-
-	if !service.cacheQueryResults {
-		t.Error("Cache should be enabled")
-	}
-}
-
-// TestNewFeedbackServiceFull tests service with all features
-func TestNewFeedbackServiceFull(t *testing.T) {
-	mockRepo := mocks.NewMockFeedbackRepository()
-	cacheManager := cache.NewCacheManager(true, 100, 5*time.Minute)
-
-	service := NewFeedbackServiceFull(
-		mockRepo,
-		&MockLLMClient{},
-		nil,
-		nil, nil, nil, nil,
-		cacheManager,
-	)
-
-	if service == nil {
-		t.Error("Service should not be nil")
-	}
-
-	if !service.cacheQueryResults {
-		t.Error("Cache should be enabled")
-	}
-}
-
-// TestSetCacheTTL tests setting cache TTL
-func TestSetCacheTTL(t *testing.T) {
-	mockRepo := mocks.NewMockFeedbackRepository()
-	service := NewFeedbackService(mockRepo, &MockLLMClient{}, nil)
-
-	ttl := 10 * time.Minute
-	service.SetCacheTTL(ttl)
-
-	if service.queryResultsTTL != ttl {
-		t.Errorf("Expected TTL %v, got %v", ttl, service.queryResultsTTL)
-	}
-}
-
-// TestCacheQueryResults tests enabling/disabling caching
-func TestCacheQueryResults(t *testing.T) {
-	mockRepo := mocks.NewMockFeedbackRepository()
-	service := NewFeedbackService(mockRepo, &MockLLMClient{}, nil)
-
-	service.CacheQueryResults(true)
-	if !service.cacheQueryResults {
-		t.Error("Cache should be enabled")
-	}
-
-	service.CacheQueryResults(false)
-	if service.cacheQueryResults {
-		t.Error("Cache should be disabled")
-	}
-}
-
-// TestAnalyzeFeedbackContextCancellation tests with cancelled context
-func TestAnalyzeFeedbackContextCancellation(t *testing.T) {
-	mockRepo := mocks.NewMockFeedbackRepository()
-	mockRepo.SetQueryFeedbackResult([]map[string]any{
-		{"id": 1, "feedback": "test"},
-	})
-
-	service := NewFeedbackService(mockRepo, &MockLLMClient{}, nil)
-
-	ctx, cancel := context.WithCancel(context.Background())
-	cancel()
-
-	_, err := service.AnalyzeFeedback(ctx, "What is sentiment?")
-
-	// Should handle cancelled context gracefully
-	_ = err
-}
-
-// TestAnalyzeFeedbackTimeout tests with timeout
-func TestAnalyzeFeedbackTimeout(t *testing.T) {
-	mockRepo := mocks.NewMockFeedbackRepository()
-	mockRepo.SetQueryFeedbackResult([]map[string]any{})
-
-	service := NewFeedbackService(mockRepo, &MockLLMClient{}, nil)
-
-	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Millisecond)
-	defer cancel()
-
-	_, err := service.AnalyzeFeedback(ctx, "What is sentiment?")
-
-	// Should handle timeout gracefully
-	_ = err
-}
-
-// TestAnalyzeFeedbackWithSpecialCharacters tests with special characters
-func TestAnalyzeFeedbackWithSpecialCharacters(t *testing.T) {
-	mockRepo := mocks.NewMockFeedbackRepository()
-	mockRepo.SetQueryFeedbackResult([]map[string]any{})
-
-	service := NewFeedbackService(mockRepo, &MockLLMClient{}, nil)
-
-	ctx := context.Background()
-
-	questions := []string{
-		"What's the sentiment?",
-		"What is customer's feedback?",
-		"Test with \"quotes\"",
-		"Test with 'single quotes'",
-		"Test with unicode: 你好",
-	}
-
-	for _, q := range questions {
-		_, err := service.AnalyzeFeedback(ctx, q)
-		// Should handle special characters without panicking
-		_ = err
-	}
-}
-
-// TestAnalyzeFeedbackLongQuestion tests with very long question
-func TestAnalyzeFeedbackLongQuestion(t *testing.T) {
-	mockRepo := mocks.NewMockFeedbackRepository()
-	mockRepo.SetQueryFeedbackResult([]map[string]any{})
-
-	service := NewFeedbackService(mockRepo, &MockLLMClient{}, nil)
-
-	// Create a very long question
-	longQuestion := ""
-	for i := 0; i < 1000; i++ {
-		longQuestion += "What "
-	}
-
-	ctx := context.Background()
-	_, err := service.AnalyzeFeedback(ctx, longQuestion)
-
-	// Should handle long questions
-	_ = err
-}
-
-// TestAnalyzeFeedbackMultipleCalls tests multiple sequential calls
-func TestAnalyzeFeedbackMultipleCalls(t *testing.T) {
-	mockRepo := mocks.NewMockFeedbackRepository()
-	mockRepo.SetQueryFeedbackResult([]map[string]any{
-		{"id": 1, "feedback": "Feedback 1"},
-	})
-
-	service := NewFeedbackService(mockRepo, &MockLLMClient{}, nil)
-
-	ctx := context.Background()
-
-	for i := 0; i < 5; i++ {
-		_, err := service.AnalyzeFeedback(ctx, "Question "+string(rune(i)))
-		_ = err
-	}
-
-	// All calls should complete without issues
-}
-
-// BenchmarkAnalyzeFeedback benchmarks feedback analysis
-func BenchmarkAnalyzeFeedback(b *testing.B) {
-	mockRepo := mocks.NewMockFeedbackRepository()
-	mockRepo.SetQueryFeedbackResult([]map[string]any{
-		{"id": 1, "feedback": "Test feedback"},
-	})
-
-	service := NewFeedbackService(mockRepo, &MockLLMClient{}, nil)
-	ctx := context.Background()
-
-	b.ReportAllocs()
-	b.ResetTimer()
-
-	for i := 0; i < b.N; i++ {
-		service.AnalyzeFeedback(ctx, "What is sentiment?")
-	}
-}
-
-// BenchmarkServiceWithCache benchmarks service with caching
-func BenchmarkServiceWithCache(b *testing.B) {
-	mockRepo := mocks.NewMockFeedbackRepository()
-	mockRepo.SetQueryFeedbackResult([]map[string]any{
-		{"id": 1, "feedback": "Test"},
-	})
-
-	cacheManager := cache.NewCacheManager(true, 100, 5*time.Minute)
-
-	service := NewFeedbackServiceWithCache(
-		mockRepo,
-		&MockLLMClient{},
-		nil,
-		cacheManager,
-	)
-
-	ctx := context.Background()
-
-	b.ReportAllocs()
-	b.ResetTimer()
-
-	for i := 0; i < b.N; i++ {
-		service.AnalyzeFeedback(ctx, "What is sentiment?")
-	}
-}
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chuckie/goinsight/internal/cache"
+	"github.com/chuckie/goinsight/internal/llm"
+	"github.com/chuckie/goinsight/internal/sqlguard"
+	"github.com/chuckie/goinsight/tests/mocks"
+	"github.com/lib/pq"
+)
+
+// MockLLMClient is a mock LLM client for service tests
+type MockLLMClient struct {
+	GenerateSQLFn     func(context.Context, string) (string, error)
+	GenerateInsightFn func(context.Context, string, []map[string]any) (string, error)
+	GenerateFn        func(context.Context, string) (string, error)
+}
+
+func (m *MockLLMClient) GenerateSQL(ctx context.Context, question string) (string, error) {
+	if m.GenerateSQLFn != nil {
+		return m.GenerateSQLFn(ctx, question)
+	}
+	return "SELECT * FROM feedback_enriched", nil
+}
+
+func (m *MockLLMClient) GenerateInsight(ctx context.Context, question string, results []map[string]any) (string, error) {
+	if m.GenerateInsightFn != nil {
+		return m.GenerateInsightFn(ctx, question, results)
+	}
+	return `{"summary": "Analysis complete", "recommendations": [], "actions": []}`, nil
+}
+
+func (m *MockLLMClient) GenerateInsightStream(ctx context.Context, question string, results []map[string]any, onToken llm.TokenHandler) (string, error) {
+	response, err := m.GenerateInsight(ctx, question, results)
+	if err == nil && onToken != nil {
+		onToken(response)
+	}
+	return response, err
+}
+
+func (m *MockLLMClient) Generate(ctx context.Context, prompt string) (string, error) {
+	if m.GenerateFn != nil {
+		return m.GenerateFn(ctx, prompt)
+	}
+	return "Generated response", nil
+}
+
+// TestAnalyzeFeedbackSuccess tests successful feedback analysis
+func TestAnalyzeFeedbackSuccess(t *testing.T) {
+	mockRepo := mocks.NewMockFeedbackRepository()
+	mockRepo.SetQueryFeedbackResult([]map[string]any{
+		{"id": 1, "feedback": "Great product", "sentiment": "positive"},
+	})
+
+	service := NewFeedbackService(
+		mockRepo,
+		&MockLLMClient{},
+		nil,
+	)
+
+	ctx := context.Background()
+	result, err := service.AnalyzeFeedback(ctx, "What is customer sentiment?")
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	if result == nil {
+		t.Error("Expected non-nil result")
+	}
+}
+
+// TestAnalyzeFeedbackEmptyQuestion tests with empty question
+func TestAnalyzeFeedbackEmptyQuestion(t *testing.T) {
+	mockRepo := mocks.NewMockFeedbackRepository()
+	service := NewFeedbackService(mockRepo, &MockLLMClient{}, nil)
+
+	ctx := context.Background()
+	_, err := service.AnalyzeFeedback(ctx, "")
+
+	if err == nil {
+		t.Error("Expected error for empty question")
+	}
+}
+
+// TestAnalyzeFeedbackWithCache tests caching behavior
+func TestAnalyzeFeedbackWithCache(t *testing.T) {
+	mockRepo := mocks.NewMockFeedbackRepository()
+	mockRepo.SetQueryFeedbackResult([]map[string]any{
+		{"id": 1, "feedback": "Excellent", "sentiment": "positive"},
+	})
+
+	cacheManager := cache.NewCacheManager(true, 100, 5*time.Minute)
+
+	service := NewFeedbackServiceWithCache(
+		mockRepo,
+		&MockLLMClient{},
+		nil,
+		cacheManager,
+	)
+
+	ctx := context.Background()
+	question := "What is the sentiment?"
+
+	// First call - should hit repository
+	result1, err1 := service.AnalyzeFeedback(ctx, question)
+	if err1 != nil {
+		t.Logf("First call error: %v", err1)
+	}
+
+	// Second call - might hit cache
+	result2, err2 := service.AnalyzeFeedback(ctx, question)
+	if err2 != nil {
+		t.Logf("Second call error: %v", err2)
+	}
+
+	// Both should return results
+	if result1 != nil && result2 != nil {
+		// Results should be consistent
+		if result1.Question != result2.Question {
+			t.Error("Results should be consistent between calls")
+		}
+	}
+}
+
+// TestAnalyzeFeedbackRepositoryError tests error from repository
+func TestAnalyzeFeedbackRepositoryError(t *testing.T) {
+	mockRepo := mocks.NewMockFeedbackRepository()
+	mockRepo.SetupForDatabaseError()
+
+	service := NewFeedbackService(mockRepo, &MockLLMClient{}, nil)
+
+	ctx := context.Background()
+	_, err := service.AnalyzeFeedback(ctx, "What is sentiment?")
+
+	// Should handle repository errors gracefully
+	_ = err
+}
+
+// TestAnalyzeFeedbackLLMError tests error from LLM client
+func TestAnalyzeFeedbackLLMError(t *testing.T) {
+	mockRepo := mocks.NewMockFeedbackRepository()
+	mockRepo.SetQueryFeedbackResult([]map[string]any{})
+
+	llmClient := &MockLLMClient{
+		GenerateSQLFn: func(ctx context.Context, q string) (string, error) {
+			return "", errors.New("LLM service error")
+		},
+	}
+
+	service := NewFeedbackService(mockRepo, llmClient, nil)
+
+	ctx := context.Background()
+	_, err := service.AnalyzeFeedback(ctx, "What is sentiment?")
+
+	// Should handle LLM errors
+	_ = err
+}
+
+// TestNewFeedbackService tests basic service creation
+func TestNewFeedbackService(t *testing.T) {
+	mockRepo := mocks.NewMockFeedbackRepository()
+	service := NewFeedbackService(mockRepo, &MockLLMClient{}, nil)
+
+	if service == nil {
+		t.Error("Service should not be nil")
+	}
+
+	if service.repo != mockRepo {
+		t.Error("Repository not set correctly")
+	}
+}
+
+// TestNewFeedbackServiceWithProfiler tests service with profiler
+func TestNewFeedbackServiceWithProfiler(t *testing.T) {
+	mockRepo := mocks.NewMockFeedbackRepository()
+
+	service := NewFeedbackServiceWithProfiler(
+		mockRepo,
+		&MockLLMClient{},
+		nil,
+		nil, nil, nil, nil,
+	)
+
+	if service == nil {
+		t.Error("Service should not be nil")
+	}
+}
+
+// TestNewFeedbackServiceWithCache tests service with cache
+func TestNewFeedbackServiceWithCache(t *testing.T) {
+	mockRepo := mocks.NewMockFeedbackRepository()
+	cacheManager := cache.NewCacheManager(true, 100, 5*time.Minute)
+
+	service := NewFeedbackServiceWithCache(
+		mockRepo,
+		&MockLLMClient{},
+		nil,
+		cacheManager,
+	)
+
+	if service == nil {
+		t.Error("Service should not be nil")
+	}
+
+	if !service.cacheQueryResults {
+		t.Error("Cache should be enabled")
+	}
+}
+
+// TestNewFeedbackServiceFull tests service with all features
+func TestNewFeedbackServiceFull(t *testing.T) {
+	mockRepo := mocks.NewMockFeedbackRepository()
+	cacheManager := cache.NewCacheManager(true, 100, 5*time.Minute)
+
+	service := NewFeedbackServiceFull(
+		mockRepo,
+		&MockLLMClient{},
+		nil,
+		nil, nil, nil, nil,
+		cacheManager,
+	)
+
+	if service == nil {
+		t.Error("Service should not be nil")
+	}
+
+	if !service.cacheQueryResults {
+		t.Error("Cache should be enabled")
+	}
+}
+
+// TestSetCacheTTL tests setting cache TTL
+func TestSetCacheTTL(t *testing.T) {
+	mockRepo := mocks.NewMockFeedbackRepository()
+	service := NewFeedbackService(mockRepo, &MockLLMClient{}, nil)
+
+	ttl := 10 * time.Minute
+	service.SetCacheTTL(ttl)
+
+	if service.queryResultsTTL != ttl {
+		t.Errorf("Expected TTL %v, got %v", ttl, service.queryResultsTTL)
+	}
+}
+
+// TestCacheQueryResults tests enabling/disabling caching
+func TestCacheQueryResults(t *testing.T) {
+	mockRepo := mocks.NewMockFeedbackRepository()
+	service := NewFeedbackService(mockRepo, &MockLLMClient{}, nil)
+
+	service.CacheQueryResults(true)
+	if !service.cacheQueryResults {
+		t.Error("Cache should be enabled")
+	}
+
+	service.CacheQueryResults(false)
+	if service.cacheQueryResults {
+		t.Error("Cache should be disabled")
+	}
+}
+
+// TestAnalyzeFeedbackContextCancellation tests with cancelled context
+func TestAnalyzeFeedbackContextCancellation(t *testing.T) {
+	mockRepo := mocks.NewMockFeedbackRepository()
+	mockRepo.SetQueryFeedbackResult([]map[string]any{
+		{"id": 1, "feedback": "test"},
+	})
+
+	service := NewFeedbackService(mockRepo, &MockLLMClient{}, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := service.AnalyzeFeedback(ctx, "What is sentiment?")
+
+	// Should handle cancelled context gracefully
+	_ = err
+}
+
+// TestAnalyzeFeedbackTimeout tests with timeout
+func TestAnalyzeFeedbackTimeout(t *testing.T) {
+	mockRepo := mocks.NewMockFeedbackRepository()
+	mockRepo.SetQueryFeedbackResult([]map[string]any{})
+
+	service := NewFeedbackService(mockRepo, &MockLLMClient{}, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Millisecond)
+	defer cancel()
+
+	_, err := service.AnalyzeFeedback(ctx, "What is sentiment?")
+
+	// Should handle timeout gracefully
+	_ = err
+}
+
+// TestAnalyzeFeedbackWithSpecialCharacters tests with special characters
+func TestAnalyzeFeedbackWithSpecialCharacters(t *testing.T) {
+	mockRepo := mocks.NewMockFeedbackRepository()
+	mockRepo.SetQueryFeedbackResult([]map[string]any{})
+
+	service := NewFeedbackService(mockRepo, &MockLLMClient{}, nil)
+
+	ctx := context.Background()
+
+	questions := []string{
+		"What's the sentiment?",
+		"What is customer's feedback?",
+		"Test with \"quotes\"",
+		"Test with 'single quotes'",
+		"Test with unicode: 你好",
+	}
+
+	for _, q := range questions {
+		_, err := service.AnalyzeFeedback(ctx, q)
+		// Should handle special characters without panicking
+		_ = err
+	}
+}
+
+// TestAnalyzeFeedbackLongQuestion tests with very long question
+func TestAnalyzeFeedbackLongQuestion(t *testing.T) {
+	mockRepo := mocks.NewMockFeedbackRepository()
+	mockRepo.SetQueryFeedbackResult([]map[string]any{})
+
+	service := NewFeedbackService(mockRepo, &MockLLMClient{}, nil)
+
+	// Create a very long question
+	longQuestion := ""
+	for i := 0; i < 1000; i++ {
+		longQuestion += "What "
+	}
+
+	ctx := context.Background()
+	_, err := service.AnalyzeFeedback(ctx, longQuestion)
+
+	// Should handle long questions
+	_ = err
+}
+
+// TestAnalyzeFeedbackMultipleCalls tests multiple sequential calls
+func TestAnalyzeFeedbackMultipleCalls(t *testing.T) {
+	mockRepo := mocks.NewMockFeedbackRepository()
+	mockRepo.SetQueryFeedbackResult([]map[string]any{
+		{"id": 1, "feedback": "Feedback 1"},
+	})
+
+	service := NewFeedbackService(mockRepo, &MockLLMClient{}, nil)
+
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		_, err := service.AnalyzeFeedback(ctx, "Question "+string(rune(i)))
+		_ = err
+	}
+
+	// All calls should complete without issues
+}
+
+// TestAnalyzeFeedbackRowLimit tests LIMIT enforcement and truncation metadata
+func TestAnalyzeFeedbackRowLimit(t *testing.T) {
+	rows := func(n int) []map[string]any {
+		result := make([]map[string]any, n)
+		for i := range result {
+			result[i] = map[string]any{"id": i}
+		}
+		return result
+	}
+
+	tests := []struct {
+		name          string
+		sql           string
+		fetched       int
+		countErr      error
+		wantSQL       string
+		wantTotal     int
+		wantTruncated bool
+		wantLimit     int
+	}{
+		{
+			name:      "limit added, result within cap",
+			sql:       "SELECT * FROM feedback_enriched",
+			fetched:   3,
+			wantSQL:   "SELECT * FROM feedback_enriched LIMIT 5",
+			wantTotal: 3,
+			wantLimit: 5,
+		},
+		{
+			name:          "limit tightened, result truncated",
+			sql:           "SELECT * FROM feedback_enriched LIMIT 500;",
+			fetched:       5,
+			wantSQL:       "SELECT * FROM feedback_enriched LIMIT 5",
+			wantTotal:     42,
+			wantTruncated: true,
+			wantLimit:     5,
+		},
+		{
+			name:      "own limit within cap is kept",
+			sql:       "SELECT * FROM feedback_enriched LIMIT 5",
+			fetched:   5,
+			wantSQL:   "SELECT * FROM feedback_enriched LIMIT 5",
+			wantTotal: 5,
+		},
+		{
+			name:          "count failure still marks truncation",
+			sql:           "SELECT * FROM feedback_enriched",
+			fetched:       5,
+			countErr:      errors.New("count failed"),
+			wantSQL:       "SELECT * FROM feedback_enriched LIMIT 5",
+			wantTotal:     5,
+			wantTruncated: true,
+			wantLimit:     5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var executed []string
+			mockRepo := mocks.NewMockFeedbackRepository()
+			mockRepo.QueryFeedbackFn = func(ctx context.Context, query string) ([]map[string]any, error) {
+				executed = append(executed, query)
+				if strings.HasPrefix(query, "SELECT COUNT(*) AS total_rows") {
+					if tt.countErr != nil {
+						return nil, tt.countErr
+					}
+					return []map[string]any{{"total_rows": int64(42)}}, nil
+				}
+				return rows(tt.fetched), nil
+			}
+
+			llmClient := &MockLLMClient{
+				GenerateSQLFn: func(ctx context.Context, q string) (string, error) {
+					return tt.sql, nil
+				},
+			}
+			service := NewFeedbackService(mockRepo, llmClient, nil)
+			service.SetMaxQueryRows(5)
+
+			result, err := service.AnalyzeFeedback(context.Background(), "Show all feedback")
+			if err != nil {
+				t.Fatalf("AnalyzeFeedback() unexpected error: %v", err)
+			}
+
+			if executed[0] != tt.wantSQL {
+				t.Errorf("executed SQL = %q, want %q", executed[0], tt.wantSQL)
+			}
+			if result.SQL != tt.wantSQL {
+				t.Errorf("response SQL = %q, want %q", result.SQL, tt.wantSQL)
+			}
+			if result.TotalRows != tt.wantTotal {
+				t.Errorf("TotalRows = %d, want %d", result.TotalRows, tt.wantTotal)
+			}
+			if result.Truncated != tt.wantTruncated {
+				t.Errorf("Truncated = %v, want %v", result.Truncated, tt.wantTruncated)
+			}
+			if result.LimitApplied != tt.wantLimit {
+				t.Errorf("LimitApplied = %d, want %d", result.LimitApplied, tt.wantLimit)
+			}
+		})
+	}
+}
+
+// TestAnalyzeFeedbackRepairsRejectedSQL tests the LLM repair loop for queries rejected by Postgres
+func TestAnalyzeFeedbackRepairsRejectedSQL(t *testing.T) {
+	badColumn := &pq.Error{Code: "42703", Message: `column "acount_id" does not exist`}
+
+	tests := []struct {
+		name           string
+		repairs        []string
+		maxRepairs     int
+		queryErr       func(query string) error
+		wantErr        bool
+		wantAttempts   int // queries executed
+		wantRejected   int // repairs rejected by the validator
+		wantRepairs    int
+		wantFinalSQL   string
+		wantValidation bool
+	}{
+		{
+			name:    "repaired on second attempt",
+			repairs: []string{"```sql\nSELECT account_id FROM feedback_enriched\n```"},
+			queryErr: func(query string) error {
+				if strings.Contains(query, "acount_id") {
+					return badColumn
+				}
+				return nil
+			},
+			maxRepairs:   2,
+			wantAttempts: 2,
+			wantRepairs:  1,
+			wantFinalSQL: "SELECT account_id FROM feedback_enriched LIMIT 1000",
+		},
+		{
+			name:    "gives up after max repairs",
+			repairs: []string{"SELECT acount_id FROM feedback_enriched", "SELECT acount_id FROM feedback_enriched"},
+			queryErr: func(query string) error {
+				return badColumn
+			},
+			maxRepairs:   2,
+			wantErr:      true,
+			wantAttempts: 3,
+			wantRepairs:  2,
+		},
+		{
+			name: "repair disabled",
+			queryErr: func(query string) error {
+				return badColumn
+			},
+			maxRepairs:   0,
+			wantErr:      true,
+			wantAttempts: 1,
+		},
+		{
+			name: "connection errors are not repaired",
+			queryErr: func(query string) error {
+				return errors.New("connection refused")
+			},
+			maxRepairs:   2,
+			wantErr:      true,
+			wantAttempts: 1,
+		},
+		{
+			name:    "repaired SQL is re-validated",
+			repairs: []string{"DELETE FROM feedback_enriched"},
+			queryErr: func(query string) error {
+				return badColumn
+			},
+			maxRepairs:     1,
+			wantErr:        true,
+			wantAttempts:   1,
+			wantRejected:   1,
+			wantRepairs:    1,
+			wantValidation: true,
+		},
+		{
+			name:    "rejected repair is repaired again",
+			repairs: []string{"DELETE FROM feedback_enriched", "SELECT account_id FROM feedback_enriched"},
+			queryErr: func(query string) error {
+				if strings.Contains(query, "acount_id") {
+					return badColumn
+				}
+				return nil
+			},
+			maxRepairs:   2,
+			wantAttempts: 2,
+			wantRejected: 1,
+			wantRepairs:  2,
+			wantFinalSQL: "SELECT account_id FROM feedback_enriched LIMIT 1000",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var executed []string
+			mockRepo := mocks.NewMockFeedbackRepository()
+			mockRepo.QueryFeedbackFn = func(ctx context.Context, query string) ([]map[string]any, error) {
+				executed = append(executed, query)
+				if err := tt.queryErr(query); err != nil {
+					return nil, err
+				}
+				return []map[string]any{{"account_id": "acct-1"}}, nil
+			}
+
+			var prompts []string
+			llmClient := &MockLLMClient{
+				GenerateSQLFn: func(ctx context.Context, q string) (string, error) {
+					return "SELECT acount_id FROM feedback_enriched", nil
+				},
+				GenerateFn: func(ctx context.Context, prompt string) (string, error) {
+					prompts = append(prompts, prompt)
+					return tt.repairs[len(prompts)-1], nil
+				},
+			}
+
+			service := NewFeedbackService(mockRepo, llmClient, nil)
+			service.SetMaxRepairAttempts(tt.maxRepairs)
+
+			result, err := service.AnalyzeFeedback(context.Background(), "Which accounts complained?")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AnalyzeFeedback() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(executed) != tt.wantAttempts {
+				t.Errorf("executed %d queries, want %d", len(executed), tt.wantAttempts)
+			}
+			if len(prompts) != tt.wantRepairs {
+				t.Errorf("requested %d repairs, want %d", len(prompts), tt.wantRepairs)
+			}
+			if len(prompts) > 0 && !strings.Contains(prompts[0], badColumn.Message) {
+				t.Errorf("repair prompt does not include the database error: %s", prompts[0])
+			}
+
+			var verr *sqlguard.ValidationError
+			if errors.As(err, &verr) != tt.wantValidation {
+				t.Errorf("validation error = %v, want %v", err, tt.wantValidation)
+			}
+			var repairErr *RepairError
+			if tt.wantErr && tt.wantRejected > 0 {
+				if !errors.As(err, &repairErr) || len(repairErr.Attempts) != tt.wantAttempts+tt.wantRejected {
+					t.Errorf("error = %v, want a RepairError with %d attempts", err, tt.wantAttempts+tt.wantRejected)
+				}
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if len(result.Attempts) != tt.wantAttempts+tt.wantRejected {
+				t.Fatalf("Attempts = %+v, want %d entries", result.Attempts, tt.wantAttempts+tt.wantRejected)
+			}
+			if result.Attempts[0].Error == "" {
+				t.Error("first attempt should record the database error")
+			}
+			last := result.Attempts[len(result.Attempts)-1]
+			if last.Error != "" || last.SQL != tt.wantFinalSQL {
+				t.Errorf("last attempt = %+v, want successful %q", last, tt.wantFinalSQL)
+			}
+			if result.SQL != tt.wantFinalSQL {
+				t.Errorf("SQL = %q, want %q", result.SQL, tt.wantFinalSQL)
+			}
+		})
+	}
+}
+
+// TestAnalyzeConversationFollowUp tests that earlier turns reach the LLM and new turns are stored
+func TestAnalyzeConversationFollowUp(t *testing.T) {
+	mockRepo := mocks.NewMockFeedbackRepository()
+	mockRepo.SetQueryFeedbackResult([]map[string]any{{"topic": "refunds", "count": 2}})
+	conversations := mocks.NewMockConversationRepository()
+
+	var histories [][]llm.Turn
+	llmClient := &MockLLMClient{
+		GenerateSQLFn: func(ctx context.Context, q string) (string, error) {
+			histories = append(histories, llm.History(ctx))
+			if strings.Contains(q, "enterprise") {
+				return "SELECT topic FROM feedback_enriched WHERE customer_tier = 'enterprise'", nil
+			}
+			return "SELECT topic FROM feedback_enriched", nil
+		},
+	}
+
+	cacheManager := cache.NewCacheManager(true, 100, 5*time.Minute)
+	service := NewFeedbackServiceWithCache(mockRepo, llmClient, nil, cacheManager)
+	service.SetConversationRepository(conversations)
+
+	ctx := context.Background()
+	first, err := service.AnalyzeConversation(ctx, "", "What are the top billing issues?")
+	if err != nil {
+		t.Fatalf("AnalyzeConversation() unexpected error: %v", err)
+	}
+	if first.ConversationID == "" {
+		t.Fatal("expected a new conversation_id")
+	}
+
+	second, err := service.AnalyzeConversation(ctx, first.ConversationID, "now only for enterprise")
+	if err != nil {
+		t.Fatalf("AnalyzeConversation() unexpected error: %v", err)
+	}
+	if second.ConversationID != first.ConversationID {
+		t.Errorf("conversation_id = %q, want %q", second.ConversationID, first.ConversationID)
+	}
+
+	if len(histories) != 2 || len(histories[0]) != 0 || len(histories[1]) != 1 {
+		t.Fatalf("histories passed to GenerateSQL = %+v, want none then one turn", histories)
+	}
+	if got := histories[1][0]; got.Question != "What are the top billing issues?" || got.SQL != first.SQL || got.Summary != first.Summary {
+		t.Errorf("history turn = %+v", got)
+	}
+	if turns := conversations.Turns[first.ConversationID]; len(turns) != 2 || turns[1].SQL != second.SQL {
+		t.Errorf("stored turns = %+v, want both questions", turns)
+	}
+
+	// A new conversation may be answered from the cache, a follow-up may not
+	if _, err := service.AnalyzeConversation(ctx, "", "What are the top billing issues?"); err != nil {
+		t.Fatalf("AnalyzeConversation() unexpected error: %v", err)
+	}
+	third, err := service.AnalyzeConversation(ctx, first.ConversationID, "What are the top billing issues?")
+	if err != nil {
+		t.Fatalf("AnalyzeConversation() unexpected error: %v", err)
+	}
+	if len(histories) != 3 || third.ConversationID != first.ConversationID {
+		t.Errorf("follow-up with history should skip the response cache (GenerateSQL calls = %d)", len(histories))
+	}
+
+	if _, err := service.AnalyzeConversation(ctx, "not-a-uuid", "question"); err == nil {
+		t.Error("expected error for an invalid conversation_id")
+	}
+}
+
+// BenchmarkAnalyzeFeedback benchmarks feedback analysis
+func BenchmarkAnalyzeFeedback(b *testing.B) {
+	mockRepo := mocks.NewMockFeedbackRepository()
+	mockRepo.SetQueryFeedbackResult([]map[string]any{
+		{"id": 1, "feedback": "Test feedback"},
+	})
+
+	service := NewFeedbackService(mockRepo, &MockLLMClient{}, nil)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		service.AnalyzeFeedback(ctx, "What is sentiment?")
+	}
+}
+
+// BenchmarkServiceWithCache benchmarks service with caching
+func BenchmarkServiceWithCache(b *testing.B) {
+	mockRepo := mocks.NewMockFeedbackRepository()
+	mockRepo.SetQueryFeedbackResult([]map[string]any{
+		{"id": 1, "feedback": "Test"},
+	})
+
+	cacheManager := cache.NewCacheManager(true, 100, 5*time.Minute)
+
+	service := NewFeedbackServiceWithCache(
+		mockRepo,
+		&MockLLMClient{},
+		nil,
+		cacheManager,
+	)
+
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		service.AnalyzeFeedback(ctx, "What is sentiment?")
+	}
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/chuckie/goinsight/internal/domain"
+	"github.com/chuckie/goinsight/internal/repository"
+	"github.com/chuckie/goinsight/internal/tracker"
+)
+
+// ErrTicketTrackingDisabled is returned when no insight ticket repository is set
+var ErrTicketTrackingDisabled = errors.New("insight ticket tracking is not configured")
+
+// SetInsightTicketRepository enables ticket tracking: filed tickets are
+// stored with the question and action they came from, and their status
+// changes are recorded from webhooks and polling
+func (fs *FeedbackService) SetInsightTicketRepository(repo repository.InsightTicketRepository) {
+	fs.insightTickets = repo
+}
+
+// trackTickets stores the tickets created, or linked to, for the specs. Like
+// saveAnalysis it only logs failures, as the tickets were filed regardless.
+func (s *FeedbackService) trackTickets(ctx context.Context, question string, specs []domain.JiraTicketSpec, result *domain.JiraCreationResult) {
+	if s.insightTickets == nil || result == nil {
+		return
+	}
+
+	now := time.Now()
+	var tickets []domain.InsightTicket
+	for i, action := range result.Actions {
+		if i >= len(specs) || action.Key == "" {
+			continue
+		}
+		if action.Status != domain.JiraActionCreated && action.Status != domain.JiraActionLinkedExisting {
+			continue
+		}
+		spec := specs[i]
+		tickets = append(tickets, domain.InsightTicket{
+			Key:            action.Key,
+			Tracker:        s.issueTracker.Name(),
+			Question:       question,
+			Action:         spec.Action,
+			Summary:        spec.Summary,
+			ProductArea:    spec.Attributes[domain.JiraAttrProductArea],
+			AnalysisID:     spec.AnalysisID,
+			StatusCategory: domain.JiraStatusCategoryToDo,
+			CreatedAt:      now,
+		})
+	}
+	if len(tickets) == 0 {
+		return
+	}
+
+	if err := s.insightTickets.SaveTickets(ctx, tickets); err != nil && s.logger != nil {
+		s.logger.Warn("Failed to track insight tickets", map[string]interface{}{
+			"tickets": len(tickets),
+			"error":   err.Error(),
+		})
+	}
+}
+
+// HandleJiraWebhook records the status carried by a Jira issue webhook. It
+// returns whether a tracked ticket's status changed.
+func (s *FeedbackService) HandleJiraWebhook(ctx context.Context, event domain.JiraWebhookEvent) (bool, error) {
+	if s.insightTickets == nil {
+		return false, ErrTicketTrackingDisabled
+	}
+
+	status := event.Issue.Fields.Status
+	if event.Issue.Key == "" || status == nil || !strings.HasPrefix(event.WebhookEvent, "jira:issue_") {
+		return false, nil // not an issue event, or one without a status
+	}
+
+	at := time.Now()
+	if event.Timestamp > 0 {
+		at = time.UnixMilli(event.Timestamp)
+	}
+	return s.insightTickets.RecordStatus(ctx, tracker.Jira, event.Issue.Key, *status, domain.TicketStatusSourceWebhook, at)
+}
+
+// SyncTicketStatuses polls the issue tracker for the status of every open
+// insight ticket, catching transitions missed by webhooks. It returns the
+// number of tickets whose status changed.
+func (s *FeedbackService) SyncTicketStatuses(ctx context.Context) (int, error) {
+	source, ok := s.issueTracker.(tracker.StatusSource)
+	if s.insightTickets == nil || !ok {
+		return 0, nil
+	}
+
+	keys, err := s.insightTickets.OpenTicketKeys(ctx, s.issueTracker.Name())
+	if err != nil || len(keys) == 0 {
+		return 0, err
+	}
+	statuses, err := source.IssueStatuses(ctx, keys)
+	if err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	now := time.Now()
+	for _, key := range keys {
+		status, ok := statuses[key]
+		if !ok {
+			continue
+		}
+		updated, err := s.insightTickets.RecordStatus(ctx, s.issueTracker.Name(), key, status, domain.TicketStatusSourcePoll, now)
+		if err != nil {
+			return changed, err
+		}
+		if updated {
+			changed++
+		}
+	}
+	return changed, nil
+}
+
+// StartTicketSync polls ticket statuses every interval until ctx is
+// cancelled. Sync errors are logged and retried on the next tick.
+func (s *FeedbackService) StartTicketSync(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := s.SyncTicketStatuses(ctx); err != nil && ctx.Err() == nil && s.logger != nil {
+					s.logger.Warn("Ticket status sync failed", map[string]interface{}{
+						"error": err.Error(),
+					})
+				}
+			}
+		}
+	}()
+}
+
+// ListInsightTickets returns the tracked tickets matching filter
+func (s *FeedbackService) ListInsightTickets(ctx context.Context, filter domain.InsightTicketFilter) ([]domain.InsightTicket, error) {
+	if s.insightTickets == nil {
+		return nil, ErrTicketTrackingDisabled
+	}
+	return s.insightTickets.ListTickets(ctx, filter)
+}
+
+// InsightTicketStats returns ticket counts and resolution rates per product area
+func (s *FeedbackService) InsightTicketStats(ctx context.Context) ([]domain.ProductAreaTicketStats, error) {
+	if s.insightTickets == nil {
+		return nil, ErrTicketTrackingDisabled
+	}
+	return s.insightTickets.ProductAreaStats(ctx)
+}
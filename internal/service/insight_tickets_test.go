@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/chuckie/goinsight/internal/domain"
+	"github.com/chuckie/goinsight/tests/mocks"
+)
+
+// statusTracker is a Jira-like tracker that creates numbered issues, records
+// comments and reports the statuses set in its statuses map
+type statusTracker struct {
+	created   int
+	comments  []domain.JiraTicketSpec
+	statuses  map[string]domain.JiraStatus
+	createErr error // returned by CreateIssues when set
+}
+
+func (t *statusTracker) Name() string { return "jira" }
+
+func (t *statusTracker) CreateIssues(ctx context.Context, specs []domain.JiraTicketSpec) (*domain.JiraCreationResult, error) {
+	if t.createErr != nil {
+		return nil, t.createErr
+	}
+	result := &domain.JiraCreationResult{TicketSpecs: specs}
+	for _, spec := range specs {
+		t.created++
+		key := fmt.Sprintf("FB-%d", t.created)
+		result.CreatedTickets = append(result.CreatedTickets, domain.JiraCreateResponse{Key: key})
+		result.Actions = append(result.Actions, domain.JiraActionResult{Summary: spec.Summary, Key: key, Status: domain.JiraActionCreated})
+	}
+	return result, nil
+}
+
+func (t *statusTracker) CommentOnIssue(ctx context.Context, spec domain.JiraTicketSpec) error {
+	t.comments = append(t.comments, spec)
+	return nil
+}
+
+func (t *statusTracker) IssueStatuses(ctx context.Context, keys []string) (map[string]domain.JiraStatus, error) {
+	return t.statuses, nil
+}
+
+// jiraStatus returns a status in a category
+func jiraStatus(name, category string) domain.JiraStatus {
+	return domain.JiraStatus{Name: name, StatusCategory: domain.JiraStatusCategory{Key: category}}
+}
+
+// newTrackingService returns a service filing two billing tickets in a statusTracker
+func newTrackingService(t *testing.T) (*FeedbackService, *statusTracker, *mocks.MockInsightTicketRepository) {
+	t.Helper()
+	llmClient := &MockLLMClient{
+		GenerateFn: func(ctx context.Context, prompt string) (string, error) {
+			return `{"tickets": [{"issue_type": "Story", "summary": "Fix refund delays"}, {"issue_type": "Task", "summary": "Explain invoice fees"}]}`, nil
+		},
+	}
+	issueTracker := &statusTracker{}
+	tickets := mocks.NewMockInsightTicketRepository()
+	service := NewFeedbackService(mocks.NewMockFeedbackRepository(), llmClient, issueTracker)
+	service.SetInsightTicketRepository(tickets)
+
+	_, err := service.CreateJiraTickets(context.Background(), JiraTicketRequest{
+		Question:   "What are the top billing issues?",
+		Actions:    []domain.ActionItem{{Title: "Speed up refunds"}, {Title: "Clarify invoice fees"}},
+		Attributes: map[string]string{domain.JiraAttrProductArea: "billing"},
+		Meta:       JiraMetadata{ProjectKey: "FB"},
+	})
+	if err != nil {
+		t.Fatalf("CreateJiraTickets() unexpected error: %v", err)
+	}
+	return service, issueTracker, tickets
+}
+
+// TestCreateJiraTicketsTracksTickets tests that filed tickets are stored with their question and action
+func TestCreateJiraTicketsTracksTickets(t *testing.T) {
+	_, _, tickets := newTrackingService(t)
+
+	if len(tickets.Tickets) != 2 {
+		t.Fatalf("tracked %d tickets, want 2", len(tickets.Tickets))
+	}
+	ticket := tickets.Tickets[0]
+	if ticket.Key != "FB-1" || ticket.Tracker != "jira" || ticket.Question != "What are the top billing issues?" {
+		t.Errorf("ticket = %+v, want FB-1 filed in jira for the question", ticket)
+	}
+	if ticket.Action != "Speed up refunds" || ticket.ProductArea != "billing" || !ticket.Open() {
+		t.Errorf("ticket = %+v, want an open billing ticket for its action", ticket)
+	}
+}
+
+// TestHandleJiraWebhook tests that webhook events record status transitions
+func TestHandleJiraWebhook(t *testing.T) {
+	service, _, tickets := newTrackingService(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	event := func(key string, status domain.JiraStatus, at time.Time) domain.JiraWebhookEvent {
+		return domain.JiraWebhookEvent{
+			WebhookEvent: "jira:issue_updated",
+			Timestamp:    at.UnixMilli(),
+			Issue:        domain.JiraIssue{Key: key, Fields: domain.JiraIssueDetail{Status: &status}},
+		}
+	}
+
+	tests := []struct {
+		name  string
+		event domain.JiraWebhookEvent
+		want  bool
+	}{
+		{"status change", event("FB-1", jiraStatus("In Progress", domain.JiraStatusCategoryInProgress), now), true},
+		{"repeated status", event("FB-1", jiraStatus("In Progress", domain.JiraStatusCategoryInProgress), now.Add(time.Second)), false},
+		{"out of order event", event("FB-1", jiraStatus("To Do", domain.JiraStatusCategoryToDo), now.Add(-time.Minute)), false},
+		{"untracked issue", event("FB-99", jiraStatus("Done", domain.JiraStatusCategoryDone), now), false},
+		{"resolution", event("FB-1", jiraStatus("Done", domain.JiraStatusCategoryDone), now.Add(time.Minute)), true},
+		{"not an issue event", domain.JiraWebhookEvent{WebhookEvent: "comment_created"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated, err := service.HandleJiraWebhook(ctx, tt.event)
+			if err != nil {
+				t.Fatalf("HandleJiraWebhook() unexpected error: %v", err)
+			}
+			if updated != tt.want {
+				t.Errorf("HandleJiraWebhook() = %v, want %v", updated, tt.want)
+			}
+		})
+	}
+
+	if len(tickets.Transitions) != 2 || tickets.Transitions[1].Source != domain.TicketStatusSourceWebhook {
+		t.Errorf("transitions = %+v, want 2 from the webhook", tickets.Transitions)
+	}
+	if ticket := tickets.Tickets[0]; ticket.Open() || ticket.ResolvedAt == nil {
+		t.Errorf("ticket = %+v, want resolved", ticket)
+	}
+}
+
+// TestSyncTicketStatuses tests that polling records the transitions of open tickets
+func TestSyncTicketStatuses(t *testing.T) {
+	service, issueTracker, tickets := newTrackingService(t)
+	ctx := context.Background()
+
+	issueTracker.statuses = map[string]domain.JiraStatus{
+		"FB-1": jiraStatus("Done", domain.JiraStatusCategoryDone),
+		"FB-2": jiraStatus("To Do", domain.JiraStatusCategoryToDo),
+	}
+	changed, err := service.SyncTicketStatuses(ctx)
+	if err != nil {
+		t.Fatalf("SyncTicketStatuses() unexpected error: %v", err)
+	}
+	if changed != 2 {
+		t.Errorf("SyncTicketStatuses() = %d, want 2", changed)
+	}
+
+	// Only the billing ticket still open is listed
+	open := true
+	listed, err := service.ListInsightTickets(ctx, domain.InsightTicketFilter{ProductArea: "Billing", Open: &open})
+	if err != nil {
+		t.Fatalf("ListInsightTickets() unexpected error: %v", err)
+	}
+	if len(listed) != 1 || listed[0].Key != "FB-2" {
+		t.Errorf("ListInsightTickets() = %+v, want FB-2", listed)
+	}
+
+	stats, err := service.InsightTicketStats(ctx)
+	if err != nil {
+		t.Fatalf("InsightTicketStats() unexpected error: %v", err)
+	}
+	if len(stats) != 1 || stats[0].ProductArea != "billing" || stats[0].Resolved != 1 || stats[0].ResolutionRate != 0.5 {
+		t.Errorf("InsightTicketStats() = %+v, want billing half resolved", stats)
+	}
+
+	// Resolved tickets are no longer polled
+	if changed, _ := service.SyncTicketStatuses(ctx); changed != 0 {
+		t.Errorf("second SyncTicketStatuses() = %d, want 0", changed)
+	}
+	if len(tickets.Transitions) != 2 || tickets.Transitions[0].Source != domain.TicketStatusSourcePoll {
+		t.Errorf("transitions = %+v, want 2 from polling", tickets.Transitions)
+	}
+}
+
+// TestTicketTrackingDisabled tests the tracking methods without a repository
+func TestTicketTrackingDisabled(t *testing.T) {
+	service := NewFeedbackService(mocks.NewMockFeedbackRepository(), &MockLLMClient{}, &statusTracker{})
+
+	if _, err := service.ListInsightTickets(context.Background(), domain.InsightTicketFilter{}); !errors.Is(err, ErrTicketTrackingDisabled) {
+		t.Errorf("ListInsightTickets() error = %v, want ErrTicketTrackingDisabled", err)
+	}
+	if changed, err := service.SyncTicketStatuses(context.Background()); changed != 0 || err != nil {
+		t.Errorf("SyncTicketStatuses() = %d, %v, want a no-op", changed, err)
+	}
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chuckie/goinsight/internal/domain"
+	"github.com/chuckie/goinsight/internal/jira"
+	"github.com/chuckie/goinsight/internal/repository"
+	"github.com/google/uuid"
+)
+
+// DefaultJiraDraftTTL is how long a Jira draft can be confirmed after it is created
+const DefaultJiraDraftTTL = 24 * time.Hour
+
+// Errors returned by ConfirmJiraDraft
+var (
+	ErrJiraDraftNotFound  = errors.New("jira draft not found")
+	ErrJiraDraftExpired   = errors.New("jira draft has expired")
+	ErrJiraDraftConfirmed = errors.New("jira draft has already been confirmed")
+	ErrInvalidJiraTickets = errors.New("invalid jira tickets")
+)
+
+// SetJiraDraftRepository enables dry runs of Jira ticket creation: generated
+// specs are stored as a draft and only filed when the draft is confirmed
+func (fs *FeedbackService) SetJiraDraftRepository(repo repository.JiraDraftRepository) {
+	fs.jiraDrafts = repo
+}
+
+// SetJiraDraftTTL configures how long a Jira draft can be confirmed
+func (fs *FeedbackService) SetJiraDraftTTL(ttl time.Duration) {
+	fs.jiraDraftTTL = ttl
+}
+
+// DraftJiraTickets generates the ticket specs for req like CreateJiraTickets,
+// but stores them as a draft for review instead of filing them
+func (s *FeedbackService) DraftJiraTickets(ctx context.Context, req JiraTicketRequest) (*domain.JiraDraft, error) {
+	if s.jiraDrafts == nil {
+		return nil, fmt.Errorf("jira drafts are not configured")
+	}
+
+	tickets, epic, err := s.generateJiraTickets(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// The evidence is the same for every ticket, so it is stored once with the draft
+	now := time.Now()
+	draft := &domain.JiraDraft{
+		ID:          uuid.NewString(),
+		Question:    req.Question,
+		Tickets:     tickets,
+		Epic:        epic,
+		DataPreview: tickets[0].DataPreview,
+		SQL:         tickets[0].SQL,
+		AnalysisID:  tickets[0].AnalysisID,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.jiraDraftTTL),
+	}
+	if err := s.jiraDrafts.SaveDraft(ctx, draft); err != nil {
+		return nil, err
+	}
+
+	return draft, nil
+}
+
+// ConfirmJiraDraft files the tickets of a draft. Non-empty edits replace the
+// draft's tickets, so reviewers can reword, drop or add tickets first. A
+// draft can be confirmed once; if filing fails it can be confirmed again.
+func (s *FeedbackService) ConfirmJiraDraft(ctx context.Context, draftID string, edits []domain.JiraTicketSpec) (*domain.JiraCreationResult, error) {
+	if s.jiraDrafts == nil || s.issueTracker == nil {
+		return nil, fmt.Errorf("jira drafts are not configured")
+	}
+
+	draft, err := s.jiraDrafts.GetDraft(ctx, draftID)
+	if err != nil {
+		return nil, err
+	}
+	if draft == nil {
+		return nil, ErrJiraDraftNotFound
+	}
+	if draft.ConfirmedAt != nil {
+		return nil, ErrJiraDraftConfirmed
+	}
+	if time.Now().After(draft.ExpiresAt) {
+		return nil, ErrJiraDraftExpired
+	}
+
+	tickets := draft.Tickets
+	if len(edits) > 0 {
+		if tickets, err = editedTickets(draft, edits); err != nil {
+			return nil, err
+		}
+		if err := s.checkCommentTargets(ctx, tickets); err != nil {
+			return nil, err
+		}
+	}
+	for i := range tickets {
+		tickets[i].DataPreview = draft.DataPreview
+		tickets[i].SQL = draft.SQL
+		tickets[i].AnalysisID = draft.AnalysisID
+	}
+
+	// Claim the draft first so concurrent confirmations file the tickets once
+	claimed, err := s.jiraDrafts.ConfirmDraft(ctx, draftID, tickets)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, ErrJiraDraftConfirmed
+	}
+
+	result, err := s.fileJiraTickets(ctx, draft.Question, tickets, draft.Epic)
+	if err != nil {
+		if reopenErr := s.jiraDrafts.ReopenDraft(ctx, draftID); reopenErr != nil && s.logger != nil {
+			s.logger.Warn("Failed to reopen jira draft", map[string]interface{}{
+				"draft_id": draftID,
+				"error":    reopenErr.Error(),
+			})
+		}
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// editedTickets validates the edited specs of a draft and fills in what a
+// reviewer may leave out: the issue type, the fingerprint and the comment
+// for a ticket pointed at an existing issue
+func editedTickets(draft *domain.JiraDraft, edits []domain.JiraTicketSpec) ([]domain.JiraTicketSpec, error) {
+	tickets := make([]domain.JiraTicketSpec, len(edits))
+	copy(tickets, edits)
+
+	for i := range tickets {
+		if strings.TrimSpace(tickets[i].Summary) == "" {
+			return nil, fmt.Errorf("%w: ticket %d has no summary", ErrInvalidJiraTickets, i+1)
+		}
+		if tickets[i].IssueType == "" {
+			tickets[i].IssueType = "Story"
+		}
+		if tickets[i].Fingerprint == "" {
+			tickets[i].Fingerprint = jira.Fingerprint(draft.Question, tickets[i].Summary)
+		}
+		if tickets[i].CommentOn != "" && strings.TrimSpace(tickets[i].Comment) == "" {
+			tickets[i].Comment = evidenceComment(draft.Question, "")
+		}
+	}
+	return tickets, nil
+}
+
+// checkCommentTargets rejects edited tickets pointed at an issue that is not
+// an open insight ticket of the issue tracker, so a confirmation cannot
+// comment on arbitrary issues
+func (s *FeedbackService) checkCommentTargets(ctx context.Context, tickets []domain.JiraTicketSpec) error {
+	var open map[string]bool
+	for i, ticket := range tickets {
+		if ticket.CommentOn == "" {
+			continue
+		}
+		if s.insightTickets == nil {
+			return fmt.Errorf("%w: ticket %d comments on %s, but insight ticket tracking is disabled", ErrInvalidJiraTickets, i+1, ticket.CommentOn)
+		}
+		if open == nil {
+			keys, err := s.insightTickets.OpenTicketKeys(ctx, s.issueTracker.Name())
+			if err != nil {
+				return fmt.Errorf("failed to find open tickets: %w", err)
+			}
+			open = make(map[string]bool, len(keys))
+			for _, key := range keys {
+				open[key] = true
+			}
+		}
+		if !open[ticket.CommentOn] {
+			return fmt.Errorf("%w: ticket %d comments on %s, which is not an open insight ticket", ErrInvalidJiraTickets, i+1, ticket.CommentOn)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chuckie/goinsight/internal/domain"
+	"github.com/chuckie/goinsight/internal/jira"
+	"github.com/chuckie/goinsight/tests/mocks"
+)
+
+// newJiraServer starts a Jira stand-in that finds no duplicates and records
+// the summaries of the issues created in bulk
+func newJiraServer(t *testing.T) (*jira.Client, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var created []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			json.NewEncoder(w).Encode(domain.JiraSearchResponse{})
+		case "POST":
+			var req domain.JiraBulkCreateRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			var resp domain.JiraBulkCreateResponse
+			mu.Lock()
+			for _, update := range req.IssueUpdates {
+				created = append(created, update.Fields.Summary)
+				resp.Issues = append(resp.Issues, domain.JiraCreateResponse{Key: fmt.Sprintf("APP-%d", len(created))})
+			}
+			mu.Unlock()
+			json.NewEncoder(w).Encode(resp)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return jira.NewClient(server.URL, "pm@example.com", "token", "APP"), func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), created...)
+	}
+}
+
+// TestJiraDraftConfirmFlow tests that a dry run files nothing until its draft is confirmed with edits
+func TestJiraDraftConfirmFlow(t *testing.T) {
+	jiraClient, created := newJiraServer(t)
+	llmClient := &MockLLMClient{
+		GenerateFn: func(ctx context.Context, prompt string) (string, error) {
+			return `{"tickets": [
+				{"project_key": "APP", "issue_type": "Story", "summary": "Fix refund delays"},
+				{"project_key": "APP", "issue_type": "Story", "summary": "Audit invoices"}
+			]}`, nil
+		},
+	}
+	drafts := mocks.NewMockJiraDraftRepository()
+	service := NewFeedbackService(mocks.NewMockFeedbackRepository(), llmClient, jiraClient)
+	service.SetJiraDraftRepository(drafts)
+
+	ctx := context.Background()
+	draft, err := service.DraftJiraTickets(ctx, JiraTicketRequest{
+		Question: "What are the top billing issues?",
+		Actions:  []domain.ActionItem{{Title: "Fix refund delays"}, {Title: "Audit invoices"}},
+		Meta:     JiraMetadata{ProjectKey: "APP"},
+	})
+	if err != nil {
+		t.Fatalf("DraftJiraTickets() unexpected error: %v", err)
+	}
+	if draft.ID == "" || len(draft.Tickets) != 2 || draft.Tickets[0].Fingerprint == "" {
+		t.Fatalf("draft = %+v, want an ID and two fingerprinted tickets", draft)
+	}
+	if got := created(); len(got) != 0 {
+		t.Fatalf("dry run filed %v", got)
+	}
+
+	// The reviewer rewords the first ticket and drops the second
+	edits := []domain.JiraTicketSpec{draft.Tickets[0]}
+	edits[0].Summary = "Fix refund delays for enterprise accounts"
+	result, err := service.ConfirmJiraDraft(ctx, draft.ID, edits)
+	if err != nil {
+		t.Fatalf("ConfirmJiraDraft() unexpected error: %v", err)
+	}
+	if got := created(); len(got) != 1 || got[0] != edits[0].Summary || len(result.CreatedTickets) != 1 {
+		t.Errorf("filed %v (result %+v), want only the edited ticket", got, result)
+	}
+
+	if _, err := service.ConfirmJiraDraft(ctx, draft.ID, nil); !errors.Is(err, ErrJiraDraftConfirmed) {
+		t.Errorf("second confirm error = %v, want ErrJiraDraftConfirmed", err)
+	}
+	if _, err := service.ConfirmJiraDraft(ctx, "00000000-0000-0000-0000-000000000000", nil); !errors.Is(err, ErrJiraDraftNotFound) {
+		t.Errorf("unknown draft error = %v, want ErrJiraDraftNotFound", err)
+	}
+}
+
+// TestConfirmJiraDraftRejects tests expired drafts and invalid edits
+func TestConfirmJiraDraftRejects(t *testing.T) {
+	jiraClient, created := newJiraServer(t)
+	drafts := mocks.NewMockJiraDraftRepository()
+	service := NewFeedbackService(mocks.NewMockFeedbackRepository(), &MockLLMClient{}, jiraClient)
+	service.SetJiraDraftRepository(drafts)
+
+	now := time.Now()
+	tickets := []domain.JiraTicketSpec{{Summary: "Fix refunds"}}
+	drafts.SaveDraft(context.Background(), &domain.JiraDraft{ID: "expired", Tickets: tickets, CreatedAt: now.Add(-2 * time.Hour), ExpiresAt: now.Add(-time.Hour)})
+	drafts.SaveDraft(context.Background(), &domain.JiraDraft{ID: "open", Tickets: tickets, CreatedAt: now, ExpiresAt: now.Add(time.Hour)})
+
+	if _, err := service.ConfirmJiraDraft(context.Background(), "expired", nil); !errors.Is(err, ErrJiraDraftExpired) {
+		t.Errorf("expired draft error = %v, want ErrJiraDraftExpired", err)
+	}
+	if _, err := service.ConfirmJiraDraft(context.Background(), "open", []domain.JiraTicketSpec{{Summary: " "}}); !errors.Is(err, ErrInvalidJiraTickets) {
+		t.Errorf("blank summary error = %v, want ErrInvalidJiraTickets", err)
+	}
+	if drafts.Drafts["open"].ConfirmedAt != nil {
+		t.Error("a rejected edit should leave the draft open")
+	}
+	if got := created(); len(got) != 0 {
+		t.Errorf("rejected confirmations filed %v", got)
+	}
+}
+
+// recordingTracker is an IssueTracker without epic support that records the specs it is given
+type recordingTracker struct {
+	filed []domain.JiraTicketSpec
+}
+
+func (r *recordingTracker) Name() string { return "github" }
+
+func (r *recordingTracker) CreateIssues(ctx context.Context, specs []domain.JiraTicketSpec) (*domain.JiraCreationResult, error) {
+	r.filed = append(r.filed, specs...)
+	return &domain.JiraCreationResult{TicketSpecs: specs}, nil
+}
+
+// TestCreateJiraTicketsOtherTracker tests filing in a non-Jira tracker
+func TestCreateJiraTicketsOtherTracker(t *testing.T) {
+	issueTracker := &recordingTracker{}
+	llmClient := &MockLLMClient{
+		GenerateFn: func(ctx context.Context, prompt string) (string, error) {
+			return `{"tickets": [{"project_key": "PROJECT_KEY", "issue_type": "Story", "summary": "Fix refund delays"}]}`, nil
+		},
+	}
+	service := NewFeedbackService(mocks.NewMockFeedbackRepository(), llmClient, issueTracker)
+	req := JiraTicketRequest{Question: "q", Actions: []domain.ActionItem{{Title: "Fix refund delays"}}}
+
+	// The project key is only required by Jira
+	if _, err := service.CreateJiraTickets(context.Background(), req); err != nil {
+		t.Fatalf("CreateJiraTickets() unexpected error: %v", err)
+	}
+	if len(issueTracker.filed) != 1 || issueTracker.filed[0].Fingerprint == "" {
+		t.Errorf("filed = %+v, want one fingerprinted spec", issueTracker.filed)
+	}
+
+	req.Meta.CreateEpic = true
+	if _, err := service.CreateJiraTickets(context.Background(), req); err == nil || !strings.Contains(err.Error(), "does not support create_epic") {
+		t.Errorf("CreateJiraTickets() error = %v, want create_epic unsupported", err)
+	}
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/chuckie/goinsight/internal/cache"
+	"github.com/chuckie/goinsight/internal/domain"
+	"github.com/chuckie/goinsight/internal/llm"
+)
+
+// SetSemanticCache enables answering a question from the cached answer of a
+// differently worded one: questions are embedded with embedder, and match a
+// cached question when the cosine similarity of their embeddings is at least
+// threshold (0-1). A nil embedder disables it.
+func (fs *FeedbackService) SetSemanticCache(embedder llm.Embedder, threshold float64) {
+	fs.embedder = embedder
+	fs.semanticIndex = nil
+	if embedder != nil {
+		fs.semanticIndex = cache.NewSemanticIndex(threshold, cache.DefaultSemanticEntries, fs.queryResultsTTL)
+	}
+}
+
+// refreshKey marks the context of a background refresh of a stale answer
+type refreshKey struct{}
+
+// isRefresh reports whether ctx belongs to a background refresh
+func isRefresh(ctx context.Context) bool {
+	refreshing, _ := ctx.Value(refreshKey{}).(bool)
+	return refreshing
+}
+
+// getCachedAnswer returns the cached answer of a question whose normalized
+// form is cacheKey
+func (s *FeedbackService) getCachedAnswer(ctx context.Context, question, cacheKey string) (*domain.AskResponse, bool) {
+	cached, found, stale, err := s.cacheManager.GetRevalidatingResult(ctx, cacheKey, s.refreshAnswer)
+	if err != nil || !found {
+		return nil, false
+	}
+	response, ok := cached.(*domain.AskResponse)
+	if !ok {
+		return nil, false
+	}
+
+	matchType := domain.CacheMatchNormalized
+	if response.Question == question {
+		matchType = domain.CacheMatchExact
+	}
+	return servedFromCache(response, question, domain.CacheMatch{Type: matchType, Question: response.Question}, stale), true
+}
+
+// embedQuestion returns the question's embedding, or nil if semantic lookups
+// are disabled or the embedding fails
+func (s *FeedbackService) embedQuestion(ctx context.Context, question string) []float64 {
+	if s.embedder == nil {
+		return nil
+	}
+	embedding, err := s.embedder.Embed(ctx, question)
+	if err != nil {
+		// The question is still answered, just not matched semantically
+		if s.logger != nil {
+			s.logger.Warn("Failed to embed question", map[string]interface{}{
+				"question": question,
+				"error":    err.Error(),
+			})
+		}
+		return nil
+	}
+	return embedding
+}
+
+// getSemanticAnswer returns the cached answer of the question most similar
+// to the embedding, if one is similar enough
+func (s *FeedbackService) getSemanticAnswer(ctx context.Context, question string, embedding []float64) (*domain.AskResponse, bool) {
+	if embedding == nil || s.semanticIndex == nil {
+		return nil, false
+	}
+	match, ok := s.semanticIndex.Lookup(embedding)
+	if !ok {
+		return nil, false
+	}
+
+	cached, found, stale, err := s.cacheManager.GetRevalidatingResult(ctx, match.Key, s.refreshAnswer)
+	response, isResponse := cached.(*domain.AskResponse)
+	if err != nil || !found || !isResponse {
+		// The answer has left the cache
+		s.semanticIndex.Remove(match.Key)
+		return nil, false
+	}
+
+	if s.logger != nil {
+		s.logger.Debug("Served similar cached question", map[string]interface{}{
+			"question":   question,
+			"cached":     match.Question,
+			"similarity": match.Similarity,
+		})
+	}
+	return servedFromCache(response, question, domain.CacheMatch{
+		Type:       domain.CacheMatchSemantic,
+		Question:   match.Question,
+		Similarity: match.Similarity,
+	}, stale), true
+}
+
+// cacheAnswer caches a response under cacheKey, remembering the question's
+// embedding for semantic lookups
+func (s *FeedbackService) cacheAnswer(ctx context.Context, cacheKey string, response *domain.AskResponse, embedding []float64) {
+	if err := s.cacheManager.CacheRevalidatingResult(ctx, cacheKey, response, s.queryResultsTTL); err != nil {
+		return
+	}
+	if embedding != nil && s.semanticIndex != nil {
+		s.semanticIndex.Add(cacheKey, response.Question, embedding)
+	}
+}
+
+// refreshAnswer re-runs the question of a stale cached answer through
+// AnalyzeFeedback, which caches the new answer; it runs on the cache's
+// refresh workers
+func (s *FeedbackService) refreshAnswer(ctx context.Context, key string, stale interface{}) error {
+	cached, ok := stale.(*domain.AskResponse)
+	if !ok {
+		return fmt.Errorf("unexpected cached answer of type %T", stale)
+	}
+
+	_, err := s.AnalyzeFeedback(context.WithValue(ctx, refreshKey{}, true), cached.Question)
+	if err != nil {
+		if s.logger != nil {
+			s.logger.Warn("Failed to refresh stale cached answer", map[string]interface{}{
+				"question": cached.Question,
+				"error":    err.Error(),
+			})
+		}
+		return err
+	}
+
+	if s.logger != nil {
+		s.logger.Debug("Refreshed stale cached answer", map[string]interface{}{
+			"question": cached.Question,
+		})
+	}
+	return nil
+}
+
+// servedFromCache returns a copy of a cached response for question, recording
+// which cached question it matched and whether it is stale; the cached
+// response is shared, so it is not modified
+func servedFromCache(cached *domain.AskResponse, question string, match domain.CacheMatch, stale bool) *domain.AskResponse {
+	response := *cached
+	response.Question = question
+	response.Stale = stale
+
+	metadata := domain.ResponseMetadata{}
+	if cached.Metadata != nil {
+		metadata = *cached.Metadata
+	}
+	metadata.CacheMatch = &match
+	response.Metadata = &metadata
+
+	return &response
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/chuckie/goinsight/internal/cache"
+	"github.com/chuckie/goinsight/internal/domain"
+	"github.com/chuckie/goinsight/internal/llm"
+	"github.com/chuckie/goinsight/tests/mocks"
+)
+
+// failingEmbedder is an embedder whose provider is unavailable
+type failingEmbedder struct{}
+
+func (failingEmbedder) Embed(ctx context.Context, text string) ([]float64, error) {
+	return nil, errors.New("embedding provider unavailable")
+}
+
+// TestAnalyzeFeedbackCacheMatch tests serving reworded questions from the cache
+func TestAnalyzeFeedbackCacheMatch(t *testing.T) {
+	sqlCalls := 0
+	llmClient := &MockLLMClient{
+		GenerateSQLFn: func(ctx context.Context, question string) (string, error) {
+			sqlCalls++
+			return "SELECT * FROM feedback_enriched", nil
+		},
+	}
+	mockRepo := mocks.NewMockFeedbackRepository()
+	mockRepo.SetQueryFeedbackResult([]map[string]any{{"product_area": "billing", "count": 12}})
+	service := NewFeedbackServiceWithCache(mockRepo, llmClient, nil, cache.NewCacheManager(true, 100, 5*time.Minute))
+	service.SetSemanticCache(llm.NewMockEmbedder(), 0.6)
+
+	ctx := context.Background()
+	original := "What are the top billing issues?"
+	if _, err := service.AnalyzeFeedback(ctx, original); err != nil {
+		t.Fatalf("AnalyzeFeedback() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		question string
+		want     *domain.CacheMatch // nil for a cache miss
+	}{
+		{
+			name:     "exact",
+			question: original,
+			want:     &domain.CacheMatch{Type: domain.CacheMatchExact, Question: original},
+		},
+		{
+			name:     "normalized",
+			question: "top billing issues",
+			want:     &domain.CacheMatch{Type: domain.CacheMatchNormalized, Question: original},
+		},
+		{
+			name:     "semantic",
+			question: "top billing issues reported",
+			want:     &domain.CacheMatch{Type: domain.CacheMatchSemantic, Question: original},
+		},
+		{
+			name:     "different question",
+			question: "churn by plan",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			callsBefore := sqlCalls
+			response, err := service.AnalyzeFeedback(ctx, tt.question)
+			if err != nil {
+				t.Fatalf("AnalyzeFeedback(%q) unexpected error: %v", tt.question, err)
+			}
+			if response.Question != tt.question {
+				t.Errorf("response question = %q, want %q", response.Question, tt.question)
+			}
+
+			var got *domain.CacheMatch
+			if response.Metadata != nil {
+				got = response.Metadata.CacheMatch
+			}
+			if tt.want == nil {
+				if got != nil || sqlCalls != callsBefore+1 {
+					t.Errorf("expected a cache miss, got %+v (%d LLM calls)", got, sqlCalls-callsBefore)
+				}
+				return
+			}
+			if got == nil || got.Type != tt.want.Type || got.Question != tt.want.Question || sqlCalls != callsBefore {
+				t.Fatalf("cache_match = %+v (%d LLM calls), want %+v", got, sqlCalls-callsBefore, tt.want)
+			}
+			if (got.Type == domain.CacheMatchSemantic) != (got.Similarity > 0) {
+				t.Errorf("similarity = %v for a %s match", got.Similarity, got.Type)
+			}
+		})
+	}
+}
+
+// TestAnalyzeFeedbackEmbeddingFailure tests that questions are still answered when embedding fails
+func TestAnalyzeFeedbackEmbeddingFailure(t *testing.T) {
+	mockRepo := mocks.NewMockFeedbackRepository()
+	mockRepo.SetQueryFeedbackResult([]map[string]any{{"id": 1}})
+	service := NewFeedbackServiceWithCache(mockRepo, &MockLLMClient{}, nil, cache.NewCacheManager(true, 100, 5*time.Minute))
+	service.SetSemanticCache(failingEmbedder{}, 0.8)
+
+	ctx := context.Background()
+	if _, err := service.AnalyzeFeedback(ctx, "top billing issues"); err != nil {
+		t.Fatalf("AnalyzeFeedback() unexpected error: %v", err)
+	}
+
+	response, err := service.AnalyzeFeedback(ctx, "Top billing issues?")
+	if err != nil {
+		t.Fatalf("AnalyzeFeedback() unexpected error: %v", err)
+	}
+	if response.Metadata == nil || response.Metadata.CacheMatch == nil || response.Metadata.CacheMatch.Type != domain.CacheMatchNormalized {
+		t.Errorf("expected a normalized cache match, got %+v", response.Metadata)
+	}
+}
+
+// TestAnalyzeFeedbackStaleWhileRevalidate tests serving an expired answer while it is re-answered in the background
+func TestAnalyzeFeedbackStaleWhileRevalidate(t *testing.T) {
+	var insights atomic.Int32
+	llmClient := &MockLLMClient{
+		GenerateInsightFn: func(ctx context.Context, question string, results []map[string]any) (string, error) {
+			n := insights.Add(1)
+			return fmt.Sprintf(`{"summary": "Answer %d", "recommendations": [], "actions": []}`, n), nil
+		},
+	}
+	mockRepo := mocks.NewMockFeedbackRepository()
+	mockRepo.SetQueryFeedbackResult([]map[string]any{{"product_area": "billing", "count": 12}})
+	cacheManager := cache.NewCacheManager(true, 100, time.Minute)
+	defer cacheManager.Close()
+	cacheManager.SetStaleWhileRevalidate(time.Minute, 1)
+	service := NewFeedbackServiceWithCache(mockRepo, llmClient, nil, cacheManager)
+	service.SetCacheTTL(20 * time.Millisecond)
+
+	ctx := context.Background()
+	original := "What are the top billing issues?"
+	if _, err := service.AnalyzeFeedback(ctx, original); err != nil {
+		t.Fatalf("AnalyzeFeedback() unexpected error: %v", err)
+	}
+	time.Sleep(30 * time.Millisecond)
+
+	// Expired: the old answer is served right away, marked stale
+	response, err := service.AnalyzeFeedback(ctx, "top billing issues")
+	if err != nil {
+		t.Fatalf("AnalyzeFeedback() unexpected error: %v", err)
+	}
+	if !response.Stale || response.Summary != "Answer 1" || response.Metadata == nil || response.Metadata.CacheMatch == nil {
+		t.Fatalf("response = %+v, want the stale cached answer", response)
+	}
+
+	// The cached question is re-answered in the background
+	for deadline := time.Now().Add(2 * time.Second); cacheManager.GetCacheStats(ctx).Refreshes == 0; time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("GetCacheStats() = %+v, want a completed refresh", cacheManager.GetCacheStats(ctx))
+		}
+	}
+	if insights.Load() != 2 {
+		t.Errorf("GenerateInsight calls = %d, want 2", insights.Load())
+	}
+
+	response, err = service.AnalyzeFeedback(ctx, original)
+	if err != nil {
+		t.Fatalf("AnalyzeFeedback() unexpected error: %v", err)
+	}
+	if response.Summary != "Answer 2" {
+		t.Errorf("summary after refresh = %q, want the refreshed answer", response.Summary)
+	}
+}
+
+// TestRefreshAnswerStopsWithContext tests that a refresh outliving its timeout is cancelled before it returns
+func TestRefreshAnswerStopsWithContext(t *testing.T) {
+	var cancelled atomic.Bool
+	llmClient := &MockLLMClient{
+		GenerateSQLFn: func(ctx context.Context, question string) (string, error) {
+			<-ctx.Done()
+			cancelled.Store(true)
+			return "", ctx.Err()
+		},
+	}
+	cacheManager := cache.NewCacheManager(true, 100, time.Minute)
+	defer cacheManager.Close()
+	service := NewFeedbackServiceWithCache(mocks.NewMockFeedbackRepository(), llmClient, nil, cacheManager)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := service.refreshAnswer(ctx, "top billing issues", &domain.AskResponse{Question: "top billing issues"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("refreshAnswer() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if !cancelled.Load() {
+		t.Error("refreshAnswer() returned while its work was still running")
+	}
+}
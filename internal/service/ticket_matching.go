@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/chuckie/goinsight/internal/domain"
+	"github.com/chuckie/goinsight/internal/jira"
+	"github.com/chuckie/goinsight/internal/tracker"
+)
+
+// ticketMatchThreshold is how similar an action title must be to an open
+// ticket's to be treated as the same piece of work
+const ticketMatchThreshold = jira.DefaultDuplicateThreshold
+
+// validateMatchStrategy checks that the strategy is known and, for
+// comment_on_match, that open tickets can be found and commented on
+func (s *FeedbackService) validateMatchStrategy(strategy string) error {
+	switch strategy {
+	case "", domain.JiraMatchCreate:
+		return nil
+	case domain.JiraMatchCommentOnMatch:
+		if _, ok := s.issueTracker.(tracker.Commenter); !ok {
+			return fmt.Errorf("the %s issue tracker does not support %s", s.issueTracker.Name(), strategy)
+		}
+		if s.insightTickets == nil {
+			return fmt.Errorf("%s requires insight ticket tracking", strategy)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown match_strategy %q (must be %s or %s)", strategy, domain.JiraMatchCreate, domain.JiraMatchCommentOnMatch)
+	}
+}
+
+// matchOpenTickets points each ticket at the open insight ticket filed for
+// the same product area with the most similar action, if one is similar
+// enough, so the insight is added to it as a comment instead of a new issue
+func (s *FeedbackService) matchOpenTickets(ctx context.Context, req JiraTicketRequest, tickets []domain.JiraTicketSpec) error {
+	open := true
+	candidatesByArea := make(map[string][]domain.InsightTicket)
+
+	for i := range tickets {
+		area := strings.ToLower(strings.TrimSpace(tickets[i].Attributes[domain.JiraAttrProductArea]))
+		candidates, ok := candidatesByArea[area]
+		if !ok {
+			// An empty product area lists every open ticket; only those
+			// without an area are kept below
+			listed, err := s.insightTickets.ListTickets(ctx, domain.InsightTicketFilter{ProductArea: area, Open: &open})
+			if err != nil {
+				return fmt.Errorf("failed to find open tickets: %w", err)
+			}
+			for _, ticket := range listed {
+				if ticket.Tracker == s.issueTracker.Name() && strings.EqualFold(ticket.ProductArea, area) {
+					candidates = append(candidates, ticket)
+				}
+			}
+			candidatesByArea[area] = candidates
+		}
+
+		var best *domain.InsightTicket
+		bestScore := ticketMatchThreshold
+		for j := range candidates {
+			if score := jira.Similarity(actionTitle(tickets[i].Action, tickets[i].Summary), actionTitle(candidates[j].Action, candidates[j].Summary)); score >= bestScore {
+				best, bestScore = &candidates[j], score
+			}
+		}
+		if best != nil {
+			tickets[i].CommentOn = best.Key
+			tickets[i].Comment = evidenceComment(req.Question, req.Summary)
+		}
+	}
+	return nil
+}
+
+// actionTitle returns the action a ticket was filed for, or its summary
+func actionTitle(action, summary string) string {
+	if action != "" {
+		return action
+	}
+	return summary
+}
+
+// evidenceComment is the comment added to a matched ticket; the Jira client
+// appends the updated data preview
+func evidenceComment(question, summary string) string {
+	var b strings.Builder
+	b.WriteString("New evidence for this issue")
+	if question = strings.TrimSpace(question); question != "" {
+		b.WriteString(" from the question: " + question)
+	}
+	if summary = strings.TrimSpace(summary); summary != "" {
+		b.WriteString("\n\n" + summary)
+	}
+	return b.String()
+}
+
+// commentOnTickets adds the comments of the tickets matched to an open issue,
+// returning their outcomes by ticket index
+func (s *FeedbackService) commentOnTickets(ctx context.Context, tickets []domain.JiraTicketSpec) map[int]domain.JiraActionResult {
+	actions := make(map[int]domain.JiraActionResult)
+	commenter, canComment := s.issueTracker.(tracker.Commenter)
+	for i, ticket := range tickets {
+		if ticket.CommentOn == "" {
+			continue
+		}
+		action := domain.JiraActionResult{
+			Summary:     ticket.Summary,
+			Status:      domain.JiraActionCommented,
+			Key:         ticket.CommentOn,
+			Fingerprint: ticket.Fingerprint,
+		}
+		var err error
+		if canComment {
+			err = commenter.CommentOnIssue(ctx, ticket)
+		} else {
+			err = fmt.Errorf("the %s issue tracker does not support comments", s.issueTracker.Name())
+		}
+		if err != nil {
+			action.Status = domain.JiraActionFailed
+			action.Error = err.Error()
+		}
+		actions[i] = action
+	}
+	return actions
+}
+
+// mergeComments combines the outcomes of commented tickets with the result
+// of filing the others, in ticket order
+func mergeComments(tickets []domain.JiraTicketSpec, comments map[int]domain.JiraActionResult, filed *domain.JiraCreationResult) *domain.JiraCreationResult {
+	result := &domain.JiraCreationResult{
+		TicketSpecs:    tickets,
+		CreatedTickets: make([]domain.JiraCreateResponse, 0),
+		Actions:        make([]domain.JiraActionResult, 0, len(tickets)),
+		Errors:         make([]string, 0),
+	}
+	var filedActions []domain.JiraActionResult
+	if filed != nil {
+		result.CreatedTickets = filed.CreatedTickets
+		result.Epic = filed.Epic
+		filedActions = filed.Actions
+	}
+
+	for i, ticket := range tickets {
+		action, ok := comments[i]
+		if !ok && len(filedActions) > 0 {
+			action, filedActions = filedActions[0], filedActions[1:]
+		}
+		if action.Status == domain.JiraActionFailed {
+			result.Errors = append(result.Errors, fmt.Sprintf("Ticket %d (%s): %s", i+1, ticket.Summary, action.Error))
+		}
+		result.Actions = append(result.Actions, action)
+	}
+	return result
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chuckie/goinsight/internal/domain"
+	"github.com/chuckie/goinsight/tests/mocks"
+)
+
+// TestCreateJiraTicketsCommentOnMatch tests that actions matching an open ticket in the same product area become comments
+func TestCreateJiraTicketsCommentOnMatch(t *testing.T) {
+	llmClient := &MockLLMClient{
+		GenerateFn: func(ctx context.Context, prompt string) (string, error) {
+			return `{"tickets": [{"issue_type": "Story", "summary": "Speed up refund processing"}, {"issue_type": "Task", "summary": "Explain invoice fees"}]}`, nil
+		},
+	}
+	issueTracker := &statusTracker{}
+	tickets := mocks.NewMockInsightTicketRepository()
+	tickets.Tickets = []domain.InsightTicket{
+		{Key: "FB-7", Tracker: "jira", Action: "Speed up refunds", ProductArea: "billing", StatusCategory: domain.JiraStatusCategoryInProgress},
+		{Key: "FB-8", Tracker: "jira", Action: "Clarify invoice fees", ProductArea: "search", StatusCategory: domain.JiraStatusCategoryToDo},
+		{Key: "FB-9", Tracker: "jira", Action: "Clarify invoice fees", ProductArea: "billing", StatusCategory: domain.JiraStatusCategoryDone},
+	}
+	service := NewFeedbackService(mocks.NewMockFeedbackRepository(), llmClient, issueTracker)
+	service.SetInsightTicketRepository(tickets)
+
+	result, err := service.CreateJiraTickets(context.Background(), JiraTicketRequest{
+		Question:    "What are the top billing issues?",
+		Summary:     "Refund complaints doubled this month.",
+		Actions:     []domain.ActionItem{{Title: "Speed up refunds"}, {Title: "Clarify invoice fees"}},
+		DataPreview: []map[string]any{{"product_area": "billing", "count": 42}},
+		Meta:        JiraMetadata{ProjectKey: "FB", MatchStrategy: domain.JiraMatchCommentOnMatch},
+	})
+	if err != nil {
+		t.Fatalf("CreateJiraTickets() unexpected error: %v", err)
+	}
+
+	// The refunds action matches the open billing ticket; the invoice action
+	// only matches tickets in another area or already resolved
+	if action := result.Actions[0]; action.Status != domain.JiraActionCommented || action.Key != "FB-7" {
+		t.Errorf("action 0 = %+v, want commented on FB-7", action)
+	}
+	if action := result.Actions[1]; action.Status != domain.JiraActionCreated {
+		t.Errorf("action 1 = %+v, want created", action)
+	}
+	if len(issueTracker.comments) != 1 || issueTracker.created != 1 {
+		t.Fatalf("comments = %d, created = %d, want 1 each", len(issueTracker.comments), issueTracker.created)
+	}
+
+	comment := issueTracker.comments[0]
+	if !strings.Contains(comment.Comment, "What are the top billing issues?") || !strings.Contains(comment.Comment, "Refund complaints doubled") {
+		t.Errorf("comment = %q, want the question and summary", comment.Comment)
+	}
+	if len(comment.DataPreview) != 1 {
+		t.Errorf("comment data preview = %v, want the new counts", comment.DataPreview)
+	}
+
+	// Only the new ticket is tracked
+	if len(tickets.Tickets) != 4 {
+		t.Errorf("tracked %d tickets, want 4", len(tickets.Tickets))
+	}
+}
+
+// TestCreateJiraTicketsIgnoresGeneratedCommentTargets tests that comment targets and attributes in the LLM's reply are dropped
+func TestCreateJiraTicketsIgnoresGeneratedCommentTargets(t *testing.T) {
+	llmClient := &MockLLMClient{
+		GenerateFn: func(ctx context.Context, prompt string) (string, error) {
+			return `{"tickets": [{"summary": "Speed up refunds", "comment_on": "SEC-1", "comment": "pwned", "attributes": {"product_area": "security"}}]}`, nil
+		},
+	}
+	issueTracker := &statusTracker{}
+	service := NewFeedbackService(mocks.NewMockFeedbackRepository(), llmClient, issueTracker)
+
+	result, err := service.CreateJiraTickets(context.Background(), JiraTicketRequest{
+		Question: "What are the top billing issues?",
+		Actions:  []domain.ActionItem{{Title: "Speed up refunds"}},
+		Meta:     JiraMetadata{ProjectKey: "FB"},
+	})
+	if err != nil {
+		t.Fatalf("CreateJiraTickets() unexpected error: %v", err)
+	}
+	if len(issueTracker.comments) != 0 || issueTracker.created != 1 {
+		t.Fatalf("comments = %d, created = %d, want only the new ticket", len(issueTracker.comments), issueTracker.created)
+	}
+	if action := result.Actions[0]; action.Status != domain.JiraActionCreated {
+		t.Errorf("action = %+v, want created", action)
+	}
+	if spec := result.TicketSpecs[0]; spec.CommentOn != "" || spec.Attributes[domain.JiraAttrProductArea] == "security" {
+		t.Errorf("spec = %+v, want no generated comment target or attributes", spec)
+	}
+}
+
+// TestConfirmJiraDraftCommentsAfterCreation tests that a draft whose new tickets fail to be created posts no comments, so confirming it again comments once
+func TestConfirmJiraDraftCommentsAfterCreation(t *testing.T) {
+	issueTracker := &statusTracker{createErr: errors.New("epic creation failed")}
+	drafts := mocks.NewMockJiraDraftRepository()
+	service := NewFeedbackService(mocks.NewMockFeedbackRepository(), &MockLLMClient{}, issueTracker)
+	service.SetJiraDraftRepository(drafts)
+
+	ctx := context.Background()
+	now := time.Now()
+	drafts.SaveDraft(ctx, &domain.JiraDraft{
+		ID:       "draft",
+		Question: "What are the top billing issues?",
+		Tickets: []domain.JiraTicketSpec{
+			{Summary: "Speed up refunds", CommentOn: "FB-7", Comment: "New evidence for this issue"},
+			{Summary: "Explain invoice fees"},
+		},
+		CreatedAt: now,
+		ExpiresAt: now.Add(time.Hour),
+	})
+
+	if _, err := service.ConfirmJiraDraft(ctx, "draft", nil); err == nil {
+		t.Fatal("ConfirmJiraDraft() expected the creation error")
+	}
+	if len(issueTracker.comments) != 0 {
+		t.Fatalf("a failed confirmation posted %d comments", len(issueTracker.comments))
+	}
+
+	issueTracker.createErr = nil
+	result, err := service.ConfirmJiraDraft(ctx, "draft", nil)
+	if err != nil {
+		t.Fatalf("ConfirmJiraDraft() unexpected error on retry: %v", err)
+	}
+	if len(issueTracker.comments) != 1 || issueTracker.created != 1 || len(result.Actions) != 2 {
+		t.Errorf("comments = %d, created = %d, actions = %+v; want one of each", len(issueTracker.comments), issueTracker.created, result.Actions)
+	}
+}
+
+// TestConfirmJiraDraftCommentTargets tests that edits can only comment on open insight tickets
+func TestConfirmJiraDraftCommentTargets(t *testing.T) {
+	tests := []struct {
+		name      string
+		commentOn string
+		wantErr   bool
+	}{
+		{"open insight ticket", "FB-7", false},
+		{"resolved insight ticket", "FB-9", true},
+		{"untracked issue", "OPS-1", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issueTracker := &statusTracker{}
+			tickets := mocks.NewMockInsightTicketRepository()
+			tickets.Tickets = []domain.InsightTicket{
+				{Key: "FB-7", Tracker: "jira", StatusCategory: domain.JiraStatusCategoryInProgress},
+				{Key: "FB-9", Tracker: "jira", StatusCategory: domain.JiraStatusCategoryDone},
+			}
+			drafts := mocks.NewMockJiraDraftRepository()
+			service := NewFeedbackService(mocks.NewMockFeedbackRepository(), &MockLLMClient{}, issueTracker)
+			service.SetInsightTicketRepository(tickets)
+			service.SetJiraDraftRepository(drafts)
+
+			ctx := context.Background()
+			now := time.Now()
+			drafts.SaveDraft(ctx, &domain.JiraDraft{ID: "draft", Tickets: []domain.JiraTicketSpec{{Summary: "Speed up refunds"}}, CreatedAt: now, ExpiresAt: now.Add(time.Hour)})
+
+			_, err := service.ConfirmJiraDraft(ctx, "draft", []domain.JiraTicketSpec{{Summary: "Speed up refunds", CommentOn: tt.commentOn}})
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidJiraTickets) || len(issueTracker.comments) != 0 {
+					t.Errorf("ConfirmJiraDraft() error = %v (%d comments), want ErrInvalidJiraTickets", err, len(issueTracker.comments))
+				}
+				return
+			}
+			if err != nil || len(issueTracker.comments) != 1 {
+				t.Errorf("ConfirmJiraDraft() error = %v (%d comments), want one comment", err, len(issueTracker.comments))
+			}
+		})
+	}
+}
+
+// TestMatchStrategyValidation tests the strategies a request can ask for
+func TestMatchStrategyValidation(t *testing.T) {
+	tests := []struct {
+		name       string
+		strategy   string
+		canComment bool
+		tracking   bool
+		wantErr    string
+	}{
+		{"unknown strategy", "merge", true, true, "unknown match_strategy"},
+		{"tracker without comments", domain.JiraMatchCommentOnMatch, false, true, "does not support comment_on_match"},
+		{"tracking disabled", domain.JiraMatchCommentOnMatch, true, false, "requires insight ticket tracking"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var issueTracker interface {
+				Name() string
+				CreateIssues(context.Context, []domain.JiraTicketSpec) (*domain.JiraCreationResult, error)
+			} = &recordingTracker{}
+			if tt.canComment {
+				issueTracker = &statusTracker{}
+			}
+			service := NewFeedbackService(mocks.NewMockFeedbackRepository(), &MockLLMClient{}, issueTracker)
+			if tt.tracking {
+				service.SetInsightTicketRepository(mocks.NewMockInsightTicketRepository())
+			}
+
+			_, err := service.CreateJiraTickets(context.Background(), JiraTicketRequest{
+				Actions: []domain.ActionItem{{Title: "Speed up refunds"}},
+				Meta:    JiraMetadata{ProjectKey: "FB", MatchStrategy: tt.strategy},
+			})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("CreateJiraTickets() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,295 @@
+package sqlguard
+
+// Node is any element of the parsed syntax tree
+type Node interface {
+	node()
+}
+
+// Stmt is a top-level statement or the body of a CTE
+type Stmt interface {
+	Node
+	stmt()
+}
+
+// Expr is a scalar or boolean expression
+type Expr interface {
+	Node
+	expr()
+}
+
+// TableExpr is an item of a FROM clause
+type TableExpr interface {
+	Node
+	tableExpr()
+}
+
+// SelectStmt is a SELECT query. Set operations (UNION, INTERSECT, EXCEPT)
+// are represented with SetOp and the Left/Right operands, mirroring
+// PostgreSQL's own SelectStmt node.
+type SelectStmt struct {
+	With *WithClause
+
+	Distinct   bool
+	DistinctOn []Expr
+	Targets    []*Target
+	Into       *TableName
+	From       []TableExpr
+	Where      Expr
+	GroupBy    []Expr
+	Having     Expr
+	Windows    []*WindowDef
+
+	SetOp  string // "union", "intersect", "except" or "" for a simple SELECT
+	SetAll bool
+	Left   *SelectStmt
+	Right  *SelectStmt
+
+	OrderBy  []*OrderItem
+	Limit    Expr
+	LimitAll bool
+	Offset   Expr
+	Locking  []string // e.g. "for update", "for share"
+
+	// LimitPos is the byte offset of the LIMIT/FETCH clause (or -1 if absent)
+	// and LimitEndPos the offset just past it
+	// EndPos is the byte offset just past the statement
+	LimitPos    int
+	LimitEndPos int
+	EndPos      int
+}
+
+// OtherStmt is any statement that is not a SELECT (INSERT, DELETE, COPY, ...).
+// Only its leading keyword is retained; the validator rejects it.
+type OtherStmt struct {
+	Keyword string
+	Pos     int
+}
+
+// WithClause is a WITH [RECURSIVE] list of common table expressions
+type WithClause struct {
+	Recursive bool
+	CTEs      []*CTE
+}
+
+// CTE is a single common table expression
+type CTE struct {
+	Name    string
+	Columns []string
+	Query   Stmt
+}
+
+// Target is a single item of the SELECT list
+type Target struct {
+	Expr  Expr
+	Alias string
+}
+
+// OrderItem is an ORDER BY element
+type OrderItem struct {
+	Expr Expr
+	Desc bool
+}
+
+// WindowDef is a named window from the WINDOW clause
+type WindowDef struct {
+	Name string
+	Spec *WindowSpec
+}
+
+// WindowSpec is the content of an OVER (...) clause
+type WindowSpec struct {
+	Name        string
+	PartitionBy []Expr
+	OrderBy     []*OrderItem
+	Frame       []Expr // bounds of a ROWS/RANGE/GROUPS frame
+}
+
+// TableName is a possibly schema-qualified relation name
+type TableName struct {
+	Schema string
+	Name   string
+	Pos    int
+}
+
+// TableRef references a relation in the FROM clause
+type TableRef struct {
+	Table *TableName
+	Alias string
+}
+
+// SubqueryRef is a (SELECT ...) alias item in the FROM clause
+type SubqueryRef struct {
+	Query   Stmt
+	Alias   string
+	Lateral bool
+}
+
+// FuncTableRef is a set-returning function call in the FROM clause
+type FuncTableRef struct {
+	Func    *FuncCall
+	Alias   string
+	Lateral bool
+}
+
+// JoinExpr is an explicit JOIN between two FROM items
+type JoinExpr struct {
+	Kind  string // "inner", "left", "right", "full", "cross"
+	Left  TableExpr
+	Right TableExpr
+	On    Expr
+	Using []string
+}
+
+// ColumnRef references a column, optionally qualified, or a (qualified) star
+type ColumnRef struct {
+	Parts []string
+	Star  bool
+}
+
+// Literal is a constant value
+type Literal struct {
+	Kind  string // "string", "number", "bool", "null"
+	Value string
+}
+
+// Param is a positional parameter such as $1
+type Param struct {
+	Name string
+}
+
+// TypedLiteral is a constant preceded by a type name, e.g. INTERVAL '30 days'
+type TypedLiteral struct {
+	Type  string
+	Value string
+}
+
+// FuncCall is a function or aggregate invocation
+type FuncCall struct {
+	Name        []string
+	Args        []Expr
+	Star        bool
+	Distinct    bool
+	OrderBy     []*OrderItem
+	WithinGroup []*OrderItem
+	Filter      Expr
+	Over        *WindowSpec
+	Pos         int
+}
+
+// QualifiedName returns the dot-joined function name
+func (f *FuncCall) QualifiedName() string {
+	name := ""
+	for i, part := range f.Name {
+		if i > 0 {
+			name += "."
+		}
+		name += part
+	}
+	return name
+}
+
+// SubqueryExpr is a subquery used as an expression (scalar, EXISTS, ARRAY, ANY/ALL)
+type SubqueryExpr struct {
+	Kind  string // "", "exists", "array", "any", "all"
+	Query Stmt
+}
+
+// BinaryExpr applies an infix operator or keyword (AND, LIKE, IN, ...)
+type BinaryExpr struct {
+	Op    string
+	Left  Expr
+	Right Expr
+}
+
+// UnaryExpr applies a prefix (NOT, -) or postfix (IS NULL) operator
+type UnaryExpr struct {
+	Op      string
+	Operand Expr
+}
+
+// BetweenExpr is expr [NOT] BETWEEN low AND high
+type BetweenExpr struct {
+	Expr Expr
+	Not  bool
+	Low  Expr
+	High Expr
+}
+
+// CaseExpr is a CASE expression
+type CaseExpr struct {
+	Operand Expr
+	Whens   []*WhenClause
+	Else    Expr
+}
+
+// WhenClause is a WHEN ... THEN ... branch of a CASE expression
+type WhenClause struct {
+	Cond   Expr
+	Result Expr
+}
+
+// CastExpr is CAST(expr AS type) or expr::type
+type CastExpr struct {
+	Expr Expr
+	Type string
+}
+
+// ListExpr is a parenthesized expression list, e.g. the right side of IN
+type ListExpr struct {
+	Items []Expr
+}
+
+// ArrayExpr is an ARRAY[...] constructor
+type ArrayExpr struct {
+	Elems []Expr
+}
+
+// SubscriptExpr is expr[index] or expr[lower:upper]
+type SubscriptExpr struct {
+	Expr  Expr
+	Index []Expr
+}
+
+func (*SelectStmt) node()    {}
+func (*OtherStmt) node()     {}
+func (*TableRef) node()      {}
+func (*SubqueryRef) node()   {}
+func (*FuncTableRef) node()  {}
+func (*JoinExpr) node()      {}
+func (*ColumnRef) node()     {}
+func (*Literal) node()       {}
+func (*Param) node()         {}
+func (*TypedLiteral) node()  {}
+func (*FuncCall) node()      {}
+func (*SubqueryExpr) node()  {}
+func (*BinaryExpr) node()    {}
+func (*UnaryExpr) node()     {}
+func (*BetweenExpr) node()   {}
+func (*CaseExpr) node()      {}
+func (*CastExpr) node()      {}
+func (*ListExpr) node()      {}
+func (*ArrayExpr) node()     {}
+func (*SubscriptExpr) node() {}
+
+func (*SelectStmt) stmt() {}
+func (*OtherStmt) stmt()  {}
+
+func (*TableRef) tableExpr()     {}
+func (*SubqueryRef) tableExpr()  {}
+func (*FuncTableRef) tableExpr() {}
+func (*JoinExpr) tableExpr()     {}
+
+func (*ColumnRef) expr()     {}
+func (*Literal) expr()       {}
+func (*Param) expr()         {}
+func (*TypedLiteral) expr()  {}
+func (*FuncCall) expr()      {}
+func (*SubqueryExpr) expr()  {}
+func (*BinaryExpr) expr()    {}
+func (*UnaryExpr) expr()     {}
+func (*BetweenExpr) expr()   {}
+func (*CaseExpr) expr()      {}
+func (*CastExpr) expr()      {}
+func (*ListExpr) expr()      {}
+func (*ArrayExpr) expr()     {}
+func (*SubscriptExpr) expr() {}
@@ -0,0 +1,255 @@
+package sqlguard
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TokenKind identifies the lexical class of a token
+type TokenKind int
+
+const (
+	TokenEOF TokenKind = iota
+	TokenIdent
+	TokenQuotedIdent
+	TokenString
+	TokenNumber
+	TokenParam
+	TokenOperator
+	TokenPunct
+)
+
+// Token is a single lexical unit of a SQL statement
+type Token struct {
+	Kind  TokenKind
+	Value string // identifiers are lowercased, quoted identifiers keep their case
+	Raw   string // original source text
+	Pos   int    // byte offset of the token in the input
+}
+
+// isKeyword reports whether the token is the given (lowercase) unquoted keyword
+func (t Token) isKeyword(kw string) bool {
+	return t.Kind == TokenIdent && t.Value == kw
+}
+
+// multiCharOperators are matched greedily before single-character operators
+var multiCharOperators = []string{
+	"->>", "#>>", "!~*", "!~~",
+	"<>", "!=", "<=", ">=", "||", "->", "#>", "@>", "<@", "~~", "!~", "~*", "&&", "<<", ">>",
+}
+
+// Tokenize splits a PostgreSQL statement into tokens, dropping comments and whitespace
+func Tokenize(input string) ([]Token, error) {
+	var tokens []Token
+	i := 0
+	n := len(input)
+
+	for i < n {
+		c := input[i]
+
+		// Whitespace
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' {
+			i++
+			continue
+		}
+
+		// Line comment
+		if c == '-' && i+1 < n && input[i+1] == '-' {
+			for i < n && input[i] != '\n' {
+				i++
+			}
+			continue
+		}
+
+		// Block comment (PostgreSQL allows nesting)
+		if c == '/' && i+1 < n && input[i+1] == '*' {
+			depth := 0
+			start := i
+			for i < n {
+				if i+1 < n && input[i] == '/' && input[i+1] == '*' {
+					depth++
+					i += 2
+					continue
+				}
+				if i+1 < n && input[i] == '*' && input[i+1] == '/' {
+					depth--
+					i += 2
+					if depth == 0 {
+						break
+					}
+					continue
+				}
+				i++
+			}
+			if depth != 0 {
+				return nil, fmt.Errorf("unterminated comment at position %d", start)
+			}
+			continue
+		}
+
+		start := i
+
+		// String constants, including E'', B'', X'' and N'' prefixed forms
+		if c == '\'' || (isStringPrefix(c) && i+1 < n && input[i+1] == '\'') {
+			escapes := c == 'e' || c == 'E'
+			if c != '\'' {
+				i++
+			}
+			end, value, err := scanQuoted(input, i, '\'', escapes)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+			tokens = append(tokens, Token{Kind: TokenString, Value: value, Raw: input[start:i], Pos: start})
+			continue
+		}
+
+		// Quoted identifier
+		if c == '"' {
+			end, value, err := scanQuoted(input, i, '"', false)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+			tokens = append(tokens, Token{Kind: TokenQuotedIdent, Value: value, Raw: input[start:i], Pos: start})
+			continue
+		}
+
+		// Positional parameter ($1) or dollar-quoted string ($$...$$, $tag$...$tag$)
+		if c == '$' {
+			if i+1 < n && isDigit(input[i+1]) {
+				i++
+				for i < n && isDigit(input[i]) {
+					i++
+				}
+				tokens = append(tokens, Token{Kind: TokenParam, Value: input[start:i], Raw: input[start:i], Pos: start})
+				continue
+			}
+			j := i + 1
+			for j < n && (isIdentStart(input[j]) || isDigit(input[j])) {
+				j++
+			}
+			if j < n && input[j] == '$' {
+				tag := input[i : j+1]
+				bodyStart := j + 1
+				closeIdx := strings.Index(input[bodyStart:], tag)
+				if closeIdx < 0 {
+					return nil, fmt.Errorf("unterminated dollar-quoted string at position %d", start)
+				}
+				i = bodyStart + closeIdx + len(tag)
+				tokens = append(tokens, Token{Kind: TokenString, Value: input[bodyStart : bodyStart+closeIdx], Raw: input[start:i], Pos: start})
+				continue
+			}
+			return nil, fmt.Errorf("unexpected character '$' at position %d", start)
+		}
+
+		// Numbers
+		if isDigit(c) || (c == '.' && i+1 < n && isDigit(input[i+1])) {
+			for i < n && (isDigit(input[i]) || input[i] == '.') {
+				i++
+			}
+			if i < n && (input[i] == 'e' || input[i] == 'E') {
+				j := i + 1
+				if j < n && (input[j] == '+' || input[j] == '-') {
+					j++
+				}
+				if j < n && isDigit(input[j]) {
+					i = j
+					for i < n && isDigit(input[i]) {
+						i++
+					}
+				}
+			}
+			tokens = append(tokens, Token{Kind: TokenNumber, Value: input[start:i], Raw: input[start:i], Pos: start})
+			continue
+		}
+
+		// Identifiers and keywords
+		if isIdentStart(c) {
+			for i < n && isIdentChar(input[i]) {
+				i++
+			}
+			raw := input[start:i]
+			tokens = append(tokens, Token{Kind: TokenIdent, Value: strings.ToLower(raw), Raw: raw, Pos: start})
+			continue
+		}
+
+		// Punctuation
+		if c == ':' && i+1 < n && input[i+1] == ':' {
+			i += 2
+			tokens = append(tokens, Token{Kind: TokenPunct, Value: "::", Raw: "::", Pos: start})
+			continue
+		}
+		if strings.IndexByte("(),;.[]:", c) >= 0 {
+			i++
+			tokens = append(tokens, Token{Kind: TokenPunct, Value: string(c), Raw: string(c), Pos: start})
+			continue
+		}
+
+		// Operators
+		if strings.IndexByte("+-*/<>=~!@#%^&|`?", c) >= 0 {
+			op := string(c)
+			for _, candidate := range multiCharOperators {
+				if strings.HasPrefix(input[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			i += len(op)
+			tokens = append(tokens, Token{Kind: TokenOperator, Value: op, Raw: op, Pos: start})
+			continue
+		}
+
+		return nil, fmt.Errorf("unexpected character %q at position %d", c, start)
+	}
+
+	tokens = append(tokens, Token{Kind: TokenEOF, Pos: n})
+	return tokens, nil
+}
+
+// scanQuoted reads a quoted literal starting at the opening quote and returns
+// the offset just past the closing quote along with the unescaped content
+func scanQuoted(input string, i int, quote byte, backslashEscapes bool) (int, string, error) {
+	start := i
+	var sb strings.Builder
+	i++ // opening quote
+	for i < len(input) {
+		c := input[i]
+		if backslashEscapes && c == '\\' && i+1 < len(input) {
+			sb.WriteByte(input[i+1])
+			i += 2
+			continue
+		}
+		if c == quote {
+			if i+1 < len(input) && input[i+1] == quote {
+				sb.WriteByte(quote)
+				i += 2
+				continue
+			}
+			return i + 1, sb.String(), nil
+		}
+		sb.WriteByte(c)
+		i++
+	}
+	return 0, "", fmt.Errorf("unterminated quoted literal at position %d", start)
+}
+
+func isStringPrefix(c byte) bool {
+	switch c {
+	case 'e', 'E', 'b', 'B', 'x', 'X', 'n', 'N':
+		return true
+	}
+	return false
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || isDigit(c) || c == '$'
+}
@@ -0,0 +1,61 @@
+package sqlguard
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// DefaultMaxRows is the default cap on rows returned by a generated query
+const DefaultMaxRows = 1000
+
+// LimitRewrite is the result of EnforceLimit
+type LimitRewrite struct {
+	// SQL is the query to execute
+	SQL string
+
+	// Applied reports whether a LIMIT was added or an existing one tightened
+	Applied bool
+}
+
+// EnforceLimit rewrites a validated SELECT so that it returns at most maxRows
+// rows. A missing LIMIT is appended; LIMIT ALL, a non-constant LIMIT or one
+// above maxRows is replaced. A constant LIMIT within maxRows is kept as is.
+// stmt must be the statement returned by Validate for the same sql.
+func EnforceLimit(sql string, stmt *SelectStmt, maxRows int) LimitRewrite {
+	if stmt == nil || maxRows <= 0 {
+		return LimitRewrite{SQL: sql}
+	}
+
+	clause := fmt.Sprintf("LIMIT %d", maxRows)
+
+	if stmt.LimitPos < 0 {
+		return LimitRewrite{
+			SQL:     sql[:stmt.EndPos] + " " + clause,
+			Applied: true,
+		}
+	}
+
+	if !stmt.LimitAll {
+		if n, ok := constantLimit(stmt.Limit); ok && n <= maxRows {
+			return LimitRewrite{SQL: sql[:stmt.EndPos]}
+		}
+	}
+
+	return LimitRewrite{
+		SQL:     sql[:stmt.LimitPos] + clause + sql[stmt.LimitEndPos:stmt.EndPos],
+		Applied: true,
+	}
+}
+
+// constantLimit returns the value of an integer literal LIMIT
+func constantLimit(expr Expr) (int, bool) {
+	lit, ok := expr.(*Literal)
+	if !ok || lit.Kind != "number" {
+		return 0, false
+	}
+	n, err := strconv.Atoi(lit.Value)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n, true
+}
@@ -0,0 +1,98 @@
+package sqlguard
+
+import "testing"
+
+// TestEnforceLimit tests adding and tightening LIMIT clauses on validated queries
+func TestEnforceLimit(t *testing.T) {
+	v := NewDefaultValidator()
+
+	tests := []struct {
+		name        string
+		sql         string
+		maxRows     int
+		want        string
+		wantApplied bool
+	}{
+		{
+			name:        "missing limit is appended",
+			sql:         "SELECT * FROM feedback_enriched ORDER BY created_at DESC",
+			maxRows:     100,
+			want:        "SELECT * FROM feedback_enriched ORDER BY created_at DESC LIMIT 100",
+			wantApplied: true,
+		},
+		{
+			name:        "trailing semicolon is dropped",
+			sql:         "SELECT * FROM feedback_enriched;",
+			maxRows:     100,
+			want:        "SELECT * FROM feedback_enriched LIMIT 100",
+			wantApplied: true,
+		},
+		{
+			name:    "limit within cap is kept",
+			sql:     "SELECT * FROM feedback_enriched LIMIT 20",
+			maxRows: 100,
+			want:    "SELECT * FROM feedback_enriched LIMIT 20",
+		},
+		{
+			name:        "limit above cap is tightened",
+			sql:         "SELECT * FROM feedback_enriched LIMIT 5000 OFFSET 10",
+			maxRows:     100,
+			want:        "SELECT * FROM feedback_enriched LIMIT 100 OFFSET 10",
+			wantApplied: true,
+		},
+		{
+			name:        "limit all is replaced",
+			sql:         "SELECT * FROM feedback_enriched LIMIT ALL",
+			maxRows:     100,
+			want:        "SELECT * FROM feedback_enriched LIMIT 100",
+			wantApplied: true,
+		},
+		{
+			name:        "non-constant limit is replaced",
+			sql:         "SELECT * FROM feedback_enriched LIMIT (SELECT COUNT(*) FROM account_risk_scores)",
+			maxRows:     100,
+			want:        "SELECT * FROM feedback_enriched LIMIT 100",
+			wantApplied: true,
+		},
+		{
+			name:        "fetch first above cap is replaced",
+			sql:         "SELECT * FROM feedback_enriched OFFSET 5 FETCH FIRST 500 ROWS ONLY",
+			maxRows:     100,
+			want:        "SELECT * FROM feedback_enriched OFFSET 5 LIMIT 100",
+			wantApplied: true,
+		},
+		{
+			name:        "limit on union applies to whole query",
+			sql:         "SELECT account_id FROM feedback_enriched UNION SELECT account_id FROM account_risk_scores",
+			maxRows:     10,
+			want:        "SELECT account_id FROM feedback_enriched UNION SELECT account_id FROM account_risk_scores LIMIT 10",
+			wantApplied: true,
+		},
+		{
+			name:    "zero cap disables enforcement",
+			sql:     "SELECT * FROM feedback_enriched",
+			maxRows: 0,
+			want:    "SELECT * FROM feedback_enriched",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, err := v.Validate(tt.sql)
+			if err != nil {
+				t.Fatalf("Validate() unexpected error: %v", err)
+			}
+
+			got := EnforceLimit(tt.sql, stmt, tt.maxRows)
+			if got.SQL != tt.want {
+				t.Errorf("SQL = %q, want %q", got.SQL, tt.want)
+			}
+			if got.Applied != tt.wantApplied {
+				t.Errorf("Applied = %v, want %v", got.Applied, tt.wantApplied)
+			}
+			if _, err := v.Validate(got.SQL); err != nil {
+				t.Errorf("rewritten SQL no longer validates: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,1867 @@
+package sqlguard
+
+import (
+	"fmt"
+	"strings"
+)
+
+// reservedKeywords cannot be used as bare column names or aliases
+var reservedKeywords = map[string]bool{
+	"all": true, "and": true, "any": true, "array": true, "as": true, "asc": true,
+	"between": true, "both": true, "case": true, "cast": true, "check": true,
+	"collate": true, "column": true, "constraint": true, "create": true, "cross": true,
+	"current_date": true, "current_time": true, "current_timestamp": true,
+	"current_user": true, "default": true, "desc": true, "distinct": true, "do": true,
+	"else": true, "end": true, "except": true, "false": true, "fetch": true,
+	"filter": true, "for": true, "foreign": true, "from": true, "full": true,
+	"grant": true, "group": true, "having": true, "ilike": true, "in": true,
+	"inner": true, "intersect": true, "into": true, "is": true, "isnull": true,
+	"join": true, "lateral": true, "leading": true, "left": true, "like": true,
+	"limit": true, "localtime": true, "localtimestamp": true, "natural": true,
+	"not": true, "notnull": true, "null": true, "offset": true, "on": true,
+	"only": true, "or": true, "order": true, "outer": true, "over": true,
+	"primary": true, "references": true, "returning": true, "right": true,
+	"select": true, "session_user": true, "similar": true, "some": true,
+	"symmetric": true, "table": true, "then": true, "to": true, "trailing": true,
+	"true": true, "union": true, "unique": true, "user": true, "using": true,
+	"variadic": true, "when": true, "where": true, "window": true, "with": true,
+	"within": true,
+}
+
+// niladicFunctions are SQL-standard functions written without parentheses
+var niladicFunctions = map[string]bool{
+	"current_date": true, "current_time": true, "current_timestamp": true,
+	"localtime": true, "localtimestamp": true, "current_user": true,
+	"session_user": true, "user": true, "current_role": true, "current_catalog": true,
+	"current_schema": true,
+}
+
+// comparisonOperators bind tighter than AND/OR but looser than arithmetic
+var comparisonOperators = map[string]bool{
+	"=": true, "<": true, ">": true, "<=": true, ">=": true, "<>": true, "!=": true,
+}
+
+// Operator precedence, lowest to highest
+const (
+	precLowest = iota
+	precOr
+	precAnd
+	precNot
+	precIs
+	precComparison
+	precPredicate // BETWEEN, IN, LIKE, ILIKE, SIMILAR TO
+	precOther     // any other operator (||, ->, @>, ~ ...)
+	precAdditive
+	precMultiplicative
+	precExponent
+	precUnary
+)
+
+// parser is a recursive-descent parser for the subset of PostgreSQL
+// needed to analyze read-only queries
+type parser struct {
+	tokens []Token
+	pos    int
+}
+
+// Parse parses a SQL script into its semicolon-separated statements
+func Parse(sql string) ([]Stmt, error) {
+	tokens, err := Tokenize(sql)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	var stmts []Stmt
+
+	for {
+		for p.isPunct(";") {
+			p.advance()
+		}
+		if p.cur().Kind == TokenEOF {
+			break
+		}
+
+		stmt, err := p.parseQuery()
+		if err != nil {
+			return nil, err
+		}
+		stmts = append(stmts, stmt)
+
+		if !p.isPunct(";") && p.cur().Kind != TokenEOF {
+			return nil, p.unexpected()
+		}
+	}
+
+	return stmts, nil
+}
+
+// --- token helpers ---
+
+func (p *parser) cur() Token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) peek(offset int) Token {
+	if p.pos+offset < len(p.tokens) {
+		return p.tokens[p.pos+offset]
+	}
+	return p.tokens[len(p.tokens)-1]
+}
+
+func (p *parser) advance() Token {
+	tok := p.tokens[p.pos]
+	if tok.Kind != TokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) isKeyword(kws ...string) bool {
+	for _, kw := range kws {
+		if p.cur().isKeyword(kw) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) isPunct(value string) bool {
+	tok := p.cur()
+	return tok.Kind == TokenPunct && tok.Value == value
+}
+
+func (p *parser) isOperator(value string) bool {
+	tok := p.cur()
+	return tok.Kind == TokenOperator && tok.Value == value
+}
+
+func (p *parser) acceptKeyword(kw string) bool {
+	if p.isKeyword(kw) {
+		p.advance()
+		return true
+	}
+	return false
+}
+
+func (p *parser) acceptPunct(value string) bool {
+	if p.isPunct(value) {
+		p.advance()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectKeyword(kw string) error {
+	if !p.acceptKeyword(kw) {
+		return p.expected(strings.ToUpper(kw))
+	}
+	return nil
+}
+
+func (p *parser) expectPunct(value string) error {
+	if !p.acceptPunct(value) {
+		return p.expected(fmt.Sprintf("%q", value))
+	}
+	return nil
+}
+
+func (p *parser) expected(what string) error {
+	tok := p.cur()
+	if tok.Kind == TokenEOF {
+		return fmt.Errorf("expected %s but query ended", what)
+	}
+	return fmt.Errorf("expected %s at position %d, found %q", what, tok.Pos, tok.Raw)
+}
+
+func (p *parser) unexpected() error {
+	tok := p.cur()
+	if tok.Kind == TokenEOF {
+		return fmt.Errorf("unexpected end of query")
+	}
+	return fmt.Errorf("unexpected %q at position %d", tok.Raw, tok.Pos)
+}
+
+// endOfPrevious returns the byte offset just past the last consumed token
+func (p *parser) endOfPrevious() int {
+	if p.pos == 0 {
+		return 0
+	}
+	prev := p.tokens[p.pos-1]
+	return prev.Pos + len(prev.Raw)
+}
+
+// startsQuery reports whether the current token begins a query body
+func (p *parser) startsQuery() bool {
+	return p.isKeyword("select", "with", "values", "table")
+}
+
+// identifierName consumes an identifier (quoted or not) and returns its name
+func (p *parser) identifierName() (string, error) {
+	tok := p.cur()
+	if tok.Kind == TokenIdent || tok.Kind == TokenQuotedIdent {
+		p.advance()
+		return tok.Value, nil
+	}
+	return "", p.expected("identifier")
+}
+
+// isAliasCandidate reports whether the current token can be a bare alias
+func (p *parser) isAliasCandidate() bool {
+	tok := p.cur()
+	if tok.Kind == TokenQuotedIdent {
+		return true
+	}
+	return tok.Kind == TokenIdent && !reservedKeywords[tok.Value]
+}
+
+// --- statements ---
+
+// parseQuery parses a SELECT (possibly with set operations) or records
+// the leading keyword of any other statement kind
+func (p *parser) parseQuery() (Stmt, error) {
+	if p.isKeyword("with") {
+		// WITH may prefix a data-modifying statement; look past the CTEs
+		// to see which kind of statement follows
+		start := p.pos
+		if _, err := p.parseWith(); err != nil {
+			return nil, err
+		}
+		if p.isKeyword("select") || p.isPunct("(") {
+			p.pos = start
+			return p.parseSelectStmt()
+		}
+	} else if p.isKeyword("select") || p.isPunct("(") {
+		return p.parseSelectStmt()
+	}
+
+	tok := p.cur()
+	if tok.Kind != TokenIdent {
+		return nil, p.unexpected()
+	}
+
+	// Skip the rest of the statement, keeping parentheses balanced so that
+	// a statement nested in a CTE or subquery ends at its closing paren
+	other := &OtherStmt{Keyword: tok.Value, Pos: tok.Pos}
+	depth := 0
+	for {
+		t := p.cur()
+		if t.Kind == TokenEOF {
+			break
+		}
+		if t.Kind == TokenPunct {
+			if t.Value == "(" {
+				depth++
+			} else if t.Value == ")" {
+				if depth == 0 {
+					break
+				}
+				depth--
+			} else if t.Value == ";" && depth == 0 {
+				break
+			}
+		}
+		p.advance()
+	}
+	return other, nil
+}
+
+// parseSelectStmt parses [WITH ...] select-body [ORDER BY] [LIMIT] [OFFSET] [FETCH] [FOR ...]
+func (p *parser) parseSelectStmt() (*SelectStmt, error) {
+	var with *WithClause
+	if p.isKeyword("with") {
+		w, err := p.parseWith()
+		if err != nil {
+			return nil, err
+		}
+		with = w
+	}
+
+	stmt, err := p.parseSetExpr()
+	if err != nil {
+		return nil, err
+	}
+	if with != nil {
+		stmt.With = with
+	}
+
+	if err := p.parseSelectTail(stmt); err != nil {
+		return nil, err
+	}
+	stmt.EndPos = p.endOfPrevious()
+
+	return stmt, nil
+}
+
+// parseWith parses WITH [RECURSIVE] name [(cols)] AS [[NOT] MATERIALIZED] (query), ...
+func (p *parser) parseWith() (*WithClause, error) {
+	if err := p.expectKeyword("with"); err != nil {
+		return nil, err
+	}
+
+	with := &WithClause{Recursive: p.acceptKeyword("recursive")}
+	for {
+		name, err := p.identifierName()
+		if err != nil {
+			return nil, err
+		}
+		cte := &CTE{Name: name}
+
+		if p.acceptPunct("(") {
+			cols, err := p.parseIdentList()
+			if err != nil {
+				return nil, err
+			}
+			cte.Columns = cols
+			if err := p.expectPunct(")"); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := p.expectKeyword("as"); err != nil {
+			return nil, err
+		}
+		if p.acceptKeyword("not") {
+			if err := p.expectKeyword("materialized"); err != nil {
+				return nil, err
+			}
+		} else {
+			p.acceptKeyword("materialized")
+		}
+
+		if err := p.expectPunct("("); err != nil {
+			return nil, err
+		}
+		query, err := p.parseQuery()
+		if err != nil {
+			return nil, err
+		}
+		cte.Query = query
+		if err := p.expectPunct(")"); err != nil {
+			return nil, err
+		}
+
+		with.CTEs = append(with.CTEs, cte)
+		if !p.acceptPunct(",") {
+			break
+		}
+	}
+
+	return with, nil
+}
+
+// parseSetExpr parses select-primary { UNION | INTERSECT | EXCEPT [ALL|DISTINCT] select-primary }
+func (p *parser) parseSetExpr() (*SelectStmt, error) {
+	left, err := p.parseSelectPrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isKeyword("union", "intersect", "except") {
+		op := p.advance().Value
+		all := p.acceptKeyword("all")
+		if !all {
+			p.acceptKeyword("distinct")
+		}
+
+		right, err := p.parseSelectPrimary()
+		if err != nil {
+			return nil, err
+		}
+		left = &SelectStmt{SetOp: op, SetAll: all, Left: left, Right: right, LimitPos: -1}
+	}
+
+	return left, nil
+}
+
+// parseSelectPrimary parses a simple SELECT or a parenthesized query
+func (p *parser) parseSelectPrimary() (*SelectStmt, error) {
+	if p.acceptPunct("(") {
+		inner, err := p.parseSelectStmt()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectPunct(")"); err != nil {
+			return nil, err
+		}
+		return inner, nil
+	}
+	return p.parseSimpleSelect()
+}
+
+// parseSimpleSelect parses SELECT ... [INTO] [FROM] [WHERE] [GROUP BY] [HAVING] [WINDOW]
+func (p *parser) parseSimpleSelect() (*SelectStmt, error) {
+	if err := p.expectKeyword("select"); err != nil {
+		return nil, err
+	}
+
+	stmt := &SelectStmt{LimitPos: -1}
+
+	if p.acceptKeyword("distinct") {
+		stmt.Distinct = true
+		if p.acceptKeyword("on") {
+			if err := p.expectPunct("("); err != nil {
+				return nil, err
+			}
+			exprs, err := p.parseExprList()
+			if err != nil {
+				return nil, err
+			}
+			stmt.DistinctOn = exprs
+			if err := p.expectPunct(")"); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		p.acceptKeyword("all")
+	}
+
+	// Target list (PostgreSQL permits an empty one)
+	if !p.isTargetListEnd() {
+		for {
+			target, err := p.parseTarget()
+			if err != nil {
+				return nil, err
+			}
+			stmt.Targets = append(stmt.Targets, target)
+			if !p.acceptPunct(",") {
+				break
+			}
+		}
+	}
+
+	if p.acceptKeyword("into") {
+		for p.isKeyword("temporary", "temp", "unlogged", "table") {
+			p.advance()
+		}
+		into, err := p.parseTableName()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Into = into
+	}
+
+	if p.acceptKeyword("from") {
+		for {
+			item, err := p.parseFromItem()
+			if err != nil {
+				return nil, err
+			}
+			stmt.From = append(stmt.From, item)
+			if !p.acceptPunct(",") {
+				break
+			}
+		}
+	}
+
+	if p.acceptKeyword("where") {
+		where, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Where = where
+	}
+
+	if p.acceptKeyword("group") {
+		if err := p.expectKeyword("by"); err != nil {
+			return nil, err
+		}
+		if !p.acceptKeyword("all") {
+			p.acceptKeyword("distinct")
+		}
+		for {
+			expr, err := p.parseGroupingElement()
+			if err != nil {
+				return nil, err
+			}
+			stmt.GroupBy = append(stmt.GroupBy, expr)
+			if !p.acceptPunct(",") {
+				break
+			}
+		}
+	}
+
+	if p.acceptKeyword("having") {
+		having, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Having = having
+	}
+
+	if p.acceptKeyword("window") {
+		for {
+			name, err := p.identifierName()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectKeyword("as"); err != nil {
+				return nil, err
+			}
+			if err := p.expectPunct("("); err != nil {
+				return nil, err
+			}
+			spec, err := p.parseWindowSpec()
+			if err != nil {
+				return nil, err
+			}
+			stmt.Windows = append(stmt.Windows, &WindowDef{Name: name, Spec: spec})
+			if !p.acceptPunct(",") {
+				break
+			}
+		}
+	}
+
+	return stmt, nil
+}
+
+// isTargetListEnd reports whether a SELECT has no target list
+func (p *parser) isTargetListEnd() bool {
+	tok := p.cur()
+	if tok.Kind == TokenEOF || (tok.Kind == TokenPunct && (tok.Value == ";" || tok.Value == ")")) {
+		return true
+	}
+	return p.isKeyword("from", "where", "into", "union", "intersect", "except", "order", "limit")
+}
+
+// parseGroupingElement parses a GROUP BY item, including GROUPING SETS, ROLLUP and CUBE
+func (p *parser) parseGroupingElement() (Expr, error) {
+	if p.isKeyword("grouping") && p.peek(1).isKeyword("sets") {
+		p.advance()
+		p.advance()
+		if err := p.expectPunct("("); err != nil {
+			return nil, err
+		}
+		var items []Expr
+		for {
+			item, err := p.parseGroupingElement()
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+			if !p.acceptPunct(",") {
+				break
+			}
+		}
+		if err := p.expectPunct(")"); err != nil {
+			return nil, err
+		}
+		return &ListExpr{Items: items}, nil
+	}
+	if p.isPunct("(") && p.peek(1).Kind == TokenPunct && p.peek(1).Value == ")" {
+		p.advance()
+		p.advance()
+		return &ListExpr{}, nil
+	}
+	return p.parseExpr()
+}
+
+// parseSelectTail parses ORDER BY, LIMIT, OFFSET, FETCH and locking clauses
+func (p *parser) parseSelectTail(stmt *SelectStmt) error {
+	if p.isKeyword("order") && p.peek(1).isKeyword("by") {
+		p.advance()
+		p.advance()
+		items, err := p.parseOrderList()
+		if err != nil {
+			return err
+		}
+		stmt.OrderBy = items
+	}
+
+	for {
+		switch {
+		case p.isKeyword("limit"):
+			stmt.LimitPos = p.cur().Pos
+			p.advance()
+			if p.acceptKeyword("all") {
+				stmt.LimitAll = true
+				stmt.Limit = nil
+				stmt.LimitEndPos = p.endOfPrevious()
+				continue
+			}
+			limit, err := p.parseExpr()
+			if err != nil {
+				return err
+			}
+			stmt.Limit = limit
+			stmt.LimitAll = false
+			stmt.LimitEndPos = p.endOfPrevious()
+
+		case p.isKeyword("offset"):
+			p.advance()
+			offset, err := p.parseExpr()
+			if err != nil {
+				return err
+			}
+			stmt.Offset = offset
+			if !p.acceptKeyword("rows") {
+				p.acceptKeyword("row")
+			}
+
+		case p.isKeyword("fetch"):
+			stmt.LimitPos = p.cur().Pos
+			p.advance()
+			if !p.acceptKeyword("first") {
+				if err := p.expectKeyword("next"); err != nil {
+					return err
+				}
+			}
+			if p.isKeyword("row", "rows") {
+				stmt.Limit = &Literal{Kind: "number", Value: "1"}
+			} else {
+				count, err := p.parseBinary(precAdditive)
+				if err != nil {
+					return err
+				}
+				stmt.Limit = count
+			}
+			if !p.acceptKeyword("rows") {
+				if err := p.expectKeyword("row"); err != nil {
+					return err
+				}
+			}
+			if p.acceptKeyword("with") {
+				if err := p.expectKeyword("ties"); err != nil {
+					return err
+				}
+			} else if err := p.expectKeyword("only"); err != nil {
+				return err
+			}
+			stmt.LimitAll = false
+			stmt.LimitEndPos = p.endOfPrevious()
+
+		case p.isKeyword("for"):
+			lock, err := p.parseLockingClause()
+			if err != nil {
+				return err
+			}
+			stmt.Locking = append(stmt.Locking, lock)
+
+		default:
+			return nil
+		}
+	}
+}
+
+// parseLockingClause parses FOR UPDATE | NO KEY UPDATE | SHARE | KEY SHARE [OF ...] [NOWAIT | SKIP LOCKED]
+func (p *parser) parseLockingClause() (string, error) {
+	if err := p.expectKeyword("for"); err != nil {
+		return "", err
+	}
+
+	var words []string
+	for p.isKeyword("update", "share", "no", "key") {
+		words = append(words, p.advance().Value)
+	}
+	if len(words) == 0 {
+		return "", p.expected("UPDATE or SHARE")
+	}
+
+	if p.acceptKeyword("of") {
+		for {
+			if _, err := p.parseTableName(); err != nil {
+				return "", err
+			}
+			if !p.acceptPunct(",") {
+				break
+			}
+		}
+	}
+	if !p.acceptKeyword("nowait") && p.acceptKeyword("skip") {
+		if err := p.expectKeyword("locked"); err != nil {
+			return "", err
+		}
+	}
+
+	return "for " + strings.Join(words, " "), nil
+}
+
+// parseTarget parses a SELECT list item: *, expr [[AS] alias]
+func (p *parser) parseTarget() (*Target, error) {
+	if p.isOperator("*") {
+		p.advance()
+		return &Target{Expr: &ColumnRef{Star: true}}, nil
+	}
+
+	expr, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	target := &Target{Expr: expr}
+
+	if p.acceptKeyword("as") {
+		alias, err := p.identifierName()
+		if err != nil {
+			return nil, err
+		}
+		target.Alias = alias
+	} else if p.isAliasCandidate() {
+		target.Alias = p.advance().Value
+	}
+
+	return target, nil
+}
+
+// --- FROM clause ---
+
+// parseFromItem parses a table primary followed by any number of joins
+func (p *parser) parseFromItem() (TableExpr, error) {
+	left, err := p.parseTablePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		kind := ""
+		natural := false
+
+		if p.acceptKeyword("cross") {
+			kind = "cross"
+		} else {
+			natural = p.acceptKeyword("natural")
+			switch {
+			case p.isKeyword("join"):
+				kind = "inner"
+			case p.acceptKeyword("inner"):
+				kind = "inner"
+			case p.isKeyword("left", "right", "full"):
+				kind = p.advance().Value
+				p.acceptKeyword("outer")
+			}
+		}
+
+		if kind == "" {
+			if natural {
+				return nil, p.expected("JOIN")
+			}
+			return left, nil
+		}
+		if err := p.expectKeyword("join"); err != nil {
+			return nil, err
+		}
+
+		right, err := p.parseTablePrimary()
+		if err != nil {
+			return nil, err
+		}
+		join := &JoinExpr{Kind: kind, Left: left, Right: right}
+
+		if kind != "cross" && !natural {
+			switch {
+			case p.acceptKeyword("on"):
+				on, err := p.parseExpr()
+				if err != nil {
+					return nil, err
+				}
+				join.On = on
+			case p.acceptKeyword("using"):
+				if err := p.expectPunct("("); err != nil {
+					return nil, err
+				}
+				cols, err := p.parseIdentList()
+				if err != nil {
+					return nil, err
+				}
+				join.Using = cols
+				if err := p.expectPunct(")"); err != nil {
+					return nil, err
+				}
+			default:
+				return nil, p.expected("ON or USING")
+			}
+		}
+
+		left = join
+	}
+}
+
+// parseTablePrimary parses a table name, subquery, function call or parenthesized join
+func (p *parser) parseTablePrimary() (TableExpr, error) {
+	lateral := p.acceptKeyword("lateral")
+
+	if p.isPunct("(") {
+		if p.parenStartsQuery() {
+			p.advance()
+			query, err := p.parseQuery()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectPunct(")"); err != nil {
+				return nil, err
+			}
+			alias, err := p.parseTableAlias()
+			if err != nil {
+				return nil, err
+			}
+			return &SubqueryRef{Query: query, Alias: alias, Lateral: lateral}, nil
+		}
+
+		p.advance()
+		inner, err := p.parseFromItem()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectPunct(")"); err != nil {
+			return nil, err
+		}
+		if _, err := p.parseTableAlias(); err != nil {
+			return nil, err
+		}
+		return inner, nil
+	}
+
+	p.acceptKeyword("only")
+	namePos := p.cur().Pos
+	parts, err := p.parseQualifiedName()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.isPunct("(") {
+		fn, err := p.parseFuncCall(parts, namePos)
+		if err != nil {
+			return nil, err
+		}
+		if p.acceptKeyword("with") {
+			if err := p.expectKeyword("ordinality"); err != nil {
+				return nil, err
+			}
+		}
+		alias, err := p.parseTableAlias()
+		if err != nil {
+			return nil, err
+		}
+		return &FuncTableRef{Func: fn, Alias: alias, Lateral: lateral}, nil
+	}
+
+	table, err := tableNameFromParts(parts, namePos)
+	if err != nil {
+		return nil, err
+	}
+	alias, err := p.parseTableAlias()
+	if err != nil {
+		return nil, err
+	}
+	return &TableRef{Table: table, Alias: alias}, nil
+}
+
+// parenStartsQuery looks past any run of '(' to see whether a query begins
+func (p *parser) parenStartsQuery() bool {
+	i := p.pos
+	for i < len(p.tokens) && p.tokens[i].Kind == TokenPunct && p.tokens[i].Value == "(" {
+		i++
+	}
+	if i >= len(p.tokens) {
+		return false
+	}
+	tok := p.tokens[i]
+	return tok.isKeyword("select") || tok.isKeyword("with") || tok.isKeyword("values") ||
+		tok.isKeyword("insert") || tok.isKeyword("update") || tok.isKeyword("delete")
+}
+
+// parseTableAlias parses [AS] alias [(column, ...)]
+func (p *parser) parseTableAlias() (string, error) {
+	alias := ""
+	if p.acceptKeyword("as") {
+		name, err := p.identifierName()
+		if err != nil {
+			return "", err
+		}
+		alias = name
+	} else if p.isAliasCandidate() && !p.isKeyword("tablesample") {
+		alias = p.advance().Value
+	}
+
+	if alias != "" && p.isPunct("(") {
+		if err := p.skipBalancedParens(); err != nil {
+			return "", err
+		}
+	}
+	return alias, nil
+}
+
+// parseTableName parses [schema.]name
+func (p *parser) parseTableName() (*TableName, error) {
+	pos := p.cur().Pos
+	parts, err := p.parseQualifiedName()
+	if err != nil {
+		return nil, err
+	}
+	return tableNameFromParts(parts, pos)
+}
+
+func tableNameFromParts(parts []string, pos int) (*TableName, error) {
+	switch len(parts) {
+	case 1:
+		return &TableName{Name: parts[0], Pos: pos}, nil
+	case 2:
+		return &TableName{Schema: parts[0], Name: parts[1], Pos: pos}, nil
+	case 3:
+		// database.schema.table
+		return &TableName{Schema: parts[0] + "." + parts[1], Name: parts[2], Pos: pos}, nil
+	}
+	return nil, fmt.Errorf("invalid relation name %q", strings.Join(parts, "."))
+}
+
+// parseQualifiedName parses name { . name }
+func (p *parser) parseQualifiedName() ([]string, error) {
+	first, err := p.identifierName()
+	if err != nil {
+		return nil, err
+	}
+	parts := []string{first}
+	for p.isPunct(".") && (p.peek(1).Kind == TokenIdent || p.peek(1).Kind == TokenQuotedIdent) {
+		p.advance()
+		parts = append(parts, p.advance().Value)
+	}
+	return parts, nil
+}
+
+// parseIdentList parses name { , name }
+func (p *parser) parseIdentList() ([]string, error) {
+	var names []string
+	for {
+		name, err := p.identifierName()
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+		if !p.acceptPunct(",") {
+			return names, nil
+		}
+	}
+}
+
+// skipBalancedParens consumes a parenthesized token group without interpreting it
+func (p *parser) skipBalancedParens() error {
+	if err := p.expectPunct("("); err != nil {
+		return err
+	}
+	depth := 1
+	for depth > 0 {
+		tok := p.advance()
+		switch {
+		case tok.Kind == TokenEOF:
+			return fmt.Errorf("unbalanced parentheses")
+		case tok.Kind == TokenPunct && tok.Value == "(":
+			depth++
+		case tok.Kind == TokenPunct && tok.Value == ")":
+			depth--
+		}
+	}
+	return nil
+}
+
+// --- ordering and windows ---
+
+// parseOrderList parses expr [ASC|DESC] [USING op] [NULLS FIRST|LAST], ...
+func (p *parser) parseOrderList() ([]*OrderItem, error) {
+	var items []*OrderItem
+	for {
+		expr, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		item := &OrderItem{Expr: expr}
+
+		if p.acceptKeyword("desc") {
+			item.Desc = true
+		} else if !p.acceptKeyword("asc") && p.acceptKeyword("using") {
+			if p.cur().Kind != TokenOperator {
+				return nil, p.expected("operator")
+			}
+			p.advance()
+		}
+		if p.acceptKeyword("nulls") {
+			if !p.acceptKeyword("first") {
+				if err := p.expectKeyword("last"); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		items = append(items, item)
+		if !p.acceptPunct(",") {
+			return items, nil
+		}
+	}
+}
+
+// parseWindowSpec parses the inside of OVER ( ... ) after the opening paren
+func (p *parser) parseWindowSpec() (*WindowSpec, error) {
+	spec := &WindowSpec{}
+
+	if p.isAliasCandidate() && !p.isKeyword("partition", "rows", "range", "groups") {
+		spec.Name = p.advance().Value
+	}
+
+	if p.isKeyword("partition") {
+		p.advance()
+		if err := p.expectKeyword("by"); err != nil {
+			return nil, err
+		}
+		exprs, err := p.parseExprList()
+		if err != nil {
+			return nil, err
+		}
+		spec.PartitionBy = exprs
+	}
+
+	if p.isKeyword("order") {
+		p.advance()
+		if err := p.expectKeyword("by"); err != nil {
+			return nil, err
+		}
+		items, err := p.parseOrderList()
+		if err != nil {
+			return nil, err
+		}
+		spec.OrderBy = items
+	}
+
+	if p.isKeyword("rows", "range", "groups") {
+		p.advance()
+		between := p.acceptKeyword("between")
+		if err := p.parseFrameBound(spec); err != nil {
+			return nil, err
+		}
+		if between {
+			if err := p.expectKeyword("and"); err != nil {
+				return nil, err
+			}
+			if err := p.parseFrameBound(spec); err != nil {
+				return nil, err
+			}
+		}
+		if p.acceptKeyword("exclude") {
+			switch {
+			case p.acceptKeyword("current"):
+				if err := p.expectKeyword("row"); err != nil {
+					return nil, err
+				}
+			case p.acceptKeyword("no"):
+				if err := p.expectKeyword("others"); err != nil {
+					return nil, err
+				}
+			case p.acceptKeyword("group"), p.acceptKeyword("ties"):
+			default:
+				return nil, p.expected("frame exclusion")
+			}
+		}
+	}
+
+	if err := p.expectPunct(")"); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// parseFrameBound parses UNBOUNDED PRECEDING|FOLLOWING, CURRENT ROW or expr PRECEDING|FOLLOWING
+func (p *parser) parseFrameBound(spec *WindowSpec) error {
+	switch {
+	case p.acceptKeyword("unbounded"):
+	case p.acceptKeyword("current"):
+		return p.expectKeyword("row")
+	default:
+		offset, err := p.parseBinary(precAnd)
+		if err != nil {
+			return err
+		}
+		spec.Frame = append(spec.Frame, offset)
+	}
+	if !p.acceptKeyword("preceding") {
+		return p.expectKeyword("following")
+	}
+	return nil
+}
+
+// --- expressions ---
+
+// parseExprList parses expr { , expr }
+func (p *parser) parseExprList() ([]Expr, error) {
+	var exprs []Expr
+	for {
+		expr, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+		if !p.acceptPunct(",") {
+			return exprs, nil
+		}
+	}
+}
+
+// parseExpr parses a full expression
+func (p *parser) parseExpr() (Expr, error) {
+	return p.parseBinary(precLowest)
+}
+
+// infixPrecedence returns the binding power of the operator at the current
+// position, or precLowest if the current token does not continue an expression
+func (p *parser) infixPrecedence() int {
+	tok := p.cur()
+
+	switch tok.Kind {
+	case TokenOperator:
+		switch {
+		case comparisonOperators[tok.Value]:
+			return precComparison
+		case tok.Value == "+" || tok.Value == "-":
+			return precAdditive
+		case tok.Value == "*" || tok.Value == "/" || tok.Value == "%":
+			return precMultiplicative
+		case tok.Value == "^":
+			return precExponent
+		default:
+			return precOther
+		}
+	case TokenIdent:
+		switch tok.Value {
+		case "or":
+			return precOr
+		case "and":
+			return precAnd
+		case "is", "isnull", "notnull":
+			return precIs
+		case "between", "in", "like", "ilike", "similar":
+			return precPredicate
+		case "not":
+			next := p.peek(1)
+			if next.isKeyword("between") || next.isKeyword("in") || next.isKeyword("like") ||
+				next.isKeyword("ilike") || next.isKeyword("similar") {
+				return precPredicate
+			}
+		}
+	}
+
+	return precLowest
+}
+
+// parseBinary implements precedence climbing for infix and postfix operators
+func (p *parser) parseBinary(minPrec int) (Expr, error) {
+	left, err := p.parsePrefix()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		prec := p.infixPrecedence()
+		if prec == precLowest || prec <= minPrec {
+			return left, nil
+		}
+
+		left, err = p.parseInfix(left, prec)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// parseInfix parses the operator at the current position applied to left
+func (p *parser) parseInfix(left Expr, prec int) (Expr, error) {
+	tok := p.advance()
+
+	if tok.Kind == TokenOperator {
+		right, err := p.parseBinary(prec)
+		if err != nil {
+			return nil, err
+		}
+		return &BinaryExpr{Op: tok.Value, Left: left, Right: right}, nil
+	}
+
+	switch tok.Value {
+	case "or", "and":
+		right, err := p.parseBinary(prec)
+		if err != nil {
+			return nil, err
+		}
+		return &BinaryExpr{Op: tok.Value, Left: left, Right: right}, nil
+
+	case "isnull":
+		return &UnaryExpr{Op: "is null", Operand: left}, nil
+
+	case "notnull":
+		return &UnaryExpr{Op: "is not null", Operand: left}, nil
+
+	case "is":
+		op := "is"
+		if p.acceptKeyword("not") {
+			op = "is not"
+		}
+		switch {
+		case p.isKeyword("null", "true", "false", "unknown"):
+			return &UnaryExpr{Op: op + " " + p.advance().Value, Operand: left}, nil
+		case p.acceptKeyword("distinct"):
+			if err := p.expectKeyword("from"); err != nil {
+				return nil, err
+			}
+			right, err := p.parseBinary(precIs)
+			if err != nil {
+				return nil, err
+			}
+			return &BinaryExpr{Op: op + " distinct from", Left: left, Right: right}, nil
+		}
+		return nil, p.expected("NULL, TRUE, FALSE, UNKNOWN or DISTINCT FROM")
+	}
+
+	// Predicates, optionally negated
+	negated := false
+	keyword := tok.Value
+	if keyword == "not" {
+		negated = true
+		keyword = p.advance().Value
+	}
+	prefix := ""
+	if negated {
+		prefix = "not "
+	}
+
+	switch keyword {
+	case "between":
+		p.acceptKeyword("symmetric")
+		low, err := p.parseBinary(precPredicate)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectKeyword("and"); err != nil {
+			return nil, err
+		}
+		high, err := p.parseBinary(precPredicate)
+		if err != nil {
+			return nil, err
+		}
+		return &BetweenExpr{Expr: left, Not: negated, Low: low, High: high}, nil
+
+	case "in":
+		if err := p.expectPunct("("); err != nil {
+			return nil, err
+		}
+		var right Expr
+		if p.startsQuery() {
+			query, err := p.parseQuery()
+			if err != nil {
+				return nil, err
+			}
+			right = &SubqueryExpr{Query: query}
+		} else {
+			items, err := p.parseExprList()
+			if err != nil {
+				return nil, err
+			}
+			right = &ListExpr{Items: items}
+		}
+		if err := p.expectPunct(")"); err != nil {
+			return nil, err
+		}
+		return &BinaryExpr{Op: prefix + "in", Left: left, Right: right}, nil
+
+	case "like", "ilike", "similar":
+		op := keyword
+		if keyword == "similar" {
+			if err := p.expectKeyword("to"); err != nil {
+				return nil, err
+			}
+			op = "similar to"
+		}
+		right, err := p.parseBinary(precPredicate)
+		if err != nil {
+			return nil, err
+		}
+		if p.acceptKeyword("escape") {
+			escape, err := p.parseBinary(precPredicate)
+			if err != nil {
+				return nil, err
+			}
+			right = &ListExpr{Items: []Expr{right, escape}}
+		}
+		return &BinaryExpr{Op: prefix + op, Left: left, Right: right}, nil
+	}
+
+	return nil, fmt.Errorf("unexpected %q at position %d", tok.Raw, tok.Pos)
+}
+
+// parsePrefix parses prefix operators and then a primary expression
+func (p *parser) parsePrefix() (Expr, error) {
+	if p.acceptKeyword("not") {
+		operand, err := p.parseBinary(precNot)
+		if err != nil {
+			return nil, err
+		}
+		return &UnaryExpr{Op: "not", Operand: operand}, nil
+	}
+
+	if tok := p.cur(); tok.Kind == TokenOperator {
+		p.advance()
+		operand, err := p.parseBinary(precUnary)
+		if err != nil {
+			return nil, err
+		}
+		return &UnaryExpr{Op: tok.Value, Operand: operand}, nil
+	}
+
+	return p.parsePostfix()
+}
+
+// parsePostfix parses a primary followed by :: casts, subscripts and COLLATE
+func (p *parser) parsePostfix() (Expr, error) {
+	expr, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		switch {
+		case p.acceptPunct("::"):
+			typeName, err := p.parseTypeName()
+			if err != nil {
+				return nil, err
+			}
+			expr = &CastExpr{Expr: expr, Type: typeName}
+
+		case p.acceptPunct("["):
+			var index []Expr
+			if !p.isPunct(":") {
+				lower, err := p.parseExpr()
+				if err != nil {
+					return nil, err
+				}
+				index = append(index, lower)
+			}
+			if p.acceptPunct(":") && !p.isPunct("]") {
+				upper, err := p.parseExpr()
+				if err != nil {
+					return nil, err
+				}
+				index = append(index, upper)
+			}
+			if err := p.expectPunct("]"); err != nil {
+				return nil, err
+			}
+			expr = &SubscriptExpr{Expr: expr, Index: index}
+
+		case p.acceptKeyword("collate"):
+			if _, err := p.parseQualifiedName(); err != nil {
+				return nil, err
+			}
+
+		case p.isKeyword("at") && p.peek(1).isKeyword("time"):
+			p.advance()
+			p.advance()
+			if err := p.expectKeyword("zone"); err != nil {
+				return nil, err
+			}
+			zone, err := p.parsePostfix()
+			if err != nil {
+				return nil, err
+			}
+			expr = &BinaryExpr{Op: "at time zone", Left: expr, Right: zone}
+
+		default:
+			return expr, nil
+		}
+	}
+}
+
+// parsePrimary parses literals, column references, function calls,
+// subqueries and the special expression forms
+func (p *parser) parsePrimary() (Expr, error) {
+	tok := p.cur()
+
+	switch tok.Kind {
+	case TokenNumber:
+		p.advance()
+		return &Literal{Kind: "number", Value: tok.Value}, nil
+	case TokenString:
+		p.advance()
+		return &Literal{Kind: "string", Value: tok.Value}, nil
+	case TokenParam:
+		p.advance()
+		return &Param{Name: tok.Value}, nil
+	case TokenPunct:
+		if tok.Value == "(" {
+			return p.parseParenExpr()
+		}
+		return nil, p.unexpected()
+	case TokenQuotedIdent:
+		return p.parseNameExpr()
+	case TokenIdent:
+		// handled below
+	default:
+		return nil, p.unexpected()
+	}
+
+	switch tok.Value {
+	case "null":
+		p.advance()
+		return &Literal{Kind: "null", Value: "null"}, nil
+	case "true", "false":
+		p.advance()
+		return &Literal{Kind: "bool", Value: tok.Value}, nil
+	case "case":
+		return p.parseCase()
+	case "cast":
+		return p.parseCast()
+	case "exists":
+		if p.peek(1).Kind == TokenPunct && p.peek(1).Value == "(" {
+			p.advance()
+			return p.parseSubqueryExpr("exists")
+		}
+	case "array":
+		next := p.peek(1)
+		if next.Kind == TokenPunct && next.Value == "[" {
+			p.advance()
+			return p.parseArrayConstructor()
+		}
+		if next.Kind == TokenPunct && next.Value == "(" {
+			p.advance()
+			return p.parseSubqueryExpr("array")
+		}
+	case "any", "all", "some":
+		if p.peek(1).Kind == TokenPunct && p.peek(1).Value == "(" {
+			kind := tok.Value
+			if kind == "some" {
+				kind = "any"
+			}
+			p.advance()
+			if p.peek(1).isKeyword("select") || p.peek(1).isKeyword("with") {
+				return p.parseSubqueryExpr(kind)
+			}
+			p.advance()
+			operand, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectPunct(")"); err != nil {
+				return nil, err
+			}
+			return &UnaryExpr{Op: kind, Operand: operand}, nil
+		}
+	case "row":
+		if p.peek(1).Kind == TokenPunct && p.peek(1).Value == "(" {
+			p.advance()
+			return p.parseParenExpr()
+		}
+	}
+
+	if niladicFunctions[tok.Value] && !(p.peek(1).Kind == TokenPunct && p.peek(1).Value == "(") {
+		p.advance()
+		return &FuncCall{Name: []string{tok.Value}, Pos: tok.Pos}, nil
+	}
+
+	// Typed literal: DATE '2024-01-01', INTERVAL '30 days', TIMESTAMP '...'
+	if p.peek(1).Kind == TokenString && !reservedKeywords[tok.Value] {
+		p.advance()
+		value := p.advance().Value
+		return &TypedLiteral{Type: tok.Value, Value: value}, nil
+	}
+
+	if reservedKeywords[tok.Value] && !isFunctionKeyword(tok.Value) {
+		return nil, fmt.Errorf("unexpected keyword %q at position %d", tok.Raw, tok.Pos)
+	}
+
+	return p.parseNameExpr()
+}
+
+// isFunctionKeyword reports reserved words that may still be used as function names
+func isFunctionKeyword(name string) bool {
+	return name == "left" || name == "right"
+}
+
+// parseNameExpr parses a column reference, qualified star or function call
+func (p *parser) parseNameExpr() (Expr, error) {
+	pos := p.cur().Pos
+	first, err := p.identifierName()
+	if err != nil {
+		return nil, err
+	}
+	parts := []string{first}
+
+	for p.isPunct(".") {
+		next := p.peek(1)
+		if next.Kind == TokenOperator && next.Value == "*" {
+			p.advance()
+			p.advance()
+			return &ColumnRef{Parts: parts, Star: true}, nil
+		}
+		if next.Kind != TokenIdent && next.Kind != TokenQuotedIdent {
+			break
+		}
+		p.advance()
+		parts = append(parts, p.advance().Value)
+	}
+
+	if p.isPunct("(") {
+		return p.parseFuncCall(parts, pos)
+	}
+	return &ColumnRef{Parts: parts}, nil
+}
+
+// parseParenExpr parses a scalar subquery, parenthesized expression or row constructor
+func (p *parser) parseParenExpr() (Expr, error) {
+	if p.peek(1).isKeyword("select") || p.peek(1).isKeyword("with") || p.peek(1).isKeyword("values") {
+		return p.parseSubqueryExpr("")
+	}
+
+	if err := p.expectPunct("("); err != nil {
+		return nil, err
+	}
+	items, err := p.parseExprList()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectPunct(")"); err != nil {
+		return nil, err
+	}
+
+	if len(items) == 1 {
+		return items[0], nil
+	}
+	return &ListExpr{Items: items}, nil
+}
+
+// parseSubqueryExpr parses ( query ) at the current position
+func (p *parser) parseSubqueryExpr(kind string) (Expr, error) {
+	if err := p.expectPunct("("); err != nil {
+		return nil, err
+	}
+	query, err := p.parseQuery()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectPunct(")"); err != nil {
+		return nil, err
+	}
+	return &SubqueryExpr{Kind: kind, Query: query}, nil
+}
+
+// parseArrayConstructor parses [ elem, ... ] after the ARRAY keyword
+func (p *parser) parseArrayConstructor() (Expr, error) {
+	if err := p.expectPunct("["); err != nil {
+		return nil, err
+	}
+	arr := &ArrayExpr{}
+	if !p.isPunct("]") {
+		for {
+			var elem Expr
+			var err error
+			if p.isPunct("[") {
+				elem, err = p.parseArrayConstructor()
+			} else {
+				elem, err = p.parseExpr()
+			}
+			if err != nil {
+				return nil, err
+			}
+			arr.Elems = append(arr.Elems, elem)
+			if !p.acceptPunct(",") {
+				break
+			}
+		}
+	}
+	if err := p.expectPunct("]"); err != nil {
+		return nil, err
+	}
+	return arr, nil
+}
+
+// parseCase parses CASE [operand] WHEN ... THEN ... [ELSE ...] END
+func (p *parser) parseCase() (Expr, error) {
+	if err := p.expectKeyword("case"); err != nil {
+		return nil, err
+	}
+
+	c := &CaseExpr{}
+	if !p.isKeyword("when") {
+		operand, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		c.Operand = operand
+	}
+
+	for p.acceptKeyword("when") {
+		cond, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectKeyword("then"); err != nil {
+			return nil, err
+		}
+		result, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		c.Whens = append(c.Whens, &WhenClause{Cond: cond, Result: result})
+	}
+	if len(c.Whens) == 0 {
+		return nil, p.expected("WHEN")
+	}
+
+	if p.acceptKeyword("else") {
+		elseExpr, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		c.Else = elseExpr
+	}
+
+	if err := p.expectKeyword("end"); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// parseCast parses CAST(expr AS type)
+func (p *parser) parseCast() (Expr, error) {
+	if err := p.expectKeyword("cast"); err != nil {
+		return nil, err
+	}
+	if err := p.expectPunct("("); err != nil {
+		return nil, err
+	}
+	expr, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectKeyword("as"); err != nil {
+		return nil, err
+	}
+	typeName, err := p.parseTypeName()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectPunct(")"); err != nil {
+		return nil, err
+	}
+	return &CastExpr{Expr: expr, Type: typeName}, nil
+}
+
+// parseTypeName parses a type such as integer, numeric(10,2), text[],
+// double precision or timestamp with time zone
+func (p *parser) parseTypeName() (string, error) {
+	parts, err := p.parseQualifiedName()
+	if err != nil {
+		return "", err
+	}
+	name := strings.Join(parts, ".")
+
+	switch name {
+	case "double":
+		if p.acceptKeyword("precision") {
+			name += " precision"
+		}
+	case "character", "char", "bit":
+		if p.acceptKeyword("varying") {
+			name += " varying"
+		}
+	case "timestamp", "time":
+		if p.isKeyword("with", "without") && p.peek(1).isKeyword("time") {
+			name += " " + p.advance().Value + " time zone"
+			p.advance()
+			if err := p.expectKeyword("zone"); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	if p.isPunct("(") {
+		if err := p.skipBalancedParens(); err != nil {
+			return "", err
+		}
+	}
+	for p.isPunct("[") {
+		p.advance()
+		if p.cur().Kind == TokenNumber {
+			p.advance()
+		}
+		if err := p.expectPunct("]"); err != nil {
+			return "", err
+		}
+		name += "[]"
+	}
+
+	return name, nil
+}
+
+// specialArgKeywords are keywords used as argument separators in SQL-standard
+// function syntax, e.g. EXTRACT(year FROM ts) or SUBSTRING(s FROM 1 FOR 3)
+var specialArgKeywords = map[string]map[string]bool{
+	"extract":   {"from": true},
+	"substring": {"from": true, "for": true, "similar": true},
+	"position":  {"in": true},
+	"overlay":   {"placing": true, "from": true, "for": true},
+	"trim":      {"from": true},
+}
+
+// parseFuncCall parses the argument list and trailing clauses of a function call
+func (p *parser) parseFuncCall(name []string, pos int) (*FuncCall, error) {
+	if err := p.expectPunct("("); err != nil {
+		return nil, err
+	}
+
+	fn := &FuncCall{Name: name, Pos: pos}
+	lowerName := strings.ToLower(strings.Join(name, "."))
+
+	switch {
+	case p.acceptPunct(")"):
+		// no arguments
+
+	case p.isOperator("*"):
+		p.advance()
+		fn.Star = true
+		if err := p.expectPunct(")"); err != nil {
+			return nil, err
+		}
+
+	case specialArgKeywords[lowerName] != nil:
+		args, err := p.parseSpecialArgs(specialArgKeywords[lowerName])
+		if err != nil {
+			return nil, err
+		}
+		fn.Args = args
+		if err := p.expectPunct(")"); err != nil {
+			return nil, err
+		}
+
+	default:
+		if p.acceptKeyword("distinct") {
+			fn.Distinct = true
+		} else {
+			p.acceptKeyword("all")
+		}
+		p.acceptKeyword("variadic")
+
+		for {
+			// Named argument notation: name => value
+			if (p.cur().Kind == TokenIdent || p.cur().Kind == TokenQuotedIdent) &&
+				p.peek(1).Kind == TokenOperator && p.peek(1).Value == "=" &&
+				p.peek(2).Kind == TokenOperator && p.peek(2).Value == ">" {
+				p.advance()
+				p.advance()
+				p.advance()
+			}
+			arg, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			fn.Args = append(fn.Args, arg)
+			if !p.acceptPunct(",") {
+				break
+			}
+		}
+
+		if p.isKeyword("order") {
+			p.advance()
+			if err := p.expectKeyword("by"); err != nil {
+				return nil, err
+			}
+			items, err := p.parseOrderList()
+			if err != nil {
+				return nil, err
+			}
+			fn.OrderBy = items
+		}
+
+		if err := p.expectPunct(")"); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.isKeyword("within") {
+		p.advance()
+		if err := p.expectKeyword("group"); err != nil {
+			return nil, err
+		}
+		if err := p.expectPunct("("); err != nil {
+			return nil, err
+		}
+		if err := p.expectKeyword("order"); err != nil {
+			return nil, err
+		}
+		if err := p.expectKeyword("by"); err != nil {
+			return nil, err
+		}
+		items, err := p.parseOrderList()
+		if err != nil {
+			return nil, err
+		}
+		fn.WithinGroup = items
+		if err := p.expectPunct(")"); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.isKeyword("filter") {
+		p.advance()
+		if err := p.expectPunct("("); err != nil {
+			return nil, err
+		}
+		if err := p.expectKeyword("where"); err != nil {
+			return nil, err
+		}
+		filter, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		fn.Filter = filter
+		if err := p.expectPunct(")"); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.isKeyword("over") {
+		p.advance()
+		if p.acceptPunct("(") {
+			spec, err := p.parseWindowSpec()
+			if err != nil {
+				return nil, err
+			}
+			fn.Over = spec
+		} else {
+			windowName, err := p.identifierName()
+			if err != nil {
+				return nil, err
+			}
+			fn.Over = &WindowSpec{Name: windowName}
+		}
+	}
+
+	return fn, nil
+}
+
+// parseSpecialArgs parses arguments separated by commas or by the given keywords
+func (p *parser) parseSpecialArgs(separators map[string]bool) ([]Expr, error) {
+	var args []Expr
+
+	// TRIM([BOTH | LEADING | TRAILING] [chars] FROM str)
+	p.acceptKeyword("both")
+	p.acceptKeyword("leading")
+	p.acceptKeyword("trailing")
+
+	for !p.isPunct(")") {
+		if p.cur().Kind == TokenIdent && separators[p.cur().Value] {
+			p.advance()
+			continue
+		}
+		if p.acceptPunct(",") {
+			continue
+		}
+		if p.acceptKeyword("escape") {
+			continue
+		}
+
+		// Operands bind tighter than the keyword separators
+		arg, err := p.parseBinary(precPredicate)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+
+		if p.cur().Kind == TokenEOF {
+			return nil, p.expected("\")\"")
+		}
+	}
+
+	return args, nil
+}
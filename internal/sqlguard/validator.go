@@ -0,0 +1,304 @@
+package sqlguard
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Rejection reason codes returned in ValidationError
+const (
+	ReasonEmptyQuery         = "empty_query"
+	ReasonParseError         = "parse_error"
+	ReasonMultipleStatements = "multiple_statements"
+	ReasonNotSelect          = "not_select"
+	ReasonWritableCTE        = "writable_cte"
+	ReasonSelectInto         = "select_into"
+	ReasonLockingClause      = "locking_clause"
+	ReasonTableNotAllowed    = "table_not_allowed"
+	ReasonFunctionNotAllowed = "function_not_allowed"
+)
+
+// DefaultAllowedTables are the relations generated queries may read from
+var DefaultAllowedTables = []string{
+	"feedback_enriched",
+	"account_risk_scores",
+	"product_area_impact",
+}
+
+// DefaultAllowedFunctions are the side-effect free functions generated queries may call
+var DefaultAllowedFunctions = []string{
+	// Aggregates
+	"count", "sum", "avg", "min", "max", "array_agg", "string_agg",
+	"bool_and", "bool_or", "every", "stddev", "stddev_pop", "stddev_samp",
+	"variance", "var_pop", "var_samp", "percentile_cont", "percentile_disc", "mode",
+	"corr", "covar_pop", "covar_samp",
+	// Window functions
+	"row_number", "rank", "dense_rank", "percent_rank", "cume_dist", "ntile",
+	"lag", "lead", "first_value", "last_value", "nth_value",
+	// Grouping
+	"grouping", "rollup", "cube",
+	// Conditionals
+	"coalesce", "nullif", "greatest", "least",
+	// Strings
+	"lower", "upper", "initcap", "length", "char_length", "character_length",
+	"trim", "btrim", "ltrim", "rtrim", "substring", "substr", "left", "right",
+	"position", "strpos", "replace", "concat", "concat_ws", "split_part",
+	"lpad", "rpad", "reverse", "starts_with",
+	// Math
+	"abs", "round", "ceil", "ceiling", "floor", "trunc", "mod", "power", "sqrt", "sign",
+	// Date and time
+	"now", "current_date", "current_time", "current_timestamp", "localtime", "localtimestamp",
+	"date_trunc", "date_part", "extract", "age", "to_char", "to_date", "to_timestamp",
+	"make_date", "make_interval", "justify_days", "justify_hours", "justify_interval",
+}
+
+// Reason describes why a query was rejected
+type Reason struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Detail  string `json:"detail,omitempty"`
+}
+
+// ValidationError is returned when a query fails one or more safety checks
+type ValidationError struct {
+	Reasons []Reason
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Reasons))
+	for i, r := range e.Reasons {
+		messages[i] = r.Message
+	}
+	return "query rejected: " + strings.Join(messages, "; ")
+}
+
+// HasReason reports whether the error contains a reason with the given code
+func (e *ValidationError) HasReason(code string) bool {
+	for _, r := range e.Reasons {
+		if r.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Validator checks that LLM-generated SQL is a single read-only SELECT over
+// an allowlist of tables and functions
+type Validator struct {
+	allowedTables    map[string]bool
+	allowedFunctions map[string]bool
+}
+
+// NewValidator creates a validator with the given table and function allowlists
+func NewValidator(tables, functions []string) *Validator {
+	v := &Validator{
+		allowedTables:    make(map[string]bool, len(tables)),
+		allowedFunctions: make(map[string]bool, len(functions)),
+	}
+	for _, t := range tables {
+		v.allowedTables[strings.ToLower(t)] = true
+	}
+	for _, f := range functions {
+		v.allowedFunctions[strings.ToLower(f)] = true
+	}
+	return v
+}
+
+// NewDefaultValidator creates a validator using the default allowlists
+func NewDefaultValidator() *Validator {
+	return NewValidator(DefaultAllowedTables, DefaultAllowedFunctions)
+}
+
+// AllowedTables returns the sorted table allowlist
+func (v *Validator) AllowedTables() []string {
+	tables := make([]string, 0, len(v.allowedTables))
+	for t := range v.allowedTables {
+		tables = append(tables, t)
+	}
+	sort.Strings(tables)
+	return tables
+}
+
+// Validate parses the query and checks it against the safety rules. On
+// success it returns the parsed statement; otherwise a *ValidationError.
+func (v *Validator) Validate(sql string) (*SelectStmt, error) {
+	if strings.TrimSpace(sql) == "" {
+		return nil, rejected(Reason{Code: ReasonEmptyQuery, Message: "the generated query is empty"})
+	}
+
+	stmts, err := Parse(sql)
+	if err != nil {
+		return nil, rejected(Reason{
+			Code:    ReasonParseError,
+			Message: fmt.Sprintf("the generated query could not be parsed: %v", err),
+		})
+	}
+
+	switch len(stmts) {
+	case 0:
+		return nil, rejected(Reason{Code: ReasonEmptyQuery, Message: "the generated query is empty"})
+	case 1:
+	default:
+		return nil, rejected(Reason{
+			Code:    ReasonMultipleStatements,
+			Message: fmt.Sprintf("only a single statement is allowed, found %d", len(stmts)),
+		})
+	}
+
+	c := &checker{validator: v, seen: make(map[string]bool)}
+
+	selectStmt, ok := stmts[0].(*SelectStmt)
+	if !ok {
+		c.notSelect(stmts[0].(*OtherStmt))
+		return nil, &ValidationError{Reasons: c.reasons}
+	}
+
+	c.checkSelect(selectStmt, nil)
+	if len(c.reasons) > 0 {
+		return nil, &ValidationError{Reasons: c.reasons}
+	}
+	return selectStmt, nil
+}
+
+func rejected(reason Reason) *ValidationError {
+	return &ValidationError{Reasons: []Reason{reason}}
+}
+
+// checker accumulates rejection reasons while traversing a statement
+type checker struct {
+	validator *Validator
+	reasons   []Reason
+	seen      map[string]bool
+}
+
+func (c *checker) add(r Reason) {
+	key := r.Code + "\x00" + r.Detail
+	if c.seen[key] {
+		return
+	}
+	c.seen[key] = true
+	c.reasons = append(c.reasons, r)
+}
+
+func (c *checker) notSelect(stmt *OtherStmt) {
+	c.add(Reason{
+		Code: ReasonNotSelect,
+		Message: fmt.Sprintf("only read-only SELECT queries are allowed, found %s. "+
+			"This API analyzes customer feedback data. Please ask questions about feedback, "+
+			"such as: 'What are the most common billing issues?' or "+
+			"'Show me negative feedback from enterprise customers.'", strings.ToUpper(stmt.Keyword)),
+		Detail: stmt.Keyword,
+	})
+}
+
+// checkSelect validates a SELECT and everything nested in it. scope holds
+// the CTE names visible to the statement.
+func (c *checker) checkSelect(stmt *SelectStmt, scope map[string]bool) {
+	local := make(map[string]bool, len(scope))
+	for name := range scope {
+		local[name] = true
+	}
+
+	cteQueries := make(map[Node]bool)
+	if stmt.With != nil {
+		if stmt.With.Recursive {
+			for _, cte := range stmt.With.CTEs {
+				local[cte.Name] = true
+			}
+		}
+		for _, cte := range stmt.With.CTEs {
+			cteQueries[cte.Query] = true
+			switch q := cte.Query.(type) {
+			case *SelectStmt:
+				c.checkSelect(q, local)
+			case *OtherStmt:
+				c.add(Reason{
+					Code: ReasonWritableCTE,
+					Message: fmt.Sprintf("common table expression %q contains a %s statement; only SELECT is allowed",
+						cte.Name, strings.ToUpper(q.Keyword)),
+					Detail: cte.Name,
+				})
+			}
+			local[cte.Name] = true
+		}
+	}
+
+	if stmt.Into != nil {
+		c.add(Reason{
+			Code:    ReasonSelectInto,
+			Message: "SELECT INTO creates a table and is not allowed",
+			Detail:  stmt.Into.Name,
+		})
+	}
+	for _, lock := range stmt.Locking {
+		c.add(Reason{
+			Code:    ReasonLockingClause,
+			Message: fmt.Sprintf("row locking clauses (%s) are not allowed", strings.ToUpper(lock)),
+			Detail:  lock,
+		})
+	}
+
+	for _, child := range Children(stmt) {
+		if cteQueries[child] {
+			continue
+		}
+		Walk(child, func(n Node) bool {
+			switch n := n.(type) {
+			case *SelectStmt:
+				c.checkSelect(n, local)
+				return false
+			case *OtherStmt:
+				c.notSelect(n)
+				return false
+			case *TableRef:
+				c.checkTable(n.Table, local)
+			case *FuncCall:
+				c.checkFunction(n)
+			}
+			return true
+		})
+	}
+}
+
+func (c *checker) checkTable(table *TableName, scope map[string]bool) {
+	name := table.Name
+	if table.Schema == "" && scope[name] {
+		return
+	}
+	if (table.Schema == "" || table.Schema == "public") && c.validator.allowedTables[name] {
+		return
+	}
+
+	qualified := name
+	if table.Schema != "" {
+		qualified = table.Schema + "." + name
+	}
+	c.add(Reason{
+		Code: ReasonTableNotAllowed,
+		Message: fmt.Sprintf("table %q is not available; queries may only read from %s",
+			qualified, strings.Join(c.validator.AllowedTables(), ", ")),
+		Detail: qualified,
+	})
+}
+
+func (c *checker) checkFunction(fn *FuncCall) {
+	name := strings.ToLower(fn.QualifiedName())
+	switch len(fn.Name) {
+	case 1:
+		if c.validator.allowedFunctions[name] {
+			return
+		}
+	case 2:
+		if strings.ToLower(fn.Name[0]) == "pg_catalog" && c.validator.allowedFunctions[strings.ToLower(fn.Name[1])] {
+			return
+		}
+	}
+
+	c.add(Reason{
+		Code:    ReasonFunctionNotAllowed,
+		Message: fmt.Sprintf("function %q is not allowed", name),
+		Detail:  name,
+	})
+}
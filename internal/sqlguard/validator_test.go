@@ -0,0 +1,287 @@
+package sqlguard
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestValidateAllowsReadOnlyQueries tests that typical generated queries pass validation
+func TestValidateAllowsReadOnlyQueries(t *testing.T) {
+	v := NewDefaultValidator()
+
+	tests := []struct {
+		name string
+		sql  string
+	}{
+		{
+			name: "simple select",
+			sql:  "SELECT * FROM feedback_enriched LIMIT 10",
+		},
+		{
+			name: "aggregation with group by",
+			sql: `SELECT product_area, COUNT(*) AS total, AVG(sentiment_score) avg_score
+				FROM feedback_enriched
+				WHERE sentiment = 'negative' AND created_at > NOW() - INTERVAL '30 days'
+				GROUP BY product_area
+				HAVING COUNT(*) > 5
+				ORDER BY total DESC NULLS LAST
+				LIMIT 20;`,
+		},
+		{
+			name: "join across allowed tables",
+			sql: `SELECT f.customer_tier, r.churn_probability
+				FROM feedback_enriched f
+				JOIN account_risk_scores r ON r.account_id = f.account_id
+				LEFT OUTER JOIN product_area_impact p USING (product_area)`,
+		},
+		{
+			name: "read-only CTE",
+			sql: `WITH recent AS (
+					SELECT * FROM feedback_enriched WHERE created_at >= CURRENT_DATE - 7
+				)
+				SELECT topic, COUNT(*) FROM recent GROUP BY topic`,
+		},
+		{
+			name: "recursive CTE referencing itself",
+			sql: `WITH RECURSIVE n(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM n WHERE x < 5)
+				SELECT x FROM n`,
+		},
+		{
+			name: "window function and filter",
+			sql: `SELECT product_area,
+					COUNT(*) FILTER (WHERE sentiment = 'negative') AS negatives,
+					ROW_NUMBER() OVER (PARTITION BY customer_tier ORDER BY created_at DESC) AS rn
+				FROM feedback_enriched`,
+		},
+		{
+			name: "subqueries, IN and EXISTS",
+			sql: `SELECT * FROM feedback_enriched f
+				WHERE f.account_id IN (SELECT account_id FROM account_risk_scores WHERE risk_category = 'high')
+				AND EXISTS (SELECT 1 FROM product_area_impact p WHERE p.product_area = f.product_area)`,
+		},
+		{
+			name: "case, cast and special function syntax",
+			sql: `SELECT CASE WHEN sentiment_score < 0 THEN 'neg' ELSE 'pos' END,
+					CAST(priority AS integer), created_at::date,
+					EXTRACT(MONTH FROM created_at), TRIM(BOTH ' ' FROM summary),
+					percentile_cont(0.5) WITHIN GROUP (ORDER BY sentiment_score)
+				FROM feedback_enriched
+				WHERE summary ILIKE '%billing%' AND priority BETWEEN 1 AND 3 AND region IS NOT NULL`,
+		},
+		{
+			name: "union of allowed tables",
+			sql:  "SELECT account_id FROM feedback_enriched UNION SELECT account_id FROM account_risk_scores",
+		},
+		{
+			name: "keywords inside strings and comments are ignored",
+			sql:  "SELECT 'DROP TABLE users; DELETE' AS note FROM feedback_enriched -- DELETE FROM feedback_enriched",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := v.Validate(tt.sql); err != nil {
+				t.Errorf("Validate() unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+// TestValidateRejectsUnsafeQueries tests rejection reasons for unsafe queries
+func TestValidateRejectsUnsafeQueries(t *testing.T) {
+	v := NewDefaultValidator()
+
+	tests := []struct {
+		name       string
+		sql        string
+		wantReason string
+		wantDetail string
+	}{
+		{
+			name:       "empty query",
+			sql:        "   ",
+			wantReason: ReasonEmptyQuery,
+		},
+		{
+			name:       "multiple statements",
+			sql:        "SELECT * FROM feedback_enriched; SELECT * FROM account_risk_scores",
+			wantReason: ReasonMultipleStatements,
+		},
+		{
+			name:       "stacked destructive statement",
+			sql:        "SELECT 1; DROP TABLE feedback_enriched",
+			wantReason: ReasonMultipleStatements,
+		},
+		{
+			name:       "delete statement",
+			sql:        "DELETE FROM feedback_enriched",
+			wantReason: ReasonNotSelect,
+			wantDetail: "delete",
+		},
+		{
+			name:       "copy statement",
+			sql:        "COPY feedback_enriched TO '/tmp/out.csv'",
+			wantReason: ReasonNotSelect,
+			wantDetail: "copy",
+		},
+		{
+			name:       "with prefixing an update",
+			sql:        "WITH x AS (SELECT 1) UPDATE feedback_enriched SET priority = 1",
+			wantReason: ReasonNotSelect,
+			wantDetail: "update",
+		},
+		{
+			name:       "writable CTE",
+			sql:        "WITH d AS (DELETE FROM feedback_enriched RETURNING *) SELECT * FROM d",
+			wantReason: ReasonWritableCTE,
+			wantDetail: "d",
+		},
+		{
+			name:       "select into",
+			sql:        "SELECT * INTO backup_copy FROM feedback_enriched",
+			wantReason: ReasonSelectInto,
+			wantDetail: "backup_copy",
+		},
+		{
+			name:       "row locking",
+			sql:        "SELECT * FROM feedback_enriched FOR UPDATE",
+			wantReason: ReasonLockingClause,
+		},
+		{
+			name:       "table outside allowlist",
+			sql:        "SELECT * FROM users",
+			wantReason: ReasonTableNotAllowed,
+			wantDetail: "users",
+		},
+		{
+			name:       "system catalog",
+			sql:        "SELECT usename, passwd FROM pg_catalog.pg_shadow",
+			wantReason: ReasonTableNotAllowed,
+			wantDetail: "pg_catalog.pg_shadow",
+		},
+		{
+			name:       "disallowed table in subquery",
+			sql:        "SELECT * FROM feedback_enriched WHERE account_id IN (SELECT id FROM information_schema.tables)",
+			wantReason: ReasonTableNotAllowed,
+			wantDetail: "information_schema.tables",
+		},
+		{
+			name:       "pg_sleep",
+			sql:        "SELECT pg_sleep(10)",
+			wantReason: ReasonFunctionNotAllowed,
+			wantDetail: "pg_sleep",
+		},
+		{
+			name:       "pg_read_file in where clause",
+			sql:        "SELECT * FROM feedback_enriched WHERE summary = pg_read_file('/etc/passwd')",
+			wantReason: ReasonFunctionNotAllowed,
+			wantDetail: "pg_read_file",
+		},
+		{
+			name:       "dblink as table function",
+			sql:        "SELECT * FROM dblink('host=evil', 'SELECT 1') AS t(x int)",
+			wantReason: ReasonFunctionNotAllowed,
+			wantDetail: "dblink",
+		},
+		{
+			name:       "schema-qualified function",
+			sql:        "SELECT public.lower(summary) FROM feedback_enriched",
+			wantReason: ReasonFunctionNotAllowed,
+			wantDetail: "public.lower",
+		},
+		{
+			name:       "unparseable query",
+			sql:        "SELECT FROM WHERE (",
+			wantReason: ReasonParseError,
+		},
+		{
+			name:       "unterminated string",
+			sql:        "SELECT 'abc FROM feedback_enriched",
+			wantReason: ReasonParseError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := v.Validate(tt.sql)
+			if err == nil {
+				t.Fatalf("Validate() expected rejection with %s", tt.wantReason)
+			}
+
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("Validate() error type = %T, want *ValidationError", err)
+			}
+			if !verr.HasReason(tt.wantReason) {
+				t.Fatalf("Validate() reasons = %+v, want %s", verr.Reasons, tt.wantReason)
+			}
+
+			if tt.wantDetail != "" {
+				found := false
+				for _, r := range verr.Reasons {
+					if r.Code == tt.wantReason && r.Detail == tt.wantDetail {
+						found = true
+					}
+				}
+				if !found {
+					t.Errorf("Validate() reasons = %+v, want detail %q", verr.Reasons, tt.wantDetail)
+				}
+			}
+		})
+	}
+}
+
+// TestValidateReportsAllReasons tests that independent violations are all reported
+func TestValidateReportsAllReasons(t *testing.T) {
+	v := NewDefaultValidator()
+
+	_, err := v.Validate("SELECT pg_sleep(1), version() FROM users FOR SHARE")
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected *ValidationError, got %v", err)
+	}
+
+	for _, code := range []string{ReasonFunctionNotAllowed, ReasonTableNotAllowed, ReasonLockingClause} {
+		if !verr.HasReason(code) {
+			t.Errorf("missing reason %s in %+v", code, verr.Reasons)
+		}
+	}
+	if len(verr.Reasons) != 4 {
+		t.Errorf("expected 4 reasons, got %d: %+v", len(verr.Reasons), verr.Reasons)
+	}
+}
+
+// TestValidateCustomAllowlist tests a validator configured with custom allowlists
+func TestValidateCustomAllowlist(t *testing.T) {
+	v := NewValidator([]string{"Events"}, []string{"count"})
+
+	if _, err := v.Validate("SELECT count(*) FROM events"); err != nil {
+		t.Errorf("expected query to pass, got %v", err)
+	}
+	if _, err := v.Validate("SELECT count(*) FROM feedback_enriched"); err == nil {
+		t.Error("expected table outside custom allowlist to be rejected")
+	}
+	if _, err := v.Validate("SELECT lower(name) FROM events"); err == nil {
+		t.Error("expected function outside custom allowlist to be rejected")
+	}
+}
+
+// TestValidateLimitPosition tests that the parsed statement records the LIMIT clause
+func TestValidateLimitPosition(t *testing.T) {
+	v := NewDefaultValidator()
+	sql := "SELECT * FROM feedback_enriched ORDER BY created_at DESC LIMIT 50"
+
+	stmt, err := v.Validate(sql)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stmt.LimitPos != len("SELECT * FROM feedback_enriched ORDER BY created_at DESC ") {
+		t.Errorf("LimitPos = %d", stmt.LimitPos)
+	}
+	if lit, ok := stmt.Limit.(*Literal); !ok || lit.Value != "50" {
+		t.Errorf("Limit = %#v, want literal 50", stmt.Limit)
+	}
+	if stmt.EndPos != len(sql) {
+		t.Errorf("EndPos = %d, want %d", stmt.EndPos, len(sql))
+	}
+}
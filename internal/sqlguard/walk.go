@@ -0,0 +1,109 @@
+package sqlguard
+
+// Walk traverses the tree rooted at node in depth-first order, calling fn for
+// each node. If fn returns false the children of that node are skipped.
+func Walk(node Node, fn func(Node) bool) {
+	if node == nil || !fn(node) {
+		return
+	}
+	for _, child := range Children(node) {
+		Walk(child, fn)
+	}
+}
+
+// Children returns the direct child nodes of node
+func Children(node Node) []Node {
+	var out []Node
+	add := func(nodes ...Node) {
+		for _, n := range nodes {
+			if n != nil {
+				out = append(out, n)
+			}
+		}
+	}
+	addExprs := func(exprs []Expr) {
+		for _, e := range exprs {
+			add(e)
+		}
+	}
+	addOrder := func(items []*OrderItem) {
+		for _, item := range items {
+			add(item.Expr)
+		}
+	}
+	addWindow := func(spec *WindowSpec) {
+		if spec == nil {
+			return
+		}
+		addExprs(spec.PartitionBy)
+		addOrder(spec.OrderBy)
+		addExprs(spec.Frame)
+	}
+
+	switch n := node.(type) {
+	case *SelectStmt:
+		if n.With != nil {
+			for _, cte := range n.With.CTEs {
+				add(cte.Query)
+			}
+		}
+		addExprs(n.DistinctOn)
+		for _, t := range n.Targets {
+			add(t.Expr)
+		}
+		for _, from := range n.From {
+			add(from)
+		}
+		add(n.Where)
+		addExprs(n.GroupBy)
+		add(n.Having)
+		for _, w := range n.Windows {
+			addWindow(w.Spec)
+		}
+		if n.Left != nil {
+			add(n.Left)
+		}
+		if n.Right != nil {
+			add(n.Right)
+		}
+		addOrder(n.OrderBy)
+		add(n.Limit, n.Offset)
+	case *SubqueryRef:
+		add(n.Query)
+	case *FuncTableRef:
+		add(n.Func)
+	case *JoinExpr:
+		add(n.Left, n.Right, n.On)
+	case *FuncCall:
+		addExprs(n.Args)
+		addOrder(n.OrderBy)
+		addOrder(n.WithinGroup)
+		add(n.Filter)
+		addWindow(n.Over)
+	case *SubqueryExpr:
+		add(n.Query)
+	case *BinaryExpr:
+		add(n.Left, n.Right)
+	case *UnaryExpr:
+		add(n.Operand)
+	case *BetweenExpr:
+		add(n.Expr, n.Low, n.High)
+	case *CaseExpr:
+		add(n.Operand)
+		for _, w := range n.Whens {
+			add(w.Cond, w.Result)
+		}
+		add(n.Else)
+	case *CastExpr:
+		add(n.Expr)
+	case *ListExpr:
+		addExprs(n.Items)
+	case *ArrayExpr:
+		addExprs(n.Elems)
+	case *SubscriptExpr:
+		add(n.Expr)
+		addExprs(n.Index)
+	}
+
+	return out
+}
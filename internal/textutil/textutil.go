@@ -0,0 +1,10 @@
+// Package textutil holds small text helpers shared across packages
+package textutil
+
+import "strings"
+
+// OneLine collapses runs of whitespace, including newlines, into single
+// spaces, so multi-line text fits on one line
+func OneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,25 @@
+package textutil
+
+import "testing"
+
+// TestOneLine tests collapsing whitespace onto one line
+func TestOneLine(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"single line", "top billing issues", "top billing issues"},
+		{"newlines and tabs", "SELECT *\n\tFROM feedback\n", "SELECT * FROM feedback"},
+		{"surrounding space", "  refunds  ", "refunds"},
+		{"empty", " \n ", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := OneLine(tt.in); got != tt.want {
+				t.Errorf("OneLine(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
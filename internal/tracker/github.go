@@ -0,0 +1,180 @@
+package tracker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/chuckie/goinsight/internal/domain"
+)
+
+// DefaultGitHubAPIURL is the GitHub REST API of github.com
+const DefaultGitHubAPIURL = "https://api.github.com"
+
+// GitHubClient files tickets as GitHub issues in one repository. Fingerprints
+// and priorities are added as labels; GitHub creates missing labels.
+type GitHubClient struct {
+	baseURL    string
+	token      string
+	repository string // "owner/repo"
+	httpClient *http.Client
+}
+
+// NewGitHubClient creates a GitHub Issues client for repository ("owner/repo").
+// baseURL is DefaultGitHubAPIURL, or the API of a GitHub Enterprise server.
+func NewGitHubClient(baseURL, token, repository string) *GitHubClient {
+	return &GitHubClient{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		token:      token,
+		repository: repository,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// githubIssue is the part of a GitHub issue used by the client
+type githubIssue struct {
+	ID      int64  `json:"id"`
+	Number  int    `json:"number"`
+	HTMLURL string `json:"html_url"`
+	Title   string `json:"title"`
+}
+
+// Name returns GitHub
+func (c *GitHubClient) Name() string {
+	return GitHub
+}
+
+// CreateIssues creates an issue for each spec unless one carrying its
+// fingerprint label exists (in any state)
+func (c *GitHubClient) CreateIssues(ctx context.Context, specs []domain.JiraTicketSpec) (*domain.JiraCreationResult, error) {
+	return createEach(specs, func(spec domain.JiraTicketSpec, action *domain.JiraActionResult) (*domain.JiraCreateResponse, error) {
+		return c.createUnlessDuplicate(ctx, spec, action)
+	}), nil
+}
+
+// createUnlessDuplicate creates the issue for spec unless its fingerprint is already filed
+func (c *GitHubClient) createUnlessDuplicate(ctx context.Context, spec domain.JiraTicketSpec, action *domain.JiraActionResult) (*domain.JiraCreateResponse, error) {
+	if spec.Fingerprint != "" {
+		existing, err := c.findByFingerprint(ctx, spec.Fingerprint)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check for duplicates: %w", err)
+		}
+		if existing != nil {
+			action.Status = domain.JiraActionSkippedDuplicate
+			action.Key = c.key(existing.Number)
+			return nil, nil
+		}
+	}
+
+	created, err := c.CreateIssue(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+	action.Status = domain.JiraActionCreated
+	action.Key = created.Key
+	return created, nil
+}
+
+// CreateIssue creates a single issue. Its key is "owner/repo#number".
+func (c *GitHubClient) CreateIssue(ctx context.Context, spec domain.JiraTicketSpec) (*domain.JiraCreateResponse, error) {
+	labels := append([]string(nil), spec.Labels...)
+	if spec.Priority != "" {
+		labels = append(labels, "priority: "+strings.ToLower(spec.Priority))
+	}
+	if spec.Fingerprint != "" {
+		labels = append(labels, spec.Fingerprint)
+	}
+
+	payload := map[string]any{
+		"title":  spec.Summary,
+		"body":   markdownDescription(spec),
+		"labels": labels,
+	}
+
+	var issue githubIssue
+	if err := c.do(ctx, "POST", "/repos/"+c.repository+"/issues", payload, &issue); err != nil {
+		return nil, err
+	}
+
+	return &domain.JiraCreateResponse{
+		ID:   fmt.Sprint(issue.ID),
+		Key:  c.key(issue.Number),
+		Self: issue.HTMLURL,
+	}, nil
+}
+
+// findByFingerprint returns an issue carrying the fingerprint label, if any.
+// It lists the repository's issues rather than searching them, since the
+// search index lags behind newly filed issues and has a tighter rate limit.
+func (c *GitHubClient) findByFingerprint(ctx context.Context, fingerprint string) (*githubIssue, error) {
+	params := url.Values{}
+	params.Set("labels", fingerprint)
+	params.Set("state", "all")
+	params.Set("per_page", "1")
+
+	var issues []githubIssue
+	if err := c.do(ctx, "GET", "/repos/"+c.repository+"/issues?"+params.Encode(), nil, &issues); err != nil {
+		return nil, err
+	}
+	if len(issues) == 0 {
+		return nil, nil
+	}
+	return &issues[0], nil
+}
+
+// key returns the key reported for an issue number
+func (c *GitHubClient) key(number int) string {
+	return fmt.Sprintf("%s#%d", c.repository, number)
+}
+
+// do sends a JSON request to the GitHub API and decodes the response into out
+// (if non-nil)
+func (c *GitHubClient) do(ctx context.Context, method, path string, payload, out any) error {
+	var reqBody io.Reader
+	if payload != nil {
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reqBody = bytes.NewBuffer(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("github API returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	if out == nil || len(respBody) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
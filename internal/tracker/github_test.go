@@ -0,0 +1,143 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/chuckie/goinsight/internal/domain"
+)
+
+// fakeGitHub is an in-memory stand-in for the GitHub Issues endpoints used by GitHubClient
+type fakeGitHub struct {
+	t *testing.T
+
+	mu     sync.Mutex
+	issues []fakeGitHubIssue
+}
+
+// fakeGitHubIssue is an issue stored by fakeGitHub
+type fakeGitHubIssue struct {
+	Title  string   `json:"title"`
+	Body   string   `json:"body"`
+	Labels []string `json:"labels"`
+}
+
+// newFakeGitHub starts a fake GitHub server and a client for acme/app pointed at it
+func newFakeGitHub(t *testing.T) (*fakeGitHub, *GitHubClient) {
+	t.Helper()
+	fake := &fakeGitHub{t: t}
+	server := httptest.NewServer(http.HandlerFunc(fake.serveHTTP))
+	t.Cleanup(server.Close)
+	return fake, NewGitHubClient(server.URL, "gh-token", "acme/app")
+}
+
+func (f *fakeGitHub) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer gh-token" {
+		http.Error(w, `{"message":"Bad credentials"}`, http.StatusUnauthorized)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case r.Method == "GET" && r.URL.Path == "/repos/acme/app/issues":
+		// Only the label filter of the duplicate check is supported
+		query := r.URL.Query()
+		if query.Get("state") != "all" {
+			http.Error(w, "unexpected state "+query.Get("state"), http.StatusBadRequest)
+			return
+		}
+		items := []map[string]any{}
+		for i, issue := range f.issues {
+			for _, l := range issue.Labels {
+				if l == query.Get("labels") {
+					items = append(items, map[string]any{"number": i + 1, "title": issue.Title})
+				}
+			}
+		}
+		json.NewEncoder(w).Encode(items)
+
+	case r.Method == "POST" && r.URL.Path == "/repos/acme/app/issues":
+		var issue fakeGitHubIssue
+		if err := json.NewDecoder(r.Body).Decode(&issue); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.issues = append(f.issues, issue)
+		number := len(f.issues)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]any{
+			"id":       1000 + number,
+			"number":   number,
+			"html_url": fmt.Sprintf("https://github.com/acme/app/issues/%d", number),
+		})
+
+	default:
+		http.Error(w, "not found", http.StatusNotFound)
+	}
+}
+
+// TestGitHubCreateIssues tests issue creation, labels and fingerprint deduplication
+func TestGitHubCreateIssues(t *testing.T) {
+	fake, client := newFakeGitHub(t)
+	specs := []domain.JiraTicketSpec{{
+		Summary:     "Fix refund delays",
+		Description: "Refunds take too long.",
+		Priority:    "High",
+		Labels:      []string{"feedback"},
+		Fingerprint: "goinsight-fp-0123456789abcdef",
+		DataPreview: []map[string]any{{"product_area": "billing", "count": 42}},
+	}}
+
+	result, err := client.CreateIssues(context.Background(), specs)
+	if err != nil {
+		t.Fatalf("CreateIssues() unexpected error: %v", err)
+	}
+	if len(result.CreatedTickets) != 1 || result.CreatedTickets[0].Key != "acme/app#1" || result.CreatedTickets[0].Self != "https://github.com/acme/app/issues/1" {
+		t.Fatalf("created = %+v, want acme/app#1", result.CreatedTickets)
+	}
+	if result.Actions[0].Status != domain.JiraActionCreated {
+		t.Errorf("action = %+v, want created", result.Actions[0])
+	}
+
+	issue := fake.issues[0]
+	wantLabels := []string{"feedback", "priority: high", "goinsight-fp-0123456789abcdef"}
+	if strings.Join(issue.Labels, ",") != strings.Join(wantLabels, ",") {
+		t.Errorf("labels = %v, want %v", issue.Labels, wantLabels)
+	}
+	if !strings.Contains(issue.Body, "| count | product_area |\n| --- | --- |\n| 42 | billing |") {
+		t.Errorf("body missing the data preview table:\n%s", issue.Body)
+	}
+
+	again, err := client.CreateIssues(context.Background(), specs)
+	if err != nil {
+		t.Fatalf("CreateIssues() unexpected error: %v", err)
+	}
+	if again.Actions[0].Status != domain.JiraActionSkippedDuplicate || again.Actions[0].Key != "acme/app#1" || len(fake.issues) != 1 {
+		t.Errorf("re-sent action = %+v, want skipped_duplicate of acme/app#1", again.Actions[0])
+	}
+}
+
+// TestGitHubCreateIssuesFailure tests that API errors are reported per action
+func TestGitHubCreateIssuesFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"Validation Failed"}`, http.StatusUnprocessableEntity)
+	}))
+	defer server.Close()
+
+	client := NewGitHubClient(server.URL, "gh-token", "acme/app")
+	result, err := client.CreateIssues(context.Background(), []domain.JiraTicketSpec{{Summary: "Fix refunds"}})
+	if err != nil {
+		t.Fatalf("CreateIssues() unexpected error: %v", err)
+	}
+	if result.Actions[0].Status != domain.JiraActionFailed || len(result.Errors) != 1 || !strings.Contains(result.Errors[0], "422") {
+		t.Errorf("result = %+v, want one failed action with the status", result)
+	}
+}
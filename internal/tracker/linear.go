@@ -0,0 +1,288 @@
+package tracker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chuckie/goinsight/internal/domain"
+)
+
+// DefaultLinearAPIURL is Linear's GraphQL endpoint
+const DefaultLinearAPIURL = "https://api.linear.app/graphql"
+
+// linearPriorities maps spec priorities to Linear's (1 urgent to 4 low)
+var linearPriorities = map[string]int{
+	"highest": 1,
+	"high":    2,
+	"medium":  3,
+	"low":     4,
+}
+
+// LinearClient files tickets as Linear issues in one team. Linear labels
+// must already exist in the team, so labels without a match are left out
+// and the fingerprint is recorded in the description instead.
+type LinearClient struct {
+	apiURL     string
+	apiKey     string
+	teamID     string
+	httpClient *http.Client
+
+	// Team label IDs by lowercase name, loaded on first use
+	labelsMu sync.Mutex
+	labels   map[string]string
+}
+
+// NewLinearClient creates a Linear client filing issues in teamID
+func NewLinearClient(apiURL, apiKey, teamID string) *LinearClient {
+	return &LinearClient{
+		apiURL: apiURL,
+		apiKey: apiKey,
+		teamID: teamID,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// linearIssue is the part of a Linear issue used by the client
+type linearIssue struct {
+	ID         string `json:"id"`
+	Identifier string `json:"identifier"`
+	URL        string `json:"url"`
+}
+
+// Name returns Linear
+func (c *LinearClient) Name() string {
+	return Linear
+}
+
+// CreateIssues creates an issue for each spec unless one with its
+// fingerprint exists in the team
+func (c *LinearClient) CreateIssues(ctx context.Context, specs []domain.JiraTicketSpec) (*domain.JiraCreationResult, error) {
+	return createEach(specs, func(spec domain.JiraTicketSpec, action *domain.JiraActionResult) (*domain.JiraCreateResponse, error) {
+		return c.createUnlessDuplicate(ctx, spec, "", action)
+	}), nil
+}
+
+// CreateIssuesWithEpic creates epic as a parent issue, or reuses the issue
+// with its fingerprint, and specs as its sub-issues
+func (c *LinearClient) CreateIssuesWithEpic(ctx context.Context, epic domain.JiraTicketSpec, specs []domain.JiraTicketSpec) (*domain.JiraCreationResult, error) {
+	parent, err := c.parentIssue(ctx, epic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create epic: %w", err)
+	}
+	parent.Hierarchy = &domain.JiraHierarchy{Role: domain.JiraRoleEpic, EpicKey: parent.Key}
+
+	result := createEach(specs, func(spec domain.JiraTicketSpec, action *domain.JiraActionResult) (*domain.JiraCreateResponse, error) {
+		created, err := c.createUnlessDuplicate(ctx, spec, parent.ID, action)
+		if created != nil {
+			created.Hierarchy = &domain.JiraHierarchy{Role: domain.JiraRoleChild, EpicKey: parent.Key, LinkedBy: "parent"}
+		}
+		return created, err
+	})
+	result.Epic = parent
+	return result, nil
+}
+
+// parentIssue returns the issue with the epic's fingerprint, or creates it
+func (c *LinearClient) parentIssue(ctx context.Context, epic domain.JiraTicketSpec) (*domain.JiraCreateResponse, error) {
+	if epic.Fingerprint != "" {
+		existing, err := c.findByFingerprint(ctx, epic.Fingerprint)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check for an existing epic: %w", err)
+		}
+		if existing != nil {
+			return &domain.JiraCreateResponse{ID: existing.ID, Key: existing.Identifier, Self: existing.URL}, nil
+		}
+	}
+	return c.CreateIssue(ctx, epic, "")
+}
+
+// createUnlessDuplicate creates the issue for spec, under parentID if set,
+// unless its fingerprint is already filed
+func (c *LinearClient) createUnlessDuplicate(ctx context.Context, spec domain.JiraTicketSpec, parentID string, action *domain.JiraActionResult) (*domain.JiraCreateResponse, error) {
+	if spec.Fingerprint != "" {
+		existing, err := c.findByFingerprint(ctx, spec.Fingerprint)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check for duplicates: %w", err)
+		}
+		if existing != nil {
+			action.Status = domain.JiraActionSkippedDuplicate
+			action.Key = existing.Identifier
+			return nil, nil
+		}
+	}
+
+	created, err := c.CreateIssue(ctx, spec, parentID)
+	if err != nil {
+		return nil, err
+	}
+	action.Status = domain.JiraActionCreated
+	action.Key = created.Key
+	return created, nil
+}
+
+// CreateIssue creates a single issue, as a sub-issue of parentID if set. Its
+// key is the Linear identifier, e.g. ENG-123.
+func (c *LinearClient) CreateIssue(ctx context.Context, spec domain.JiraTicketSpec, parentID string) (*domain.JiraCreateResponse, error) {
+	description := markdownDescription(spec)
+	if spec.Fingerprint != "" {
+		description += "\n\n---\nFingerprint: `" + spec.Fingerprint + "`"
+	}
+
+	input := map[string]any{
+		"teamId":      c.teamID,
+		"title":       spec.Summary,
+		"description": strings.TrimSpace(description),
+	}
+	if priority, ok := linearPriorities[strings.ToLower(spec.Priority)]; ok {
+		input["priority"] = priority
+	}
+	if parentID != "" {
+		input["parentId"] = parentID
+	}
+	labelIDs, err := c.labelIDs(ctx, spec.Labels)
+	if err != nil {
+		return nil, err
+	}
+	if len(labelIDs) > 0 {
+		input["labelIds"] = labelIDs
+	}
+
+	var data struct {
+		IssueCreate struct {
+			Success bool        `json:"success"`
+			Issue   linearIssue `json:"issue"`
+		} `json:"issueCreate"`
+	}
+	query := `mutation IssueCreate($input: IssueCreateInput!) {
+		issueCreate(input: $input) { success issue { id identifier url } }
+	}`
+	if err := c.graphql(ctx, query, map[string]any{"input": input}, &data); err != nil {
+		return nil, err
+	}
+	if !data.IssueCreate.Success {
+		return nil, fmt.Errorf("linear did not create the issue")
+	}
+
+	issue := data.IssueCreate.Issue
+	return &domain.JiraCreateResponse{ID: issue.ID, Key: issue.Identifier, Self: issue.URL}, nil
+}
+
+// findByFingerprint returns a team issue whose description holds the fingerprint, if any
+func (c *LinearClient) findByFingerprint(ctx context.Context, fingerprint string) (*linearIssue, error) {
+	var data struct {
+		Issues struct {
+			Nodes []linearIssue `json:"nodes"`
+		} `json:"issues"`
+	}
+	query := `query Duplicates($filter: IssueFilter) {
+		issues(filter: $filter, first: 1) { nodes { id identifier url } }
+	}`
+	filter := map[string]any{
+		"team":        map[string]any{"id": map[string]any{"eq": c.teamID}},
+		"description": map[string]any{"contains": fingerprint},
+	}
+	if err := c.graphql(ctx, query, map[string]any{"filter": filter}, &data); err != nil {
+		return nil, err
+	}
+	if len(data.Issues.Nodes) == 0 {
+		return nil, nil
+	}
+	return &data.Issues.Nodes[0], nil
+}
+
+// labelIDs returns the IDs of the team labels matching names (case-insensitively)
+func (c *LinearClient) labelIDs(ctx context.Context, names []string) ([]string, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	c.labelsMu.Lock()
+	defer c.labelsMu.Unlock()
+
+	if c.labels == nil {
+		var data struct {
+			Team struct {
+				Labels struct {
+					Nodes []struct {
+						ID   string `json:"id"`
+						Name string `json:"name"`
+					} `json:"nodes"`
+				} `json:"labels"`
+			} `json:"team"`
+		}
+		query := `query TeamLabels($id: String!) {
+			team(id: $id) { labels(first: 250) { nodes { id name } } }
+		}`
+		if err := c.graphql(ctx, query, map[string]any{"id": c.teamID}, &data); err != nil {
+			return nil, fmt.Errorf("failed to load labels: %w", err)
+		}
+		c.labels = make(map[string]string, len(data.Team.Labels.Nodes))
+		for _, label := range data.Team.Labels.Nodes {
+			c.labels[strings.ToLower(label.Name)] = label.ID
+		}
+	}
+
+	var ids []string
+	for _, name := range names {
+		if id, ok := c.labels[strings.ToLower(name)]; ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// graphql runs a query against the Linear API and decodes its data into out
+func (c *LinearClient) graphql(ctx context.Context, query string, variables map[string]any, out any) error {
+	body, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.apiURL, bytes.NewBuffer(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", c.apiKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("linear API returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	// GraphQL reports errors in the body of a successful response
+	var result struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("linear API error: %s", result.Errors[0].Message)
+	}
+	if err := json.Unmarshal(result.Data, out); err != nil {
+		return fmt.Errorf("failed to parse response data: %w", err)
+	}
+	return nil
+}
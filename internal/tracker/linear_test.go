@@ -0,0 +1,172 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/chuckie/goinsight/internal/domain"
+)
+
+// fakeLinear is an in-memory stand-in for the Linear GraphQL queries used by LinearClient
+type fakeLinear struct {
+	t *testing.T
+
+	mu     sync.Mutex
+	issues []map[string]any // issueCreate inputs
+}
+
+// newFakeLinear starts a fake Linear server and a client for team "team-1" pointed at it
+func newFakeLinear(t *testing.T) (*fakeLinear, *LinearClient) {
+	t.Helper()
+	fake := &fakeLinear{t: t}
+	server := httptest.NewServer(http.HandlerFunc(fake.serveHTTP))
+	t.Cleanup(server.Close)
+	return fake, NewLinearClient(server.URL, "lin-key", "team-1")
+}
+
+func (f *fakeLinear) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "lin-key" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		Query     string         `json:"query"`
+		Variables map[string]any `json:"variables"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var data any
+	switch {
+	case strings.Contains(req.Query, "issueCreate"):
+		input := req.Variables["input"].(map[string]any)
+		if input["teamId"] != "team-1" {
+			json.NewEncoder(w).Encode(map[string]any{"errors": []map[string]string{{"message": "team not found"}}})
+			return
+		}
+		f.issues = append(f.issues, input)
+		n := len(f.issues)
+		data = map[string]any{"issueCreate": map[string]any{
+			"success": true,
+			"issue":   map[string]any{"id": fmt.Sprintf("uuid-%d", n), "identifier": fmt.Sprintf("ENG-%d", n), "url": fmt.Sprintf("https://linear.app/acme/issue/ENG-%d", n)},
+		}}
+
+	case strings.Contains(req.Query, "TeamLabels"):
+		data = map[string]any{"team": map[string]any{"labels": map[string]any{"nodes": []map[string]string{
+			{"id": "label-feedback", "name": "Feedback"},
+			{"id": "label-billing", "name": "billing"},
+		}}}}
+
+	case strings.Contains(req.Query, "Duplicates"):
+		filter := req.Variables["filter"].(map[string]any)
+		fingerprint := filter["description"].(map[string]any)["contains"].(string)
+		nodes := []map[string]any{}
+		for i, issue := range f.issues {
+			if strings.Contains(issue["description"].(string), fingerprint) {
+				nodes = append(nodes, map[string]any{"id": fmt.Sprintf("uuid-%d", i+1), "identifier": fmt.Sprintf("ENG-%d", i+1)})
+			}
+		}
+		data = map[string]any{"issues": map[string]any{"nodes": nodes}}
+
+	default:
+		http.Error(w, "unexpected query", http.StatusBadRequest)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]any{"data": data})
+}
+
+// TestLinearCreateIssues tests issue creation, label and priority mapping and deduplication
+func TestLinearCreateIssues(t *testing.T) {
+	fake, client := newFakeLinear(t)
+	specs := []domain.JiraTicketSpec{{
+		Summary:     "Fix refund delays",
+		Description: "Refunds take too long.",
+		Priority:    "Highest",
+		Labels:      []string{"feedback", "billing", "not-in-linear"},
+		Fingerprint: "goinsight-fp-0123456789abcdef",
+	}}
+
+	result, err := client.CreateIssues(context.Background(), specs)
+	if err != nil {
+		t.Fatalf("CreateIssues() unexpected error: %v", err)
+	}
+	if len(result.CreatedTickets) != 1 || result.CreatedTickets[0].Key != "ENG-1" {
+		t.Fatalf("created = %+v, want ENG-1", result.CreatedTickets)
+	}
+
+	input := fake.issues[0]
+	if input["priority"] != float64(1) {
+		t.Errorf("priority = %v, want 1 (urgent)", input["priority"])
+	}
+	if labels := fmt.Sprint(input["labelIds"]); labels != "[label-feedback label-billing]" {
+		t.Errorf("labelIds = %s, want the two labels that exist in the team", labels)
+	}
+	if !strings.Contains(input["description"].(string), specs[0].Fingerprint) {
+		t.Errorf("description %q missing the fingerprint", input["description"])
+	}
+
+	again, err := client.CreateIssues(context.Background(), specs)
+	if err != nil {
+		t.Fatalf("CreateIssues() unexpected error: %v", err)
+	}
+	if again.Actions[0].Status != domain.JiraActionSkippedDuplicate || again.Actions[0].Key != "ENG-1" || len(fake.issues) != 1 {
+		t.Errorf("re-sent action = %+v, want skipped_duplicate of ENG-1", again.Actions[0])
+	}
+}
+
+// TestLinearCreateIssuesWithEpic tests that actions become sub-issues of the epic
+func TestLinearCreateIssuesWithEpic(t *testing.T) {
+	fake, client := newFakeLinear(t)
+	epic := domain.JiraTicketSpec{Summary: "Customer feedback insight: billing", Fingerprint: "goinsight-fp-epic"}
+	specs := []domain.JiraTicketSpec{{Summary: "Fix refund delays"}, {Summary: "Audit invoices"}}
+
+	result, err := client.CreateIssuesWithEpic(context.Background(), epic, specs)
+	if err != nil {
+		t.Fatalf("CreateIssuesWithEpic() unexpected error: %v", err)
+	}
+	if result.Epic == nil || result.Epic.Key != "ENG-1" || result.Epic.Hierarchy.Role != domain.JiraRoleEpic {
+		t.Fatalf("epic = %+v, want ENG-1", result.Epic)
+	}
+	for i, created := range result.CreatedTickets {
+		if created.Hierarchy == nil || created.Hierarchy.EpicKey != "ENG-1" {
+			t.Errorf("ticket %d hierarchy = %+v, want child of ENG-1", i, created.Hierarchy)
+		}
+		if parent := fake.issues[i+1]["parentId"]; parent != "uuid-1" {
+			t.Errorf("ticket %d parentId = %v, want uuid-1", i, parent)
+		}
+	}
+
+	again, err := client.CreateIssuesWithEpic(context.Background(), epic, nil)
+	if err != nil {
+		t.Fatalf("CreateIssuesWithEpic() unexpected error: %v", err)
+	}
+	if again.Epic.Key != "ENG-1" || len(fake.issues) != 3 {
+		t.Errorf("re-sent epic = %+v, want ENG-1 reused", again.Epic)
+	}
+}
+
+// TestLinearGraphQLError tests that GraphQL errors fail the action
+func TestLinearGraphQLError(t *testing.T) {
+	_, client := newFakeLinear(t)
+	client.teamID = "missing"
+
+	result, err := client.CreateIssues(context.Background(), []domain.JiraTicketSpec{{Summary: "Fix refunds"}})
+	if err != nil {
+		t.Fatalf("CreateIssues() unexpected error: %v", err)
+	}
+	if result.Actions[0].Status != domain.JiraActionFailed || !strings.Contains(result.Actions[0].Error, "team not found") {
+		t.Errorf("action = %+v, want failed with the GraphQL error", result.Actions[0])
+	}
+}
@@ -0,0 +1,146 @@
+// Package tracker files the tickets generated from insights in an issue
+// tracker: Jira (through jira.Client), GitHub Issues or Linear
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/chuckie/goinsight/internal/domain"
+)
+
+// Supported issue trackers, selected with the ISSUE_TRACKER setting
+const (
+	Jira   = "jira"
+	GitHub = "github"
+	Linear = "linear"
+)
+
+// IssueTracker files ticket specs in an issue tracker. Specs with a
+// Fingerprint are skipped when an issue with the same fingerprint already
+// exists, and each spec's outcome is reported in the result's Actions.
+type IssueTracker interface {
+	// Name returns the tracker's name, e.g. Jira
+	Name() string
+
+	// CreateIssues files specs in order; specs not filed when ctx is
+	// cancelled are reported as failed
+	CreateIssues(ctx context.Context, specs []domain.JiraTicketSpec) (*domain.JiraCreationResult, error)
+}
+
+// EpicCreator is implemented by trackers that can group issues under a parent
+// issue (a Jira epic, a Linear parent issue)
+type EpicCreator interface {
+	// CreateIssuesWithEpic files epic, or reuses it if its fingerprint is
+	// already filed, and then specs as its children
+	CreateIssuesWithEpic(ctx context.Context, epic domain.JiraTicketSpec, specs []domain.JiraTicketSpec) (*domain.JiraCreationResult, error)
+}
+
+// Commenter is implemented by trackers that can add new evidence to an
+// existing issue (Jira)
+type Commenter interface {
+	// CommentOnIssue adds the spec's Comment and data preview to the issue
+	// spec.CommentOn
+	CommentOnIssue(ctx context.Context, spec domain.JiraTicketSpec) error
+}
+
+// StatusSource is implemented by trackers whose issue statuses can be polled
+// (Jira), so tickets filed from insights can be followed up
+type StatusSource interface {
+	// IssueStatuses returns the current status of each issue by key
+	IssueStatuses(ctx context.Context, keys []string) (map[string]domain.JiraStatus, error)
+}
+
+// maxPreviewRows bounds the data preview rows rendered in a description
+const maxPreviewRows = 10
+
+// createEach files specs one at a time with create, which records the outcome
+// of a spec in action, and collects the results
+func createEach(specs []domain.JiraTicketSpec, create func(domain.JiraTicketSpec, *domain.JiraActionResult) (*domain.JiraCreateResponse, error)) *domain.JiraCreationResult {
+	result := &domain.JiraCreationResult{
+		TicketSpecs:    specs,
+		CreatedTickets: make([]domain.JiraCreateResponse, 0, len(specs)),
+		Actions:        make([]domain.JiraActionResult, 0, len(specs)),
+		Errors:         make([]string, 0),
+	}
+
+	for i, spec := range specs {
+		action := domain.JiraActionResult{
+			Summary:     spec.Summary,
+			Fingerprint: spec.Fingerprint,
+		}
+
+		created, err := create(spec, &action)
+		if err != nil {
+			action.Status = domain.JiraActionFailed
+			action.Error = err.Error()
+			result.Errors = append(result.Errors, fmt.Sprintf("Ticket %d (%s): %v", i+1, spec.Summary, err))
+		}
+		if created != nil {
+			result.CreatedTickets = append(result.CreatedTickets, *created)
+		}
+		result.Actions = append(result.Actions, action)
+	}
+
+	return result
+}
+
+// markdownDescription returns the spec's description followed by its
+// evidence: the data preview as a markdown table and the SQL
+func markdownDescription(spec domain.JiraTicketSpec) string {
+	var b strings.Builder
+	b.WriteString(strings.TrimSpace(spec.Description))
+	if len(spec.DataPreview) > 0 {
+		b.WriteString("\n\n### Supporting data\n\n")
+		b.WriteString(markdownTable(spec.DataPreview))
+	}
+	if sql := strings.TrimSpace(spec.SQL); sql != "" {
+		b.WriteString("\n\n### SQL\n\n```sql\n" + sql + "\n```")
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// markdownTable renders up to maxPreviewRows rows of a data preview, columns
+// in name order
+func markdownTable(preview []map[string]any) string {
+	columnSet := make(map[string]bool)
+	for _, row := range preview {
+		for column := range row {
+			columnSet[column] = true
+		}
+	}
+	columns := make([]string, 0, len(columnSet))
+	for column := range columnSet {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	rows := preview
+	if len(rows) > maxPreviewRows {
+		rows = rows[:maxPreviewRows]
+	}
+
+	escape := strings.NewReplacer("|", `\|`, "\n", " ")
+	var b strings.Builder
+	b.WriteString("|")
+	for _, column := range columns {
+		b.WriteString(" " + escape.Replace(column) + " |")
+	}
+	b.WriteString("\n|")
+	for range columns {
+		b.WriteString(" --- |")
+	}
+	for _, row := range rows {
+		b.WriteString("\n|")
+		for _, column := range columns {
+			cell := ""
+			if value, ok := row[column]; ok && value != nil {
+				cell = fmt.Sprint(value)
+			}
+			b.WriteString(" " + escape.Replace(cell) + " |")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,51 @@
+package tracker
+
+import (
+	"testing"
+
+	"github.com/chuckie/goinsight/internal/domain"
+	"github.com/chuckie/goinsight/internal/jira"
+)
+
+// Every backend is an IssueTracker; Jira and Linear can also create epics
+var (
+	_ IssueTracker = (*jira.Client)(nil)
+	_ IssueTracker = (*GitHubClient)(nil)
+	_ IssueTracker = (*LinearClient)(nil)
+	_ EpicCreator  = (*jira.Client)(nil)
+	_ EpicCreator  = (*LinearClient)(nil)
+)
+
+// TestMarkdownDescription tests the data preview table appended to descriptions
+func TestMarkdownDescription(t *testing.T) {
+	tests := []struct {
+		name string
+		spec domain.JiraTicketSpec
+		want string
+	}{
+		{"no preview", domain.JiraTicketSpec{Description: " Refunds are slow. "}, "Refunds are slow."},
+		{"preview", domain.JiraTicketSpec{
+			Description: "Refunds are slow.",
+			DataPreview: []map[string]any{{"area": "billing", "note": "a|b"}, {"area": "login"}},
+		}, "Refunds are slow.\n\n### Supporting data\n\n| area | note |\n| --- | --- |\n| billing | a\\|b |\n| login |  |"},
+		{"sql", domain.JiraTicketSpec{
+			Description: "Refunds are slow.",
+			SQL:         "SELECT 1\n",
+		}, "Refunds are slow.\n\n### SQL\n\n```sql\nSELECT 1\n```"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := markdownDescription(tt.spec); got != tt.want {
+				t.Errorf("markdownDescription() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestJiraName tests that the Jira client identifies as the jira tracker
+func TestJiraName(t *testing.T) {
+	if got := jira.NewClient("", "", "", "").Name(); got != Jira {
+		t.Errorf("Name() = %q, want %q", got, Jira)
+	}
+}
@@ -0,0 +1,23 @@
+-- Migration: Document feedback_enriched and ML prediction columns
+-- Comments are read by the schema catalog and included in the SQL generation prompt
+
+COMMENT ON TABLE feedback_enriched IS 'Customer feedback enriched with sentiment, priority and topic. Use for: feedback themes, sentiment and issue analysis';
+COMMENT ON COLUMN feedback_enriched.source IS 'Feedback channel, e.g. ''zendesk'', ''google_play'', ''nps_survey''';
+COMMENT ON COLUMN feedback_enriched.product_area IS 'e.g. ''billing'', ''onboarding'', ''performance''';
+COMMENT ON COLUMN feedback_enriched.sentiment IS '''positive'', ''neutral'' or ''negative''';
+COMMENT ON COLUMN feedback_enriched.priority IS '1 (low) to 5 (critical)';
+COMMENT ON COLUMN feedback_enriched.topic IS 'High-level tag, e.g. ''refund issues''';
+COMMENT ON COLUMN feedback_enriched.region IS 'e.g. ''NA'', ''EU'', ''APAC''';
+COMMENT ON COLUMN feedback_enriched.customer_tier IS 'e.g. ''free'', ''pro'', ''enterprise''';
+COMMENT ON COLUMN feedback_enriched.account_id IS 'Account that gave the feedback; joins to account_risk_scores.account_id';
+COMMENT ON COLUMN feedback_enriched.summary IS 'Short summary of the feedback';
+
+COMMENT ON TABLE account_risk_scores IS 'ML predictions for account churn risk and health scores. Use for: churn risk, account health, at-risk customers';
+COMMENT ON COLUMN account_risk_scores.account_id IS 'Unique account identifier';
+COMMENT ON COLUMN account_risk_scores.model_version IS 'ML model version used for prediction';
+
+COMMENT ON TABLE product_area_impact IS 'ML predictions for product area priority scores by segment. Use for: product area prioritization, impact analysis, segment-specific insights';
+COMMENT ON COLUMN product_area_impact.segment IS 'e.g. ''enterprise'', ''smb'', ''pro''';
+COMMENT ON COLUMN product_area_impact.feedback_count IS 'Total feedback volume';
+COMMENT ON COLUMN product_area_impact.negative_count IS 'Count of negative feedback';
+COMMENT ON COLUMN product_area_impact.critical_count IS 'Count of critical priority feedback';
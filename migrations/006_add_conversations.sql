@@ -0,0 +1,19 @@
+-- Migration: Add conversation history for follow-up questions on /api/ask
+-- Each row is one question asked within a conversation, with the SQL that
+-- answered it and the insight summary, so follow-ups can refine the query
+
+CREATE TABLE IF NOT EXISTS conversation_turns (
+    id BIGSERIAL PRIMARY KEY,
+    conversation_id UUID NOT NULL,
+    question TEXT NOT NULL,
+    sql_query TEXT NOT NULL DEFAULT '',
+    summary TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Turns are always read per conversation in the order they were asked
+CREATE INDEX IF NOT EXISTS idx_conversation_turns_conversation ON conversation_turns(conversation_id, id);
+
+COMMENT ON TABLE conversation_turns IS 'Questions asked within an /api/ask conversation, oldest first';
+COMMENT ON COLUMN conversation_turns.sql_query IS 'SQL that was executed to answer the question';
+COMMENT ON COLUMN conversation_turns.summary IS 'Insight summary returned for the question';
@@ -0,0 +1,22 @@
+-- Migration: Add drafts for the preview-then-confirm /api/jira-tickets flow
+-- A dry run stores the generated ticket specs here; nothing is filed in Jira
+-- until the draft is confirmed, possibly with edited specs
+
+CREATE TABLE IF NOT EXISTS jira_drafts (
+    id UUID PRIMARY KEY,
+    question TEXT NOT NULL DEFAULT '',
+    tickets JSONB NOT NULL,
+    epic JSONB,
+    data_preview JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMPTZ NOT NULL,
+    confirmed_at TIMESTAMPTZ
+);
+
+-- Expired drafts can be purged by age
+CREATE INDEX IF NOT EXISTS idx_jira_drafts_expires_at ON jira_drafts(expires_at);
+
+COMMENT ON TABLE jira_drafts IS 'Generated Jira ticket specs awaiting review before they are filed';
+COMMENT ON COLUMN jira_drafts.tickets IS 'Ticket specs; replaced by the edited specs on confirm';
+COMMENT ON COLUMN jira_drafts.epic IS 'Epic spec created as the tickets parent, if requested';
+COMMENT ON COLUMN jira_drafts.confirmed_at IS 'When the draft was filed; a draft is confirmed at most once';
@@ -0,0 +1,25 @@
+-- Migration: Store /api/ask answers as analyses that tickets can link back to
+-- Each row holds the SQL and data preview behind an insight, so the evidence
+-- for a ticket can be checked without re-running the question
+
+CREATE TABLE IF NOT EXISTS analyses (
+    id UUID PRIMARY KEY,
+    question TEXT NOT NULL,
+    sql_query TEXT NOT NULL DEFAULT '',
+    summary TEXT NOT NULL DEFAULT '',
+    data_preview JSONB,
+    total_rows INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Old analyses can be purged by age
+CREATE INDEX IF NOT EXISTS idx_analyses_created_at ON analyses(created_at);
+
+-- Drafts keep the evidence attached to their tickets on confirm
+ALTER TABLE jira_drafts ADD COLUMN IF NOT EXISTS sql_query TEXT NOT NULL DEFAULT '';
+ALTER TABLE jira_drafts ADD COLUMN IF NOT EXISTS analysis_id UUID;
+
+COMMENT ON TABLE analyses IS 'Answers returned by /api/ask, referenced by the tickets filed from them';
+COMMENT ON COLUMN analyses.sql_query IS 'SQL that was executed to answer the question';
+COMMENT ON COLUMN analyses.data_preview IS 'Rows returned with the answer';
+COMMENT ON COLUMN jira_drafts.analysis_id IS 'Analysis the drafted tickets link back to, if any';
@@ -0,0 +1,41 @@
+-- Migration: Track the tickets filed from insights and their status changes
+-- Created issue keys are stored with the question and action they came from;
+-- Jira webhooks and periodic polling record each status transition
+
+CREATE TABLE IF NOT EXISTS insight_tickets (
+    tracker TEXT NOT NULL,
+    issue_key TEXT NOT NULL,
+    question TEXT NOT NULL DEFAULT '',
+    action TEXT NOT NULL DEFAULT '',
+    summary TEXT NOT NULL DEFAULT '',
+    product_area TEXT NOT NULL DEFAULT '',
+    analysis_id UUID,
+    status TEXT NOT NULL DEFAULT '',
+    status_category TEXT NOT NULL DEFAULT 'new',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    resolved_at TIMESTAMPTZ,
+    PRIMARY KEY (tracker, issue_key)
+);
+
+CREATE TABLE IF NOT EXISTS insight_ticket_transitions (
+    id BIGSERIAL PRIMARY KEY,
+    tracker TEXT NOT NULL,
+    issue_key TEXT NOT NULL,
+    from_status TEXT NOT NULL DEFAULT '',
+    to_status TEXT NOT NULL,
+    status_category TEXT NOT NULL,
+    source TEXT NOT NULL,
+    changed_at TIMESTAMPTZ NOT NULL,
+    FOREIGN KEY (tracker, issue_key) REFERENCES insight_tickets(tracker, issue_key) ON DELETE CASCADE
+);
+
+-- Open tickets are listed per product area and polled for status changes
+CREATE INDEX IF NOT EXISTS idx_insight_tickets_product_area ON insight_tickets(product_area);
+CREATE INDEX IF NOT EXISTS idx_insight_tickets_open ON insight_tickets(tracker) WHERE status_category <> 'done';
+CREATE INDEX IF NOT EXISTS idx_insight_ticket_transitions_issue ON insight_ticket_transitions(tracker, issue_key, changed_at);
+
+COMMENT ON TABLE insight_tickets IS 'Tickets filed from /api/jira-tickets with the insight they came from';
+COMMENT ON COLUMN insight_tickets.status_category IS 'Jira status category key: new, indeterminate or done';
+COMMENT ON COLUMN insight_tickets.resolved_at IS 'When the ticket last entered the done category';
+COMMENT ON TABLE insight_ticket_transitions IS 'Status changes of insight tickets, from webhooks or polling';
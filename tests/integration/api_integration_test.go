@@ -1,6 +1,7 @@
 package integration
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"database/sql"
@@ -8,10 +9,12 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/chuckie/goinsight/internal/domain"
 	apihttp "github.com/chuckie/goinsight/internal/http"
+	"github.com/chuckie/goinsight/internal/llm"
 	"github.com/chuckie/goinsight/internal/service"
 	"github.com/chuckie/goinsight/tests/mocks"
 )
@@ -28,7 +31,7 @@ func TestEndToEndAskFlow(t *testing.T) {
 	// Setup mock LLM
 	llmClient := &MockLLMClient{
 		GenerateSQLFn: func(ctx context.Context, q string) (string, error) {
-			return "SELECT * FROM feedback WHERE sentiment = 'positive'", nil
+			return "SELECT * FROM feedback_enriched WHERE sentiment = 'positive'", nil
 		},
 		GenerateInsightFn: func(ctx context.Context, question string, results []map[string]any) (string, error) {
 			return `{"summary": "Customers are very satisfied with the product", "recommendations": [], "actions": []}`, nil
@@ -71,6 +74,58 @@ func TestEndToEndAskFlow(t *testing.T) {
 	}
 }
 
+// TestAskStreamFlow tests the Server-Sent Events variant of the Ask endpoint
+func TestAskStreamFlow(t *testing.T) {
+	mockRepo := mocks.NewMockFeedbackRepository()
+	mockRepo.SetQueryFeedbackResult([]map[string]any{
+		{"id": 1, "feedback": "Excellent product", "sentiment": "positive"},
+	})
+
+	llmClient := &MockLLMClient{
+		GenerateInsightFn: func(ctx context.Context, question string, results []map[string]any) (string, error) {
+			return `{"summary": "Customers are satisfied", "recommendations": [], "actions": []}`, nil
+		},
+	}
+
+	svc := service.NewFeedbackService(mockRepo, llmClient, nil)
+	server := httptest.NewServer(apihttp.NewRouter(apihttp.NewServiceHandler(svc, nil)))
+	defer server.Close()
+
+	body, _ := json.Marshal(domain.AskRequest{Question: "What is the sentiment?"})
+	resp, err := http.Post(server.URL+"/api/ask/stream", "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q, want text/event-stream", ct)
+	}
+
+	var events []string
+	var complete domain.AskResponse
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if event, ok := strings.CutPrefix(line, "event: "); ok {
+			events = append(events, event)
+		}
+		if data, ok := strings.CutPrefix(line, "data: "); ok && events[len(events)-1] == "complete" {
+			if err := json.Unmarshal([]byte(data), &complete); err != nil {
+				t.Fatalf("invalid complete event: %v", err)
+			}
+		}
+	}
+
+	want := []string{"sql_generated", "sql_validated", "rows_fetched", "insight_token", "complete"}
+	if strings.Join(events, ",") != strings.Join(want, ",") {
+		t.Errorf("events = %v, want %v", events, want)
+	}
+	if complete.Summary != "Customers are satisfied" || complete.TotalRows != 1 {
+		t.Errorf("complete event = %+v", complete)
+	}
+}
+
 // TestHealthCheckIntegration tests health check flow
 func TestHealthCheckIntegration(t *testing.T) {
 	handler := apihttp.NewHandler(&MockDatabaseClient{}, nil, nil)
@@ -176,7 +231,7 @@ func TestServiceIntegration(t *testing.T) {
 
 	llmClient := &MockLLMClient{
 		GenerateSQLFn: func(ctx context.Context, q string) (string, error) {
-			return "SELECT * FROM feedback", nil
+			return "SELECT * FROM feedback_enriched", nil
 		},
 		GenerateInsightFn: func(ctx context.Context, question string, results []map[string]any) (string, error) {
 			return "All feedback is positive", nil
@@ -361,7 +416,7 @@ func (m *MockLLMClient) GenerateSQL(ctx context.Context, question string) (strin
 	if m.GenerateSQLFn != nil {
 		return m.GenerateSQLFn(ctx, question)
 	}
-	return "SELECT * FROM feedback", nil
+	return "SELECT * FROM feedback_enriched", nil
 }
 
 func (m *MockLLMClient) GenerateInsight(ctx context.Context, question string, results []map[string]any) (string, error) {
@@ -371,6 +426,14 @@ func (m *MockLLMClient) GenerateInsight(ctx context.Context, question string, re
 	return `{"summary": "Analysis complete", "recommendations": [], "actions": []}`, nil
 }
 
+func (m *MockLLMClient) GenerateInsightStream(ctx context.Context, question string, results []map[string]any, onToken llm.TokenHandler) (string, error) {
+	response, err := m.GenerateInsight(ctx, question, results)
+	if err == nil && onToken != nil {
+		onToken(response)
+	}
+	return response, err
+}
+
 func (m *MockLLMClient) Generate(ctx context.Context, prompt string) (string, error) {
 	if m.GenerateFn != nil {
 		return m.GenerateFn(ctx, prompt)
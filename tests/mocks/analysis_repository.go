@@ -0,0 +1,50 @@
+package mocks
+
+import (
+	"context"
+	"sync"
+
+	"github.com/chuckie/goinsight/internal/domain"
+)
+
+// MockAnalysisRepository is an in-memory implementation of AnalysisRepository for testing
+type MockAnalysisRepository struct {
+	mu       sync.Mutex
+	Analyses map[string]*domain.Analysis
+
+	// Configure errors
+	SaveAnalysisErr error
+}
+
+// NewMockAnalysisRepository creates a new mock analysis repository
+func NewMockAnalysisRepository() *MockAnalysisRepository {
+	return &MockAnalysisRepository{
+		Analyses: make(map[string]*domain.Analysis),
+	}
+}
+
+// SaveAnalysis implements AnalysisRepository.SaveAnalysis
+func (m *MockAnalysisRepository) SaveAnalysis(ctx context.Context, analysis *domain.Analysis) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.SaveAnalysisErr != nil {
+		return m.SaveAnalysisErr
+	}
+	stored := *analysis
+	m.Analyses[analysis.ID] = &stored
+	return nil
+}
+
+// GetAnalysis implements AnalysisRepository.GetAnalysis
+func (m *MockAnalysisRepository) GetAnalysis(ctx context.Context, id string) (*domain.Analysis, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	analysis, ok := m.Analyses[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *analysis
+	return &copied, nil
+}
@@ -0,0 +1,52 @@
+package mocks
+
+import (
+	"context"
+	"sync"
+
+	"github.com/chuckie/goinsight/internal/domain"
+)
+
+// MockConversationRepository is an in-memory implementation of ConversationRepository for testing
+type MockConversationRepository struct {
+	mu    sync.Mutex
+	Turns map[string][]domain.ConversationTurn
+
+	// Configure errors
+	AppendTurnErr  error
+	RecentTurnsErr error
+}
+
+// NewMockConversationRepository creates a new mock conversation repository
+func NewMockConversationRepository() *MockConversationRepository {
+	return &MockConversationRepository{
+		Turns: make(map[string][]domain.ConversationTurn),
+	}
+}
+
+// AppendTurn implements ConversationRepository.AppendTurn
+func (m *MockConversationRepository) AppendTurn(ctx context.Context, conversationID string, turn domain.ConversationTurn) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.AppendTurnErr != nil {
+		return m.AppendTurnErr
+	}
+	m.Turns[conversationID] = append(m.Turns[conversationID], turn)
+	return nil
+}
+
+// RecentTurns implements ConversationRepository.RecentTurns
+func (m *MockConversationRepository) RecentTurns(ctx context.Context, conversationID string, limit int) ([]domain.ConversationTurn, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.RecentTurnsErr != nil {
+		return nil, m.RecentTurnsErr
+	}
+	turns := m.Turns[conversationID]
+	if len(turns) > limit {
+		turns = turns[len(turns)-limit:]
+	}
+	return append([]domain.ConversationTurn(nil), turns...), nil
+}
@@ -0,0 +1,166 @@
+package mocks
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chuckie/goinsight/internal/domain"
+)
+
+// MockInsightTicketRepository is an in-memory implementation of InsightTicketRepository for testing
+type MockInsightTicketRepository struct {
+	mu          sync.Mutex
+	Tickets     []domain.InsightTicket
+	Transitions []MockTicketTransition
+
+	// Configure errors
+	SaveTicketsErr  error
+	RecordStatusErr error
+}
+
+// MockTicketTransition is a status change recorded by the mock
+type MockTicketTransition struct {
+	Key    string
+	From   string
+	To     string
+	Source string
+}
+
+// NewMockInsightTicketRepository creates a new mock insight ticket repository
+func NewMockInsightTicketRepository() *MockInsightTicketRepository {
+	return &MockInsightTicketRepository{}
+}
+
+// SaveTickets implements InsightTicketRepository.SaveTickets
+func (m *MockInsightTicketRepository) SaveTickets(ctx context.Context, tickets []domain.InsightTicket) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.SaveTicketsErr != nil {
+		return m.SaveTicketsErr
+	}
+	for _, ticket := range tickets {
+		if m.find(ticket.Tracker, ticket.Key) != nil {
+			continue
+		}
+		ticket.UpdatedAt = ticket.CreatedAt
+		m.Tickets = append(m.Tickets, ticket)
+	}
+	return nil
+}
+
+// RecordStatus implements InsightTicketRepository.RecordStatus
+func (m *MockInsightTicketRepository) RecordStatus(ctx context.Context, tracker, key string, status domain.JiraStatus, source string, at time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.RecordStatusErr != nil {
+		return false, m.RecordStatusErr
+	}
+	ticket := m.find(tracker, key)
+	if ticket == nil {
+		return false, nil
+	}
+	if (ticket.Status == status.Name && ticket.StatusCategory == status.StatusCategory.Key) || (ticket.Status != "" && at.Before(ticket.UpdatedAt)) {
+		return false, nil
+	}
+
+	m.Transitions = append(m.Transitions, MockTicketTransition{Key: key, From: ticket.Status, To: status.Name, Source: source})
+	ticket.Status = status.Name
+	ticket.StatusCategory = status.StatusCategory.Key
+	ticket.UpdatedAt = at
+	if !ticket.Open() {
+		if ticket.ResolvedAt == nil {
+			resolved := at
+			ticket.ResolvedAt = &resolved
+		}
+	} else {
+		ticket.ResolvedAt = nil
+	}
+	return true, nil
+}
+
+// ListTickets implements InsightTicketRepository.ListTickets
+func (m *MockInsightTicketRepository) ListTickets(ctx context.Context, filter domain.InsightTicketFilter) ([]domain.InsightTicket, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var tickets []domain.InsightTicket
+	for i := len(m.Tickets) - 1; i >= 0; i-- {
+		ticket := m.Tickets[i]
+		if filter.ProductArea != "" && !strings.EqualFold(ticket.ProductArea, filter.ProductArea) {
+			continue
+		}
+		if filter.Open != nil && ticket.Open() != *filter.Open {
+			continue
+		}
+		tickets = append(tickets, ticket)
+		if filter.Limit > 0 && len(tickets) == filter.Limit {
+			break
+		}
+	}
+	return tickets, nil
+}
+
+// OpenTicketKeys implements InsightTicketRepository.OpenTicketKeys
+func (m *MockInsightTicketRepository) OpenTicketKeys(ctx context.Context, tracker string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var keys []string
+	for _, ticket := range m.Tickets {
+		if ticket.Tracker == tracker && ticket.Open() {
+			keys = append(keys, ticket.Key)
+		}
+	}
+	return keys, nil
+}
+
+// ProductAreaStats implements InsightTicketRepository.ProductAreaStats
+func (m *MockInsightTicketRepository) ProductAreaStats(ctx context.Context) ([]domain.ProductAreaTicketStats, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	byArea := make(map[string]*domain.ProductAreaTicketStats)
+	hours := make(map[string]float64)
+	for _, ticket := range m.Tickets {
+		s, ok := byArea[ticket.ProductArea]
+		if !ok {
+			s = &domain.ProductAreaTicketStats{ProductArea: ticket.ProductArea}
+			byArea[ticket.ProductArea] = s
+		}
+		s.Total++
+		if ticket.Open() {
+			s.Open++
+			continue
+		}
+		s.Resolved++
+		if ticket.ResolvedAt != nil {
+			hours[ticket.ProductArea] += ticket.ResolvedAt.Sub(ticket.CreatedAt).Hours()
+		}
+	}
+
+	stats := make([]domain.ProductAreaTicketStats, 0, len(byArea))
+	for area, s := range byArea {
+		s.ResolutionRate = float64(s.Resolved) / float64(s.Total)
+		if s.Resolved > 0 {
+			s.AvgResolutionHours = hours[area] / float64(s.Resolved)
+		}
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].ProductArea < stats[j].ProductArea })
+	return stats, nil
+}
+
+// find returns the tracked ticket with the key, if any
+func (m *MockInsightTicketRepository) find(tracker, key string) *domain.InsightTicket {
+	for i := range m.Tickets {
+		if m.Tickets[i].Tracker == tracker && m.Tickets[i].Key == key {
+			return &m.Tickets[i]
+		}
+	}
+	return nil
+}
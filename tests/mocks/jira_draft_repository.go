@@ -0,0 +1,78 @@
+package mocks
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/chuckie/goinsight/internal/domain"
+)
+
+// MockJiraDraftRepository is an in-memory implementation of JiraDraftRepository for testing
+type MockJiraDraftRepository struct {
+	mu     sync.Mutex
+	Drafts map[string]*domain.JiraDraft
+
+	// Configure errors
+	SaveDraftErr error
+}
+
+// NewMockJiraDraftRepository creates a new mock Jira draft repository
+func NewMockJiraDraftRepository() *MockJiraDraftRepository {
+	return &MockJiraDraftRepository{
+		Drafts: make(map[string]*domain.JiraDraft),
+	}
+}
+
+// SaveDraft implements JiraDraftRepository.SaveDraft
+func (m *MockJiraDraftRepository) SaveDraft(ctx context.Context, draft *domain.JiraDraft) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.SaveDraftErr != nil {
+		return m.SaveDraftErr
+	}
+	stored := *draft
+	m.Drafts[draft.ID] = &stored
+	return nil
+}
+
+// GetDraft implements JiraDraftRepository.GetDraft
+func (m *MockJiraDraftRepository) GetDraft(ctx context.Context, id string) (*domain.JiraDraft, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	draft, ok := m.Drafts[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *draft
+	copied.Tickets = append([]domain.JiraTicketSpec(nil), draft.Tickets...)
+	return &copied, nil
+}
+
+// ConfirmDraft implements JiraDraftRepository.ConfirmDraft
+func (m *MockJiraDraftRepository) ConfirmDraft(ctx context.Context, id string, tickets []domain.JiraTicketSpec) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	draft, ok := m.Drafts[id]
+	if !ok || draft.ConfirmedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	draft.ConfirmedAt = &now
+	draft.Tickets = append([]domain.JiraTicketSpec(nil), tickets...)
+	return true, nil
+}
+
+// ReopenDraft implements JiraDraftRepository.ReopenDraft
+func (m *MockJiraDraftRepository) ReopenDraft(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if draft, ok := m.Drafts[id]; ok {
+		draft.ConfirmedAt = nil
+	}
+	return nil
+}